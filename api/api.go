@@ -1,27 +1,88 @@
 package api
 
 import (
+	"context"
 	"github.com/cemalunal/sample-internship-management-api/model"
 	"github.com/gorilla/mux"
 	"log"
 	"net/http"
+	"os"
+	"time"
 )
 
 type api struct {
 	AssigneeService model.AssigneeService
 	CandidateService model.CandidateService
+	// ExportLocation is the time zone of the exported timestamps
+	ExportLocation *time.Location
+	AttachmentService model.AttachmentService
+	WebhookService model.WebhookService
+	EventStream model.EventStream
+	ReportService model.ReportService
+	// shutdown is closed when the server shuts down, which stops the event streams
+	shutdown <-chan struct{}
 }
 
-func Api(router *mux.Router, assigneeService model.AssigneeService, candidateService model.CandidateService) *mux.Router {
+// shutdownTimeout is how long the requests in progress are waited for when the server shuts down
+const shutdownTimeout = 10 * time.Second
+
+// Option configures the optional services of the api
+type Option func(a *api)
+
+// WithAttachments serves the attachments of the candidates
+func WithAttachments(attachmentService model.AttachmentService) Option {
+	return func(a *api) {
+		a.AttachmentService = attachmentService
+	}
+}
+
+// WithWebhooks serves the management of the webhooks and their deliveries
+func WithWebhooks(webhookService model.WebhookService) Option {
+	return func(a *api) {
+		a.WebhookService = webhookService
+	}
+}
+
+// WithReports serves the reports of the candidates
+func WithReports(reportService model.ReportService) Option {
+	return func(a *api) {
+		a.ReportService = reportService
+	}
+}
+
+// WithEventStream streams the events of the candidates and their meetings to the clients
+func WithEventStream(eventStream model.EventStream) Option {
+	return func(a *api) {
+		a.EventStream = eventStream
+	}
+}
+
+// Api serves the rest api on port 8080 until the context is done, and then shuts the server down gracefully
+func Api(ctx context.Context, router *mux.Router, assigneeService model.AssigneeService, candidateService model.CandidateService, options ...Option) error {
 	_api := &api{
 		AssigneeService: assigneeService,
 		CandidateService: candidateService,
+		ExportLocation: exportLocation(),
+		shutdown: ctx.Done(),
+	}
+	for _, option := range options {
+		option(_api)
 	}
 
 	router.HandleFunc("/candidates", _api.CreateCandidate).Methods(http.MethodPost)
 	router.HandleFunc("/candidates", _api.FindAllCandidates).Methods(http.MethodGet)
+	router.HandleFunc("/candidates/import", _api.ImportCandidates).Methods(http.MethodPost)
+	router.HandleFunc("/candidates/export", _api.ExportCandidates).Methods(http.MethodGet)
 	router.HandleFunc("/candidates/{id}", _api.ReadCandidate).Methods(http.MethodGet)
+	router.HandleFunc("/candidates/{id}", _api.UpdateCandidate).Methods(http.MethodPut)
 	router.HandleFunc("/candidates/{id}", _api.DeleteCandidate).Methods(http.MethodDelete)
+	router.HandleFunc("/candidates/{id}/meetings", _api.FindCandidatesMeetings).Methods(http.MethodGet)
+	if _api.AttachmentService != nil {
+		router.HandleFunc("/candidates/{id}/attachments", _api.UploadAttachment).Methods(http.MethodPost)
+		router.HandleFunc("/candidates/{id}/attachments", _api.FindCandidatesAttachments).Methods(http.MethodGet)
+		router.HandleFunc("/candidates/{id}/attachments/{attachmentId}", _api.DownloadAttachment).Methods(http.MethodGet)
+		router.HandleFunc("/candidates/{id}/attachments/{attachmentId}", _api.DeleteAttachment).Methods(http.MethodDelete)
+	}
 	router.HandleFunc("/candidates/deny/{id}", _api.DenyCandidate).Methods(http.MethodPatch)
 	router.HandleFunc("/candidates/accept/{id}", _api.AcceptCandidate).Methods(http.MethodPatch)
 	router.HandleFunc("/candidates/assigneeId/{assigneeId}", _api.FindAssigneesCandidates).Methods(http.MethodGet)
@@ -29,10 +90,70 @@ func Api(router *mux.Router, assigneeService model.AssigneeService, candidateSer
 	router.HandleFunc("/assignees", _api.FindAllAssignees).Methods(http.MethodGet)
 	router.HandleFunc("/assignees/name/{name}", _api.FindAssigneeIDByName).Methods(http.MethodGet)
 	router.HandleFunc("/assignees/department/{department}", _api.FindAllAssigneesByDepartment).Methods(http.MethodGet)
+	router.HandleFunc("/assignees/{id}/calendar-token", _api.CreateCalendarToken).Methods(http.MethodPost)
+	router.HandleFunc("/assignees/{id}/calendar.ics", _api.DownloadAssigneesCalendar).Methods(http.MethodGet)
 	router.HandleFunc("/meetings/arrange", _api.ArrangeMeeting).Methods(http.MethodPost)
 	router.HandleFunc("/meetings/complete/{candidateId}", _api.CompleteMeeting).Methods(http.MethodPost)
+	router.HandleFunc("/meetings/overdue", _api.FindOverdueMeetings).Methods(http.MethodGet)
+	router.HandleFunc("/meetings/{id}.ics", _api.DownloadMeetingCalendar).Methods(http.MethodGet)
+	router.HandleFunc("/meetings/{candidateId}", _api.RescheduleMeeting).Methods(http.MethodPatch)
+	router.HandleFunc("/meetings/{candidateId}", _api.CancelMeeting).Methods(http.MethodDelete)
+	router.HandleFunc("/meetings/{candidateId}/no-show", _api.RecordNoShow).Methods(http.MethodPost)
+	router.HandleFunc("/meetings/{id}/feedback", _api.RecordMeetingFeedback).Methods(http.MethodPut)
+	if _api.WebhookService != nil {
+		router.HandleFunc("/webhooks", _api.CreateWebhook).Methods(http.MethodPost)
+		router.HandleFunc("/webhooks", _api.FindAllWebhooks).Methods(http.MethodGet)
+		router.HandleFunc("/webhooks/{id}", _api.ReadWebhook).Methods(http.MethodGet)
+		router.HandleFunc("/webhooks/{id}", _api.UpdateWebhook).Methods(http.MethodPut)
+		router.HandleFunc("/webhooks/{id}", _api.DeleteWebhook).Methods(http.MethodDelete)
+		router.HandleFunc("/webhooks/{id}/deliveries", _api.FindWebhooksDeliveries).Methods(http.MethodGet)
+	}
+	if _api.EventStream != nil {
+		router.HandleFunc("/events/stream", _api.StreamEvents).Methods(http.MethodGet)
+	}
+	if _api.ReportService != nil {
+		router.HandleFunc("/reports/funnel", _api.FunnelReport).Methods(http.MethodGet)
+		router.HandleFunc("/reports/time-to-hire", _api.TimeToHireReport).Methods(http.MethodGet)
+		router.HandleFunc("/reports/assignees", _api.AssigneeWorkloadReport).Methods(http.MethodGet)
+	}
+	router.Use(Tracing)
 	router.Use(RequestLogger)
 
-	log.Fatalln(http.ListenAndServe(":8080", router))
-	return router
+	return serve(ctx, &http.Server{Addr: ":8080", Handler: router})
+}
+
+// serve listens until the context is done, and then waits for the requests in progress to complete for at most
+// shutdownTimeout. The event streams are stopped right away, since their clients reconnect and resume them.
+func serve(ctx context.Context, server *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down the rest api")
+	// the requests in progress are waited for with a new context, since the context of the server is done
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// exportLocation loads the time zone of the exported timestamps from the EXPORT_TIMEZONE environment variable
+func exportLocation() *time.Location {
+	timezone := os.Getenv("EXPORT_TIMEZONE")
+	if timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Fatalf("Unknown EXPORT_TIMEZONE %s. Error is: %s", timezone, err)
+	}
+
+	return location
 }
@@ -2,57 +2,123 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/calendar"
 	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/spreadsheet"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
+	"io"
 	"log"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// validate reports the invalid fields with their json names
+var validate = newValidator()
+
 // CreateCandidate creates candidate by given request body
 func (a *api) CreateCandidate(w http.ResponseWriter, req *http.Request) {
 	// create candidate model from request body
 	var candidate model.Candidate
 	err := json.NewDecoder(req.Body).Decode(&candidate)
 	if err != nil {
-		a.ReturnBadRequest(w, err)
-		return
-	}
-
-	// try to validate the fields of the candidate
-	if ok, err := a.IsRequestValid(candidate); !ok {
-		a.ReturnBadRequest(w, err)
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
 		return
 	}
 
-	// Check given department is in the existing departments
-	// and do not allow to create candidate if not
-	departmentIsValid := a.CheckDepartmentExists(candidate.Department)
-	if !departmentIsValid {
-		a.ReturnBadRequest(w, model.ErrDepartmentDoesNotExist)
+	// try to validate the fields and the department of the candidate
+	if err := a.ValidateCandidate(candidate); err != nil {
+		a.ReturnError(w, err)
 		return
 	}
 
 	createdCandidate, err := a.CandidateService.CreateCandidate(req.Context(), candidate)
 	if err != nil {
-		if err == model.ErrCandidateAlreadyExists {
-			a.ReturnBadRequest(w, err)
-			return
-		}
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", candidateETag(createdCandidate))
 	a.ReturnCreated(w, "Successfully created candidate", createdCandidate)
 	log.Println("Successfully created candidate with id: ", createdCandidate.ID)
 }
 
+// ImportCandidates creates the candidates in an uploaded CSV or XLSX file.
+// Each row goes through the same validation as CreateCandidate, and the outcome of each row is reported.
+func (a *api) ImportCandidates(w http.ResponseWriter, req *http.Request) {
+	request, err := parseImportRequest(w, req)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+	defer request.file.Close()
+
+	// the header and one row more than the limit are read, so the longer spreadsheets are rejected without reading them all
+	rows, err := spreadsheet.ReadRows(request.file, request.format, maxImportRows+2)
+	if err != nil {
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
+		return
+	}
+
+	candidates, report, err := a.readImportedCandidates(rows, request.mapping)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+	report.DryRun = request.dryRun
+
+	// only the valid rows are sent to the service, its results are matched to the rows in the same order
+	results := a.CandidateService.ImportCandidates(req.Context(), candidates, request.dryRun)
+	i := 0
+	for r := range report.Rows {
+		if report.Rows[r].Status == model.ImportFailed {
+			continue
+		}
+
+		result := results[i]
+		i++
+		if result.Err != nil {
+			report.Rows[r].Status = model.ImportFailed
+			report.Rows[r].Error = importError(result.Err)
+			continue
+		}
+		if request.dryRun {
+			report.Rows[r].Status = model.ImportValid
+		} else {
+			report.Rows[r].Status = model.ImportCreated
+			report.Rows[r].CandidateID = result.Candidate.ID
+		}
+	}
+
+	for _, row := range report.Rows {
+		if row.Status == model.ImportFailed {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+	}
+
+	a.ReturnOk(w, "Successfully imported candidates", report)
+	log.Printf("Imported candidates, %d rows succeeded and %d rows failed (dry run: %t)\n", report.Succeeded, report.Failed, report.DryRun)
+}
+
 // FindAllCandidates finds all candidates that are available in the system
+// The candidates can be filtered by the status, department, university and assignee query parameters
 func (a *api) FindAllCandidates(w http.ResponseWriter, req *http.Request) {
-	candidates, err := a.CandidateService.FindAllCandidates(req.Context())
+	filter, err := candidateFilter(req)
 	if err != nil {
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
+		return
+	}
+
+	candidates, err := a.CandidateService.FindCandidates(req.Context(), filter)
+	if err != nil {
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -60,6 +126,51 @@ func (a *api) FindAllCandidates(w http.ResponseWriter, req *http.Request) {
 	log.Println("Successfully fetched all candidates.")
 }
 
+// ExportCandidates writes the candidates that match the same filters as FindAllCandidates as CSV, XLSX or JSON Lines.
+// The candidates are written as they are read from the repository, so the export is never kept in memory as a whole.
+func (a *api) ExportCandidates(w http.ResponseWriter, req *http.Request) {
+	filter, err := candidateFilter(req)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = spreadsheet.CSV
+	}
+	if format != spreadsheet.CSV && format != spreadsheet.XLSX && format != jsonLines {
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails("format must be csv, xlsx or jsonl"))
+		return
+	}
+
+	location := a.ExportLocation
+	if timezone := req.URL.Query().Get("timezone"); timezone != "" {
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			a.ReturnError(w, model.ErrMalformedRequest.WithDetails("unknown timezone: "+timezone))
+			return
+		}
+	}
+
+	exporter := newCandidateExporter(w, format, location)
+	err = a.CandidateService.ExportCandidates(req.Context(), filter, exporter.write)
+	if err == nil {
+		err = exporter.close()
+	}
+	if err != nil {
+		// the error can only be reported if nothing is written yet
+		if !exporter.started {
+			a.ReturnError(w, err)
+			return
+		}
+		log.Println("Couldn't complete the export of candidates: ", err)
+		return
+	}
+
+	log.Printf("Successfully exported %d candidates as %s\n", exporter.count, format)
+}
+
 // ReadCandidate finds a candidate by given id
 func (a *api) ReadCandidate(w http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
@@ -67,14 +178,68 @@ func (a *api) ReadCandidate(w http.ResponseWriter, req *http.Request) {
 
 	candidate, err := a.CandidateService.ReadCandidate(req.Context(), id)
 	if err != nil {
-		a.ReturnBadRequest(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", candidateETag(candidate))
 	a.ReturnOk(w, "Successfully read candidate", candidate)
 	log.Println("Successfully read candidate with id: ", id)
 }
 
+// UpdateCandidate updates a candidate by given id and request body
+// The request must have an If-Match header with the ETag of the candidate that was read
+func (a *api) UpdateCandidate(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	// create candidate model from request body
+	var candidate model.Candidate
+	err := json.NewDecoder(req.Body).Decode(&candidate)
+	if err != nil {
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
+		return
+	}
+
+	// try to validate the fields and the department of the candidate
+	if err := a.ValidateCandidate(candidate); err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	// the version of the candidate is taken from the If-Match header, not from the body
+	ifMatch := req.Header.Get("If-Match")
+	if ifMatch == "" {
+		a.ReturnError(w, model.ErrIfMatchRequired)
+		return
+	}
+	if ifMatch == "*" {
+		current, err := a.CandidateService.ReadCandidate(req.Context(), id)
+		if err != nil {
+			a.ReturnError(w, err)
+			return
+		}
+		candidate.Version = current.Version
+	} else {
+		version, ok := parseETag(ifMatch)
+		if !ok {
+			a.ReturnError(w, model.ErrCandidateModified)
+			return
+		}
+		candidate.Version = version
+	}
+
+	updatedCandidate, err := a.CandidateService.UpdateCandidate(req.Context(), id, candidate)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", candidateETag(updatedCandidate))
+	a.ReturnOk(w, "Successfully updated candidate", updatedCandidate)
+	log.Println("Successfully updated candidate with id: ", id)
+}
+
 // DeleteCandidate deletes a candidate by given id
 func (a *api) DeleteCandidate(w http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
@@ -82,11 +247,7 @@ func (a *api) DeleteCandidate(w http.ResponseWriter, req *http.Request) {
 
 	err := a.CandidateService.DeleteCandidate(req.Context(), id)
 	if err != nil {
-		if err == model.ErrCandidateDoesNotExist {
-			a.ReturnBadRequest(w, err)
-			return
-		}
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -94,6 +255,86 @@ func (a *api) DeleteCandidate(w http.ResponseWriter, req *http.Request) {
 	log.Println("Successfully deleted candidate with id: ", id)
 }
 
+// UploadAttachment stores a CV, transcript or cover letter of the candidate from the file field of a multipart form
+func (a *api) UploadAttachment(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	upload, err := parseAttachmentUpload(w, req)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+	defer upload.file.Close()
+
+	upload.attachment.CandidateID = id
+	attachment, err := a.AttachmentService.UploadAttachment(req.Context(), upload.attachment, upload.content)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnCreated(w, "Successfully uploaded attachment", attachment)
+	log.Println("Successfully uploaded attachment with id: ", attachment.ID)
+}
+
+// FindCandidatesAttachments lists the attachments of the candidate by given id
+func (a *api) FindCandidatesAttachments(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	attachments, err := a.AttachmentService.FindCandidatesAttachments(req.Context(), id)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully found attachments of candidate", attachments)
+	log.Println("Successfully found attachments of candidate with id: ", id)
+}
+
+// DownloadAttachment writes the content of the attachment with the headers of a file download
+func (a *api) DownloadAttachment(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+	attachmentId := params["attachmentId"]
+
+	attachment, content, err := a.AttachmentService.DownloadAttachment(req.Context(), id, attachmentId)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", contentDisposition(attachment.FileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		log.Println("Couldn't complete the download of attachment: ", err)
+		return
+	}
+
+	log.Println("Successfully downloaded attachment with id: ", attachmentId)
+}
+
+// DeleteAttachment deletes an attachment of the candidate by given ids
+func (a *api) DeleteAttachment(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+	attachmentId := params["attachmentId"]
+
+	err := a.AttachmentService.DeleteAttachment(req.Context(), id, attachmentId)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully deleted attachment", attachmentId)
+	log.Println("Successfully deleted attachment with id: ", attachmentId)
+}
+
 // DenyCandidate denies a candidate by given id
 func (a *api) DenyCandidate(w http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
@@ -101,11 +342,7 @@ func (a *api) DenyCandidate(w http.ResponseWriter, req *http.Request) {
 
 	err := a.CandidateService.DenyCandidate(req.Context(), id)
 	if err != nil {
-		if err == model.ErrCandidateDoesNotExist {
-			a.ReturnBadRequest(w, err)
-			return
-		}
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -120,11 +357,7 @@ func (a *api) AcceptCandidate(w http.ResponseWriter, req *http.Request) {
 
 	err := a.CandidateService.AcceptCandidate(req.Context(), id)
 	if err != nil {
-		if err == model.ErrCandidateDoesNotExist || err == model.ErrMeetingCountNotEnough {
-			a.ReturnBadRequest(w, err)
-			return
-		}
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -139,11 +372,7 @@ func (a *api) FindAssigneesCandidates(w http.ResponseWriter, req *http.Request)
 
 	candidates, err := a.CandidateService.FindAssigneesCandidates(req.Context(), assigneeId)
 	if err != nil {
-		if err == model.ErrAssigneeDoesNotExist {
-			a.ReturnBadRequest(w, err)
-			return
-		}
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -155,7 +384,7 @@ func (a *api) FindAssigneesCandidates(w http.ResponseWriter, req *http.Request)
 func (a *api) FindAllAssignees(w http.ResponseWriter, req *http.Request) {
 	assignees, err := a.AssigneeService.FindAllAssignees(req.Context())
 	if err != nil {
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -169,13 +398,13 @@ func (a *api) CreateAssignee(w http.ResponseWriter, req *http.Request) {
 	var assignee model.Assignee
 	err := json.NewDecoder(req.Body).Decode(&assignee)
 	if err != nil {
-		a.ReturnBadRequest(w, err)
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
 		return
 	}
 
 	// try to validate the fields of the assignee
-	if ok, err := a.IsRequestValid(assignee); !ok {
-		a.ReturnBadRequest(w, err)
+	if err := a.ValidateRequest(assignee); err != nil {
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -183,13 +412,13 @@ func (a *api) CreateAssignee(w http.ResponseWriter, req *http.Request) {
 	// and do not allow to create assignee if not
 	departmentIsValid := a.CheckDepartmentExists(assignee.Department)
 	if !departmentIsValid {
-		a.ReturnBadRequest(w, model.ErrDepartmentDoesNotExist)
+		a.ReturnError(w, model.ErrDepartmentDoesNotExist)
 		return
 	}
 
 	createdAssignee, err := a.AssigneeService.CreateAssignee(req.Context(), assignee)
 	if err != nil {
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -202,9 +431,9 @@ func (a *api) FindAssigneeIDByName(w http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
 	assigneeName := params["name"]
 
-	id := a.AssigneeService.FindAssigneeIDByName(req.Context(), assigneeName)
-	if id == "" {
-		a.ReturnBadRequest(w, model.ErrAssigneeDoesNotExist)
+	id, err := a.AssigneeService.FindAssigneeIDByName(req.Context(), assigneeName)
+	if err != nil {
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -221,13 +450,13 @@ func (a *api) FindAllAssigneesByDepartment(w http.ResponseWriter, req *http.Requ
 	// and do not allow to create candidate if not
 	departmentIsValid := a.CheckDepartmentExists(department)
 	if !departmentIsValid {
-		a.ReturnBadRequest(w, model.ErrDepartmentDoesNotExist)
+		a.ReturnError(w, model.ErrDepartmentDoesNotExist)
 		return
 	}
 
 	assignees, err := a.AssigneeService.FindAllAssigneesByDepartment(req.Context(), department)
 	if err != nil {
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -235,28 +464,73 @@ func (a *api) FindAllAssigneesByDepartment(w http.ResponseWriter, req *http.Requ
 	log.Println("Successfully fetched all assignees by department: ", department)
 }
 
+// CreateCalendarToken creates a new secret token for the calendar feed of the assignee by given id
+// The previous token of the assignee stops working, and the token cannot be read again.
+func (a *api) CreateCalendarToken(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	token, err := a.AssigneeService.CreateCalendarToken(req.Context(), id)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully created calendar token", model.CalendarToken{Token: token, URL: calendarFeedURL(req, id, token)})
+	log.Println("Successfully created calendar token of assignee with id: ", id)
+}
+
+// DownloadAssigneesCalendar serves the upcoming meetings of the assignee by given id as an iCalendar feed
+// The feed is secured with the calendar token of the assignee, which is given in the token query parameter,
+// since the calendar applications cannot send the other credentials.
+func (a *api) DownloadAssigneesCalendar(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	assignee, meetings, err := a.CandidateService.FindAssigneesCalendar(req.Context(), id, req.URL.Query().Get("token"))
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	feed := calendar.Feed(fmt.Sprintf("Interviews of %s", assignee.Name), meetings)
+	etag := calendarFeedETag(feed)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(req.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", calendar.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(feed)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(feed); err != nil {
+		log.Println("Couldn't write the calendar of assignee: ", err)
+		return
+	}
+
+	log.Printf("Successfully downloaded calendar of assignee with id: %s, %d meetings\n", id, len(meetings))
+}
+
 // ArrangeMeeting arranges a meeting with the given candidate on the given date
 func (a *api) ArrangeMeeting(w http.ResponseWriter, req *http.Request) {
 	// create meeting model from request body
 	var meeting model.Meeting
 	err := json.NewDecoder(req.Body).Decode(&meeting)
 	if err != nil {
-		a.ReturnBadRequest(w, err)
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
 		return
 	}
 	// try to validate the fields of the meeting
-	if ok, err := a.IsRequestValid(meeting); !ok {
-		a.ReturnBadRequest(w, err)
+	if err := a.ValidateRequest(meeting); err != nil {
+		a.ReturnError(w, err)
 		return
 	}
 
 	err = a.CandidateService.ArrangeMeeting(req.Context(), meeting.CandidateID, meeting.NextMeetingTime)
 	if err != nil {
-		if err == model.ErrCandidateDoesNotExist || err == model.ErrAssigneeDoesNotExist {
-			a.ReturnBadRequest(w, err)
-			return
-		}
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -265,6 +539,127 @@ func (a *api) ArrangeMeeting(w http.ResponseWriter, req *http.Request) {
 		meeting.CandidateID, meeting.NextMeetingTime)
 }
 
+// RescheduleMeeting moves the arranged meeting of a candidate by given candidate id to the given date
+func (a *api) RescheduleMeeting(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	candidateId := params["candidateId"]
+
+	var reschedule model.MeetingReschedule
+	err := json.NewDecoder(req.Body).Decode(&reschedule)
+	if err != nil {
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
+		return
+	}
+	if err := a.ValidateRequest(reschedule); err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	err = a.CandidateService.RescheduleMeeting(req.Context(), candidateId, reschedule.NextMeetingTime)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully rescheduled meeting with candidate to given date", reschedule)
+	log.Printf("Successfully rescheduled meeting with candidate with id: %s, to date %s\n",
+		candidateId, reschedule.NextMeetingTime)
+}
+
+// CancelMeeting cancels the arranged meeting of a candidate by given candidate id for the given reason
+func (a *api) CancelMeeting(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	candidateId := params["candidateId"]
+
+	var cancellation model.MeetingCancellation
+	err := json.NewDecoder(req.Body).Decode(&cancellation)
+	if err != nil {
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
+		return
+	}
+	if err := a.ValidateRequest(cancellation); err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	err = a.CandidateService.CancelMeeting(req.Context(), candidateId, cancellation.Reason)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully cancelled meeting with candidate", candidateId)
+	log.Println("Successfully cancelled meeting with candidate with id: ", candidateId)
+}
+
+// RecordNoShow records that a candidate by given candidate id has missed the arranged meeting
+func (a *api) RecordNoShow(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	candidateId := params["candidateId"]
+
+	err := a.CandidateService.RecordNoShow(req.Context(), candidateId)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully recorded no-show of candidate", candidateId)
+	log.Println("Successfully recorded no-show of candidate with id: ", candidateId)
+}
+
+// RecordMeetingFeedback records the feedback score of a completed meeting by given meeting id
+func (a *api) RecordMeetingFeedback(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	var feedback model.MeetingFeedback
+	err := json.NewDecoder(req.Body).Decode(&feedback)
+	if err != nil {
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
+		return
+	}
+	if err := a.ValidateRequest(feedback); err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	err = a.CandidateService.RecordMeetingFeedback(req.Context(), id, feedback.Score)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully recorded feedback of meeting", id)
+	log.Println("Successfully recorded feedback of meeting with id: ", id)
+}
+
+// FindCandidatesMeetings finds the history of the meetings of a candidate by given candidate id
+func (a *api) FindCandidatesMeetings(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	meetings, err := a.CandidateService.FindCandidatesMeetings(req.Context(), id)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully found meetings of candidate", meetings)
+	log.Println("Successfully found meetings of candidate with id: ", id)
+}
+
+// FindOverdueMeetings finds the meetings whose time has passed without being completed
+func (a *api) FindOverdueMeetings(w http.ResponseWriter, req *http.Request) {
+	meetings, err := a.CandidateService.FindOverdueMeetings(req.Context())
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully found overdue meetings", meetings)
+	log.Println("Successfully found overdue meetings")
+}
+
 // CompleteMeeting completes a meeting of a candidate by given candidate id
 func (a *api) CompleteMeeting(w http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
@@ -272,11 +667,7 @@ func (a *api) CompleteMeeting(w http.ResponseWriter, req *http.Request) {
 
 	err := a.CandidateService.CompleteMeeting(req.Context(), candidateId)
 	if err != nil {
-		if err == model.ErrCandidateDoesNotExist || err == model.ErrArrangedMeetingDoesNotExist {
-			a.ReturnBadRequest(w, err)
-			return
-		}
-		a.ReturnInternalServerError(w, err)
+		a.ReturnError(w, err)
 		return
 	}
 
@@ -284,6 +675,261 @@ func (a *api) CompleteMeeting(w http.ResponseWriter, req *http.Request) {
 	log.Println("Successfully completed meeting with candidate with id: ", candidateId)
 }
 
+// DownloadMeetingCalendar serves the meeting by given id as an iCalendar file, to be imported to the calendar applications
+func (a *api) DownloadMeetingCalendar(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	details, err := a.CandidateService.ReadMeeting(req.Context(), id)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	event := calendar.Event(details, "", "", time.Now())
+	w.Header().Set("Content-Type", calendar.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(event)))
+	w.Header().Set("Content-Disposition", contentDisposition("meeting-"+id+".ics"))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(event); err != nil {
+		log.Println("Couldn't write the calendar of meeting: ", err)
+		return
+	}
+
+	log.Println("Successfully downloaded calendar of meeting with id: ", id)
+}
+
+// CreateWebhook creates a webhook by given request body, the secret of the webhook is only returned in this response
+func (a *api) CreateWebhook(w http.ResponseWriter, req *http.Request) {
+	var webhook model.Webhook
+	err := json.NewDecoder(req.Body).Decode(&webhook)
+	if err != nil {
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
+		return
+	}
+
+	if err := a.ValidateWebhook(webhook); err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	createdWebhook, err := a.WebhookService.CreateWebhook(req.Context(), webhook)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnCreated(w, "Successfully created webhook", createdWebhook)
+	log.Println("Successfully created webhook with id: ", createdWebhook.ID)
+}
+
+// FindAllWebhooks lists all webhooks
+func (a *api) FindAllWebhooks(w http.ResponseWriter, req *http.Request) {
+	webhooks, err := a.WebhookService.FindAllWebhooks(req.Context())
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully fetched all webhooks", webhooks)
+	log.Println("Successfully fetched all webhooks.")
+}
+
+// ReadWebhook reads a webhook by given id
+func (a *api) ReadWebhook(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	webhook, err := a.WebhookService.ReadWebhook(req.Context(), id)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully fetched webhook", webhook)
+	log.Println("Successfully fetched webhook with id: ", id)
+}
+
+// UpdateWebhook updates the url, the events and the pausing of a webhook by given id and request body
+func (a *api) UpdateWebhook(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	var webhook model.Webhook
+	err := json.NewDecoder(req.Body).Decode(&webhook)
+	if err != nil {
+		a.ReturnError(w, model.ErrMalformedRequest.WithDetails(err.Error()))
+		return
+	}
+
+	if err := a.ValidateWebhook(webhook); err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	updatedWebhook, err := a.WebhookService.UpdateWebhook(req.Context(), id, webhook)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully updated webhook", updatedWebhook)
+	log.Println("Successfully updated webhook with id: ", id)
+}
+
+// DeleteWebhook deletes a webhook by given id
+func (a *api) DeleteWebhook(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	err := a.WebhookService.DeleteWebhook(req.Context(), id)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully deleted webhook", id)
+	log.Println("Successfully deleted webhook with id: ", id)
+}
+
+// FindWebhooksDeliveries lists the latest deliveries of the webhook by given id
+func (a *api) FindWebhooksDeliveries(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	id := params["id"]
+
+	deliveries, err := a.WebhookService.FindWebhooksDeliveries(req.Context(), id)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully found deliveries of webhook", deliveries)
+	log.Println("Successfully found deliveries of webhook with id: ", id)
+}
+
+// StreamEvents streams the changes of the candidates and their meetings as server-sent events until the client disconnects
+// or the server shuts down. The events can be filtered by the department of the candidates and by the assignee,
+// and the stream is resumed after the event given with the Last-Event-ID header, or with the last_event_id parameter.
+func (a *api) StreamEvents(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	filter := model.EventFilter{Department: query.Get("department"), AssigneeID: query.Get("assignee")}
+	if filter.Department != "" && !a.CheckDepartmentExists(filter.Department) {
+		a.ReturnError(w, model.ErrDepartmentDoesNotExist)
+		return
+	}
+	lastEventID := req.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("last_event_id")
+	}
+
+	events, unsubscribe := a.EventStream.Subscribe(filter, lastEventID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// the proxies must not buffer the events
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	log.Println("Started streaming events with filter: ", filter)
+
+	stream := newEventStreamWriter(w)
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	err := stream.retry(streamRetryDelay)
+	for err == nil {
+		select {
+		case <-req.Context().Done():
+			log.Println("Stopped streaming events, the client has disconnected")
+			return
+		case <-a.shutdown:
+			log.Println("Stopped streaming events, the server is shutting down")
+			return
+		case <-heartbeat.C:
+			err = stream.heartbeat()
+		case event, ok := <-events:
+			if !ok {
+				// the client has fallen behind the events, it reconnects and resumes its stream
+				log.Println("Stopped streaming events, the client has fallen behind")
+				return
+			}
+			err = stream.send(event)
+		}
+	}
+
+	log.Println("Couldn't stream the events: ", err)
+}
+
+// FunnelReport counts the candidates by their status and their completed meetings, broken down by department and university
+func (a *api) FunnelReport(w http.ResponseWriter, req *http.Request) {
+	report, err := a.ReportService.FunnelReport(req.Context())
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully created funnel report", report)
+	log.Println("Successfully created funnel report")
+}
+
+// TimeToHireReport finds the median and the 90th percentile of the times from the application of the candidates to their decision
+func (a *api) TimeToHireReport(w http.ResponseWriter, req *http.Request) {
+	report, err := a.ReportService.TimeToHireReport(req.Context())
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully created time to hire report", report)
+	log.Println("Successfully created time to hire report")
+}
+
+// AssigneeWorkloadReport finds the upcoming meetings, the completed interviews and the open candidates of each assignee,
+// the assignees can be filtered by their department and the interviews by the time they are completed
+func (a *api) AssigneeWorkloadReport(w http.ResponseWriter, req *http.Request) {
+	filter, err := a.workloadFilter(req)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	report, err := a.ReportService.AssigneeWorkloadReport(req.Context(), filter)
+	if err != nil {
+		a.ReturnError(w, err)
+		return
+	}
+
+	a.ReturnOk(w, "Successfully created assignee workload report", report)
+	log.Println("Successfully created assignee workload report")
+}
+
+// workloadFilter reads the department of the assignees and the RFC 3339 time range of the completed interviews from the query
+func (a *api) workloadFilter(req *http.Request) (model.WorkloadFilter, error) {
+	query := req.URL.Query()
+	filter := model.WorkloadFilter{Department: query.Get("department")}
+	if filter.Department != "" && !a.CheckDepartmentExists(filter.Department) {
+		return filter, model.ErrDepartmentDoesNotExist
+	}
+
+	for _, field := range []struct {
+		name string
+		time *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := query.Get(field.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, model.ErrValidationFailed.WithDetails([]model.FieldError{
+				{Field: field.name, Rule: "datetime", Message: field.name + " must be an RFC 3339 time"},
+			})
+		}
+		*field.time = parsed
+	}
+
+	return filter, nil
+}
+
 // EncodeApiResponse is a helper function to create response body as json
 func (a *api) EncodeApiResponse(w http.ResponseWriter, response model.ApiResponse) {
 	err := json.NewEncoder(w).Encode(response)
@@ -293,20 +939,16 @@ func (a *api) EncodeApiResponse(w http.ResponseWriter, response model.ApiRespons
 	}
 }
 
-// ReturnInternalServerError is a helper function to return Internal Server Error response with given error message
-func (a *api) ReturnInternalServerError(w http.ResponseWriter, err error) {
-	var response model.ApiResponse
-	log.Println(err)
-	response, w = model.GetInternalServerErrorResponse(w, err.Error())
-
-	a.EncodeApiResponse(w, response)
-}
-
-// ReturnBadRequest is a helper function to return Bad Request response with given error message
-func (a *api) ReturnBadRequest(w http.ResponseWriter, err error) {
+// ReturnError is a helper function to map the given error to an error response.
+// Errors that are not model.Error are reported as Internal Server Error without exposing their message
+func (a *api) ReturnError(w http.ResponseWriter, err error) {
 	var response model.ApiResponse
+	var apiErr *model.Error
+	if !errors.As(err, &apiErr) {
+		apiErr = model.NewInternalError(err)
+	}
 	log.Println(err)
-	response, w = model.GetBadRequestResponse(w, err.Error())
+	response, w = model.GetErrorResponse(w, apiErr)
 
 	a.EncodeApiResponse(w, response)
 }
@@ -327,15 +969,65 @@ func (a *api) ReturnOk(w http.ResponseWriter, message string, responseBody inter
 	a.EncodeApiResponse(w, response)
 }
 
-// IsRequestValid is a helper function to validate request body
-func (a *api) IsRequestValid(body interface{}) (bool, error) {
-	v := validator.New()
-	err := v.Struct(body)
-	if err != nil {
-		return false, err
+// ValidateRequest is a helper function to validate request body
+// It returns a validation error that lists every invalid field
+func (a *api) ValidateRequest(body interface{}) error {
+	err := validate.Struct(body)
+	if err == nil {
+		return nil
 	}
 
-	return true, nil
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return model.NewInternalError(err)
+	}
+
+	fieldErrors := make([]model.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fieldErrors = append(fieldErrors, model.FieldError{
+			Field: fieldErr.Field(),
+			Rule: fieldErr.Tag(),
+			Message: validationMessage(fieldErr),
+		})
+	}
+
+	return model.ErrValidationFailed.WithDetails(fieldErrors)
+}
+
+// ValidateCandidate is a helper function to validate the fields of a candidate, and check its department exists.
+// Candidates are not allowed to be created or updated with a department that does not exist
+func (a *api) ValidateCandidate(candidate model.Candidate) error {
+	if err := a.ValidateRequest(candidate); err != nil {
+		return err
+	}
+
+	if !a.CheckDepartmentExists(candidate.Department) {
+		return model.ErrDepartmentDoesNotExist
+	}
+
+	return nil
+}
+
+// ValidateWebhook is a helper function to validate the fields of a webhook, and check its events exist
+func (a *api) ValidateWebhook(webhook model.Webhook) error {
+	if err := a.ValidateRequest(webhook); err != nil {
+		return err
+	}
+
+	events := model.GetWebhookEventsAsArray()
+	for _, event := range webhook.Events {
+		eventIsValid := false
+		for i := 0; i < len(events); i++ {
+			if events[i] == event {
+				eventIsValid = true
+			}
+		}
+		if !eventIsValid {
+			return model.ErrWebhookEventDoesNotExist.WithDetails(event)
+		}
+	}
+
+	return nil
 }
 
 // CheckDepartmentExists is a helper function to check the given department exists in the system
@@ -350,3 +1042,50 @@ func (a *api) CheckDepartmentExists(department string) bool {
 
 	return departmentIsValid
 }
+
+// candidateETag is a helper function to create a strong entity tag from the version of the candidate
+func candidateETag(candidate model.Candidate) string {
+	return strconv.Quote(strconv.FormatInt(candidate.Version, 10))
+}
+
+// parseETag is a helper function to get the candidate version from a strong entity tag
+func parseETag(etag string) (int64, bool) {
+	unquoted, err := strconv.Unquote(strings.TrimSpace(etag))
+	if err != nil {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return version, true
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	return v
+}
+
+// validationMessage creates a human readable message for a failed validation rule
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fieldErr.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fieldErr.Field())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", fieldErr.Field(), fieldErr.Param())
+	default:
+		return fmt.Sprintf("%s does not satisfy the %s rule", fieldErr.Field(), fieldErr.Tag())
+	}
+}
@@ -1,8 +1,22 @@
 package api
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/model/mocks"
+	"github.com/cemalunal/sample-internship-management-api/spreadsheet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestApi_CreateCandidate(t *testing.T) {
@@ -18,7 +32,7 @@ func TestApi_CreateCandidate(t *testing.T) {
 		candidate := mockCandidateModel()
 		candidate.Department = "test"
 		jsonCandidate, _ := json.Marshal(candidate)
-		sendPostAndExpectBadRequest(t, router, "/candidates", jsonCandidate)
+		sendPostAndExpectUnprocessableEntity(t, router, "/candidates", jsonCandidate)
 	})
 
 	t.Run("email-is-invalid", func(t *testing.T) {
@@ -26,14 +40,158 @@ func TestApi_CreateCandidate(t *testing.T) {
 		candidate := mockCandidateModel()
 		candidate.Email = "invalidEmail"
 		jsonCandidate, _ := json.Marshal(candidate)
-		sendPostAndExpectBadRequest(t, router, "/candidates", jsonCandidate)
+		response := sendRequest(router, "POST", "/candidates", jsonCandidate)
+
+		var body struct {
+			ErrorCode string             `json:"error_code"`
+			Details   []model.FieldError `json:"details"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		assert.Equal(t, 422, response.Code)
+		assert.Equal(t, model.ErrValidationFailed.Code, body.ErrorCode)
+		assert.Equal(t, []model.FieldError{
+			{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+		}, body.Details)
+	})
+
+	t.Run("language-is-not-supported", func(t *testing.T) {
+		router := createCandidateSuccessRouter()
+		candidate := mockCandidateModel()
+		candidate.Language = "de"
+		jsonCandidate, _ := json.Marshal(candidate)
+		response := sendRequest(router, "POST", "/candidates", jsonCandidate)
+
+		var body struct {
+			Details []model.FieldError `json:"details"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		assert.Equal(t, 422, response.Code)
+		assert.Equal(t, []model.FieldError{
+			{Field: "language", Rule: "oneof", Message: "language must be one of en tr"},
+		}, body.Details)
+	})
+
+	t.Run("malformed-body", func(t *testing.T) {
+		router := createCandidateSuccessRouter()
+		sendPostAndExpectBadRequest(t, router, "/candidates", []byte("{"))
 	})
 
 	t.Run("candidate-already-exists", func(t *testing.T) {
 		router := createCandidateAlreadyExistsRouter()
 		candidate := mockCandidateModel()
 		jsonCandidate, _ := json.Marshal(candidate)
-		sendPostAndExpectBadRequest(t, router, "/candidates", jsonCandidate)
+		sendPostAndExpectConflict(t, router, "/candidates", jsonCandidate)
+	})
+}
+
+func TestApi_ImportCandidates(t *testing.T) {
+	csv := []byte("Ad,Soyad,E-posta,Department,University,Experience\n" +
+		"Ayse,Kaya,ayse@e.com,Design,METU,yes\n" +
+		"Ali,Veli,invalidEmail,Design,METU,no\n" +
+		"\n" +
+		"Can,Demir,taken@e.com,Development,Bilkent,\n" +
+		"Elif,Aydin,elif@e.com,test,METU,false\n")
+	mapping := `{"first_name": "Ad", "last_name": "Soyad", "email": "E-posta"}`
+
+	decodeReport := func(response *httptest.ResponseRecorder) model.CandidateImportReport {
+		var body struct {
+			Data model.CandidateImportReport `json:"data"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		return body.Data
+	}
+
+	t.Run("success", func(t *testing.T) {
+		router := importCandidatesRouter()
+		response := sendMultipartRequest(router, "/candidates/import", "fair.csv", csv, map[string]string{"mapping": mapping})
+
+		report := decodeReport(response)
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, 4, report.Total)
+		assert.Equal(t, 1, report.Succeeded)
+		assert.Equal(t, 3, report.Failed)
+		assert.Equal(t, model.CandidateImportRow{Row: 2, Email: "ayse@e.com", Status: model.ImportCreated, CandidateID: "id-0"}, report.Rows[0])
+		assert.Equal(t, model.ErrValidationFailed.Code, report.Rows[1].Error.Code)
+		assert.Equal(t, 5, report.Rows[2].Row)
+		assert.Equal(t, model.ErrCandidateAlreadyExists.Code, report.Rows[2].Error.Code)
+		assert.Equal(t, model.ErrDepartmentDoesNotExist.Code, report.Rows[3].Error.Code)
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		router := importCandidatesRouter()
+		response := sendMultipartRequest(router, "/candidates/import?dry_run=true", "fair.csv", csv, map[string]string{"mapping": mapping})
+
+		report := decodeReport(response)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, model.ImportValid, report.Rows[0].Status)
+		assert.Empty(t, report.Rows[0].CandidateID)
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		file := excelize.NewFile()
+		_ = file.SetSheetRow("Sheet1", "A1", &[]string{"email", "department", "university"})
+		_ = file.SetSheetRow("Sheet1", "A2", &[]string{"ayse@e.com", "Design", "METU"})
+		xlsx, _ := file.WriteToBuffer()
+
+		router := importCandidatesRouter()
+		response := sendMultipartRequest(router, "/candidates/import", "fair.xlsx", xlsx.Bytes(), nil)
+
+		report := decodeReport(response)
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, 1, report.Succeeded)
+	})
+
+	t.Run("too-many-rows", func(t *testing.T) {
+		file := excelize.NewFile()
+		_ = file.SetSheetRow("Sheet1", "A1", &[]string{"email", "department", "university"})
+		for row := 2; row <= maxImportRows+10; row++ {
+			_ = file.SetSheetRow("Sheet1", fmt.Sprintf("A%d", row), &[]string{fmt.Sprintf("c%d@e.com", row), "Design", "METU"})
+		}
+		xlsx, _ := file.WriteToBuffer()
+
+		router := importCandidatesRouter()
+		response := sendMultipartRequest(router, "/candidates/import", "fair.xlsx", xlsx.Bytes(), nil)
+		assert.Equal(t, 400, response.Code)
+	})
+
+	t.Run("decompression-bomb", func(t *testing.T) {
+		// the sheet is padded with whitespace that decompresses to more than the limit
+		file := excelize.NewFile()
+		_ = file.SetSheetRow("Sheet1", "A1", &[]string{"email", "department", "university"})
+		workbook, _ := file.WriteToBuffer()
+		reader, _ := zip.NewReader(bytes.NewReader(workbook.Bytes()), int64(workbook.Len()))
+
+		xlsx := &bytes.Buffer{}
+		writer := zip.NewWriter(xlsx)
+		for _, entry := range reader.File {
+			w, _ := writer.Create(entry.Name)
+			r, _ := entry.Open()
+			_, _ = io.Copy(w, r)
+			if entry.Name == "xl/worksheets/sheet1.xml" {
+				padding := bytes.Repeat([]byte(" "), 1<<20)
+				for i := 0; i < 80; i++ {
+					_, _ = w.Write(padding)
+				}
+			}
+		}
+		_ = writer.Close()
+		assert.Less(t, xlsx.Len(), 10<<20)
+
+		router := importCandidatesRouter()
+		response := sendMultipartRequest(router, "/candidates/import", "fair.xlsx", xlsx.Bytes(), nil)
+		assert.Equal(t, 400, response.Code)
+	})
+
+	t.Run("column-is-missing", func(t *testing.T) {
+		router := importCandidatesRouter()
+		response := sendMultipartRequest(router, "/candidates/import", "fair.csv", csv, nil)
+		assert.Equal(t, 400, response.Code)
+	})
+
+	t.Run("unknown-format", func(t *testing.T) {
+		router := importCandidatesRouter()
+		response := sendMultipartRequest(router, "/candidates/import", "fair.txt", csv, nil)
+		assert.Equal(t, 400, response.Code)
 	})
 }
 
@@ -42,6 +200,82 @@ func TestApi_FindAllCandidates(t *testing.T) {
 		router := findAllCandidatesSuccessRouter()
 		sendGetAndExpectOk(t, router, "/candidates")
 	})
+
+	t.Run("search", func(t *testing.T) {
+		router := findAllCandidatesSuccessRouter()
+		sendGetAndExpectOk(t, router, "/candidates?search=Go+Kubernetes")
+	})
+
+	t.Run("search-with-too-many-words", func(t *testing.T) {
+		router := findAllCandidatesSuccessRouter()
+		response := sendRequest(router, "GET", "/candidates?search=a+b+c+d+e+f+g+h+i+j+k", nil)
+		assert.Equal(t, 422, response.Code)
+	})
+
+	t.Run("backend-failure", func(t *testing.T) {
+		router := findAllCandidatesInternalErrorRouter()
+		response := sendRequest(router, "GET", "/candidates", nil)
+
+		var body model.ApiResponse
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		assert.Equal(t, 500, response.Code)
+		assert.Equal(t, model.ErrInternal.Code, body.ErrorCode)
+		assert.Equal(t, model.ErrInternal.Message, body.Message)
+	})
+}
+
+func TestApi_ExportCandidates(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		router := exportCandidatesSuccessRouter()
+		response := sendRequest(router, "GET", "/candidates/export?status=Pending", nil)
+
+		rows, err := spreadsheet.ReadRows(response.Body, spreadsheet.CSV, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", response.Header().Get("Content-Type"))
+		assert.Len(t, rows, 6)
+		assert.Equal(t, "assignee_name", rows[0][12])
+		assert.Equal(t, "Zafer", rows[1][12])
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		router := exportCandidatesSuccessRouter()
+		response := sendRequest(router, "GET", "/candidates/export?format=xlsx", nil)
+
+		rows, err := spreadsheet.ReadRows(response.Body, spreadsheet.XLSX, 0)
+		assert.NoError(t, err)
+		assert.Len(t, rows, 6)
+		assert.Equal(t, "e@e.com", rows[1][3])
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		router := exportCandidatesSuccessRouter()
+		response := sendRequest(router, "GET", "/candidates/export?format=jsonl", nil)
+
+		lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+		var record map[string]interface{}
+		assert.Len(t, lines, 5)
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+		assert.Equal(t, "Zafer", record["assignee_name"])
+	})
+
+	t.Run("invalid-status", func(t *testing.T) {
+		router := exportCandidatesSuccessRouter()
+		response := sendRequest(router, "GET", "/candidates/export?status=Waiting", nil)
+		assert.Equal(t, 422, response.Code)
+	})
+
+	t.Run("unknown-timezone", func(t *testing.T) {
+		router := exportCandidatesSuccessRouter()
+		response := sendRequest(router, "GET", "/candidates/export?timezone=Mars/Olympus", nil)
+		assert.Equal(t, 400, response.Code)
+	})
+
+	t.Run("backend-failure", func(t *testing.T) {
+		router := exportCandidatesInternalErrorRouter()
+		response := sendRequest(router, "GET", "/candidates/export", nil)
+		assert.Equal(t, 500, response.Code)
+	})
 }
 
 func TestApi_ReadCandidate(t *testing.T) {
@@ -49,6 +283,50 @@ func TestApi_ReadCandidate(t *testing.T) {
 		router := readCandidateSuccessRouter()
 		sendGetAndExpectOk(t, router, "/candidates/abcd")
 	})
+
+	t.Run("etag", func(t *testing.T) {
+		router := readCandidateSuccessRouter()
+		response := sendRequest(router, "GET", "/candidates/abcd", nil)
+		assert.Equal(t, `"2"`, response.Header().Get("ETag"))
+	})
+
+	t.Run("candidate-does-not-exist", func(t *testing.T) {
+		router := readCandidateDoesNotExistRouter()
+		sendGetAndExpectNotFound(t, router, "/candidates/abcd")
+	})
+}
+
+func TestApi_UpdateCandidate(t *testing.T) {
+	candidate := mockCandidateModel()
+	jsonCandidate, _ := json.Marshal(candidate)
+
+	t.Run("success", func(t *testing.T) {
+		router := updateCandidateSuccessRouter()
+		response := sendRequestWithHeaders(router, "PUT", "/candidates/asd", jsonCandidate,
+			map[string]string{"If-Match": `"2"`})
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, `"3"`, response.Header().Get("ETag"))
+	})
+
+	t.Run("if-match-is-missing", func(t *testing.T) {
+		router := updateCandidateSuccessRouter()
+		response := sendRequest(router, "PUT", "/candidates/asd", jsonCandidate)
+		assert.Equal(t, 428, response.Code)
+	})
+
+	t.Run("if-match-is-invalid", func(t *testing.T) {
+		router := updateCandidateSuccessRouter()
+		response := sendRequestWithHeaders(router, "PUT", "/candidates/asd", jsonCandidate,
+			map[string]string{"If-Match": `W/"2"`})
+		assert.Equal(t, 412, response.Code)
+	})
+
+	t.Run("candidate-was-modified", func(t *testing.T) {
+		router := updateCandidateModifiedRouter()
+		response := sendRequestWithHeaders(router, "PUT", "/candidates/asd", jsonCandidate,
+			map[string]string{"If-Match": `"1"`})
+		assert.Equal(t, 412, response.Code)
+	})
 }
 
 func TestApi_DeleteCandidate(t *testing.T) {
@@ -59,7 +337,196 @@ func TestApi_DeleteCandidate(t *testing.T) {
 
 	t.Run("candidate-does-not-exist", func(t *testing.T) {
 		router := deleteCandidateDoesNotExistRouter()
-		sendDeleteAndExpectBadRequest(t, router, "/candidates/abcd")
+		sendDeleteAndExpectNotFound(t, router, "/candidates/abcd")
+	})
+}
+
+func TestApi_UploadAttachment(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+
+	t.Run("success", func(t *testing.T) {
+		router := attachmentsRouter()
+		response := sendMultipartRequest(router, "/candidates/abcd/attachments", "cv.pdf", pdf, map[string]string{"kind": model.AttachmentCV})
+
+		var body struct {
+			Data model.Attachment `json:"data"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		assert.Equal(t, 201, response.Code)
+		assert.Equal(t, "application/pdf", body.Data.ContentType)
+		assert.Equal(t, "cv.pdf", body.Data.FileName)
+		// the sniffed bytes are still uploaded
+		assert.Equal(t, int64(len(pdf)), body.Data.Size)
+	})
+
+	t.Run("candidate-does-not-exist", func(t *testing.T) {
+		router := attachmentsRouter()
+		response := sendMultipartRequest(router, "/candidates/efgh/attachments", "cv.pdf", pdf, map[string]string{"kind": model.AttachmentCV})
+		assert.Equal(t, 404, response.Code)
+	})
+
+	t.Run("invalid-kind", func(t *testing.T) {
+		router := attachmentsRouter()
+		response := sendMultipartRequest(router, "/candidates/abcd/attachments", "cv.pdf", pdf, map[string]string{"kind": "Photo"})
+		assert.Equal(t, 422, response.Code)
+	})
+
+	t.Run("unsupported-extension", func(t *testing.T) {
+		router := attachmentsRouter()
+		response := sendMultipartRequest(router, "/candidates/abcd/attachments", "cv.exe", pdf, map[string]string{"kind": model.AttachmentCV})
+		assert.Equal(t, 415, response.Code)
+	})
+
+	t.Run("content-does-not-match-type", func(t *testing.T) {
+		router := attachmentsRouter()
+		response := sendMultipartRequest(router, "/candidates/abcd/attachments", "cv.pdf", []byte("MZ\x90\x00\x03\x00\x00\x00"), map[string]string{"kind": model.AttachmentCV})
+		assert.Equal(t, 415, response.Code)
+	})
+
+	t.Run("too-large", func(t *testing.T) {
+		router := attachmentsRouter()
+		large := append(append([]byte{}, pdf...), make([]byte, maxAttachmentRequestSize)...)
+		response := sendMultipartRequest(router, "/candidates/abcd/attachments", "cv.pdf", large, map[string]string{"kind": model.AttachmentCV})
+		assert.Equal(t, 413, response.Code)
+	})
+}
+
+func TestApi_FindCandidatesAttachments(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := attachmentsRouter()
+		sendGetAndExpectOk(t, router, "/candidates/abcd/attachments")
+	})
+
+	t.Run("candidate-does-not-exist", func(t *testing.T) {
+		router := attachmentsRouter()
+		sendGetAndExpectNotFound(t, router, "/candidates/efgh/attachments")
+	})
+}
+
+func TestApi_DownloadAttachment(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := attachmentsRouter()
+		response := sendRequest(router, "GET", "/candidates/abcd/attachments/456", nil)
+
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, "application/pdf", response.Header().Get("Content-Type"))
+		assert.Equal(t, "11", response.Header().Get("Content-Length"))
+		assert.Equal(t, "attachment; filename*=utf-8''Ay%C5%9Fe%20Kaya%20CV.pdf", response.Header().Get("Content-Disposition"))
+		assert.Equal(t, "nosniff", response.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "%PDF-1.4 cv", response.Body.String())
+	})
+
+	t.Run("attachment-does-not-exist", func(t *testing.T) {
+		router := attachmentsRouter()
+		sendGetAndExpectNotFound(t, router, "/candidates/abcd/attachments/789")
+	})
+}
+
+func TestApi_DeleteAttachment(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := attachmentsRouter()
+		sendDeleteAndExpectOk(t, router, "/candidates/abcd/attachments/456")
+	})
+
+	t.Run("attachment-does-not-exist", func(t *testing.T) {
+		router := attachmentsRouter()
+		sendDeleteAndExpectNotFound(t, router, "/candidates/abcd/attachments/789")
+	})
+}
+
+func TestApi_CreateWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := webhooksRouter()
+		response := sendRequest(router, "POST", "/webhooks", []byte(`{"url":"https://ats.example.com/hooks","events":["CandidateCreated"]}`))
+
+		assert.Equal(t, 201, response.Code)
+		assert.Contains(t, response.Body.String(), `"secret":"s3cr3t"`)
+	})
+
+	t.Run("invalid-url", func(t *testing.T) {
+		router := webhooksRouter()
+		sendPostAndExpectUnprocessableEntity(t, router, "/webhooks", []byte(`{"url":"ftp://ats.example.com/hooks","events":["CandidateCreated"]}`))
+	})
+
+	t.Run("no-events", func(t *testing.T) {
+		router := webhooksRouter()
+		sendPostAndExpectUnprocessableEntity(t, router, "/webhooks", []byte(`{"url":"https://ats.example.com/hooks","events":[]}`))
+	})
+
+	t.Run("event-does-not-exist", func(t *testing.T) {
+		router := webhooksRouter()
+		response := sendRequest(router, "POST", "/webhooks", []byte(`{"url":"https://ats.example.com/hooks","events":["CandidateHired"]}`))
+
+		assert.Equal(t, 422, response.Code)
+		assert.Contains(t, response.Body.String(), `"error_code":"webhook_event_not_found"`)
+	})
+
+	t.Run("malformed-request", func(t *testing.T) {
+		router := webhooksRouter()
+		sendPostAndExpectBadRequest(t, router, "/webhooks", []byte(`{"url":`))
+	})
+}
+
+func TestApi_FindAllWebhooks(t *testing.T) {
+	router := webhooksRouter()
+	sendGetAndExpectOk(t, router, "/webhooks")
+}
+
+func TestApi_ReadWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := webhooksRouter()
+		sendGetAndExpectOk(t, router, "/webhooks/w1")
+	})
+
+	t.Run("webhook-does-not-exist", func(t *testing.T) {
+		router := webhooksRouter()
+		sendGetAndExpectNotFound(t, router, "/webhooks/w2")
+	})
+}
+
+func TestApi_UpdateWebhook(t *testing.T) {
+	body := []byte(`{"url":"https://ats.example.com/hooks","events":["MeetingCompleted"],"paused":true}`)
+
+	t.Run("success", func(t *testing.T) {
+		router := webhooksRouter()
+		response := sendRequest(router, "PUT", "/webhooks/w1", body)
+
+		assert.Equal(t, 200, response.Code)
+		assert.Contains(t, response.Body.String(), `"paused":true`)
+	})
+
+	t.Run("webhook-does-not-exist", func(t *testing.T) {
+		router := webhooksRouter()
+		assertHelper(t, router, "PUT", "/webhooks/w2", body, 404)
+	})
+
+	t.Run("event-does-not-exist", func(t *testing.T) {
+		router := webhooksRouter()
+		assertHelper(t, router, "PUT", "/webhooks/w1", []byte(`{"url":"https://ats.example.com/hooks","events":["CandidateHired"]}`), 422)
+	})
+}
+
+func TestApi_DeleteWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := webhooksRouter()
+		sendDeleteAndExpectOk(t, router, "/webhooks/w1")
+	})
+
+	t.Run("webhook-does-not-exist", func(t *testing.T) {
+		router := webhooksRouter()
+		sendDeleteAndExpectNotFound(t, router, "/webhooks/w2")
+	})
+}
+
+func TestApi_FindWebhooksDeliveries(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := webhooksRouter()
+		sendGetAndExpectOk(t, router, "/webhooks/w1/deliveries")
+	})
+
+	t.Run("webhook-does-not-exist", func(t *testing.T) {
+		router := webhooksRouter()
+		sendGetAndExpectNotFound(t, router, "/webhooks/w2/deliveries")
 	})
 }
 
@@ -71,7 +538,7 @@ func TestApi_DenyCandidate(t *testing.T) {
 
 	t.Run("candidate-does-not-exist", func(t *testing.T) {
 		router := denyCandidateDoesNotExistRouter()
-		sendPatchAndExpectBadRequest(t, router, "/candidates/deny/abcd")
+		sendPatchAndExpectNotFound(t, router, "/candidates/deny/abcd")
 	})
 }
 
@@ -83,12 +550,12 @@ func TestApi_AcceptCandidate(t *testing.T) {
 
 	t.Run("candidate-does-not-exist", func(t *testing.T) {
 		router := acceptCandidateDoesNotExistRouter()
-		sendPatchAndExpectBadRequest(t, router, "/candidates/accept/abcd")
+		sendPatchAndExpectNotFound(t, router, "/candidates/accept/abcd")
 	})
 
 	t.Run("meeting-count-not-enough", func(t *testing.T) {
 		router := acceptCandidateNotEnoughMeetingRouter()
-		sendPatchAndExpectBadRequest(t, router, "/candidates/accept/abcd")
+		sendPatchAndExpectConflict(t, router, "/candidates/accept/abcd")
 	})
 }
 
@@ -100,7 +567,7 @@ func TestApi_FindAssigneesCandidates(t *testing.T) {
 
 	t.Run("assignee-does-not-exist", func(t *testing.T) {
 		router := findAssigneesCandidatesAssigneeDoesNotExistRouter()
-		sendGetAndExpectBadRequest(t, router, "/candidates/assigneeId/abcd")
+		sendGetAndExpectNotFound(t, router, "/candidates/assigneeId/abcd")
 	})
 }
 
@@ -118,7 +585,7 @@ func TestApi_CreateAssignee(t *testing.T) {
 		assignee.Department = "test"
 
 		jsonAssignee, _ := json.Marshal(assignee)
-		sendPostAndExpectBadRequest(t, router, "/assignees", jsonAssignee)
+		sendPostAndExpectUnprocessableEntity(t, router, "/assignees", jsonAssignee)
 	})
 }
 
@@ -137,7 +604,7 @@ func TestApi_FindAssigneeIDByName(t *testing.T) {
 
 	t.Run("assignee-does-not-exist", func(t *testing.T) {
 		router := findAssigneeIdByNameAssigneeDoesNotExistRouter()
-		sendGetAndExpectBadRequest(t, router, "/assignees/name/test")
+		sendGetAndExpectNotFound(t, router, "/assignees/name/test")
 	})
 }
 
@@ -148,6 +615,58 @@ func TestApi_FindAllAssigneesByDepartment(t *testing.T) {
 	})
 }
 
+func TestApi_CreateCalendarToken(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := assigneesCalendarRouter()
+		response := sendRequestWithHeaders(router, "POST", "http://example.com/assignees/asd123/calendar-token", nil,
+			map[string]string{"X-Forwarded-Proto": "https"})
+
+		var body struct {
+			Data model.CalendarToken `json:"data"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, "s3cr3t-t0ken", body.Data.Token)
+		assert.Equal(t, "https://example.com/assignees/asd123/calendar.ics?token=s3cr3t-t0ken", body.Data.URL)
+	})
+
+	t.Run("assignee-does-not-exist", func(t *testing.T) {
+		router := createCalendarTokenAssigneeDoesNotExistRouter()
+		sendPostAndExpectNotFound(t, router, "/assignees/asd123/calendar-token", nil)
+	})
+}
+
+func TestApi_DownloadAssigneesCalendar(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := assigneesCalendarRouter()
+		response := sendRequest(router, "GET", "/assignees/asd123/calendar.ics?token=s3cr3t-t0ken", nil)
+
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", response.Header().Get("Content-Type"))
+		assert.NotEmpty(t, response.Header().Get("ETag"))
+		assert.Contains(t, response.Body.String(), "\r\nUID:5eb2e1a4b8e5f1a3c0d4e5f6@internship-management\r\n")
+	})
+
+	t.Run("not-modified", func(t *testing.T) {
+		etag := sendRequest(assigneesCalendarRouter(), "GET", "/assignees/asd123/calendar.ics?token=s3cr3t-t0ken", nil).
+			Header().Get("ETag")
+
+		response := sendRequestWithHeaders(assigneesCalendarRouter(), "GET", "/assignees/asd123/calendar.ics?token=s3cr3t-t0ken", nil,
+			map[string]string{"If-None-Match": `"other", W/` + etag})
+
+		assert.Equal(t, 304, response.Code)
+		assert.Equal(t, etag, response.Header().Get("ETag"))
+		assert.Empty(t, response.Body.String())
+	})
+
+	t.Run("token-is-not-valid", func(t *testing.T) {
+		router := assigneesCalendarRouter()
+		response := sendRequest(router, "GET", "/assignees/asd123/calendar.ics?token=guess", nil)
+
+		assert.Equal(t, 403, response.Code)
+	})
+}
+
 func TestApi_ArrangeMeeting(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		router := arrangeMeetingSuccessRouter()
@@ -161,14 +680,14 @@ func TestApi_ArrangeMeeting(t *testing.T) {
 		meeting := mockMeetingModel()
 		meeting.CandidateID = ""
 		jsonMeeting, _ := json.Marshal(meeting)
-		sendPostAndExpectBadRequest(t, router, "/meetings/arrange", jsonMeeting)
+		sendPostAndExpectUnprocessableEntity(t, router, "/meetings/arrange", jsonMeeting)
 	})
 
 	t.Run("candidate-does-not-exist", func(t *testing.T) {
 		router := arrangeMeetingCandidateDoesNotExistRouter()
 		meeting := mockMeetingModel()
 		jsonMeeting, _ := json.Marshal(meeting)
-		sendPostAndExpectBadRequest(t, router, "/meetings/arrange", jsonMeeting)
+		sendPostAndExpectNotFound(t, router, "/meetings/arrange", jsonMeeting)
 	})
 }
 
@@ -180,6 +699,260 @@ func TestApi_CompleteMeeting(t *testing.T) {
 
 	t.Run("candidate-does-not-exist", func(t *testing.T) {
 		router := completeMeetingCandidateDoesNotExistRouter()
-		sendPostAndExpectBadRequest(t, router, "/meetings/complete/qwe123", nil)
+		sendPostAndExpectNotFound(t, router, "/meetings/complete/qwe123", nil)
+	})
+}
+
+func TestApi_RescheduleMeeting(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := changeMeetingSuccessRouter()
+		response := sendRequest(router, "PATCH", "/meetings/qwe123", []byte(`{"next_meeting_time": "2020-05-05T10:00:00Z"}`))
+
+		assert.Equal(t, 200, response.Code)
+	})
+
+	t.Run("field-required", func(t *testing.T) {
+		router := changeMeetingSuccessRouter()
+		response := sendRequest(router, "PATCH", "/meetings/qwe123", []byte(`{}`))
+
+		assert.Equal(t, 422, response.Code)
+	})
+
+	t.Run("meeting-time-in-past", func(t *testing.T) {
+		router := changeMeetingErrRouter()
+		response := sendRequest(router, "PATCH", "/meetings/qwe123", []byte(`{"next_meeting_time": "2020-05-05T10:00:00Z"}`))
+
+		assert.Equal(t, 422, response.Code)
+		assert.Contains(t, response.Body.String(), `"error_code":"meeting_time_in_past"`)
+	})
+}
+
+func TestApi_CancelMeeting(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := changeMeetingSuccessRouter()
+		response := sendRequest(router, "DELETE", "/meetings/qwe123", []byte(`{"reason": "The assignee is on leave"}`))
+
+		assert.Equal(t, 200, response.Code)
+	})
+
+	t.Run("reason-required", func(t *testing.T) {
+		router := changeMeetingSuccessRouter()
+		response := sendRequest(router, "DELETE", "/meetings/qwe123", []byte(`{"reason": ""}`))
+
+		assert.Equal(t, 422, response.Code)
+	})
+
+	t.Run("arranged-meeting-does-not-exist", func(t *testing.T) {
+		router := changeMeetingErrRouter()
+		response := sendRequest(router, "DELETE", "/meetings/qwe123", []byte(`{"reason": "The assignee is on leave"}`))
+
+		assert.Equal(t, 409, response.Code)
+		assert.Contains(t, response.Body.String(), `"error_code":"arranged_meeting_not_found"`)
+	})
+}
+
+func TestApi_RecordNoShow(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := changeMeetingSuccessRouter()
+		sendPostAndExpectOk(t, router, "/meetings/qwe123/no-show", nil)
+	})
+
+	t.Run("meeting-not-started", func(t *testing.T) {
+		router := changeMeetingErrRouter()
+		response := sendRequest(router, "POST", "/meetings/qwe123/no-show", nil)
+
+		assert.Equal(t, 409, response.Code)
+		assert.Contains(t, response.Body.String(), `"error_code":"meeting_not_started"`)
+	})
+}
+
+func TestApi_RecordMeetingFeedback(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := changeMeetingSuccessRouter()
+		response := sendRequest(router, "PUT", "/meetings/5eb2e1a4b8e5f1a3c0d4e5f6/feedback", []byte(`{"score": 4}`))
+
+		assert.Equal(t, 200, response.Code)
+	})
+
+	t.Run("score-out-of-range", func(t *testing.T) {
+		router := changeMeetingSuccessRouter()
+		response := sendRequest(router, "PUT", "/meetings/5eb2e1a4b8e5f1a3c0d4e5f6/feedback", []byte(`{"score": 6}`))
+
+		assert.Equal(t, 422, response.Code)
+	})
+
+	t.Run("meeting-not-completed", func(t *testing.T) {
+		router := changeMeetingErrRouter()
+		response := sendRequest(router, "PUT", "/meetings/5eb2e1a4b8e5f1a3c0d4e5f6/feedback", []byte(`{"score": 4}`))
+
+		assert.Equal(t, 409, response.Code)
+		assert.Contains(t, response.Body.String(), `"error_code":"meeting_not_completed"`)
+	})
+}
+
+func TestApi_FindCandidatesMeetings(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := changeMeetingSuccessRouter()
+		response := sendRequest(router, "GET", "/candidates/qwe123/meetings", nil)
+
+		assert.Equal(t, 200, response.Code)
+		assert.Contains(t, response.Body.String(), `"id":"5eb2e1a4b8e5f1a3c0d4e5f6"`)
+	})
+
+	t.Run("candidate-does-not-exist", func(t *testing.T) {
+		router := findCandidatesMeetingsDoesNotExistRouter()
+		sendGetAndExpectNotFound(t, router, "/candidates/qwe123/meetings")
+	})
+}
+
+func TestApi_FindOverdueMeetings(t *testing.T) {
+	router := changeMeetingSuccessRouter()
+	response := sendRequest(router, "GET", "/meetings/overdue", nil)
+
+	assert.Equal(t, 200, response.Code)
+	assert.Contains(t, response.Body.String(), `"id":"5eb2e1a4b8e5f1a3c0d4e5f6"`)
+}
+
+func TestApi_DownloadMeetingCalendar(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := meetingCalendarSuccessRouter()
+		response := sendRequest(router, "GET", "/meetings/5eb2e1a4b8e5f1a3c0d4e5f6.ics", nil)
+
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", response.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename=meeting-5eb2e1a4b8e5f1a3c0d4e5f6.ics`, response.Header().Get("Content-Disposition"))
+		body := response.Body.String()
+		assert.Contains(t, body, "\r\nUID:5eb2e1a4b8e5f1a3c0d4e5f6@internship-management\r\n")
+		assert.Contains(t, body, "\r\nDTSTART:20200503T134000Z\r\n")
+		assert.NotContains(t, body, "METHOD:")
+	})
+
+	t.Run("meeting-does-not-exist", func(t *testing.T) {
+		router := meetingCalendarDoesNotExistRouter()
+		sendGetAndExpectNotFound(t, router, "/meetings/5eb2e1a4b8e5f1a3c0d4e5f6.ics")
+	})
+}
+
+func TestApi_StreamEvents(t *testing.T) {
+	candidate := mockCandidateModel()
+
+	t.Run("success", func(t *testing.T) {
+		mockEventStream := mockEventStream(model.EventFilter{Department: model.Development, AssigneeID: "a1"}, "e1",
+			model.DomainEvent{ID: "e2", Type: model.CandidateDeniedEvent, Candidate: &candidate})
+		router := eventStreamRouter(mockEventStream)
+		response := sendRequestWithHeaders(router, "GET", "/events/stream?department=Development&assignee=a1", nil,
+			map[string]string{"Last-Event-ID": "e1"})
+
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, "text/event-stream", response.Header().Get("Content-Type"))
+		assert.Equal(t, "no-cache", response.Header().Get("Cache-Control"))
+		body := response.Body.String()
+		assert.True(t, strings.HasPrefix(body, "retry: 3000\n\nid: e2\nevent: CandidateDenied\ndata: {\"id\":\"e2\",\"type\":\"CandidateDenied\""))
+		assert.True(t, strings.HasSuffix(body, "}\n\n"))
+		mockEventStream.AssertExpectations(t)
+	})
+
+	t.Run("last-event-id-parameter", func(t *testing.T) {
+		mockEventStream := mockEventStream(model.EventFilter{}, "e1")
+		router := eventStreamRouter(mockEventStream)
+		response := sendRequest(router, "GET", "/events/stream?last_event_id=e1", nil)
+
+		assert.Equal(t, 200, response.Code)
+		mockEventStream.AssertExpectations(t)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		router := eventStreamRouter(mockEventStream(model.EventFilter{}, "e1", model.DomainEvent{Type: model.StreamResetEvent}))
+		response := sendRequestWithHeaders(router, "GET", "/events/stream", nil, map[string]string{"Last-Event-ID": "e1"})
+
+		// the reset does not have an id, so the client keeps its last event id
+		assert.Contains(t, response.Body.String(), "\n\nevent: StreamReset\ndata: ")
+		assert.NotContains(t, response.Body.String(), "id: ")
+	})
+
+	t.Run("server-shuts-down", func(t *testing.T) {
+		mockEventStream := new(mocks.EventStream)
+		mockEventStream.On("Subscribe", model.EventFilter{}, "").
+			Return((<-chan model.DomainEvent)(make(chan model.DomainEvent)), func() {}).Once()
+		shutdown := make(chan struct{})
+		close(shutdown)
+		mockApi := api{EventStream: mockEventStream, shutdown: shutdown}
+
+		response := httptest.NewRecorder()
+		mockApi.StreamEvents(response, httptest.NewRequest("GET", "/events/stream", nil))
+		assert.Equal(t, 200, response.Code)
+		assert.Equal(t, "retry: 3000\n\n", response.Body.String())
+	})
+
+	t.Run("department-does-not-exist", func(t *testing.T) {
+		router := eventStreamRouter(mockEventStream(model.EventFilter{}, ""))
+		response := sendRequest(router, "GET", "/events/stream?department=Sales", nil)
+
+		assert.Equal(t, 422, response.Code)
+	})
+}
+
+func TestApi_FunnelReport(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		router := reportsRouter(mockReportService())
+		response := sendRequest(router, "GET", "/reports/funnel", nil)
+
+		assert.Equal(t, 200, response.Code)
+		var body struct {
+			Data model.FunnelReport `json:"data"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		assert.Equal(t, 2, body.Data.Candidates)
+		assert.Equal(t, []int{1, 0, 0, 0, 1}, body.Data.Meetings)
+		assert.Equal(t, model.Design, body.Data.Departments[0].Name)
+		assert.Equal(t, 1, body.Data.Departments[0].Statuses[model.Accepted])
+	})
+
+	t.Run("backend-failure", func(t *testing.T) {
+		mockReportService := new(mocks.ReportService)
+		mockReportService.On("FunnelReport", mock.Anything).Return(model.FunnelReport{}, errors.New("connection refused"))
+		router := reportsRouter(mockReportService)
+
+		sendGetAndExpectInternalServerError(t, router, "/reports/funnel")
+	})
+}
+
+func TestApi_TimeToHireReport(t *testing.T) {
+	router := reportsRouter(mockReportService())
+	response := sendRequest(router, "GET", "/reports/time-to-hire", nil)
+
+	assert.Equal(t, 200, response.Code)
+	assert.Contains(t, response.Body.String(), `"decided":{"candidates":3,"median_days":20,"p90_days":30}`)
+}
+
+func TestApi_AssigneeWorkloadReport(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		reportService := mockReportService()
+		router := reportsRouter(reportService)
+		response := sendRequest(router, "GET", "/reports/assignees?department=Design&from=2020-04-01T00:00:00Z&to=2020-05-01T00:00:00Z", nil)
+
+		assert.Equal(t, 200, response.Code)
+		assert.Contains(t, response.Body.String(), `"upcoming_meetings":3,"completed_interviews":2,"open_candidates":4,"scored_interviews":2,"average_feedback_score":4.5`)
+		reportService.AssertCalled(t, "AssigneeWorkloadReport", mock.Anything, model.WorkloadFilter{
+			Department: model.Design,
+			From:       time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+		})
+	})
+
+	t.Run("department-does-not-exist", func(t *testing.T) {
+		router := reportsRouter(mockReportService())
+		response := sendRequest(router, "GET", "/reports/assignees?department=Sales", nil)
+
+		assert.Equal(t, 422, response.Code)
+		assert.Contains(t, response.Body.String(), `"error_code":"department_not_found"`)
+	})
+
+	t.Run("malformed-time", func(t *testing.T) {
+		router := reportsRouter(mockReportService())
+		response := sendRequest(router, "GET", "/reports/assignees?from=2020-04-01", nil)
+
+		assert.Equal(t, 422, response.Code)
+		assert.Contains(t, response.Body.String(), `"field":"from"`)
 	})
 }
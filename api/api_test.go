@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	t.Run("context-is-done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		errs := make(chan error, 1)
+		go func() {
+			errs <- serve(ctx, &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()})
+		}()
+
+		cancel()
+		select {
+		case err := <-errs:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the server did not shut down")
+		}
+	})
+
+	t.Run("address-is-in-use", func(t *testing.T) {
+		listener, _ := net.Listen("tcp", "127.0.0.1:0")
+		defer listener.Close()
+
+		err := serve(context.TODO(), &http.Server{Addr: listener.Addr().String(), Handler: http.NotFoundHandler()})
+		assert.Error(t, err)
+	})
+}
@@ -2,11 +2,16 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/cemalunal/sample-internship-management-api/model"
 	"github.com/cemalunal/sample-internship-management-api/model/mocks"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -14,10 +19,36 @@ import (
 )
 
 func assertHelper (t *testing.T, r *mux.Router, method string, path string, body []byte, expectedCode int) {
+	response := sendRequest(r, method, path, body)
+	assert.Equal(t, expectedCode, response.Code)
+}
+
+func sendRequest(r *mux.Router, method string, path string, body []byte) *httptest.ResponseRecorder {
+	return sendRequestWithHeaders(r, method, path, body, nil)
+}
+
+func sendRequestWithHeaders(r *mux.Router, method string, path string, body []byte, headers map[string]string) *httptest.ResponseRecorder {
 	request, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
 	response := httptest.NewRecorder()
 	r.ServeHTTP(response, request)
-	assert.Equal(t, expectedCode, response.Code)
+	return response
+}
+
+// sendMultipartRequest posts the given file in the file field of a multipart form, along with the given form fields
+func sendMultipartRequest(r *mux.Router, path string, filename string, file []byte, fields map[string]string) *httptest.ResponseRecorder {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filename)
+	_, _ = part.Write(file)
+	for key, value := range fields {
+		_ = writer.WriteField(key, value)
+	}
+	_ = writer.Close()
+
+	return sendRequestWithHeaders(r, "POST", path, body.Bytes(), map[string]string{"Content-Type": writer.FormDataContentType()})
 }
 
 func sendPostAndExpectOk(t *testing.T, r *mux.Router, path string, body []byte) {
@@ -32,28 +63,48 @@ func sendPostAndExpectBadRequest(t *testing.T, r *mux.Router, path string, body
 	assertHelper(t, r, "POST", path, body, 400)
 }
 
+func sendPostAndExpectNotFound(t *testing.T, r *mux.Router, path string, body []byte) {
+	assertHelper(t, r, "POST", path, body, 404)
+}
+
+func sendPostAndExpectConflict(t *testing.T, r *mux.Router, path string, body []byte) {
+	assertHelper(t, r, "POST", path, body, 409)
+}
+
+func sendPostAndExpectUnprocessableEntity(t *testing.T, r *mux.Router, path string, body []byte) {
+	assertHelper(t, r, "POST", path, body, 422)
+}
+
 func sendGetAndExpectOk(t *testing.T, r *mux.Router, path string) {
 	assertHelper(t, r, "GET", path, nil, 200)
 }
 
-func sendGetAndExpectBadRequest(t *testing.T, r *mux.Router, path string) {
-	assertHelper(t, r, "GET", path, nil, 400)
+func sendGetAndExpectNotFound(t *testing.T, r *mux.Router, path string) {
+	assertHelper(t, r, "GET", path, nil, 404)
+}
+
+func sendGetAndExpectInternalServerError(t *testing.T, r *mux.Router, path string) {
+	assertHelper(t, r, "GET", path, nil, 500)
 }
 
 func sendDeleteAndExpectOk(t *testing.T, r *mux.Router, path string) {
 	assertHelper(t, r, "DELETE", path, nil, 200)
 }
 
-func sendDeleteAndExpectBadRequest(t *testing.T, r *mux.Router, path string) {
-	assertHelper(t, r, "DELETE", path, nil, 400)
+func sendDeleteAndExpectNotFound(t *testing.T, r *mux.Router, path string) {
+	assertHelper(t, r, "DELETE", path, nil, 404)
 }
 
 func sendPatchAndExpectOk(t *testing.T, r *mux.Router, path string) {
 	assertHelper(t, r, "PATCH", path, nil, 200)
 }
 
-func sendPatchAndExpectBadRequest(t *testing.T, r *mux.Router, path string) {
-	assertHelper(t, r, "PATCH", path, nil, 400)
+func sendPatchAndExpectNotFound(t *testing.T, r *mux.Router, path string) {
+	assertHelper(t, r, "PATCH", path, nil, 404)
+}
+
+func sendPatchAndExpectConflict(t *testing.T, r *mux.Router, path string) {
+	assertHelper(t, r, "PATCH", path, nil, 409)
 }
 
 func createCandidateSuccessRouter() *mux.Router {
@@ -86,6 +137,46 @@ func readCandidateSuccessRouter() *mux.Router {
 	return router
 }
 
+func readCandidateDoesNotExistRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateServiceDoesNotExistErr(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/candidates/{id}", mockApi.ReadCandidate).Methods(http.MethodGet)
+	return router
+}
+
+func findAllCandidatesInternalErrorRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateServiceInternalErr(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/candidates", mockApi.FindAllCandidates).Methods(http.MethodGet)
+	return router
+}
+
+func updateCandidateSuccessRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/candidates/{id}", mockApi.UpdateCandidate).Methods(http.MethodPut)
+	return router
+}
+
+func updateCandidateModifiedRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateServiceModifiedErr(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/candidates/{id}", mockApi.UpdateCandidate).Methods(http.MethodPut)
+	return router
+}
+
 func deleteCandidateSuccessRouter() *mux.Router {
 	router := mux.NewRouter()
 	mockApi := api{
@@ -277,25 +368,231 @@ func completeMeetingCandidateDoesNotExistRouter() *mux.Router {
 	return router
 }
 
+func changeMeetingSuccessRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/meetings/{candidateId}", mockApi.RescheduleMeeting).Methods(http.MethodPatch)
+	router.HandleFunc("/meetings/{candidateId}", mockApi.CancelMeeting).Methods(http.MethodDelete)
+	router.HandleFunc("/meetings/{candidateId}/no-show", mockApi.RecordNoShow).Methods(http.MethodPost)
+	router.HandleFunc("/meetings/{id}/feedback", mockApi.RecordMeetingFeedback).Methods(http.MethodPut)
+	router.HandleFunc("/candidates/{id}/meetings", mockApi.FindCandidatesMeetings).Methods(http.MethodGet)
+	router.HandleFunc("/meetings/overdue", mockApi.FindOverdueMeetings).Methods(http.MethodGet)
+	return router
+}
+
+func changeMeetingErrRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateServiceMeetingErr(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/meetings/{candidateId}", mockApi.RescheduleMeeting).Methods(http.MethodPatch)
+	router.HandleFunc("/meetings/{candidateId}", mockApi.CancelMeeting).Methods(http.MethodDelete)
+	router.HandleFunc("/meetings/{candidateId}/no-show", mockApi.RecordNoShow).Methods(http.MethodPost)
+	router.HandleFunc("/meetings/{id}/feedback", mockApi.RecordMeetingFeedback).Methods(http.MethodPut)
+	return router
+}
+
+func findCandidatesMeetingsDoesNotExistRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateServiceDoesNotExistErr(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/candidates/{id}/meetings", mockApi.FindCandidatesMeetings).Methods(http.MethodGet)
+	return router
+}
+
+func assigneesCalendarRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/assignees/{id}/calendar-token", mockApi.CreateCalendarToken).Methods(http.MethodPost)
+	router.HandleFunc("/assignees/{id}/calendar.ics", mockApi.DownloadAssigneesCalendar).Methods(http.MethodGet)
+	return router
+}
+
+func createCalendarTokenAssigneeDoesNotExistRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeServiceDoesNotExistErr(),
+	}
+	router.HandleFunc("/assignees/{id}/calendar-token", mockApi.CreateCalendarToken).Methods(http.MethodPost)
+	return router
+}
+
+func meetingCalendarSuccessRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/meetings/{id}.ics", mockApi.DownloadMeetingCalendar).Methods(http.MethodGet)
+	return router
+}
+
+func meetingCalendarDoesNotExistRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateServiceDoesNotExistErr(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/meetings/{id}.ics", mockApi.DownloadMeetingCalendar).Methods(http.MethodGet)
+	return router
+}
+
+func exportCandidatesSuccessRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeService(),
+		ExportLocation:   time.UTC,
+	}
+	router.HandleFunc("/candidates/export", mockApi.ExportCandidates).Methods(http.MethodGet)
+	return router
+}
+
+func exportCandidatesInternalErrorRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateServiceInternalErr(),
+		AssigneeService:  mockAssigneeService(),
+		ExportLocation:   time.UTC,
+	}
+	router.HandleFunc("/candidates/export", mockApi.ExportCandidates).Methods(http.MethodGet)
+	return router
+}
+
+func importCandidatesRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateServiceImport(),
+		AssigneeService:  mockAssigneeService(),
+	}
+	router.HandleFunc("/candidates/import", mockApi.ImportCandidates).Methods(http.MethodPost)
+	return router
+}
+
+func attachmentsRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService:  mockCandidateService(),
+		AssigneeService:   mockAssigneeService(),
+		AttachmentService: mockAttachmentService(),
+	}
+	router.HandleFunc("/candidates/{id}/attachments", mockApi.UploadAttachment).Methods(http.MethodPost)
+	router.HandleFunc("/candidates/{id}/attachments", mockApi.FindCandidatesAttachments).Methods(http.MethodGet)
+	router.HandleFunc("/candidates/{id}/attachments/{attachmentId}", mockApi.DownloadAttachment).Methods(http.MethodGet)
+	router.HandleFunc("/candidates/{id}/attachments/{attachmentId}", mockApi.DeleteAttachment).Methods(http.MethodDelete)
+	return router
+}
+
+func webhooksRouter() *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeService(),
+		WebhookService:   mockWebhookService(),
+	}
+	router.HandleFunc("/webhooks", mockApi.CreateWebhook).Methods(http.MethodPost)
+	router.HandleFunc("/webhooks", mockApi.FindAllWebhooks).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id}", mockApi.ReadWebhook).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id}", mockApi.UpdateWebhook).Methods(http.MethodPut)
+	router.HandleFunc("/webhooks/{id}", mockApi.DeleteWebhook).Methods(http.MethodDelete)
+	router.HandleFunc("/webhooks/{id}/deliveries", mockApi.FindWebhooksDeliveries).Methods(http.MethodGet)
+	return router
+}
+
+func reportsRouter(reportService model.ReportService) *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeService(),
+		ReportService:    reportService,
+	}
+	router.HandleFunc("/reports/funnel", mockApi.FunnelReport).Methods(http.MethodGet)
+	router.HandleFunc("/reports/time-to-hire", mockApi.TimeToHireReport).Methods(http.MethodGet)
+	router.HandleFunc("/reports/assignees", mockApi.AssigneeWorkloadReport).Methods(http.MethodGet)
+	return router
+}
+
+func eventStreamRouter(eventStream model.EventStream) *mux.Router {
+	router := mux.NewRouter()
+	mockApi := api{
+		CandidateService: mockCandidateService(),
+		AssigneeService:  mockAssigneeService(),
+		EventStream:      eventStream,
+	}
+	router.HandleFunc("/events/stream", mockApi.StreamEvents).Methods(http.MethodGet)
+	return router
+}
+
+// mockEventStream streams the given events, and closes the stream after them
+func mockEventStream(filter model.EventFilter, lastEventID string, events ...model.DomainEvent) *mocks.EventStream {
+	stream := make(chan model.DomainEvent, len(events))
+	for _, event := range events {
+		stream <- event
+	}
+	close(stream)
+
+	mockEventStream := new(mocks.EventStream)
+	mockEventStream.On("Subscribe", filter, lastEventID).Return((<-chan model.DomainEvent)(stream), func() {}).Once()
+	return mockEventStream
+}
+
 func mockCandidateService() *mocks.CandidateService{
 	candidate := mockCandidateModel()
 	mockCandidateService := new(mocks.CandidateService)
 	mockCandidateService.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(candidate, nil).Once()
-	mockCandidateService.On("FindAllCandidates", mock.Anything).Return(mockCandidateArray(), nil).Once()
+	mockCandidateService.On("FindCandidates", mock.Anything, mock.AnythingOfType("model.CandidateFilter")).
+		Return(mockCandidateArray(), nil).Once()
+	mockCandidateService.On("ExportCandidates", mock.Anything, mock.AnythingOfType("model.CandidateFilter"), mock.Anything).
+		Return(func(ctx context.Context, filter model.CandidateFilter, fn func(model.ExportedCandidate) error) error {
+			for _, candidate := range mockCandidateArray() {
+				if err := fn(model.ExportedCandidate{Candidate: candidate, AssigneeName: "Zafer"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Once()
 	mockCandidateService.On("CreateCandidate", mock.Anything, candidate).Return(candidate, nil).Once()
+	updatedCandidate := candidate
+	updatedCandidate.Version = candidate.Version + 1
+	mockCandidateService.On("UpdateCandidate", mock.Anything, mock.AnythingOfType("string"), candidate).
+		Return(updatedCandidate, nil).Once()
 	mockCandidateService.On("DeleteCandidate", mock.Anything, mock.AnythingOfType("string")).Return(nil).Once()
 	mockCandidateService.On("DenyCandidate", mock.Anything, mock.AnythingOfType("string")).Return(nil).Once()
 	mockCandidateService.On("AcceptCandidate", mock.Anything, mock.AnythingOfType("string")).Return(nil).Once()
 	mockCandidateService.On("FindAssigneesCandidates", mock.Anything, mock.AnythingOfType("string")).
 		Return(mockCandidateArray(), nil).Once()
 	mockCandidateService.On("ArrangeMeeting", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+	mockCandidateService.On("RescheduleMeeting", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+	mockCandidateService.On("CancelMeeting", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Once()
+	mockCandidateService.On("RecordNoShow", mock.Anything, mock.AnythingOfType("string")).Return(nil).Once()
+	mockCandidateService.On("RecordMeetingFeedback", mock.Anything, mock.AnythingOfType("string"), 4).Return(nil).Once()
 	mockCandidateService.On("CompleteMeeting", mock.Anything, mock.AnythingOfType("string")).Return(nil).Once()
+	mockCandidateService.On("FindCandidatesMeetings", mock.Anything, mock.AnythingOfType("string")).
+		Return([]model.MeetingRecord{mockMeetingDetailsModel().Meeting}, nil).Once()
+	mockCandidateService.On("ReadMeeting", mock.Anything, mock.AnythingOfType("string")).Return(mockMeetingDetailsModel(), nil).Once()
+	mockCandidateService.On("FindOverdueMeetings", mock.Anything).Return([]model.MeetingDetails{mockMeetingDetailsModel()}, nil).Once()
+	mockCandidateService.On("FindAssigneesCalendar", mock.Anything, mock.AnythingOfType("string"), "s3cr3t-t0ken").
+		Return(mockAssigneeModel(), []model.MeetingDetails{mockMeetingDetailsModel()}, nil).Once()
+	mockCandidateService.On("FindAssigneesCalendar", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Return(model.Assignee{}, nil, model.ErrCalendarTokenInvalid).Once()
 
 	return mockCandidateService
 }
 
 func mockCandidateServiceDoesNotExistErr() *mocks.CandidateService{
 	mockCandidateService := new(mocks.CandidateService)
+	mockCandidateService.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).
+		Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
 	mockCandidateService.On("DeleteCandidate", mock.Anything, mock.AnythingOfType("string")).
 		Return(model.ErrCandidateDoesNotExist).Once()
 	mockCandidateService.On("DenyCandidate", mock.Anything, mock.AnythingOfType("string")).
@@ -308,6 +605,42 @@ func mockCandidateServiceDoesNotExistErr() *mocks.CandidateService{
 		Return(model.ErrCandidateDoesNotExist).Once()
 	mockCandidateService.On("CompleteMeeting", mock.Anything, mock.AnythingOfType("string")).
 		Return(model.ErrCandidateDoesNotExist).Once()
+	mockCandidateService.On("ReadMeeting", mock.Anything, mock.AnythingOfType("string")).
+		Return(model.MeetingDetails{}, model.ErrMeetingDoesNotExist).Once()
+	mockCandidateService.On("FindCandidatesMeetings", mock.Anything, mock.AnythingOfType("string")).
+		Return([]model.MeetingRecord(nil), model.ErrCandidateDoesNotExist).Once()
+
+	return mockCandidateService
+}
+
+func mockCandidateServiceMeetingErr() *mocks.CandidateService{
+	mockCandidateService := new(mocks.CandidateService)
+	mockCandidateService.On("RescheduleMeeting", mock.Anything, mock.AnythingOfType("string"), mock.Anything).
+		Return(model.ErrMeetingTimeInPast).Once()
+	mockCandidateService.On("CancelMeeting", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Return(model.ErrArrangedMeetingDoesNotExist).Once()
+	mockCandidateService.On("RecordNoShow", mock.Anything, mock.AnythingOfType("string")).
+		Return(model.ErrMeetingNotStarted).Once()
+	mockCandidateService.On("RecordMeetingFeedback", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("int")).
+		Return(model.ErrMeetingNotCompleted).Once()
+
+	return mockCandidateService
+}
+
+func mockCandidateServiceModifiedErr() *mocks.CandidateService{
+	mockCandidateService := new(mocks.CandidateService)
+	mockCandidateService.On("UpdateCandidate", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("model.Candidate")).
+		Return(model.Candidate{}, model.ErrCandidateModified).Once()
+
+	return mockCandidateService
+}
+
+func mockCandidateServiceInternalErr() *mocks.CandidateService{
+	mockCandidateService := new(mocks.CandidateService)
+	mockCandidateService.On("FindCandidates", mock.Anything, mock.AnythingOfType("model.CandidateFilter")).
+		Return([]model.Candidate(nil), errors.New("server selection timeout")).Once()
+	mockCandidateService.On("ExportCandidates", mock.Anything, mock.AnythingOfType("model.CandidateFilter"), mock.Anything).
+		Return(errors.New("server selection timeout")).Once()
 
 	return mockCandidateService
 }
@@ -328,6 +661,100 @@ func mockCandidateServiceAlreadyExistsErr() *mocks.CandidateService {
 	return mockCandidateService
 }
 
+// mockCandidateServiceImport creates the imported candidates, except the ones with taken@e.com email
+func mockCandidateServiceImport() *mocks.CandidateService {
+	mockCandidateService := new(mocks.CandidateService)
+	mockCandidateService.On("ImportCandidates", mock.Anything, mock.AnythingOfType("[]model.Candidate"), mock.AnythingOfType("bool")).
+		Return(func(ctx context.Context, candidates []model.Candidate, dryRun bool) []model.CandidateImportResult {
+			results := make([]model.CandidateImportResult, len(candidates))
+			for i, candidate := range candidates {
+				if candidate.Email == "taken@e.com" {
+					results[i].Err = model.ErrCandidateAlreadyExists
+					continue
+				}
+				if !dryRun {
+					candidate.ID = fmt.Sprintf("id-%d", i)
+				}
+				results[i].Candidate = candidate
+			}
+			return results
+		}).Once()
+
+	return mockCandidateService
+}
+
+// mockAttachmentService knows the attachment 456 of the candidate abcd, and no other candidates
+func mockAttachmentService() *mocks.AttachmentService {
+	mockAttachmentService := new(mocks.AttachmentService)
+	mockAttachmentService.On("UploadAttachment", mock.Anything, mock.MatchedBy(func(a model.Attachment) bool { return a.CandidateID == "abcd" }), mock.Anything).
+		Return(func(ctx context.Context, attachment model.Attachment, content io.Reader) model.Attachment {
+			data, _ := io.ReadAll(content)
+			attachment.ID = "456"
+			attachment.Size = int64(len(data))
+			return attachment
+		}, nil)
+	mockAttachmentService.On("UploadAttachment", mock.Anything, mock.Anything, mock.Anything).Return(model.Attachment{}, model.ErrCandidateDoesNotExist)
+	mockAttachmentService.On("FindCandidatesAttachments", mock.Anything, "abcd").Return([]model.Attachment{mockAttachmentModel()}, nil)
+	mockAttachmentService.On("FindCandidatesAttachments", mock.Anything, mock.Anything).Return(nil, model.ErrCandidateDoesNotExist)
+	mockAttachmentService.On("DownloadAttachment", mock.Anything, "abcd", "456").
+		Return(mockAttachmentModel(), io.NopCloser(bytes.NewReader([]byte("%PDF-1.4 cv"))), nil)
+	mockAttachmentService.On("DownloadAttachment", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, model.ErrAttachmentDoesNotExist)
+	mockAttachmentService.On("DeleteAttachment", mock.Anything, "abcd", "456").Return(nil)
+	mockAttachmentService.On("DeleteAttachment", mock.Anything, mock.Anything, mock.Anything).Return(model.ErrAttachmentDoesNotExist)
+	return mockAttachmentService
+}
+
+// mockWebhookService knows the webhook w1, and no other webhooks
+func mockReportService() *mocks.ReportService {
+	mockReportService := new(mocks.ReportService)
+	mockReportService.On("FunnelReport", mock.Anything).Return(model.FunnelReport{
+		FunnelCounts: model.FunnelCounts{Candidates: 2, Statuses: map[string]int{model.Pending: 1, model.Accepted: 1}, Meetings: []int{1, 0, 0, 0, 1}},
+		Departments: []model.FunnelGroup{
+			{Name: model.Design, FunnelCounts: model.FunnelCounts{Candidates: 2, Statuses: map[string]int{model.Pending: 1, model.Accepted: 1}, Meetings: []int{1, 0, 0, 0, 1}}},
+		},
+	}, nil)
+	mockReportService.On("TimeToHireReport", mock.Anything).Return(model.TimeToHireReport{
+		Decided:  model.DecisionTimes{Candidates: 3, MedianDays: 20, P90Days: 30},
+		Accepted: model.DecisionTimes{Candidates: 1, MedianDays: 30, P90Days: 30},
+		Denied:   model.DecisionTimes{Candidates: 2, MedianDays: 10, P90Days: 20},
+	}, nil)
+	average := 4.5
+	mockReportService.On("AssigneeWorkloadReport", mock.Anything, mock.AnythingOfType("model.WorkloadFilter")).Return(model.AssigneeWorkloadReport{
+		From: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+		Assignees: []model.AssigneeWorkload{
+			{Assignee: model.Assignee{ID: "asd123dsa", Name: "Zafer", Department: model.Design}, UpcomingMeetings: 3, CompletedInterviews: 2,
+				OpenCandidates: 4, ScoredInterviews: 2, AverageFeedbackScore: &average},
+		},
+	}, nil)
+	return mockReportService
+}
+
+func mockWebhookService() *mocks.WebhookService {
+	mockWebhookService := new(mocks.WebhookService)
+	mockWebhookService.On("CreateWebhook", mock.Anything, mock.AnythingOfType("model.Webhook")).
+		Return(func(ctx context.Context, webhook model.Webhook) model.Webhook {
+			webhook.ID = "w1"
+			webhook.Secret = "s3cr3t"
+			return webhook
+		}, nil)
+	mockWebhookService.On("FindAllWebhooks", mock.Anything).Return([]model.Webhook{mockWebhookModel()}, nil)
+	mockWebhookService.On("ReadWebhook", mock.Anything, "w1").Return(mockWebhookModel(), nil)
+	mockWebhookService.On("ReadWebhook", mock.Anything, mock.Anything).Return(model.Webhook{}, model.ErrWebhookDoesNotExist)
+	mockWebhookService.On("UpdateWebhook", mock.Anything, "w1", mock.AnythingOfType("model.Webhook")).
+		Return(func(ctx context.Context, id string, webhook model.Webhook) model.Webhook {
+			webhook.ID = id
+			return webhook
+		}, nil)
+	mockWebhookService.On("UpdateWebhook", mock.Anything, mock.Anything, mock.Anything).Return(model.Webhook{}, model.ErrWebhookDoesNotExist)
+	mockWebhookService.On("DeleteWebhook", mock.Anything, "w1").Return(nil)
+	mockWebhookService.On("DeleteWebhook", mock.Anything, mock.Anything).Return(model.ErrWebhookDoesNotExist)
+	mockWebhookService.On("FindWebhooksDeliveries", mock.Anything, "w1").
+		Return([]model.WebhookDelivery{{ID: "d1", WebhookID: "w1", Event: model.CandidateCreatedEvent, Status: model.DeliveryDelivered}}, nil)
+	mockWebhookService.On("FindWebhooksDeliveries", mock.Anything, mock.Anything).Return(nil, model.ErrWebhookDoesNotExist)
+	return mockWebhookService
+}
+
 func mockAssigneeService() *mocks.AssigneeService {
 	assignee := mockAssigneeModel()
 	mockAssigneeService := new(mocks.AssigneeService)
@@ -337,6 +764,8 @@ func mockAssigneeService() *mocks.AssigneeService {
 		Return(mockAssigneeArray(), nil).Once()
 	mockAssigneeService.On("FindAssigneeIDByName", mock.Anything, mock.AnythingOfType("string")).
 		Return(assignee.ID, nil).Once()
+	mockAssigneeService.On("CreateCalendarToken", mock.Anything, mock.AnythingOfType("string")).
+		Return("s3cr3t-t0ken", nil).Once()
 
 	return mockAssigneeService
 }
@@ -345,6 +774,8 @@ func mockAssigneeServiceDoesNotExistErr() *mocks.AssigneeService {
 	mockAssigneeService := new(mocks.AssigneeService)
 	mockAssigneeService.On("FindAssigneeIDByName", mock.Anything, mock.AnythingOfType("string")).
 		Return("", model.ErrAssigneeDoesNotExist).Once()
+	mockAssigneeService.On("CreateCalendarToken", mock.Anything, mock.AnythingOfType("string")).
+		Return("", model.ErrAssigneeDoesNotExist).Once()
 
 	return mockAssigneeService
 }
@@ -352,6 +783,7 @@ func mockAssigneeServiceDoesNotExistErr() *mocks.AssigneeService {
 func mockCandidateModel() model.Candidate {
 	return model.Candidate{
 		ID: "asd",
+		Version: 2,
 		FirstName: "FN",
 		LastName: "LN",
 		Email: "e@e.com",
@@ -369,6 +801,25 @@ func mockAssigneeModel() model.Assignee {
 	}
 }
 
+func mockAttachmentModel() model.Attachment {
+	return model.Attachment{
+		ID: "456",
+		CandidateID: "abcd",
+		Kind: model.AttachmentCV,
+		FileName: "Ayşe Kaya CV.pdf",
+		ContentType: "application/pdf",
+		Size: 11,
+	}
+}
+
+func mockWebhookModel() model.Webhook {
+	return model.Webhook{
+		ID: "w1",
+		URL: "https://ats.example.com/hooks",
+		Events: []string{model.CandidateCreatedEvent, model.MeetingArrangedEvent},
+	}
+}
+
 func mockMeetingModel() model.Meeting {
 	nextMeetingTime, _ := time.Parse(time.RFC3339, "2020-05-03T13:40:00.000+00:00")
 	return model.Meeting{
@@ -377,6 +828,22 @@ func mockMeetingModel() model.Meeting {
 	}
 }
 
+func mockMeetingDetailsModel() model.MeetingDetails {
+	meetingTime, _ := time.Parse(time.RFC3339, "2020-05-03T13:40:00.000+00:00")
+	candidate := mockCandidateModel()
+	return model.MeetingDetails{
+		Meeting: model.MeetingRecord{
+			ID: "5eb2e1a4b8e5f1a3c0d4e5f6",
+			CandidateID: candidate.ID,
+			AssigneeID: "asd123",
+			Time: meetingTime,
+			Status: model.MeetingArranged,
+		},
+		Candidate: candidate,
+		Assignee: mockAssigneeModel(),
+	}
+}
+
 func mockCandidateArray() []model.Candidate {
 	candidateArray := make([]model.Candidate, 5)
 	for i := 0; i < len(candidateArray); i++ {
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// calendarFeedURL returns the absolute url of the calendar feed of the assignee, as it is reached by the client
+func calendarFeedURL(req *http.Request, id string, token string) string {
+	scheme := "http"
+	if req.TLS != nil || strings.EqualFold(req.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+
+	feed := url.URL{
+		Scheme:   scheme,
+		Host:     req.Host,
+		Path:     "/assignees/" + id + "/calendar.ics",
+		RawQuery: url.Values{"token": {token}}.Encode(),
+	}
+	return feed.String()
+}
+
+// calendarFeedETag creates a strong entity tag from the content of the calendar feed
+func calendarFeedETag(feed []byte) string {
+	hash := sha256.Sum256(feed)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// etagMatches reports whether the If-None-Match header matches the entity tag, with the weak comparison
+// https://tools.ietf.org/html/rfc7232#section-3.2
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// limits of an uploaded attachment, the request may be slightly larger than the file because of the multipart encoding
+const (
+	maxAttachmentSize        = 10 << 20
+	maxAttachmentRequestSize = maxAttachmentSize + (1 << 20)
+	// sniffLength is the number of bytes that http.DetectContentType considers
+	sniffLength = 512
+)
+
+// attachmentType describes a content type that can be uploaded
+type attachmentType struct {
+	extensions []string
+	// matches checks the first bytes of the content are what is expected from the content type
+	matches func(head []byte) bool
+}
+
+// attachmentTypes are the content types of the documents that can be uploaded
+var attachmentTypes = map[string]attachmentType{
+	"application/pdf": {
+		extensions: []string{".pdf"},
+		matches:    sniffedAs("application/pdf"),
+	},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": {
+		extensions: []string{".docx"},
+		matches:    sniffedAs("application/zip"),
+	},
+	"application/vnd.oasis.opendocument.text": {
+		extensions: []string{".odt"},
+		matches:    sniffedAs("application/zip"),
+	},
+	"application/msword": {
+		extensions: []string{".doc"},
+		matches:    hasPrefix([]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}),
+	},
+	"text/plain": {
+		extensions: []string{".txt"},
+		matches:    sniffedAs("text/plain"),
+	},
+}
+
+// attachmentUpload is the uploaded file with its validated metadata
+type attachmentUpload struct {
+	attachment model.Attachment
+	content    io.Reader
+	file       multipart.File
+}
+
+// parseAttachmentUpload reads the attachment from the file field of a multipart form, and its kind from the kind field.
+// The content type is taken from the part header, or from the file extension if the client did not declare it,
+// and it must agree with the first bytes of the content.
+func parseAttachmentUpload(w http.ResponseWriter, req *http.Request) (attachmentUpload, error) {
+	req.Body = http.MaxBytesReader(w, req.Body, maxAttachmentRequestSize)
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return attachmentUpload{}, model.ErrAttachmentTooLarge
+		}
+		return attachmentUpload{}, model.ErrMalformedRequest.WithDetails(err.Error())
+	}
+
+	kind := req.FormValue("kind")
+	if !contains(model.GetAttachmentKindsAsArray(), kind) {
+		return attachmentUpload{}, model.ErrValidationFailed.WithDetails([]model.FieldError{{
+			Field:   "kind",
+			Rule:    "oneof",
+			Message: fmt.Sprintf("kind must be one of %v", model.GetAttachmentKindsAsArray()),
+		}})
+	}
+
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		return attachmentUpload{}, model.ErrMalformedRequest.WithDetails("file field is required: " + err.Error())
+	}
+	if header.Size > maxAttachmentSize {
+		file.Close()
+		return attachmentUpload{}, model.ErrAttachmentTooLarge
+	}
+
+	contentType, err := attachmentContentType(header)
+	if err != nil {
+		file.Close()
+		return attachmentUpload{}, err
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		file.Close()
+		return attachmentUpload{}, err
+	}
+	head = head[:n]
+	if n == 0 || !attachmentTypes[contentType].matches(head) {
+		file.Close()
+		return attachmentUpload{}, model.ErrAttachmentTypeNotSupported.WithDetails("content of the file is not " + contentType)
+	}
+
+	return attachmentUpload{
+		attachment: model.Attachment{
+			Kind:        kind,
+			FileName:    filepath.Base(header.Filename),
+			ContentType: contentType,
+		},
+		content: io.MultiReader(bytes.NewReader(head), file),
+		file:    file,
+	}, nil
+}
+
+// attachmentContentType finds the supported content type of the uploaded file
+func attachmentContentType(header *multipart.FileHeader) (string, error) {
+	declared := header.Header.Get("Content-Type")
+	if declared != "" {
+		mediaType, _, err := mime.ParseMediaType(declared)
+		if err != nil {
+			return "", model.ErrAttachmentTypeNotSupported.WithDetails("content type cannot be parsed: " + declared)
+		}
+		declared = mediaType
+	}
+
+	// clients send the files they do not recognize as octet-stream, so the extension is the only hint left
+	if declared == "" || declared == "application/octet-stream" {
+		extension := strings.ToLower(filepath.Ext(header.Filename))
+		for contentType, t := range attachmentTypes {
+			if contains(t.extensions, extension) {
+				return contentType, nil
+			}
+		}
+		return "", model.ErrAttachmentTypeNotSupported.WithDetails("file extension is not supported: " + extension)
+	}
+
+	if _, ok := attachmentTypes[declared]; !ok {
+		return "", model.ErrAttachmentTypeNotSupported.WithDetails("content type is not supported: " + declared)
+	}
+
+	return declared, nil
+}
+
+// contentDisposition creates the Content-Disposition header of a downloaded attachment,
+// non ASCII file names are encoded as RFC 2231 extended parameters
+func contentDisposition(fileName string) string {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": fileName})
+	if disposition == "" {
+		return "attachment"
+	}
+
+	return disposition
+}
+
+func sniffedAs(contentType string) func(head []byte) bool {
+	return func(head []byte) bool {
+		mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+		return mediaType == contentType
+	}
+}
+
+func hasPrefix(prefix []byte) func(head []byte) bool {
+	return func(head []byte) bool {
+		return bytes.HasPrefix(head, prefix)
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/spreadsheet"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// jsonLines is the export format that writes a json object per line
+const jsonLines = "jsonl"
+
+// maxSearchTerms is the number of words that the candidates can be searched with at once
+const maxSearchTerms = 10
+
+// exportColumns are the columns of the exported spreadsheets, and the fields of the exported json objects
+var exportColumns = []string{"id", "first_name", "last_name", "email", "department", "university", "experience",
+	"application_date", "status", "meeting_count", "next_meeting", "assignee_id", "assignee_name"}
+
+// candidateFilter reads the filter of the candidates from the query parameters
+func candidateFilter(req *http.Request) (model.CandidateFilter, error) {
+	query := req.URL.Query()
+	filter := model.CandidateFilter{
+		Status:     query.Get("status"),
+		Department: query.Get("department"),
+		University: query.Get("university"),
+		AssigneeID: query.Get("assignee"),
+		Search:     model.NewSearchTerms(query.Get("search")),
+	}
+
+	if filter.Status != "" && !contains(model.GetStatusesAsArray(), filter.Status) {
+		return filter, model.ErrValidationFailed.WithDetails([]model.FieldError{
+			{Field: "status", Rule: "oneof", Message: fmt.Sprintf("status must be one of %v", model.GetStatusesAsArray())},
+		})
+	}
+	if filter.Department != "" && !contains(model.GetDepartmentsAsArray(), filter.Department) {
+		return filter, model.ErrDepartmentDoesNotExist
+	}
+	if len(filter.Search) > maxSearchTerms {
+		return filter, model.ErrValidationFailed.WithDetails([]model.FieldError{
+			{Field: "search", Rule: "max", Message: fmt.Sprintf("search can have at most %d words", maxSearchTerms)},
+		})
+	}
+
+	return filter, nil
+}
+
+// candidateExporter writes the exported candidates in the requested format.
+// The response headers are only written with the first candidate, so the errors that happen
+// before any candidate is read can still be reported as an error response.
+type candidateExporter struct {
+	w        http.ResponseWriter
+	format   string
+	location *time.Location
+	started  bool
+	count    int
+	writer   spreadsheet.Writer
+	encoder  *json.Encoder
+}
+
+func newCandidateExporter(w http.ResponseWriter, format string, location *time.Location) *candidateExporter {
+	return &candidateExporter{w: w, format: format, location: location}
+}
+
+func (exporter *candidateExporter) start() error {
+	exporter.started = true
+
+	contentType := "application/x-ndjson"
+	if exporter.format != jsonLines {
+		contentType = spreadsheet.ContentType(exporter.format)
+	}
+	exporter.w.Header().Set("Content-Type", contentType)
+	exporter.w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="candidates-%s.%s"`, time.Now().In(exporter.location).Format("20060102"), exporter.format))
+
+	if exporter.format == jsonLines {
+		exporter.encoder = json.NewEncoder(exporter.w)
+		return nil
+	}
+
+	writer, err := spreadsheet.NewWriter(exporter.w, exporter.format)
+	if err != nil {
+		return err
+	}
+	exporter.writer = writer
+
+	return writer.Write(exportColumns)
+}
+
+func (exporter *candidateExporter) write(candidate model.ExportedCandidate) error {
+	if !exporter.started {
+		if err := exporter.start(); err != nil {
+			return err
+		}
+	}
+	exporter.count++
+
+	record := exporter.record(candidate)
+	if exporter.format == jsonLines {
+		return exporter.encoder.Encode(record)
+	}
+
+	return exporter.writer.Write(record.row())
+}
+
+// close completes the export. The header is written even if there are no candidates.
+func (exporter *candidateExporter) close() error {
+	if !exporter.started {
+		if err := exporter.start(); err != nil {
+			return err
+		}
+	}
+	if exporter.writer != nil {
+		return exporter.writer.Close()
+	}
+
+	return nil
+}
+
+// record creates the exported record of the candidate, where the timestamps are formatted in the time zone of the export
+func (exporter *candidateExporter) record(candidate model.ExportedCandidate) exportRecord {
+	record := exportRecord{
+		ID:           candidate.ID,
+		FirstName:    candidate.FirstName,
+		LastName:     candidate.LastName,
+		Email:        candidate.Email,
+		Department:   candidate.Department,
+		University:   candidate.University,
+		Experience:   candidate.Experience,
+		Status:       candidate.Status,
+		MeetingCount: candidate.MeetingCount,
+		AssigneeID:   candidate.Assignee,
+		AssigneeName: candidate.AssigneeName,
+	}
+	if !candidate.ApplicationDate.IsZero() {
+		record.ApplicationDate = candidate.ApplicationDate.In(exporter.location).Format(time.RFC3339)
+	}
+	if candidate.NextMeeting != nil {
+		record.NextMeeting = candidate.NextMeeting.In(exporter.location).Format(time.RFC3339)
+	}
+
+	return record
+}
+
+// exportRecord is an exported candidate, its fields are in the same order as the exported columns
+type exportRecord struct {
+	ID              string `json:"id"`
+	FirstName       string `json:"first_name"`
+	LastName        string `json:"last_name"`
+	Email           string `json:"email"`
+	Department      string `json:"department"`
+	University      string `json:"university"`
+	Experience      bool   `json:"experience"`
+	ApplicationDate string `json:"application_date"`
+	Status          string `json:"status"`
+	MeetingCount    int    `json:"meeting_count"`
+	NextMeeting     string `json:"next_meeting"`
+	AssigneeID      string `json:"assignee_id"`
+	AssigneeName    string `json:"assignee_name"`
+}
+
+func (record exportRecord) row() []string {
+	return []string{
+		record.ID,
+		record.FirstName,
+		record.LastName,
+		record.Email,
+		record.Department,
+		record.University,
+		strconv.FormatBool(record.Experience),
+		record.ApplicationDate,
+		record.Status,
+		strconv.Itoa(record.MeetingCount),
+		record.NextMeeting,
+		record.AssigneeID,
+		record.AssigneeName,
+	}
+}
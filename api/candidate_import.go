@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/spreadsheet"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// limits of an imported spreadsheet
+const (
+	maxImportSize = 10 << 20
+	maxImportRows = 5000
+)
+
+// importFields are the candidate fields that can be imported, with the columns that they are read from by default
+var importFields = []string{"first_name", "last_name", "email", "department", "university", "experience"}
+
+// requiredImportFields must have a column in the imported spreadsheet
+var requiredImportFields = []string{"email", "department", "university"}
+
+// importRequest is the uploaded spreadsheet with its import options
+type importRequest struct {
+	file    multipart.File
+	format  string
+	mapping map[string]string
+	dryRun  bool
+}
+
+// parseImportRequest reads the spreadsheet from the file field of a multipart form.
+// The format, mapping and dry_run options are read from the form or the query parameters.
+func parseImportRequest(w http.ResponseWriter, req *http.Request) (importRequest, error) {
+	req.Body = http.MaxBytesReader(w, req.Body, maxImportSize)
+	if err := req.ParseMultipartForm(maxImportSize); err != nil {
+		return importRequest{}, model.ErrMalformedRequest.WithDetails(err.Error())
+	}
+
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		return importRequest{}, model.ErrMalformedRequest.WithDetails("file field is required: " + err.Error())
+	}
+
+	request := importRequest{file: file}
+	request.format = strings.ToLower(req.FormValue("format"))
+	if request.format == "" {
+		request.format = strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), "."))
+	}
+	if request.format != spreadsheet.CSV && request.format != spreadsheet.XLSX {
+		file.Close()
+		return importRequest{}, model.ErrMalformedRequest.WithDetails("format must be csv or xlsx")
+	}
+
+	if dryRun := req.FormValue("dry_run"); dryRun != "" {
+		request.dryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			file.Close()
+			return importRequest{}, model.ErrMalformedRequest.WithDetails("dry_run must be true or false")
+		}
+	}
+
+	if mapping := req.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &request.mapping); err != nil {
+			file.Close()
+			return importRequest{}, model.ErrMalformedRequest.WithDetails("mapping must be a json object of field names to column names")
+		}
+	}
+
+	return request, nil
+}
+
+// readImportedCandidates creates a candidate from each row of the spreadsheet, whose first row is the header.
+// It returns the valid candidates in order, and a report where the invalid rows are already failed.
+func (a *api) readImportedCandidates(rows [][]string, mapping map[string]string) ([]model.Candidate, model.CandidateImportReport, error) {
+	report := model.CandidateImportReport{Rows: []model.CandidateImportRow{}}
+	if len(rows) == 0 {
+		return nil, report, model.ErrMalformedRequest.WithDetails("the spreadsheet does not have a header")
+	}
+	if len(rows)-1 > maxImportRows {
+		return nil, report, model.ErrMalformedRequest.WithDetails(fmt.Sprintf("the spreadsheet has more than %d rows", maxImportRows))
+	}
+
+	columns, err := mapColumns(rows[0], mapping)
+	if err != nil {
+		return nil, report, err
+	}
+
+	var candidates []model.Candidate
+	for i, row := range rows[1:] {
+		if isEmptyRow(row) {
+			continue
+		}
+
+		candidate, err := columns.candidate(row)
+		if err == nil {
+			err = a.ValidateCandidate(candidate)
+		}
+
+		importRow := model.CandidateImportRow{Row: i + 2, Email: candidate.Email}
+		if err != nil {
+			importRow.Status = model.ImportFailed
+			importRow.Error = importError(err)
+		} else {
+			candidates = append(candidates, candidate)
+		}
+		report.Rows = append(report.Rows, importRow)
+	}
+	report.Total = len(report.Rows)
+
+	return candidates, report, nil
+}
+
+// importColumns is the index of the column of each field in the spreadsheet
+type importColumns map[string]int
+
+// mapColumns finds the column of each field in the header. The columns are matched case-insensitively,
+// and a field is read from the column with its own name unless the mapping gives another column.
+func mapColumns(header []string, mapping map[string]string) (importColumns, error) {
+	for field := range mapping {
+		if !contains(importFields, field) {
+			return nil, model.ErrMalformedRequest.WithDetails(fmt.Sprintf("%s is not a field of the candidates, the fields are %s",
+				field, strings.Join(importFields, ", ")))
+		}
+	}
+
+	columns := importColumns{}
+	for _, field := range importFields {
+		column := field
+		if mapped, ok := mapping[field]; ok {
+			column = mapped
+		}
+
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(column)) {
+				columns[field] = i
+				break
+			}
+		}
+
+		if _, ok := columns[field]; !ok && contains(requiredImportFields, field) {
+			return nil, model.ErrMalformedRequest.WithDetails(fmt.Sprintf("column %s of the %s field is not in the header", column, field))
+		}
+	}
+
+	return columns, nil
+}
+
+// candidate creates a candidate from the values of the row
+func (columns importColumns) candidate(row []string) (model.Candidate, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	candidate := model.Candidate{
+		FirstName:  value("first_name"),
+		LastName:   value("last_name"),
+		Email:      value("email"),
+		Department: value("department"),
+		University: value("university"),
+	}
+
+	experience, ok := parseExperience(value("experience"))
+	if !ok {
+		return candidate, model.ErrValidationFailed.WithDetails([]model.FieldError{
+			{Field: "experience", Rule: "boolean", Message: "experience must be true or false"},
+		})
+	}
+	candidate.Experience = experience
+
+	return candidate, nil
+}
+
+// parseExperience reads the experience column, which is false when it is empty
+func parseExperience(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "", "false", "no", "0":
+		return false, true
+	case "true", "yes", "1":
+		return true, true
+	}
+
+	return false, false
+}
+
+// importError maps the error of a row to the error in the report, without exposing the unexpected errors
+func importError(err error) *model.Error {
+	var apiErr *model.Error
+	if !errors.As(err, &apiErr) {
+		return model.NewInternalError(err)
+	}
+
+	return apiErr
+}
+
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
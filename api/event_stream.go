@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"net/http"
+	"time"
+)
+
+const (
+	// streamHeartbeatInterval is the interval of the comments that keep the idle streams open through the proxies
+	streamHeartbeatInterval = 15 * time.Second
+	// streamRetryDelay is the delay that the clients wait before reconnecting to a closed stream
+	streamRetryDelay = 3 * time.Second
+)
+
+// eventStreamWriter writes the server-sent events, and flushes each of them to the client
+// https://html.spec.whatwg.org/multipage/server-sent-events.html
+type eventStreamWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+func newEventStreamWriter(w http.ResponseWriter) *eventStreamWriter {
+	return &eventStreamWriter{w: w, controller: http.NewResponseController(w)}
+}
+
+// send writes the event with its id, so the client sends it back as the Last-Event-ID when it reconnects
+func (stream *eventStreamWriter) send(event model.DomainEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if event.ID != "" {
+		if _, err := fmt.Fprintf(stream.w, "id: %s\n", event.ID); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(stream.w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+
+	return stream.controller.Flush()
+}
+
+// retry sets the reconnection delay of the client
+func (stream *eventStreamWriter) retry(delay time.Duration) error {
+	if _, err := fmt.Fprintf(stream.w, "retry: %d\n\n", delay.Milliseconds()); err != nil {
+		return err
+	}
+
+	return stream.controller.Flush()
+}
+
+// heartbeat writes a comment, which is ignored by the clients
+func (stream *eventStreamWriter) heartbeat() error {
+	if _, err := fmt.Fprint(stream.w, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+
+	return stream.controller.Flush()
+}
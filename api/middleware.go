@@ -1,15 +1,95 @@
 package api
 
 import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"log"
 	"net/http"
 	"time"
 )
 
+var tracer = otel.Tracer("github.com/cemalunal/sample-internship-management-api/api")
+
+// secretQueryParameters are the query parameters that carry credentials, such as the token of a calendar feed,
+// which are redacted from the logs
+var secretQueryParameters = []string{"token"}
+
 func RequestLogger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		log.Printf("%s %s %s \n", r.Method, r.RequestURI, time.Since(start))
+		log.Printf("%s %s %s \n", r.Method, redactedRequestURI(r), time.Since(start))
 		next.ServeHTTP(w, r)
 	})
 }
+
+// redactedRequestURI returns the uri of the request with the values of the secret query parameters redacted
+func redactedRequestURI(r *http.Request) string {
+	query := r.URL.Query()
+	redacted := false
+	for _, name := range secretQueryParameters {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return r.RequestURI
+	}
+
+	uri := *r.URL
+	uri.RawQuery = query.Encode()
+	return uri.RequestURI()
+}
+
+// statusRecorder keeps the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the original writer, so the streaming handlers can flush it through http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Tracing starts a server span for each request. The incoming W3C trace context
+// is extracted from the request headers, so the span joins the caller's trace
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, fmt.Sprintf("%s %s", r.Method, route),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+var (
+	spanRecorderOnce sync.Once
+	spanRecorder     *tracetest.SpanRecorder
+)
+
+// recordSpans installs a tracer provider that records the ended spans, the tracer of the package
+// delegates to the first provider that is installed, so it is shared by the tests
+func recordSpans() *tracetest.SpanRecorder {
+	spanRecorderOnce.Do(func() {
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return spanRecorder
+}
+
+// tracedRequest serves the request through the Tracing middleware, and returns the span of the request
+func tracedRequest(t *testing.T, req *http.Request, status int) sdktrace.ReadOnlySpan {
+	recorder := recordSpans()
+	router := mux.NewRouter()
+	router.HandleFunc("/candidates/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}).Methods(http.MethodGet)
+	router.Use(Tracing)
+
+	ended := len(recorder.Ended())
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, ended+1) {
+		t.FailNow()
+	}
+	return spans[len(spans)-1]
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing(t *testing.T) {
+	t.Run("route-and-status", func(t *testing.T) {
+		span := tracedRequest(t, httptest.NewRequest(http.MethodGet, "/candidates/5ea980281dafc611002fbc41", nil), http.StatusNotFound)
+
+		assert.Equal(t, "GET /candidates/{id}", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, "/candidates/{id}", spanAttribute(span, "http.route").AsString())
+		assert.Equal(t, "/candidates/5ea980281dafc611002fbc41", spanAttribute(span, "url.path").AsString())
+		assert.Equal(t, int64(http.StatusNotFound), spanAttribute(span, "http.response.status_code").AsInt64())
+		// the client errors are not the errors of the server
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	})
+
+	t.Run("server-error", func(t *testing.T) {
+		span := tracedRequest(t, httptest.NewRequest(http.MethodGet, "/candidates/5ea980281dafc611002fbc41", nil), http.StatusInternalServerError)
+
+		assert.Equal(t, int64(http.StatusInternalServerError), spanAttribute(span, "http.response.status_code").AsInt64())
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, "Internal Server Error", span.Status().Description)
+	})
+
+	t.Run("traceparent", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/candidates/5ea980281dafc611002fbc41", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		span := tracedRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+		assert.True(t, span.Parent().IsRemote())
+	})
+}
+
+func TestRedactedRequestURI(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want string
+	}{
+		{"without-query", "/candidates/abcd", "/candidates/abcd"},
+		{"without-secrets", "/candidates?status=Pending", "/candidates?status=Pending"},
+		{"token", "/assignees/a1/calendar.ics?token=secret", "/assignees/a1/calendar.ics?token=REDACTED"},
+		{"token-with-other-parameters", "/assignees/a1/calendar.ics?b=2&token=secret&a=1", "/assignees/a1/calendar.ics?a=1&b=2&token=REDACTED"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.uri, nil)
+			assert.Equal(t, test.want, redactedRequestURI(req))
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type fileSystemStore struct {
+	dir string
+}
+
+// FileSystem will create an implementation of BlobStore that keeps each blob in a file of the given directory.
+// The directory is created if it does not exist.
+func FileSystem(dir string) (model.BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &fileSystemStore{dir: dir}, nil
+}
+
+// Put writes the content to a temporary file first, so a failed upload never leaves a partial blob behind
+func (store *fileSystemStore) Put(ctx context.Context, key string, content io.Reader) (int64, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.CreateTemp(store.dir, ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+
+	size, err := io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return size, os.Rename(file.Name(), path)
+}
+
+func (store *fileSystemStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, model.ErrAttachmentDoesNotExist
+	}
+
+	return file, err
+}
+
+// Delete does not return an error for the blobs that do not exist
+func (store *fileSystemStore) Delete(ctx context.Context, key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// path maps the key to a file of the directory, keys that could point outside of it are rejected
+func (store *fileSystemStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(store.dir, key), nil
+}
@@ -0,0 +1,50 @@
+package blob
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	store, err := FileSystem(dir)
+	assert.NoError(t, err)
+
+	t.Run("put-and-get", func(t *testing.T) {
+		size, err := store.Put(context.TODO(), "abc", strings.NewReader("content"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), size)
+
+		content, err := store.Get(context.TODO(), "abc")
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(content)
+		_ = content.Close()
+		assert.Equal(t, "content", string(data))
+
+		// the temporary file of the upload is not left behind
+		entries, _ := os.ReadDir(dir)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, _ = store.Put(context.TODO(), "def", strings.NewReader("content"))
+
+		assert.NoError(t, store.Delete(context.TODO(), "def"))
+		_, err := store.Get(context.TODO(), "def")
+		assert.Equal(t, model.ErrAttachmentDoesNotExist, err)
+		// deleting again is not an error
+		assert.NoError(t, store.Delete(context.TODO(), "def"))
+	})
+
+	t.Run("invalid-keys", func(t *testing.T) {
+		for _, key := range []string{"", "..", "../abc", "a/b", `a\\b`, ".hidden"} {
+			_, err := store.Put(context.TODO(), key, strings.NewReader("content"))
+			assert.Error(t, err, key)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package blob
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"io"
+)
+
+// bucketName is the prefix of the files and chunks collections of the attachments
+const bucketName = "attachments"
+
+type gridFSStore struct {
+	bucket *gridfs.Bucket
+}
+
+// GridFS will create an implementation of BlobStore that keeps the blobs in a GridFS bucket of the given database
+func GridFS(database *mongo.Database) (model.BlobStore, error) {
+	bucket, err := gridfs.NewBucket(database, options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &gridFSStore{bucket: bucket}, nil
+}
+
+func (store *gridFSStore) Put(ctx context.Context, key string, content io.Reader) (int64, error) {
+	counter := &countingReader{reader: content}
+	if err := store.bucket.UploadFromStreamWithID(key, key, counter); err != nil {
+		return 0, err
+	}
+
+	return counter.count, nil
+}
+
+func (store *gridFSStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	stream, err := store.bucket.OpenDownloadStream(key)
+	if err == gridfs.ErrFileNotFound {
+		return nil, model.ErrAttachmentDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// Delete does not return an error for the blobs that do not exist
+func (store *gridFSStore) Delete(ctx context.Context, key string) error {
+	err := store.bucket.Delete(key)
+	if err == gridfs.ErrFileNotFound {
+		return nil
+	}
+
+	return err
+}
+
+// countingReader counts the bytes that are read, since the bucket does not report the size of the uploaded file
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,187 @@
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	netmail "net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// methods of the calendars that are sent by email, https://tools.ietf.org/html/rfc5546#section-1.4
+// The calendars that are downloaded do not have a method.
+const (
+	// MethodRequest is used for the calendars that invite the attendees by email
+	MethodRequest = "REQUEST"
+	// MethodCancel is used for the calendars of the cancelled meetings
+	MethodCancel = "CANCEL"
+)
+
+// ContentType is the media type of the calendars
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	productID = "-//Internship Management//Meetings//EN"
+	uidDomain = "internship-management"
+	// the calendar applications are asked to refresh the feeds every 15 minutes
+	refreshInterval = "PT15M"
+	timeFormat      = "20060102T150405Z"
+	// content lines are folded after 75 octets, https://tools.ietf.org/html/rfc5545#section-3.1
+	maxLineLength = 75
+)
+
+// UID returns the unique identifier of the calendar event of the meeting. It does not change when the meeting is changed,
+// so the calendar applications update the same event.
+func UID(meetingID string) string {
+	return meetingID + "@" + uidDomain
+}
+
+// Method returns the method of the calendar that is sent by email for the meeting
+func Method(meeting model.MeetingRecord) string {
+	if meeting.Status == model.MeetingCancelled {
+		return MethodCancel
+	}
+
+	return MethodRequest
+}
+
+// Event writes the meeting as an RFC 5545 calendar that contains a single event, https://tools.ietf.org/html/rfc5545.
+// The candidate is the attendee of the event, and organizer is the optional email address that the event is organized by.
+// Stamp is the time that the calendar is created at. Method is empty for the calendars that are downloaded,
+// otherwise the event of a cancelled meeting is always sent with MethodCancel.
+func Event(details model.MeetingDetails, method string, organizer string, stamp time.Time) []byte {
+	if details.Meeting.Status == model.MeetingCancelled && method != "" {
+		method = MethodCancel
+	}
+
+	var buffer bytes.Buffer
+	writeHeader(&buffer)
+	if method != "" {
+		writeLine(&buffer, "METHOD:"+method)
+	}
+	writeEvent(&buffer, details, organizer, stamp)
+	writeLine(&buffer, "END:VCALENDAR")
+
+	return buffer.Bytes()
+}
+
+// Feed writes the meetings as an RFC 5545 calendar that the calendar applications subscribe to.
+// The stamp of each event is the time that its meeting was last changed, so the feed does not change until its meetings change.
+func Feed(name string, meetings []model.MeetingDetails) []byte {
+	var buffer bytes.Buffer
+	writeHeader(&buffer)
+	writeLine(&buffer, "X-WR-CALNAME:"+escapeText(name))
+	writeLine(&buffer, "REFRESH-INTERVAL;VALUE=DURATION:"+refreshInterval)
+	writeLine(&buffer, "X-PUBLISHED-TTL:"+refreshInterval)
+	for _, details := range meetings {
+		writeEvent(&buffer, details, "", lastChanged(details.Meeting))
+	}
+	writeLine(&buffer, "END:VCALENDAR")
+
+	return buffer.Bytes()
+}
+
+// lastChanged returns the time that the meeting was last changed
+func lastChanged(meeting model.MeetingRecord) time.Time {
+	if len(meeting.Changes) > 0 {
+		return meeting.Changes[len(meeting.Changes)-1].At
+	}
+	if meeting.ArrangedAt.IsZero() {
+		// the meetings that are arranged before the meeting records were kept are not known to change
+		return meeting.Time
+	}
+
+	return meeting.ArrangedAt
+}
+
+func writeHeader(buffer *bytes.Buffer) {
+	writeLine(buffer, "BEGIN:VCALENDAR")
+	writeLine(buffer, "VERSION:2.0")
+	writeLine(buffer, "PRODID:"+productID)
+	writeLine(buffer, "CALSCALE:GREGORIAN")
+}
+
+func writeEvent(buffer *bytes.Buffer, details model.MeetingDetails, organizer string, stamp time.Time) {
+	meeting, candidate := details.Meeting, details.Candidate
+	status := "CONFIRMED"
+	if meeting.Status == model.MeetingCancelled {
+		status = "CANCELLED"
+	}
+
+	writeLine(buffer, "BEGIN:VEVENT")
+	writeLine(buffer, "UID:"+UID(meeting.ID))
+	writeLine(buffer, fmt.Sprintf("SEQUENCE:%d", meeting.Sequence))
+	writeLine(buffer, "DTSTAMP:"+formatTime(stamp))
+	writeLine(buffer, "DTSTART:"+formatTime(meeting.Time))
+	writeLine(buffer, "DTEND:"+formatTime(meeting.Time.Add(model.MeetingDuration)))
+	writeLine(buffer, "SUMMARY:"+escapeText(summary(details)))
+	writeLine(buffer, "DESCRIPTION:"+escapeText(description(details)))
+	writeLine(buffer, "STATUS:"+status)
+	if address, err := netmail.ParseAddress(organizer); err == nil {
+		writeLine(buffer, "ORGANIZER"+commonName(address.Name)+":mailto:"+address.Address)
+	}
+	if candidate.Email != "" {
+		name := strings.TrimSpace(candidate.FirstName + " " + candidate.LastName)
+		writeLine(buffer, "ATTENDEE"+commonName(name)+";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=FALSE:mailto:"+candidate.Email)
+	}
+	writeLine(buffer, "END:VEVENT")
+}
+
+func summary(details model.MeetingDetails) string {
+	name := strings.TrimSpace(details.Candidate.FirstName + " " + details.Candidate.LastName)
+	if name == "" {
+		name = details.Candidate.Email
+	}
+
+	return fmt.Sprintf("Internship interview: %s (%s)", name, details.Candidate.Department)
+}
+
+func description(details model.MeetingDetails) string {
+	lines := []string{fmt.Sprintf("Interview with the candidate for the %s internship.", details.Candidate.Department)}
+	if details.Assignee.Name != "" {
+		lines = append(lines, "Assignee: "+details.Assignee.Name)
+	}
+	if details.Candidate.University != "" {
+		lines = append(lines, "University: "+details.Candidate.University)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// commonName returns the CN parameter of the name, the name is quoted as it may contain the separators of the parameters
+func commonName(name string) string {
+	name = strings.NewReplacer(`"`, "'", "\r", "", "\n", " ").Replace(strings.TrimSpace(name))
+	if name == "" {
+		return ""
+	}
+
+	return `;CN="` + name + `"`
+}
+
+// escapeText escapes the special characters of a TEXT value, https://tools.ietf.org/html/rfc5545#section-3.3.11
+func escapeText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(text)
+}
+
+// writeLine writes a content line, folding it without splitting the multi-byte characters
+func writeLine(buffer *bytes.Buffer, line string) {
+	length := 0
+	for len(line) > 0 {
+		r, size := utf8.DecodeRuneInString(line)
+		if length+size > maxLineLength {
+			// the continuation lines start with a space, which counts towards their length
+			buffer.WriteString("\r\n ")
+			length = 1
+		}
+		buffer.WriteRune(r)
+		length += size
+		line = line[size:]
+	}
+	buffer.WriteString("\r\n")
+}
@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	stamp := time.Date(2020, 5, 1, 9, 0, 0, 0, time.UTC)
+	details := model.MeetingDetails{
+		Meeting: model.MeetingRecord{
+			ID:          "5eb2e1a4b8e5f1a3c0d4e5f6",
+			CandidateID: "abcd",
+			AssigneeID:  "a1",
+			Time:        time.Date(2020, 5, 3, 16, 40, 0, 0, time.FixedZone("+03", 3*60*60)),
+			Status:      model.MeetingArranged,
+		},
+		Candidate: model.Candidate{ID: "abcd", FirstName: "Ayşe", LastName: "Kaya", Email: "ayse@e.com",
+			Department: model.Design, University: "Hacettepe; Ankara, Turkey"},
+		Assignee: model.Assignee{ID: "a1", Name: "Zafer", Department: model.Design},
+	}
+
+	t.Run("request", func(t *testing.T) {
+		event := string(Event(details, MethodRequest, "Internship Team <internship@example.com>", stamp))
+
+		assert.True(t, strings.HasPrefix(event, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		assert.True(t, strings.HasSuffix(event, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+		for _, line := range []string{
+			"METHOD:REQUEST",
+			"UID:5eb2e1a4b8e5f1a3c0d4e5f6@internship-management",
+			"SEQUENCE:0",
+			"DTSTAMP:20200501T090000Z",
+			// the times are written in UTC
+			"DTSTART:20200503T134000Z",
+			"DTEND:20200503T144000Z",
+			"STATUS:CONFIRMED",
+			`ORGANIZER;CN="Internship Team":mailto:internship@example.com`,
+		} {
+			assert.Contains(t, event, "\r\n"+line+"\r\n")
+		}
+		assert.Contains(t, unfold(event), "\r\nSUMMARY:Internship interview: Ayşe Kaya (Design)\r\n")
+		assert.Contains(t, unfold(event), `\nUniversity: Hacettepe\; Ankara\, Turkey`)
+		assert.Contains(t, unfold(event), `ATTENDEE;CN="Ayşe Kaya";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=FALSE:mailto:ayse@e.com`)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		event := string(Event(details, "", "", stamp))
+
+		assert.NotContains(t, event, "METHOD:")
+		assert.NotContains(t, event, "ORGANIZER")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled := details
+		cancelled.Meeting.Status = model.MeetingCancelled
+		cancelled.Meeting.Sequence = 2
+
+		assert.Equal(t, MethodCancel, Method(cancelled.Meeting))
+		event := string(Event(cancelled, MethodRequest, "", stamp))
+
+		assert.Contains(t, event, "\r\nMETHOD:CANCEL\r\n")
+		assert.Contains(t, event, "\r\nSTATUS:CANCELLED\r\n")
+		// the cancelled event replaces the event that is arranged
+		assert.Contains(t, event, "\r\nUID:5eb2e1a4b8e5f1a3c0d4e5f6@internship-management\r\n")
+		assert.Contains(t, event, "\r\nSEQUENCE:2\r\n")
+	})
+
+	t.Run("lines-are-folded", func(t *testing.T) {
+		long := details
+		long.Candidate.University = strings.Repeat("Üniversite ", 20)
+		event := string(Event(long, MethodRequest, "", stamp))
+
+		for _, line := range strings.Split(strings.TrimSuffix(event, "\r\n"), "\r\n") {
+			assert.LessOrEqual(t, len(line), maxLineLength)
+			assert.True(t, strings.ToValidUTF8(line, "") == line, "multi-byte characters are not split")
+		}
+		assert.Contains(t, unfold(event), strings.Repeat("Üniversite ", 20))
+	})
+}
+
+// unfold joins the folded content lines
+func unfold(event string) string {
+	return strings.ReplaceAll(event, "\r\n ", "")
+}
+
+func TestFeed(t *testing.T) {
+	arrangedAt := time.Date(2020, 5, 1, 9, 0, 0, 0, time.UTC)
+	meetings := []model.MeetingDetails{
+		{
+			Meeting:   model.MeetingRecord{ID: "m1", Time: time.Date(2020, 5, 3, 13, 40, 0, 0, time.UTC), ArrangedAt: arrangedAt, Status: model.MeetingArranged},
+			Candidate: model.Candidate{FirstName: "Ayşe", Email: "ayse@e.com", Department: model.Design},
+		},
+		{
+			Meeting:   model.MeetingRecord{ID: "m2", Time: time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC), Status: model.MeetingCancelled, Sequence: 1},
+			Candidate: model.Candidate{FirstName: "Ali", Email: "ali@e.com", Department: model.Design},
+		},
+	}
+
+	feed := string(Feed("Zafer, Design", meetings))
+
+	assert.NotContains(t, feed, "METHOD:")
+	assert.Contains(t, feed, "\r\nX-WR-CALNAME:Zafer\\, Design\r\n")
+	assert.Equal(t, 2, strings.Count(feed, "BEGIN:VEVENT"))
+	assert.Contains(t, feed, "\r\nUID:m1@internship-management\r\nSEQUENCE:0\r\nDTSTAMP:20200501T090000Z\r\n")
+	// the meetings without the time that they are arranged at are stamped with their own time
+	assert.Contains(t, feed, "\r\nUID:m2@internship-management\r\nSEQUENCE:1\r\nDTSTAMP:20200504T100000Z\r\n")
+	assert.Contains(t, feed, "\r\nSTATUS:CANCELLED\r\n")
+	// the feed is the same until the meetings change
+	assert.Equal(t, feed, string(Feed("Zafer, Design", meetings)))
+
+	rescheduledAt := time.Date(2020, 5, 2, 11, 30, 0, 0, time.UTC)
+	meetings[0].Meeting.Sequence = 1
+	meetings[0].Meeting.Changes = []model.MeetingChange{{Type: model.MeetingRescheduled, At: rescheduledAt, PreviousTime: meetings[0].Meeting.Time}}
+	// the meetings that are changed are stamped with the time of their last change
+	assert.Contains(t, string(Feed("Zafer, Design", meetings)), "\r\nUID:m1@internship-management\r\nSEQUENCE:1\r\nDTSTAMP:20200502T113000Z\r\n")
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"net/url"
+)
+
+func createAssignee(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("assignees create")
+	name := flags.String("name", "", "name of the assignee")
+	department := flags.String("department", "", "department of the assignee")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if *name == "" || *department == "" {
+		return usageError("-name and -department are required")
+	}
+	if err := checkDepartmentExists(*department); err != nil {
+		return err
+	}
+
+	assignee, err := env.AssigneeService.CreateAssignee(ctx, model.Assignee{Name: *name, Department: *department})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Assignee %s is created with id %s\n", assignee.Name, assignee.ID)
+	return nil
+}
+
+func listAssignees(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("assignees list")
+	output := outputFlag(flags)
+	department := flags.String("department", "", "only list the assignees of the department")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	var assignees []model.Assignee
+	var err error
+	if *department != "" {
+		if err := checkDepartmentExists(*department); err != nil {
+			return err
+		}
+		assignees, err = env.AssigneeService.FindAllAssigneesByDepartment(ctx, *department)
+	} else {
+		assignees, err = env.AssigneeService.FindAllAssignees(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printAssignees(env.Stdout, *output, assignees)
+}
+
+// createCalendarToken prints the new calendar token of the assignee, along with the path of the calendar feed on the rest api
+func createCalendarToken(ctx context.Context, env Environment, args []string) error {
+	id, err := singleArgument(newFlagSet("assignees calendar-token"), args, "assignee id")
+	if err != nil {
+		return err
+	}
+
+	token, err := env.AssigneeService.CreateCalendarToken(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Calendar token of assignee %s is %s\n", id, token)
+	_, _ = fmt.Fprintf(env.Stdout, "The calendar feed is served at /assignees/%s/calendar.ics?token=%s\n", id, url.QueryEscape(token))
+	return nil
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+)
+
+func listCandidates(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("candidates list")
+	output := outputFlag(flags)
+	var filter model.CandidateFilter
+	flags.StringVar(&filter.AssigneeID, "assignee", "", "only list the candidates of the assignee with given id")
+	flags.StringVar(&filter.Status, "status", "", "only list the candidates with the status")
+	flags.StringVar(&filter.Department, "department", "", "only list the candidates of the department")
+	flags.StringVar(&filter.University, "university", "", "only list the candidates of the university")
+	search := flags.String("search", "", "only list the candidates whose fields or attachments contain all words")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	filter.Search = model.NewSearchTerms(*search)
+	if filter.Department != "" {
+		if err := checkDepartmentExists(filter.Department); err != nil {
+			return err
+		}
+	}
+
+	candidates, err := env.CandidateService.FindCandidates(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	return printCandidates(env.Stdout, *output, candidates)
+}
+
+func showCandidate(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("candidates show")
+	output := outputFlag(flags)
+	id, err := singleArgument(flags, args, "candidate id")
+	if err != nil {
+		return err
+	}
+
+	candidate, err := env.CandidateService.ReadCandidate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return printCandidate(env.Stdout, *output, candidate)
+}
+
+func denyCandidate(ctx context.Context, env Environment, args []string) error {
+	id, err := singleArgument(newFlagSet("candidates deny"), args, "candidate id")
+	if err != nil {
+		return err
+	}
+
+	if err := env.CandidateService.DenyCandidate(ctx, id); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Candidate %s is denied\n", id)
+	return nil
+}
+
+func acceptCandidate(ctx context.Context, env Environment, args []string) error {
+	id, err := singleArgument(newFlagSet("candidates accept"), args, "candidate id")
+	if err != nil {
+		return err
+	}
+
+	if err := env.CandidateService.AcceptCandidate(ctx, id); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Candidate %s is accepted\n", id)
+	return nil
+}
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/api"
+	"github.com/cemalunal/sample-internship-management-api/migrations"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/gorilla/mux"
+	"io"
+	"strings"
+	"sync"
+)
+
+// usage is printed when the command is unknown or missing its arguments
+const usage = `Usage: server [command]
+
+Commands:
+  serve                                                    start the rest api (default)
+  migrate [-dry-run]                                       apply the pending data migrations
+  candidates list [-status S] [-department D] [-university U] [-assignee ID] [-search WORDS]
+                                                           list the candidates
+  candidates show ID                                       show a candidate
+  candidates deny ID                                       deny a candidate
+  candidates accept ID                                     accept a candidate
+  assignees create -name NAME -department DEPARTMENT       create an assignee
+  assignees list [-department DEPARTMENT]                  list the assignees
+  assignees calendar-token ID                              create a new token for the calendar feed of an assignee
+  meetings arrange -candidate ID -time 2006-01-02T15:04:05Z07:00
+                                                           arrange the next meeting of a candidate
+  meetings reschedule -candidate ID -time 2006-01-02T15:04:05Z07:00
+                                                           move the arranged meeting of a candidate to another time
+  meetings cancel -candidate ID -reason REASON             cancel the arranged meeting of a candidate
+  meetings complete ID                                     complete the meeting of a candidate
+  meetings no-show ID                                      record that a candidate has missed the meeting
+  seed [-reset] FILE                                       load the assignees and candidates of a YAML or JSON fixture
+  export [-file FILE] [-format yaml|json]                  export the assignees and candidates as a fixture
+
+The list and show commands accept -output table|json.
+`
+
+// ErrUsage is returned when the command line cannot be parsed
+var ErrUsage = errors.New("invalid command line")
+
+// Environment contains the services that the commands are run against.
+// The repositories are only used to load and dump fixtures, and the migrator is nil for the in-memory backend.
+type Environment struct {
+	AssigneeService     model.AssigneeService
+	CandidateService    model.CandidateService
+	AttachmentService   model.AttachmentService
+	WebhookService      model.WebhookService
+	EventStream         model.EventStream
+	ReportService       model.ReportService
+	AssigneeRepository  model.AssigneeRepository
+	CandidateRepository model.CandidateRepository
+	Migrator            *migrations.Migrator
+	Stdout              io.Writer
+	// Background are the workers that run along with the rest api, such as the delivery of the notifications
+	Background []func(ctx context.Context)
+}
+
+// Run runs the command given with the command line arguments. Without a command, the rest api is started.
+func Run(ctx context.Context, env Environment, args []string) error {
+	if len(args) == 0 {
+		return serve(ctx, env)
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "serve":
+		return serve(ctx, env)
+	case "migrate":
+		return migrate(ctx, env, args)
+	case "candidates":
+		return runSubcommand(ctx, env, args, map[string]func(context.Context, Environment, []string) error{
+			"list":   listCandidates,
+			"show":   showCandidate,
+			"deny":   denyCandidate,
+			"accept": acceptCandidate,
+		})
+	case "assignees":
+		return runSubcommand(ctx, env, args, map[string]func(context.Context, Environment, []string) error{
+			"create":         createAssignee,
+			"list":           listAssignees,
+			"calendar-token": createCalendarToken,
+		})
+	case "meetings":
+		return runSubcommand(ctx, env, args, map[string]func(context.Context, Environment, []string) error{
+			"arrange":    arrangeMeeting,
+			"reschedule": rescheduleMeeting,
+			"cancel":     cancelMeeting,
+			"complete":   completeMeeting,
+			"no-show":    recordNoShow,
+		})
+	case "seed":
+		return seedFixture(ctx, env, args)
+	case "export":
+		return export(ctx, env, args)
+	case "help", "-h", "-help", "--help":
+		_, _ = fmt.Fprint(env.Stdout, usage)
+		return nil
+	}
+
+	return usageError("unknown command %q", command)
+}
+
+// Usage returns the usage text of the commands
+func Usage() string {
+	return usage
+}
+
+func runSubcommand(ctx context.Context, env Environment, args []string, subcommands map[string]func(context.Context, Environment, []string) error) error {
+	if len(args) == 0 {
+		return usageError("missing subcommand")
+	}
+
+	subcommand, ok := subcommands[args[0]]
+	if !ok {
+		return usageError("unknown subcommand %q", args[0])
+	}
+
+	return subcommand(ctx, env, args[1:])
+}
+
+// serve runs the background workers along with the rest api until the context is done,
+// and waits for the workers to stop, so they can release their locks
+func serve(ctx context.Context, env Environment) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var workers sync.WaitGroup
+	for _, worker := range env.Background {
+		workers.Add(1)
+		go func(worker func(ctx context.Context)) {
+			defer workers.Done()
+			worker(ctx)
+		}(worker)
+	}
+
+	var options []api.Option
+	if env.AttachmentService != nil {
+		options = append(options, api.WithAttachments(env.AttachmentService))
+	}
+	if env.WebhookService != nil {
+		options = append(options, api.WithWebhooks(env.WebhookService))
+	}
+	if env.EventStream != nil {
+		options = append(options, api.WithEventStream(env.EventStream))
+	}
+	if env.ReportService != nil {
+		options = append(options, api.WithReports(env.ReportService))
+	}
+
+	err := api.Api(ctx, mux.NewRouter(), env.AssigneeService, env.CandidateService, options...)
+	// the workers are stopped as well when the rest api cannot be served
+	cancel()
+	workers.Wait()
+	return err
+}
+
+func migrate(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("migrate")
+	dryRun := flags.Bool("dry-run", false, "report the documents that would be changed without changing them")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	if env.Migrator == nil {
+		return errors.New("migrations are only supported with the mongodb storage backend")
+	}
+
+	results, err := env.Migrator.Up(ctx, *dryRun)
+	for _, result := range results {
+		if result.DryRun {
+			_, _ = fmt.Fprintf(env.Stdout, "Migration %d (%s) would change %d documents\n", result.Version, result.Description, result.Affected)
+		} else {
+			_, _ = fmt.Fprintf(env.Stdout, "Migration %d (%s) changed %d documents\n", result.Version, result.Description, result.Affected)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(env.Stdout, "All migrations are already applied")
+	}
+
+	return nil
+}
+
+// newFlagSet creates a flag set that returns the parse errors instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// outputFlag adds the output format flag to the given flag set
+func outputFlag(flags *flag.FlagSet) *string {
+	return flags.String("output", tableOutput, "output format, table or json")
+}
+
+// singleArgument returns the only positional argument of the command, after parsing the flags
+func singleArgument(flags *flag.FlagSet, args []string, name string) (string, error) {
+	if err := flags.Parse(args); err != nil {
+		return "", usageError(err.Error())
+	}
+	if flags.NArg() != 1 || strings.TrimSpace(flags.Arg(0)) == "" {
+		return "", usageError("expected a single %s argument", name)
+	}
+
+	return flags.Arg(0), nil
+}
+
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrUsage, fmt.Sprintf(format, args...))
+}
+
+// checkDepartmentExists returns an error if the given department does not exist in the system
+func checkDepartmentExists(department string) error {
+	for _, d := range model.GetDepartmentsAsArray() {
+		if d == department {
+			return nil
+		}
+	}
+
+	return model.ErrDepartmentDoesNotExist
+}
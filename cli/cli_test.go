@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/model/mocks"
+	"github.com/cemalunal/sample-internship-management-api/repository"
+	"github.com/cemalunal/sample-internship-management-api/seed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestEnvironment() (Environment, *mocks.AssigneeService, *mocks.CandidateService, *bytes.Buffer) {
+	assigneeService := new(mocks.AssigneeService)
+	candidateService := new(mocks.CandidateService)
+	stdout := new(bytes.Buffer)
+
+	return Environment{
+		AssigneeService:  assigneeService,
+		CandidateService: candidateService,
+		Stdout:           stdout,
+	}, assigneeService, candidateService, stdout
+}
+
+func TestCli_ListCandidates(t *testing.T) {
+	candidates := []model.Candidate{{ID: "abcd", FirstName: "FN", LastName: "LN", Email: "e@e.com",
+		Department: model.Development, Status: model.Pending}}
+
+	t.Run("table", func(t *testing.T) {
+		env, _, candidateService, stdout := newTestEnvironment()
+		candidateService.On("FindCandidates", mock.Anything, model.CandidateFilter{}).Return(candidates, nil).Once()
+
+		err := Run(context.TODO(), env, []string{"candidates", "list"})
+
+		assert.NoError(t, err)
+		assert.Contains(t, stdout.String(), "NAME")
+		assert.Contains(t, stdout.String(), "FN LN")
+		candidateService.AssertExpectations(t)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		env, _, candidateService, stdout := newTestEnvironment()
+		candidateService.On("FindCandidates", mock.Anything, model.CandidateFilter{AssigneeID: "qwe", Status: model.Pending}).
+			Return(candidates, nil).Once()
+
+		err := Run(context.TODO(), env, []string{"candidates", "list", "-assignee", "qwe", "-status", model.Pending, "-output", "json"})
+
+		var printed []model.Candidate
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(stdout.Bytes(), &printed))
+		assert.Equal(t, candidates[0].ID, printed[0].ID)
+		candidateService.AssertExpectations(t)
+	})
+
+	t.Run("unknown-output", func(t *testing.T) {
+		env, _, candidateService, _ := newTestEnvironment()
+		candidateService.On("FindCandidates", mock.Anything, model.CandidateFilter{}).Return(candidates, nil).Once()
+
+		err := Run(context.TODO(), env, []string{"candidates", "list", "-output", "xml"})
+
+		assert.ErrorIs(t, err, ErrUsage)
+	})
+}
+
+func TestCli_ShowCandidate(t *testing.T) {
+	t.Run("candidate-does-not-exist", func(t *testing.T) {
+		env, _, candidateService, _ := newTestEnvironment()
+		candidateService.On("ReadCandidate", mock.Anything, "abcd").
+			Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
+
+		err := Run(context.TODO(), env, []string{"candidates", "show", "abcd"})
+
+		assert.ErrorIs(t, err, model.ErrCandidateDoesNotExist)
+		candidateService.AssertExpectations(t)
+	})
+
+	t.Run("missing-id", func(t *testing.T) {
+		env, _, _, _ := newTestEnvironment()
+
+		err := Run(context.TODO(), env, []string{"candidates", "show"})
+
+		assert.ErrorIs(t, err, ErrUsage)
+	})
+}
+
+func TestCli_AcceptCandidate(t *testing.T) {
+	env, _, candidateService, stdout := newTestEnvironment()
+	candidateService.On("AcceptCandidate", mock.Anything, "abcd").Return(nil).Once()
+
+	err := Run(context.TODO(), env, []string{"candidates", "accept", "abcd"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Candidate abcd is accepted\n", stdout.String())
+	candidateService.AssertExpectations(t)
+}
+
+func TestCli_CreateAssignee(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		env, assigneeService, _, _ := newTestEnvironment()
+		assigneeService.On("CreateAssignee", mock.Anything, model.Assignee{Name: "Zafer", Department: model.CEO}).
+			Return(model.Assignee{ID: "abcd", Name: "Zafer", Department: model.CEO}, nil).Once()
+
+		err := Run(context.TODO(), env, []string{"assignees", "create", "-name", "Zafer", "-department", model.CEO})
+
+		assert.NoError(t, err)
+		assigneeService.AssertExpectations(t)
+	})
+
+	t.Run("department-does-not-exist", func(t *testing.T) {
+		env, _, _, _ := newTestEnvironment()
+
+		err := Run(context.TODO(), env, []string{"assignees", "create", "-name", "Zafer", "-department", "test"})
+
+		assert.ErrorIs(t, err, model.ErrDepartmentDoesNotExist)
+	})
+}
+
+func TestCli_CreateCalendarToken(t *testing.T) {
+	env, assigneeService, _, stdout := newTestEnvironment()
+	assigneeService.On("CreateCalendarToken", mock.Anything, "abcd").Return("s3cr3t", nil).Once()
+
+	err := Run(context.TODO(), env, []string{"assignees", "calendar-token", "abcd"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Calendar token of assignee abcd is s3cr3t\n"+
+		"The calendar feed is served at /assignees/abcd/calendar.ics?token=s3cr3t\n", stdout.String())
+}
+
+func TestCli_ArrangeMeeting(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		env, _, candidateService, _ := newTestEnvironment()
+		meetingTime := time.Date(2020, 3, 10, 14, 0, 0, 0, time.UTC)
+		candidateService.On("ArrangeMeeting", mock.Anything, "abcd",
+			mock.MatchedBy(func(t *time.Time) bool { return t.Equal(meetingTime) })).Return(nil).Once()
+
+		err := Run(context.TODO(), env, []string{"meetings", "arrange", "-candidate", "abcd", "-time", "2020-03-10T14:00:00Z"})
+
+		assert.NoError(t, err)
+		candidateService.AssertExpectations(t)
+	})
+
+	t.Run("invalid-time", func(t *testing.T) {
+		env, _, _, _ := newTestEnvironment()
+
+		err := Run(context.TODO(), env, []string{"meetings", "arrange", "-candidate", "abcd", "-time", "tomorrow"})
+
+		assert.ErrorIs(t, err, ErrUsage)
+	})
+}
+
+func TestCli_CancelMeeting(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		env, _, candidateService, stdout := newTestEnvironment()
+		candidateService.On("CancelMeeting", mock.Anything, "abcd", "The assignee is on leave").Return(nil).Once()
+
+		err := Run(context.TODO(), env, []string{"meetings", "cancel", "-candidate", "abcd", "-reason", "The assignee is on leave"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Meeting of candidate abcd is cancelled\n", stdout.String())
+		candidateService.AssertExpectations(t)
+	})
+
+	t.Run("reason-required", func(t *testing.T) {
+		env, _, _, _ := newTestEnvironment()
+
+		err := Run(context.TODO(), env, []string{"meetings", "cancel", "-candidate", "abcd"})
+
+		assert.ErrorIs(t, err, ErrUsage)
+	})
+}
+
+func TestCli_Seed(t *testing.T) {
+	env, _, _, stdout := newTestEnvironment()
+	env.AssigneeRepository = repository.MemoryAssigneeRepository()
+	env.CandidateRepository = repository.MemoryCandidateRepository()
+	path := filepath.Join(t.TempDir(), "seed.json")
+	_ = ioutil.WriteFile(path, []byte(`{
+		"assignees": [{"name": "Sercan", "department": "Development"}],
+		"candidates": [{"email": "a@a.com", "department": "Development", "assignee": "Sercan"}]
+	}`), 0644)
+
+	err := Run(context.TODO(), env, []string{"seed", path})
+	assert.NoError(t, err)
+	assert.Equal(t, "1 assignees and 1 candidates are created, 0 existing records are skipped\n", stdout.String())
+
+	stdout.Reset()
+	err = Run(context.TODO(), env, []string{"export", "-format", "json"})
+
+	var fixture seed.Fixture
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &fixture))
+	assert.Equal(t, "Sercan", fixture.Candidates[0].Assignee)
+}
+
+func TestCli_UnknownCommand(t *testing.T) {
+	env, _, _, _ := newTestEnvironment()
+
+	err := Run(context.TODO(), env, []string{"candidate"})
+
+	assert.ErrorIs(t, err, ErrUsage)
+}
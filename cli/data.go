@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/seed"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// seedFixture loads the assignees and candidates of a YAML or JSON fixture through the repositories
+func seedFixture(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("seed")
+	reset := flags.Bool("reset", false, "delete all assignees, candidates and meetings before seeding")
+	path, err := singleArgument(flags, args, "file")
+	if err != nil {
+		return err
+	}
+
+	fixture, err := seed.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	result, err := seed.Load(ctx, env.AssigneeRepository, env.CandidateRepository, fixture, seed.Options{Reset: *reset})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "%d assignees and %d candidates are created, %d existing records are skipped\n",
+		result.AssigneesCreated, result.CandidatesCreated, result.Skipped)
+	return nil
+}
+
+// export writes all assignees and candidates as a fixture, which can be loaded with the seed command
+func export(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("export")
+	path := flags.String("file", "", "file to write to, the standard output by default")
+	format := flags.String("format", "", "yaml or json, taken from the file extension by default")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*path), ".")
+	}
+	if *format == "" {
+		*format = "yaml"
+	}
+
+	fixture, err := seed.Dump(ctx, env.AssigneeRepository, env.CandidateRepository)
+	if err != nil {
+		return err
+	}
+
+	data, err := seed.Encode(fixture, *format)
+	if err != nil {
+		return usageError(err.Error())
+	}
+
+	if *path == "" {
+		_, err = env.Stdout.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(*path, data, 0644)
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func arrangeMeeting(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("meetings arrange")
+	candidateID := flags.String("candidate", "", "id of the candidate")
+	meetingTime := flags.String("time", "", "time of the meeting in RFC 3339 format")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if *candidateID == "" || *meetingTime == "" {
+		return usageError("-candidate and -time are required")
+	}
+
+	nextMeetingTime, err := time.Parse(time.RFC3339, *meetingTime)
+	if err != nil {
+		return usageError("invalid meeting time: %s", err)
+	}
+
+	if err := env.CandidateService.ArrangeMeeting(ctx, *candidateID, &nextMeetingTime); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Meeting is arranged for candidate %s at %s\n", *candidateID, nextMeetingTime.Format(time.RFC3339))
+	return nil
+}
+
+func rescheduleMeeting(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("meetings reschedule")
+	candidateID := flags.String("candidate", "", "id of the candidate")
+	meetingTime := flags.String("time", "", "new time of the meeting in RFC 3339 format")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if *candidateID == "" || *meetingTime == "" {
+		return usageError("-candidate and -time are required")
+	}
+
+	nextMeetingTime, err := time.Parse(time.RFC3339, *meetingTime)
+	if err != nil {
+		return usageError("invalid meeting time: %s", err)
+	}
+
+	if err := env.CandidateService.RescheduleMeeting(ctx, *candidateID, &nextMeetingTime); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Meeting of candidate %s is rescheduled to %s\n", *candidateID, nextMeetingTime.Format(time.RFC3339))
+	return nil
+}
+
+func cancelMeeting(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet("meetings cancel")
+	candidateID := flags.String("candidate", "", "id of the candidate")
+	reason := flags.String("reason", "", "reason of the cancellation")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if *candidateID == "" || strings.TrimSpace(*reason) == "" {
+		return usageError("-candidate and -reason are required")
+	}
+
+	if err := env.CandidateService.CancelMeeting(ctx, *candidateID, *reason); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Meeting of candidate %s is cancelled\n", *candidateID)
+	return nil
+}
+
+func completeMeeting(ctx context.Context, env Environment, args []string) error {
+	id, err := singleArgument(newFlagSet("meetings complete"), args, "candidate id")
+	if err != nil {
+		return err
+	}
+
+	if err := env.CandidateService.CompleteMeeting(ctx, id); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Meeting of candidate %s is completed\n", id)
+	return nil
+}
+
+func recordNoShow(ctx context.Context, env Environment, args []string) error {
+	id, err := singleArgument(newFlagSet("meetings no-show"), args, "candidate id")
+	if err != nil {
+		return err
+	}
+
+	if err := env.CandidateService.RecordNoShow(ctx, id); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "No-show of candidate %s is recorded\n", id)
+	return nil
+}
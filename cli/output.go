@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// supported output formats
+const (
+	tableOutput = "table"
+	jsonOutput  = "json"
+)
+
+var candidateColumns = []string{"ID", "NAME", "EMAIL", "DEPARTMENT", "STATUS", "MEETINGS", "NEXT MEETING", "ASSIGNEE"}
+
+var assigneeColumns = []string{"ID", "NAME", "DEPARTMENT"}
+
+func printCandidates(w io.Writer, format string, candidates []model.Candidate) error {
+	if format == jsonOutput {
+		return printJSON(w, candidates)
+	}
+
+	rows := make([][]string, 0, len(candidates))
+	for _, c := range candidates {
+		rows = append(rows, candidateRow(c))
+	}
+	return printTable(w, format, candidateColumns, rows)
+}
+
+func printCandidate(w io.Writer, format string, candidate model.Candidate) error {
+	if format == jsonOutput {
+		return printJSON(w, candidate)
+	}
+
+	return printTable(w, format, candidateColumns, [][]string{candidateRow(candidate)})
+}
+
+func printAssignees(w io.Writer, format string, assignees []model.Assignee) error {
+	if format == jsonOutput {
+		return printJSON(w, assignees)
+	}
+
+	rows := make([][]string, 0, len(assignees))
+	for _, a := range assignees {
+		rows = append(rows, []string{a.ID, a.Name, a.Department})
+	}
+	return printTable(w, format, assigneeColumns, rows)
+}
+
+func candidateRow(c model.Candidate) []string {
+	nextMeeting := "-"
+	if c.NextMeeting != nil {
+		nextMeeting = c.NextMeeting.Format(time.RFC3339)
+	}
+	assignee := c.Assignee
+	if assignee == "" {
+		assignee = "-"
+	}
+
+	return []string{
+		c.ID,
+		strings.TrimSpace(c.FirstName + " " + c.LastName),
+		c.Email,
+		c.Department,
+		c.Status,
+		strconv.Itoa(c.MeetingCount),
+		nextMeeting,
+		assignee,
+	}
+}
+
+func printTable(w io.Writer, format string, columns []string, rows [][]string) error {
+	if format != tableOutput {
+		return usageError("unknown output format %q", format)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+)
+
+// collectionSchema describes the indexes and the validation rules of a collection
+type collectionSchema struct {
+	name      string
+	indexes   []mongo.IndexModel
+	validator bson.M
+}
+
+// Bootstrap creates the indexes of the collections and applies their JSON schema validation.
+// It is safe to run on each startup, existing indexes and validators are left as they are or updated.
+func Bootstrap(ctx context.Context, database *mongo.Database) error {
+	existingCollections, err := database.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return err
+	}
+
+	for _, schema := range schemas() {
+		if err := applyValidator(ctx, database, schema, contains(existingCollections, schema.name)); err != nil {
+			return err
+		}
+
+		if len(schema.indexes) > 0 {
+			if _, err := database.Collection(schema.name).Indexes().CreateMany(ctx, schema.indexes); err != nil {
+				return err
+			}
+		}
+		log.Printf("Bootstrapped %s collection\n", schema.name)
+	}
+
+	return nil
+}
+
+func schemas() []collectionSchema {
+	return []collectionSchema{
+		{
+			name: "Candidates",
+			indexes: []mongo.IndexModel{
+				{Keys: bson.D{{"email", 1}}, Options: options.Index().SetName("email_unique").SetUnique(true)},
+				{Keys: bson.D{{"assignee", 1}}, Options: options.Index().SetName("assignee")},
+				{Keys: bson.D{{"department", 1}, {"status", 1}}, Options: options.Index().SetName("department_status")},
+				{Keys: bson.D{{"status", 1}}, Options: options.Index().SetName("status")},
+			},
+			validator: bson.M{"$jsonSchema": bson.M{
+				"bsonType": "object",
+				"required": bson.A{"_id", "email", "department", "university", "status", "application_date"},
+				"properties": bson.M{
+					"email":            bson.M{"bsonType": "string"},
+					"department":       bson.M{"enum": stringsToArray(model.GetDepartmentsAsArray())},
+					"university":       bson.M{"bsonType": "string"},
+					"status":           bson.M{"enum": stringsToArray(model.GetStatusesAsArray())},
+					"application_date": bson.M{"bsonType": "date"},
+					"decided_at":       bson.M{"bsonType": bson.A{"date", "null"}},
+					"meeting_count":    bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+					"no_show_count":    bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+					"next_meeting":     bson.M{"bsonType": bson.A{"date", "null"}},
+					"assignee":         bson.M{"bsonType": "string"},
+					"version":          bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+				},
+			}},
+		},
+		{
+			name: "Assignees",
+			indexes: []mongo.IndexModel{
+				{Keys: bson.D{{"name", 1}}, Options: options.Index().SetName("name")},
+				{Keys: bson.D{{"department", 1}}, Options: options.Index().SetName("department")},
+			},
+			validator: bson.M{"$jsonSchema": bson.M{
+				"bsonType": "object",
+				"required": bson.A{"_id", "name", "department"},
+				"properties": bson.M{
+					"name":       bson.M{"bsonType": "string", "minLength": 1},
+					"department": bson.M{"enum": stringsToArray(model.GetDepartmentsAsArray())},
+				},
+			}},
+		},
+		{
+			name: "Meetings",
+			indexes: []mongo.IndexModel{
+				{Keys: bson.D{{"candidate_id", 1}}, Options: options.Index().SetName("candidate_id")},
+				{Keys: bson.D{{"assignee_id", 1}, {"time", 1}}, Options: options.Index().SetName("assignee_id_time")},
+				{Keys: bson.D{{"status", 1}, {"time", 1}}, Options: options.Index().SetName("status_time")},
+			},
+		},
+		{
+			name: "Attachments",
+			indexes: []mongo.IndexModel{
+				{Keys: bson.D{{"candidate_id", 1}}, Options: options.Index().SetName("candidate_id")},
+				// the words of the texts are indexed as they are, without the stemming and the stop words of a language
+				{Keys: bson.D{{"text", "text"}}, Options: options.Index().SetName("text").SetDefaultLanguage("none")},
+			},
+		},
+		{
+			name: "Notifications",
+			indexes: []mongo.IndexModel{
+				{Keys: bson.D{{"status", 1}, {"next_attempt_at", 1}}, Options: options.Index().SetName("status_next_attempt_at")},
+				{Keys: bson.D{{"candidate_id", 1}}, Options: options.Index().SetName("candidate_id")},
+			},
+		},
+		{
+			name: "Events",
+			indexes: []mongo.IndexModel{
+				{Keys: bson.D{{"status", 1}, {"next_attempt_at", 1}}, Options: options.Index().SetName("status_next_attempt_at")},
+			},
+		},
+		{
+			name: "Webhooks",
+			indexes: []mongo.IndexModel{
+				{Keys: bson.D{{"events", 1}}, Options: options.Index().SetName("events")},
+			},
+		},
+		{
+			name: "WebhookDeliveries",
+			indexes: []mongo.IndexModel{
+				{Keys: bson.D{{"status", 1}, {"next_attempt_at", 1}}, Options: options.Index().SetName("status_next_attempt_at")},
+				{Keys: bson.D{{"webhook_id", 1}, {"created_at", -1}}, Options: options.Index().SetName("webhook_id_created_at")},
+			},
+		},
+	}
+}
+
+// applyValidator creates the collection with its validator, or updates the validator of the existing collection.
+// The validation level is moderate, so the existing invalid documents can still be updated.
+func applyValidator(ctx context.Context, database *mongo.Database, schema collectionSchema, exists bool) error {
+	if schema.validator == nil {
+		return nil
+	}
+
+	command := bson.D{{"create", schema.name}}
+	if exists {
+		command = bson.D{{"collMod", schema.name}}
+	}
+	command = append(command,
+		bson.E{Key: "validator", Value: schema.validator},
+		bson.E{Key: "validationLevel", Value: "moderate"},
+		bson.E{Key: "validationAction", Value: "error"},
+	)
+
+	return database.RunCommand(ctx, command).Err()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func stringsToArray(values []string) bson.A {
+	array := bson.A{}
+	for _, value := range values {
+		array = append(array, value)
+	}
+	return array
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"sync"
+	"time"
+)
+
+var tracer = otel.Tracer("github.com/cemalunal/sample-internship-management-api/events")
+
+// subscription is a handler of the events of the given types, or of all of the events if there are no types
+type subscription struct {
+	name    string
+	handler model.EventHandler
+	types   map[string]bool
+}
+
+func (s subscription) matches(eventType string) bool {
+	return len(s.types) == 0 || s.types[eventType]
+}
+
+// Bus fans the published events out to the subscriptions in memory. The subscriptions handle an event one after the other,
+// in the order they are subscribed, before Publish returns. A failing subscription does not stop the others,
+// and the event is not handled again, so the events are lost if the process stops.
+type Bus struct {
+	mutex         sync.RWMutex
+	subscriptions []subscription
+	now           func() time.Time
+}
+
+// NewBus creates a Bus without any subscriptions
+func NewBus() *Bus {
+	return &Bus{
+		now: time.Now,
+	}
+}
+
+// Subscribe adds the handler of the events of the given types, or of all of the events if no type is given
+func (bus *Bus) Subscribe(name string, handler model.EventHandler, types ...string) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	s := subscription{name: name, handler: handler}
+	if len(types) > 0 {
+		s.types = make(map[string]bool)
+		for _, eventType := range types {
+			s.types[eventType] = true
+		}
+	}
+	bus.subscriptions = append(bus.subscriptions, s)
+}
+
+// Publish hands the event to the subscriptions of its type, and returns the errors of the subscriptions that have failed
+func (bus *Bus) Publish(ctx context.Context, event model.DomainEvent) error {
+	ctx, span := tracer.Start(ctx, "Bus.Publish")
+	defer span.End()
+
+	event = stamp(event, bus.now())
+	span.SetAttributes(attribute.String("event.id", event.ID), attribute.String("event.type", event.Type))
+
+	_, err := bus.handle(ctx, event, nil)
+	if err != nil {
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+// handle hands the event to the subscriptions of its type, except the ones that have already handled it.
+// It returns the names of the subscriptions that have handled the event, including the ones that were skipped.
+func (bus *Bus) handle(ctx context.Context, event model.DomainEvent, handledBy []string) ([]string, error) {
+	bus.mutex.RLock()
+	subscriptions := bus.subscriptions
+	bus.mutex.RUnlock()
+
+	handled := make(map[string]bool)
+	for _, name := range handledBy {
+		handled[name] = true
+	}
+
+	var errs []error
+	for _, s := range subscriptions {
+		if handled[s.name] || !s.matches(event.Type) {
+			continue
+		}
+
+		if err := s.handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		handledBy = append(handledBy, s.name)
+	}
+
+	return handledBy, errors.Join(errs...)
+}
+
+// stamp sets the id and the time of the event, unless the publisher has set them
+func stamp(event model.DomainEvent, now time.Time) model.DomainEvent {
+	if event.ID == "" {
+		event.ID = primitive.NewObjectID().Hex()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = now
+	}
+	return event
+}
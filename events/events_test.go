@@ -0,0 +1,316 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/model/mocks"
+	"github.com/cemalunal/sample-internship-management-api/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+// recorder records the types of the events that it handles, and fails the given number of events first
+type recorder struct {
+	failures int
+	handled  []string
+}
+
+func (recorder *recorder) handle(ctx context.Context, event model.DomainEvent) error {
+	if recorder.failures > 0 {
+		recorder.failures--
+		return errors.New("unavailable")
+	}
+	recorder.handled = append(recorder.handled, event.Type)
+	return nil
+}
+
+func TestBus(t *testing.T) {
+	candidate := model.Candidate{ID: "abcd"}
+
+	t.Run("fan-out", func(t *testing.T) {
+		notifications, audit := &recorder{}, &recorder{}
+		bus := NewBus()
+		bus.Subscribe("notifications", notifications.handle, model.CandidateDeniedEvent)
+		bus.Subscribe("audit", audit.handle)
+
+		assert.NoError(t, bus.Publish(context.TODO(), model.DomainEvent{Type: model.CandidateCreatedEvent, Candidate: &candidate}))
+		assert.NoError(t, bus.Publish(context.TODO(), model.DomainEvent{Type: model.CandidateDeniedEvent, Candidate: &candidate}))
+
+		assert.Equal(t, []string{model.CandidateDeniedEvent}, notifications.handled)
+		assert.Equal(t, []string{model.CandidateCreatedEvent, model.CandidateDeniedEvent}, audit.handled)
+	})
+
+	t.Run("failing-subscription", func(t *testing.T) {
+		notifications, audit := &recorder{failures: 1}, &recorder{}
+		bus := NewBus()
+		bus.Subscribe("notifications", notifications.handle)
+		bus.Subscribe("audit", audit.handle)
+
+		err := bus.Publish(context.TODO(), model.DomainEvent{Type: model.CandidateDeniedEvent, Candidate: &candidate})
+
+		assert.EqualError(t, err, "notifications: unavailable")
+		// the other subscriptions still handle the event
+		assert.Equal(t, []string{model.CandidateDeniedEvent}, audit.handled)
+	})
+
+	t.Run("stamps-event", func(t *testing.T) {
+		var handled model.DomainEvent
+		bus := NewBus()
+		bus.Subscribe("audit", func(ctx context.Context, event model.DomainEvent) error {
+			handled = event
+			return nil
+		})
+
+		_ = bus.Publish(context.TODO(), model.DomainEvent{Type: model.CandidateCreatedEvent, Candidate: &candidate})
+
+		assert.NotEmpty(t, handled.ID)
+		assert.False(t, handled.OccurredAt.IsZero())
+	})
+}
+
+func TestOutbox(t *testing.T) {
+	candidate := model.Candidate{ID: "abcd"}
+
+	t.Run("handled", func(t *testing.T) {
+		notifications, audit := &recorder{}, &recorder{}
+		outbox := NewOutbox(repository.MemoryEventRepository())
+		outbox.Subscribe("notifications", notifications.handle, model.CandidateDeniedEvent)
+		outbox.Subscribe("audit", audit.handle)
+
+		assert.NoError(t, outbox.Publish(context.TODO(), model.DomainEvent{Type: model.CandidateCreatedEvent, Candidate: &candidate}))
+		assert.NoError(t, outbox.Publish(context.TODO(), model.DomainEvent{Type: model.CandidateDeniedEvent, Candidate: &candidate}))
+		// the events are only handled when they are dispatched
+		assert.Empty(t, audit.handled)
+
+		handled, err := outbox.Dispatch(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, handled)
+		assert.Equal(t, []string{model.CandidateDeniedEvent}, notifications.handled)
+		// the events are handled in the order they are published
+		assert.Equal(t, []string{model.CandidateCreatedEvent, model.CandidateDeniedEvent}, audit.handled)
+
+		handled, _ = outbox.Dispatch(context.TODO())
+		assert.Equal(t, 0, handled)
+	})
+
+	t.Run("retries-failed-subscriptions", func(t *testing.T) {
+		notifications, audit := &recorder{failures: 1}, &recorder{}
+		eventRepository := repository.MemoryEventRepository()
+		outbox := NewOutbox(eventRepository)
+		outbox.Subscribe("notifications", notifications.handle)
+		outbox.Subscribe("audit", audit.handle)
+		now := time.Now()
+		outbox.now = func() time.Time { return now }
+		_ = outbox.Publish(context.TODO(), model.DomainEvent{Type: model.CandidateDeniedEvent, Candidate: &candidate})
+
+		handled, _ := outbox.Dispatch(context.TODO())
+		assert.Equal(t, 0, handled)
+		assert.Equal(t, []string{model.CandidateDeniedEvent}, audit.handled)
+
+		// the first retry is delayed by 30 seconds, and the subscriptions that have handled the event are skipped
+		now = now.Add(30 * time.Second)
+		handled, _ = outbox.Dispatch(context.TODO())
+		assert.Equal(t, 1, handled)
+		assert.Equal(t, []string{model.CandidateDeniedEvent}, notifications.handled)
+		assert.Equal(t, []string{model.CandidateDeniedEvent}, audit.handled)
+	})
+
+	t.Run("failed-after-max-attempts", func(t *testing.T) {
+		eventRepository := repository.MemoryEventRepository()
+		outbox := NewOutbox(eventRepository)
+		outbox.Subscribe("notifications", (&recorder{failures: 100}).handle)
+		outbox.MaxAttempts = 1
+		now := time.Now()
+		outbox.now = func() time.Time { return now }
+		_ = outbox.Publish(context.TODO(), model.DomainEvent{Type: model.CandidateDeniedEvent, Candidate: &candidate})
+
+		_, _ = outbox.Dispatch(context.TODO())
+
+		// the failed event is not claimed again
+		_, found, _ := eventRepository.ClaimEvent(context.TODO(), now.Add(24*time.Hour), time.Minute)
+		assert.False(t, found)
+	})
+}
+
+func TestNotifications(t *testing.T) {
+	meeting := model.MeetingRecord{ID: "m1"}
+	notifier := new(mocks.Notifier)
+	notifier.On("Notify", mock.Anything, model.CandidateEvent{Event: model.MeetingArrangedEvent, Candidate: model.Candidate{ID: "abcd"}, Meeting: &meeting}).
+		Return(nil).Once()
+	handler := Notifications(notifier)
+
+	assert.NoError(t, handler(context.TODO(), model.DomainEvent{Type: model.MeetingArrangedEvent, Candidate: &model.Candidate{ID: "abcd"}, Meeting: &meeting}))
+	// the events without a candidate are skipped
+	assert.NoError(t, handler(context.TODO(), model.DomainEvent{Type: model.AssigneeCreatedEvent, Assignee: &model.Assignee{ID: "a1"}}))
+
+	notifier.AssertExpectations(t)
+}
+
+// changeStream streams the given changes, and records the resume tokens that are saved
+type changeStream struct {
+	changes []model.CandidateChange
+	err     error
+	resumed []byte
+	saved   []byte
+}
+
+func (stream *changeStream) WatchCandidates(ctx context.Context, resumeToken []byte, fn func(model.CandidateChange) error) error {
+	stream.resumed = resumeToken
+	for _, change := range stream.changes {
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+	return stream.err
+}
+
+func (stream *changeStream) ReadResumeToken(ctx context.Context, name string) ([]byte, error) {
+	return stream.saved, nil
+}
+
+func (stream *changeStream) SaveResumeToken(ctx context.Context, name string, token []byte) error {
+	stream.saved = token
+	return nil
+}
+
+func TestWatcher(t *testing.T) {
+	newCandidateRepository := func() model.CandidateRepository {
+		candidateRepository := repository.MemoryCandidateRepository()
+		_, _ = candidateRepository.CreateCandidate(context.TODO(), model.Candidate{ID: "abcd", Email: "ayse@e.com", Status: model.Pending})
+		_, _ = candidateRepository.CreateCandidate(context.TODO(), model.Candidate{ID: "efgh", Email: "mehmet@e.com", Status: model.Pending})
+		return candidateRepository
+	}
+
+	t.Run("change-stream", func(t *testing.T) {
+		candidateRepository := newCandidateRepository()
+		candidate, _ := candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", Time: time.Now().Add(time.Hour), Status: model.MeetingArranged})
+		denied := model.Candidate{ID: "efgh", Email: "mehmet@e.com", Status: model.Denied}
+		changes := &changeStream{saved: []byte("t0"), changes: []model.CandidateChange{
+			{Token: []byte("t1"), Operation: model.ChangeUpdate, CandidateID: "abcd", Candidate: &candidate, UpdatedFields: []string{"next_meeting", "meeting_id", "assignee", "last_meeting_transition", "version"},
+				MeetingTransition: &model.MeetingTransition{Event: model.MeetingArrangedEvent, MeetingID: "m1"}},
+			{Token: []byte("t2"), Operation: model.ChangeUpdate, CandidateID: "efgh", Candidate: &denied, UpdatedFields: []string{"status", "version"}, Status: model.Denied},
+			{Token: []byte("t3"), Operation: model.ChangeDelete, CandidateID: "efgh"},
+			// a change that cannot be decoded only has its token
+			{Token: []byte("t4")},
+		}}
+		audit := &recorder{}
+		bus := NewBus()
+		bus.Subscribe("audit", audit.handle)
+
+		watcher := NewWatcher(changes, candidateRepository, bus)
+		assert.NoError(t, watcher.watch(context.TODO()))
+
+		assert.Equal(t, []string{model.MeetingArrangedEvent, model.CandidateDeniedEvent, model.CandidateDeletedEvent}, audit.handled)
+		// the watcher resumes after the saved token, and saves the token of each change, including the skipped ones
+		assert.Equal(t, []byte("t0"), changes.resumed)
+		assert.Equal(t, []byte("t4"), changes.saved)
+	})
+
+	t.Run("changes-in-quick-succession", func(t *testing.T) {
+		candidateRepository := newCandidateRepository()
+		candidate, _ := candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", Time: time.Now().Add(time.Hour), Status: model.MeetingArranged})
+		_, _ = candidateRepository.CompleteMeeting(context.TODO(), "abcd", time.Now())
+		candidate, _ = candidateRepository.ArrangeMeeting(context.TODO(), "abcd", candidate.Version+1,
+			model.MeetingRecord{ID: "m2", CandidateID: "abcd", Time: time.Now().Add(time.Hour), Status: model.MeetingArranged})
+		_, _, _ = candidateRepository.CancelMeeting(context.TODO(), "abcd", candidate.Version, model.MeetingChange{Reason: "sick"})
+		candidate, _ = candidateRepository.ReadCandidate(context.TODO(), "abcd")
+		candidate.Status = model.Denied
+		_ = candidateRepository.UpdateCandidate(context.TODO(), "abcd", candidate)
+		// each change is looked up after all of the changes are made, so they all carry the denied candidate
+		candidate, _ = candidateRepository.ReadCandidate(context.TODO(), "abcd")
+		changes := &changeStream{changes: []model.CandidateChange{
+			{Token: []byte("t1"), Operation: model.ChangeUpdate, CandidateID: "abcd", Candidate: &candidate,
+				MeetingTransition: &model.MeetingTransition{Event: model.MeetingArrangedEvent, MeetingID: "m1"}},
+			{Token: []byte("t2"), Operation: model.ChangeUpdate, CandidateID: "abcd", Candidate: &candidate, Status: model.InProgress,
+				MeetingTransition: &model.MeetingTransition{Event: model.MeetingCompletedEvent}},
+			{Token: []byte("t3"), Operation: model.ChangeUpdate, CandidateID: "abcd", Candidate: &candidate,
+				MeetingTransition: &model.MeetingTransition{Event: model.MeetingArrangedEvent, MeetingID: "m2"}},
+			{Token: []byte("t4"), Operation: model.ChangeUpdate, CandidateID: "abcd", Candidate: &candidate,
+				MeetingTransition: &model.MeetingTransition{Event: model.MeetingCancelledEvent, MeetingID: "m2"}},
+			{Token: []byte("t5"), Operation: model.ChangeUpdate, CandidateID: "abcd", Candidate: &candidate, Status: model.Denied},
+		}}
+		var events []model.DomainEvent
+		bus := NewBus()
+		bus.Subscribe("audit", func(ctx context.Context, event model.DomainEvent) error {
+			events = append(events, event)
+			return nil
+		})
+
+		watcher := NewWatcher(changes, candidateRepository, bus)
+		assert.NoError(t, watcher.watch(context.TODO()))
+
+		var types []string
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		assert.Equal(t, []string{model.MeetingArrangedEvent, model.MeetingCompletedEvent, model.MeetingArrangedEvent,
+			model.MeetingCancelledEvent, model.CandidateDeniedEvent}, types)
+		assert.Equal(t, "m1", events[0].Meeting.ID)
+		assert.Equal(t, "m2", events[2].Meeting.ID)
+		assert.Equal(t, "m2", events[3].Meeting.ID)
+	})
+
+	t.Run("poll", func(t *testing.T) {
+		candidateRepository := newCandidateRepository()
+		audit := &recorder{}
+		bus := NewBus()
+		bus.Subscribe("audit", audit.handle)
+		watcher := NewWatcher(nil, candidateRepository, bus)
+
+		// the first poll takes the snapshot of the candidates
+		assert.NoError(t, watcher.Poll(context.TODO()))
+		assert.Empty(t, audit.handled)
+
+		_, _ = candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", Time: time.Now().Add(time.Hour), Status: model.MeetingArranged})
+		_ = candidateRepository.UpdateCandidate(context.TODO(), "efgh", model.Candidate{Email: "mehmet@e.com", Status: model.Accepted})
+		_, _ = candidateRepository.CreateCandidate(context.TODO(), model.Candidate{ID: "ijkl", Email: "zeynep@e.com", Status: model.Pending})
+		assert.NoError(t, watcher.Poll(context.TODO()))
+		assert.ElementsMatch(t, []string{model.MeetingArrangedEvent, model.CandidateAcceptedEvent, model.CandidateCreatedEvent}, audit.handled)
+
+		audit.handled = nil
+		_, _, _ = candidateRepository.CancelMeeting(context.TODO(), "abcd", 1, model.MeetingChange{Reason: "sick"})
+		_ = candidateRepository.DeleteCandidate(context.TODO(), "efgh")
+		assert.NoError(t, watcher.Poll(context.TODO()))
+		assert.ElementsMatch(t, []string{model.MeetingCancelledEvent, model.CandidateDeletedEvent}, audit.handled)
+
+		// only the latest transition of the candidate is kept, so the same change is published again by its new id
+		audit.handled = nil
+		meetingTime := time.Now().Add(2 * time.Hour)
+		_, _ = candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 2,
+			model.MeetingRecord{ID: "m2", CandidateID: "abcd", Time: time.Now().Add(time.Hour), Status: model.MeetingArranged})
+		_, _, _ = candidateRepository.RescheduleMeeting(context.TODO(), "abcd", 3, model.MeetingChange{Time: &meetingTime})
+		assert.NoError(t, watcher.Poll(context.TODO()))
+		_, _, _ = candidateRepository.RescheduleMeeting(context.TODO(), "abcd", 4, model.MeetingChange{Time: &meetingTime})
+		assert.NoError(t, watcher.Poll(context.TODO()))
+		assert.Equal(t, []string{model.MeetingRescheduledEvent, model.MeetingRescheduledEvent}, audit.handled)
+	})
+
+	t.Run("same-ids-on-replicas", func(t *testing.T) {
+		candidateRepository := newCandidateRepository()
+		var ids [2][]string
+		var watchers [2]*Watcher
+		for i := range watchers {
+			bus := NewBus()
+			bus.Subscribe("audit", func(ctx context.Context, event model.DomainEvent) error {
+				ids[i] = append(ids[i], event.ID)
+				return nil
+			})
+			watchers[i] = NewWatcher(nil, candidateRepository, bus)
+			_ = watchers[i].Poll(context.TODO())
+		}
+
+		_ = candidateRepository.DeleteCandidate(context.TODO(), "abcd")
+		for _, watcher := range watchers {
+			_ = watcher.Poll(context.TODO())
+		}
+
+		assert.Len(t, ids[0], 1)
+		assert.Equal(t, ids[0], ids[1])
+	})
+}
@@ -0,0 +1,18 @@
+package events
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+)
+
+// Notifications is the handler that notifies the candidates of their events through the notifier.
+// It should be subscribed to the events that the candidates are notified of, see model.GetNotificationEventsAsArray.
+func Notifications(notifier model.Notifier) model.EventHandler {
+	return func(ctx context.Context, event model.DomainEvent) error {
+		if event.Candidate == nil {
+			return nil
+		}
+
+		return notifier.Notify(ctx, model.CandidateEvent{Event: event.Type, Candidate: *event.Candidate, Meeting: event.Meeting})
+	}
+}
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/queue"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"log"
+	"time"
+)
+
+// Outbox stores the published events in the database, and hands them to the subscriptions in the background,
+// so the events are not lost if the process stops before they are handled. An event is retried with an exponential backoff
+// until MaxAttempts, and only the subscriptions that have failed to handle it are called again.
+// The events are handled in the order they are published, but a retried event is handled after the later events.
+type Outbox struct {
+	queue.Worker
+	bus        *Bus
+	repository model.EventRepository
+	now        func() time.Time
+}
+
+// NewOutbox creates an Outbox of the events in the repository without any subscriptions
+func NewOutbox(repository model.EventRepository) *Outbox {
+	return &Outbox{
+		Worker:     queue.NewWorker(),
+		bus:        NewBus(),
+		repository: repository,
+		now:        time.Now,
+	}
+}
+
+// Subscribe adds the handler of the events of the given types, or of all of the events if no type is given.
+// The name is recorded with the events that the handler has handled, so it should not change between the releases.
+func (outbox *Outbox) Subscribe(name string, handler model.EventHandler, types ...string) {
+	outbox.bus.Subscribe(name, handler, types...)
+}
+
+// Publish stores the event in the outbox. When it is published in the transaction of a change, see model.TransactionRunner,
+// the event is stored only if the change is committed, and it is handled on the next poll if the outbox is polled before the commit.
+func (outbox *Outbox) Publish(ctx context.Context, event model.DomainEvent) error {
+	ctx, span := tracer.Start(ctx, "Outbox.Publish")
+	defer span.End()
+
+	now := outbox.now()
+	event = stamp(event, now)
+	span.SetAttributes(attribute.String("event.id", event.ID), attribute.String("event.type", event.Type))
+
+	_, err := outbox.repository.CreateEvent(ctx, model.OutboxEvent{
+		ID:            event.ID,
+		Event:         event,
+		Status:        model.EventPending,
+		NextAttemptAt: now,
+		HandledBy:     []string{},
+		CreatedAt:     now,
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	// the event is handled right away, instead of waiting for the next poll
+	outbox.Wake()
+
+	return nil
+}
+
+// Run hands the due events to the subscriptions until the context is done. The events are polled on each PollInterval,
+// and right after an event is published by this process.
+func (outbox *Outbox) Run(ctx context.Context) {
+	outbox.Poll(ctx, "events", outbox.Dispatch)
+}
+
+// Dispatch hands the events that are due to the subscriptions, and returns the number of events that are handled by all of them
+func (outbox *Outbox) Dispatch(ctx context.Context) (int, error) {
+	return queue.Dispatch(ctx, func(ctx context.Context) (model.OutboxEvent, bool, error) {
+		return outbox.repository.ClaimEvent(ctx, outbox.now(), outbox.Lease)
+	}, outbox.handle)
+}
+
+// handle hands the claimed event to the subscriptions that have not handled it yet, and records the outcome of the attempt
+func (outbox *Outbox) handle(ctx context.Context, event model.OutboxEvent) bool {
+	ctx, span := tracer.Start(ctx, "Outbox.handle")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("event.id", event.ID),
+		attribute.String("event.type", event.Event.Type),
+		attribute.Int("event.attempt", event.Attempts),
+	)
+
+	var err error
+	event.HandledBy, err = outbox.bus.handle(ctx, event.Event, event.HandledBy)
+	if err == nil {
+		event.Status = model.EventHandled
+		event.LastError = ""
+	} else {
+		log.Printf("Couldn't handle event %s of %s, attempt %d. Error is: %s\n", event.ID, event.Event.Type, event.Attempts, err)
+		tracing.RecordError(span, err)
+		event.LastError = err.Error()
+		var failed bool
+		event.NextAttemptAt, failed = outbox.Retry(outbox.now(), event.Attempts)
+		if failed {
+			event.Status = model.EventFailed
+		}
+	}
+
+	if err := outbox.repository.UpdateEvent(ctx, event); err != nil {
+		log.Println("Couldn't record the outcome of event: ", err)
+	}
+
+	return err == nil
+}
@@ -0,0 +1,291 @@
+package events
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"log"
+	"strconv"
+	"time"
+)
+
+// defaults of the watcher of the candidates
+const (
+	defaultResumeTokenName = "candidates"
+	defaultWatchPoll       = 5 * time.Second
+	defaultWatchRetryDelay = 5 * time.Second
+)
+
+// Watcher derives the events of the candidates and their meetings from the changes of the candidates in the database,
+// and publishes them to the subscriptions of this replica, such as its event stream. Unlike the events of the services, which are handled by one
+// of the replicas, each replica that runs a Watcher sees the changes made by all of the replicas and by the other
+// clients of the database, so it keeps the event streams of the replicas consistent.
+//
+// The changes are watched through the change stream of the database, and the watcher resumes after the last change
+// it has published when it is restarted. When the database does not support change streams, the candidates are
+// polled each PollInterval and compared with the previous poll, so the changes between the polls are merged and the
+// changes made while the watcher is stopped are not published. The ids of the events are derived from the changes,
+// so the replicas publish the same change with the same id.
+type Watcher struct {
+	changes    model.ChangeStreamRepository
+	candidates model.CandidateRepository
+	publisher  model.EventPublisher
+	now        func() time.Time
+	// snapshot are the candidates of the previous poll, it is nil until the first poll
+	snapshot map[string]model.Candidate
+
+	Name         string
+	PollInterval time.Duration
+	RetryDelay   time.Duration
+}
+
+// NewWatcher creates a Watcher of the changes of the candidates that publishes their events to the publisher.
+// The candidates are polled if the repository of the changes is nil, e.g. with the in-memory storage backend.
+func NewWatcher(changes model.ChangeStreamRepository, candidates model.CandidateRepository, publisher model.EventPublisher) *Watcher {
+	return &Watcher{
+		changes:      changes,
+		candidates:   candidates,
+		publisher:    publisher,
+		now:          time.Now,
+		Name:         defaultResumeTokenName,
+		PollInterval: defaultWatchPoll,
+		RetryDelay:   defaultWatchRetryDelay,
+	}
+}
+
+// Run publishes the events of the changes until the context is done. The change stream is reopened after RetryDelay
+// when it fails, and the candidates are polled instead if the database does not support change streams.
+func (watcher *Watcher) Run(ctx context.Context) {
+	for watcher.changes != nil && ctx.Err() == nil {
+		err := watcher.watch(ctx)
+		if errors.Is(err, model.ErrChangeStreamNotSupported) {
+			log.Println("MongoDB does not support change streams, the changes of the candidates are polled")
+			break
+		}
+		if errors.Is(err, model.ErrChangeStreamHistoryLost) {
+			// the changes after the token are lost, the watcher starts from the current changes
+			log.Println("Couldn't resume the change stream of the candidates, watching the changes from now on")
+			err = watcher.changes.SaveResumeToken(ctx, watcher.Name, nil)
+		}
+		if err != nil {
+			log.Println("Couldn't watch the changes of the candidates: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watcher.RetryDelay):
+		}
+	}
+
+	ticker := time.NewTicker(watcher.PollInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		if err := watcher.Poll(ctx); err != nil {
+			log.Println("Couldn't poll the changes of the candidates: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// watch publishes the events of the changes after the saved resume token, and saves the token of each published change
+func (watcher *Watcher) watch(ctx context.Context) error {
+	resumeToken, err := watcher.changes.ReadResumeToken(ctx, watcher.Name)
+	if err != nil {
+		return err
+	}
+
+	return watcher.changes.WatchCandidates(ctx, resumeToken, func(change model.CandidateChange) error {
+		watcher.publish(ctx, change)
+		return watcher.changes.SaveResumeToken(ctx, watcher.Name, change.Token)
+	})
+}
+
+// Poll reads the candidates and publishes the events of their changes since the previous poll.
+// The first poll only takes the snapshot of the candidates.
+func (watcher *Watcher) Poll(ctx context.Context) error {
+	candidates, err := watcher.candidates.FindAllCandidates(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshot := make(map[string]model.Candidate, len(candidates))
+	for _, candidate := range candidates {
+		snapshot[candidate.ID] = candidate
+	}
+	previousSnapshot := watcher.snapshot
+	watcher.snapshot = snapshot
+	if previousSnapshot == nil {
+		return nil
+	}
+
+	now := watcher.now()
+	for _, candidate := range candidates {
+		previous, ok := previousSnapshot[candidate.ID]
+		if ok && previous.Version == candidate.Version {
+			continue
+		}
+
+		// the version of the candidate identifies the change, as it is incremented on each change
+		change := model.CandidateChange{
+			Token:       []byte(candidate.ID + "/" + strconv.FormatInt(candidate.Version, 10)),
+			Operation:   model.ChangeInsert,
+			CandidateID: candidate.ID,
+			Candidate:   &candidate,
+			OccurredAt:  now,
+		}
+		if ok {
+			change.Operation = model.ChangeUpdate
+			change.UpdatedFields = changedFields(previous, candidate)
+			if previous.Status != candidate.Status {
+				change.Status = candidate.Status
+			}
+			// the transitions between the polls are merged, only the latest one is published
+			transitionID, transition := candidate.LatestMeetingTransition()
+			if previousTransitionID, _ := previous.LatestMeetingTransition(); transitionID != previousTransitionID {
+				change.MeetingTransition = transition
+			}
+		}
+		watcher.publish(ctx, change)
+	}
+	for id := range previousSnapshot {
+		if _, ok := snapshot[id]; !ok {
+			watcher.publish(ctx, model.CandidateChange{
+				Token:       []byte(id + "/" + model.ChangeDelete),
+				Operation:   model.ChangeDelete,
+				CandidateID: id,
+				OccurredAt:  now,
+			})
+		}
+	}
+
+	return nil
+}
+
+// publish publishes the events of the change. The subscriptions that fail to handle the events are only logged,
+// as the change is not watched again.
+func (watcher *Watcher) publish(ctx context.Context, change model.CandidateChange) {
+	ctx, span := tracer.Start(ctx, "Watcher.publish")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", change.CandidateID), attribute.String("change.operation", change.Operation))
+
+	events, err := watcher.events(ctx, change)
+	if err != nil {
+		log.Printf("Couldn't derive the events of the change of candidate %s. Error is: %s\n", change.CandidateID, err)
+		tracing.RecordError(span, err)
+		return
+	}
+
+	for _, event := range events {
+		if err := watcher.publisher.Publish(ctx, event); err != nil {
+			log.Printf("Couldn't handle %s of candidate %s. Error is: %s\n", event.Type, change.CandidateID, err)
+			tracing.RecordError(span, err)
+		}
+	}
+}
+
+// events derives the events of the change from the status and the meeting transition that it has set, rather than from
+// the candidate, which can already be changed again. The meeting events carry the meeting record of the transition.
+// An update that does not change the status or the meeting of the candidate is a CandidateUpdatedEvent.
+func (watcher *Watcher) events(ctx context.Context, change model.CandidateChange) ([]model.DomainEvent, error) {
+	if change.Operation == model.ChangeDelete {
+		return []model.DomainEvent{{
+			ID:         eventID(change.Token, model.CandidateDeletedEvent),
+			Type:       model.CandidateDeletedEvent,
+			OccurredAt: change.OccurredAt,
+			Candidate:  &model.Candidate{ID: change.CandidateID},
+		}}, nil
+	}
+	if change.Candidate == nil {
+		// the candidate is deleted before its change is looked up, the deletion follows.
+		// The changes that cannot be decoded do not have a candidate either, they are skipped
+		return nil, nil
+	}
+
+	candidate := *change.Candidate
+	var types []string
+	var meeting *model.MeetingRecord
+
+	switch change.Operation {
+	case model.ChangeInsert:
+		types = append(types, model.CandidateCreatedEvent)
+	default:
+		switch change.Status {
+		case model.Denied:
+			types = append(types, model.CandidateDeniedEvent)
+		case model.Accepted:
+			types = append(types, model.CandidateAcceptedEvent)
+		case model.Expired:
+			types = append(types, model.CandidateExpiredEvent)
+		}
+		if transition := change.MeetingTransition; transition != nil {
+			types = append(types, transition.Event)
+			if transition.MeetingID != "" {
+				record, err := watcher.candidates.ReadMeeting(ctx, transition.MeetingID)
+				if err != nil && !errors.Is(err, model.ErrMeetingDoesNotExist) {
+					return nil, err
+				}
+				if err == nil {
+					meeting = &record
+				}
+			}
+		}
+		if len(types) == 0 {
+			types = append(types, model.CandidateUpdatedEvent)
+		}
+	}
+
+	events := make([]model.DomainEvent, 0, len(types))
+	for _, eventType := range types {
+		event := model.DomainEvent{
+			ID:         eventID(change.Token, eventType),
+			Type:       eventType,
+			OccurredAt: change.OccurredAt,
+			Candidate:  &candidate,
+		}
+		if eventType != model.CandidateDeniedEvent && eventType != model.CandidateAcceptedEvent && eventType != model.CandidateExpiredEvent {
+			event.Meeting = meeting
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// changedFields returns the fields of the candidate that the events are derived from, which are changed between the polls
+func changedFields(previous model.Candidate, current model.Candidate) []string {
+	var fields []string
+	if previous.Status != current.Status {
+		fields = append(fields, "status")
+	}
+	if previous.MeetingID != current.MeetingID {
+		fields = append(fields, "meeting_id")
+	}
+	if (previous.NextMeeting == nil) != (current.NextMeeting == nil) ||
+		previous.NextMeeting != nil && !previous.NextMeeting.Equal(*current.NextMeeting) {
+		fields = append(fields, "next_meeting")
+	}
+	if previous.MeetingCount != current.MeetingCount {
+		fields = append(fields, "meeting_count")
+	}
+	if previous.NoShowCount != current.NoShowCount {
+		fields = append(fields, "no_show_count")
+	}
+	return append(fields, "version")
+}
+
+// eventID derives the id of an event from the token of its change, so it is the same on each replica.
+// It is as long as the ids of the published events.
+func eventID(token []byte, eventType string) string {
+	hash := sha256.Sum256(append(append([]byte{}, token...), eventType...))
+	return hex.EncodeToString(hash[:12])
+}
@@ -0,0 +1,84 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// documentPart is the part of a DOCX package that contains the body of the document
+const documentPart = "word/document.xml"
+
+// maxDocumentPartLength is the number of bytes that are read from the decompressed body of the document, so a small
+// package that decompresses to gigabytes cannot exhaust the memory. The markup of the body is much longer than its text.
+const maxDocumentPartLength = 64 << 20
+
+// docxText extracts the text runs of the document body, paragraphs, tabs and line breaks are kept as whitespace
+func docxText(content []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range archive.File {
+		if file.Name == documentPart {
+			part, err := file.Open()
+			if err != nil {
+				return "", err
+			}
+			defer part.Close()
+
+			return documentText(&io.LimitedReader{R: part, N: maxDocumentPartLength})
+		}
+	}
+
+	return "", errors.New("document does not have " + documentPart)
+}
+
+// documentText collects the text of the body until it reaches MaxTextLength, or until the body is cut at its limit
+func documentText(part *io.LimitedReader) (string, error) {
+	var text strings.Builder
+	decoder := xml.NewDecoder(part)
+	inText := false
+	for text.Len() < MaxTextLength {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return text.String(), nil
+		}
+		if err != nil && part.N <= 0 {
+			// the body is longer than the limit, the text before the limit is kept
+			return text.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteString("\t")
+			case "br", "cr":
+				text.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+
+	return text.String(), nil
+}
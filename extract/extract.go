@@ -0,0 +1,66 @@
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTextLength is the number of bytes that are kept from the text of a document,
+// longer texts are cut so the text always fits in a MongoDB document
+const MaxTextLength = 1 << 20
+
+// ErrNotSupported is returned for the content types that text cannot be extracted from
+var ErrNotSupported = errors.New("text extraction is not supported for the content type")
+
+// extractors are the functions that extract the text of the supported content types
+var extractors = map[string]func(content []byte) (string, error){
+	"application/pdf": pdfText,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": docxText,
+	"text/plain": plainText,
+}
+
+// Text extracts the plain text of the document with the given content type.
+// The whitespace of the text is collapsed, and the text is cut at MaxTextLength.
+func Text(contentType string, content []byte) (text string, err error) {
+	extractor, ok := extractors[contentType]
+	if !ok {
+		return "", ErrNotSupported
+	}
+
+	// the parsers may panic on malformed documents, which must not stop the upload
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("document cannot be parsed: %v", r)
+		}
+	}()
+
+	text, err = extractor(content)
+	if err != nil {
+		return "", err
+	}
+
+	return truncate(strings.Join(strings.Fields(text), " "), MaxTextLength), nil
+}
+
+func plainText(content []byte) (string, error) {
+	if !utf8.Valid(content) {
+		return "", errors.New("text is not valid UTF-8")
+	}
+
+	return string(content), nil
+}
+
+// truncate cuts the text at the given length without splitting a character
+func truncate(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+
+	for length > 0 && !utf8.RuneStart(text[length]) {
+		length--
+	}
+
+	return text[:length]
+}
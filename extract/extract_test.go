@@ -0,0 +1,184 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	t.Run("pdf", func(t *testing.T) {
+		text, err := Text("application/pdf", samplePDF("Go and Kubernetes"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Go and Kubernetes", text)
+	})
+
+	t.Run("pdf-pages", func(t *testing.T) {
+		text, err := Text("application/pdf", samplePDF("Go", "Kubernetes"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Go Kubernetes", text)
+	})
+
+	t.Run("pdf-decompression-bomb", func(t *testing.T) {
+		// the content of the second page decompresses to more than the limit, only the pages before it are read
+		bomb := bytes.Repeat([]byte(" "), maxContentLength+1)
+		pdf := pdfDocument(pdfStream("", []byte("BT /F1 12 Tf (Go) Tj ET")), pdfStream("FlateDecode", bomb), pdfStream("", []byte("BT /F1 12 Tf (Kubernetes) Tj ET")))
+		assert.Less(t, len(pdf), 1<<20)
+
+		text, err := Text("application/pdf", pdf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Go", text)
+	})
+
+	t.Run("pdf-longer-than-max", func(t *testing.T) {
+		// the pages are not read after the text reaches the max length, the last page would fail with its unknown filter
+		long := strings.Repeat("a", MaxTextLength/2+1)
+		pdf := pdfDocument(
+			pdfStream("", []byte("BT /F1 12 Tf ("+long+") Tj ET")),
+			pdfStream("", []byte("BT /F1 12 Tf ("+long+") Tj ET")),
+			pdfStream("UnknownDecode", []byte("BT /F1 12 Tf (Go) Tj ET")),
+		)
+
+		text, err := Text("application/pdf", pdf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, MaxTextLength, len(text))
+	})
+
+	t.Run("docx", func(t *testing.T) {
+		text, err := Text("application/vnd.openxmlformats-officedocument.wordprocessingml.document", sampleDOCX(
+			`<w:p><w:r><w:t>Skills:</w:t></w:r><w:r><w:tab/><w:t>Go</w:t></w:r></w:p><w:p><w:r><w:t>Kubernetes</w:t></w:r></w:p>`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Skills: Go Kubernetes", text)
+	})
+
+	t.Run("docx-decompression-bomb", func(t *testing.T) {
+		// the body decompresses to more than the limit, only the part before the limit is read
+		var docx bytes.Buffer
+		archive := zip.NewWriter(&docx)
+		part, _ := archive.Create(documentPart)
+		_, _ = part.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>Go</w:t></w:r></w:p>`))
+		paragraphs := []byte(strings.Repeat("<w:p></w:p>", 1<<16))
+		for written := 0; written <= maxDocumentPartLength; written += len(paragraphs) {
+			_, _ = part.Write(paragraphs)
+		}
+		_ = archive.Close()
+		assert.Less(t, docx.Len(), 1<<20)
+
+		text, err := Text("application/vnd.openxmlformats-officedocument.wordprocessingml.document", docx.Bytes())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Go", text)
+	})
+
+	t.Run("docx-longer-than-max", func(t *testing.T) {
+		run := "<w:p><w:r><w:t>" + strings.Repeat("a", 1023) + "</w:t></w:r></w:p>"
+		text, err := documentText(&io.LimitedReader{R: strings.NewReader("<w:body>" + strings.Repeat(run, 2048) + "</w:body>"), N: maxDocumentPartLength})
+
+		// the text is not collected after it reaches the max length
+		assert.NoError(t, err)
+		assert.Equal(t, MaxTextLength, len(text))
+	})
+
+	t.Run("txt", func(t *testing.T) {
+		text, err := Text("text/plain", []byte("  Go\n\tKubernetes  "))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Go Kubernetes", text)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := Text("application/pdf", []byte("%PDF-1.4 broken"))
+		assert.Error(t, err)
+
+		_, err = Text("application/vnd.openxmlformats-officedocument.wordprocessingml.document", []byte("PK broken"))
+		assert.Error(t, err)
+	})
+
+	t.Run("not-supported", func(t *testing.T) {
+		_, err := Text("application/msword", []byte("content"))
+		assert.Equal(t, ErrNotSupported, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		text, err := Text("text/plain", []byte(strings.Repeat("ş", MaxTextLength)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, MaxTextLength, len(text))
+	})
+}
+
+// samplePDF creates a PDF with a page for each of the given texts
+func samplePDF(texts ...string) []byte {
+	contents := make([]string, len(texts))
+	for i, text := range texts {
+		contents[i] = pdfStream("", []byte(fmt.Sprintf("BT /F1 12 Tf 72 712 Td (%s) Tj ET", text)))
+	}
+	return pdfDocument(contents...)
+}
+
+// pdfStream creates a stream object of the given data, which is compressed if the filter is FlateDecode
+func pdfStream(filter string, data []byte) string {
+	if filter == "" {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data)
+	}
+	if filter == "FlateDecode" {
+		var compressed bytes.Buffer
+		writer, _ := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+		_, _ = writer.Write(data)
+		_ = writer.Close()
+		data = compressed.Bytes()
+	}
+	return fmt.Sprintf("<< /Length %d /Filter /%s >>\nstream\n%s\nendstream", len(data), filter, data)
+}
+
+// pdfDocument creates a PDF whose pages have the given content streams
+func pdfDocument(contents ...string) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", "", "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"}
+	var kids []string
+	for _, content := range contents {
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)+1))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>", len(objects)+2),
+			content,
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents))
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return pdf.Bytes()
+}
+
+// sampleDOCX creates a DOCX package with the given body
+func sampleDOCX(body string) []byte {
+	var docx bytes.Buffer
+	archive := zip.NewWriter(&docx)
+	part, _ := archive.Create(documentPart)
+	_, _ = part.Write([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body + `</w:body></w:document>`))
+	_ = archive.Close()
+
+	return docx.Bytes()
+}
@@ -0,0 +1,67 @@
+package extract
+
+import (
+	"bytes"
+	"github.com/ledongthuc/pdf"
+	"io"
+	"strings"
+)
+
+// maxContentLength is the number of bytes that are decompressed from the content streams and the character maps
+// of the pages, so a small document that decompresses to gigabytes cannot exhaust the memory. The pages after
+// the limit are not read, the text of the pages before the limit is kept.
+const maxContentLength = 64 << 20
+
+// pdfText extracts the text of each page of the PDF until it reaches MaxTextLength, pages are separated with new lines
+func pdfText(content []byte) (string, error) {
+	reader, err := pdf.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	fonts := make(map[string]*pdf.Font)
+	remaining := int64(maxContentLength)
+	for i := 1; i <= reader.NumPage() && text.Len() < MaxTextLength; i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		streams := []pdf.Value{page.V.Key("Contents")}
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+				streams = append(streams, font.V.Key("ToUnicode"))
+			}
+		}
+
+		// the parser collects the text of a page as a whole, so its streams are measured before the page is parsed
+		if remaining -= streamsLength(streams, remaining); remaining < 0 {
+			break
+		}
+
+		pageText, err := page.GetPlainText(fonts)
+		if err != nil {
+			return "", err
+		}
+		text.WriteString(pageText)
+		text.WriteString("\n")
+	}
+
+	return text.String(), nil
+}
+
+// streamsLength returns the decompressed length of the given streams, it stops counting once the length is over the limit
+func streamsLength(streams []pdf.Value, limit int64) int64 {
+	var length int64
+	for _, stream := range streams {
+		if stream.Kind() != pdf.Stream || length > limit {
+			continue
+		}
+		n, _ := io.Copy(io.Discard, io.LimitReader(stream.Reader(), limit-length+1))
+		length += n
+	}
+
+	return length
+}
@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type directoryMailer struct {
+	dir  string
+	from string
+}
+
+// Directory will create an implementation of Mailer that writes each email to an .eml file of the given directory
+// instead of sending it. It is meant for development, the files can be opened with any email client.
+func Directory(dir string, from string) (model.Mailer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &directoryMailer{dir: dir, from: from}, nil
+}
+
+func (mailer *directoryMailer) Send(ctx context.Context, email model.Email) error {
+	now := time.Now()
+	body, err := message(mailer.from, email, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), email.MessageID)
+	return os.WriteFile(filepath.Join(mailer.dir, filepath.Base(name)), body, 0o640)
+}
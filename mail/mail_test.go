@@ -0,0 +1,176 @@
+package mail
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirectory(t *testing.T) {
+	dir := t.TempDir()
+	mailer, err := Directory(dir, "Internship Team <internship@example.com>")
+	assert.NoError(t, err)
+
+	err = mailer.Send(context.TODO(), model.Email{
+		MessageID: "123@internship-management",
+		To:        "ayse@e.com",
+		Subject:   "Design stajına hoş geldiniz",
+		TextBody:  "Tebrikler!",
+		HTMLBody:  "<p>Tebrikler!</p>",
+	})
+	assert.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*-123@internship-management.eml"))
+	assert.Len(t, files, 1)
+	file, _ := os.Open(files[0])
+	defer file.Close()
+
+	message, err := mail.ReadMessage(file)
+	assert.NoError(t, err)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	assert.Equal(t, "Design stajına hoş geldiniz", subject)
+	assert.Equal(t, "ayse@e.com", message.Header.Get("To"))
+	assert.Equal(t, "<123@internship-management>", message.Header.Get("Message-ID"))
+	date, err := message.Header.Date()
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), date, time.Minute)
+
+	mediaType, params, _ := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	assert.Equal(t, "multipart/alternative", mediaType)
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		// the quoted-printable parts are decoded by the reader
+		body, _ := io.ReadAll(part)
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+strings.TrimSpace(string(body)))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8: Tebrikler!", "text/html; charset=utf-8: <p>Tebrikler!</p>"}, bodies)
+}
+
+func TestSMTP(t *testing.T) {
+	t.Run("from-is-not-valid", func(t *testing.T) {
+		_, err := SMTP(SMTPConfig{Host: "localhost", From: "not an address"})
+		assert.Error(t, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		listener, _ := net.Listen("tcp", "127.0.0.1:0")
+		defer listener.Close()
+		received := make(chan string, 1)
+		go serveSMTP(listener, received)
+
+		mailer := smtpMailerListeningOn(t, listener)
+		err := mailer.Send(context.TODO(), model.Email{To: "ayse@e.com", Subject: "Welcome", TextBody: "Tebrikler!"})
+		assert.NoError(t, err)
+		assert.Contains(t, <-received, "To: ayse@e.com")
+	})
+
+	t.Run("server-does-not-respond", func(t *testing.T) {
+		listener, _ := net.Listen("tcp", "127.0.0.1:0")
+		defer listener.Close()
+
+		mailer := smtpMailerListeningOn(t, listener)
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := mailer.Send(ctx, model.Email{To: "ayse@e.com", Subject: "Welcome", TextBody: "Tebrikler!"})
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func smtpMailerListeningOn(t *testing.T, listener net.Listener) model.Mailer {
+	address := listener.Addr().(*net.TCPAddr)
+	mailer, err := SMTP(SMTPConfig{Host: address.IP.String(), Port: address.Port, From: "internship@example.com"})
+	assert.NoError(t, err)
+	return mailer
+}
+
+// serveSMTP answers a single session without extensions, and sends the data of the message to received.
+func serveSMTP(listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 localhost")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(strings.Fields(line)[0]) {
+		case "DATA":
+			_ = text.PrintfLine("354 go ahead")
+			data, _ := text.ReadDotBytes()
+			received <- string(data)
+			_ = text.PrintfLine("250 ok")
+		case "QUIT":
+			_ = text.PrintfLine("221 bye")
+			return
+		default:
+			_ = text.PrintfLine("250 ok")
+		}
+	}
+}
+
+func TestMessage_Attachments(t *testing.T) {
+	invite := []byte("BEGIN:VCALENDAR\r\nMETHOD:REQUEST\r\nEND:VCALENDAR\r\n")
+	raw, err := message("internship@example.com", model.Email{
+		To:       "ayse@e.com",
+		Subject:  "Meeting",
+		TextBody: "See you",
+		Attachments: []model.EmailAttachment{
+			{FileName: "invite.ics", ContentType: "text/calendar; charset=utf-8; method=REQUEST", Content: invite},
+		},
+	}, time.Now())
+	assert.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	assert.NoError(t, err)
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.Equal(t, "multipart/mixed", mediaType)
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+
+	// the first part contains the alternative bodies
+	part, err := reader.NextPart()
+	assert.NoError(t, err)
+	mediaType, params, _ = mime.ParseMediaType(part.Header.Get("Content-Type"))
+	assert.Equal(t, "multipart/alternative", mediaType)
+	alternative, err := multipart.NewReader(part, params["boundary"]).NextPart()
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(alternative)
+	assert.Equal(t, "See you", string(body))
+
+	part, err = reader.NextPart()
+	assert.NoError(t, err)
+	assert.Equal(t, "invite.ics", part.FileName())
+	assert.Equal(t, "text/calendar; charset=utf-8; method=REQUEST", part.Header.Get("Content-Type"))
+	// the base64 parts are not decoded by the reader
+	encoded, _ := io.ReadAll(part)
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, invite, decoded)
+
+	_, err = reader.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
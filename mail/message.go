@@ -0,0 +1,131 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+)
+
+// base64LineLength is the length of the lines of the base64 encoded attachments, https://tools.ietf.org/html/rfc2045#section-6.8
+const base64LineLength = 76
+
+// message creates a MIME message of the email, whose text and HTML bodies are the alternatives of each other.
+// If the email has attachments, the alternatives are the first part of a multipart/mixed message, followed by the attachments.
+func message(from string, email model.Email, date time.Time) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "From: %s\r\n", from)
+	fmt.Fprintf(&buffer, "To: %s\r\n", email.To)
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	if email.MessageID != "" {
+		fmt.Fprintf(&buffer, "Message-ID: <%s>\r\n", email.MessageID)
+	}
+	fmt.Fprintf(&buffer, "MIME-Version: 1.0\r\n")
+
+	if len(email.Attachments) == 0 {
+		alternatives := multipart.NewWriter(&buffer)
+		fmt.Fprintf(&buffer, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", alternatives.Boundary())
+		if err := writeAlternatives(alternatives, email); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buffer)
+	fmt.Fprintf(&buffer, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	var body bytes.Buffer
+	alternatives := multipart.NewWriter(&body)
+	if err := writeAlternatives(alternatives, email); err != nil {
+		return nil, err
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", "multipart/alternative; boundary="+alternatives.Boundary())
+	partWriter, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := partWriter.Write(body.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range email.Attachments {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", attachment.ContentType)
+		header.Set("Content-Transfer-Encoding", "base64")
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(partWriter, attachment.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// writeAlternatives writes the text and HTML bodies as the parts of a multipart/alternative entity
+func writeAlternatives(writer *multipart.Writer, email model.Email) error {
+	// the last alternative is the preferred one
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", email.TextBody},
+		{"text/html; charset=utf-8", email.HTMLBody},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.body)); err != nil {
+			return err
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// writeBase64 writes the base64 encoding of the content in lines of base64LineLength
+func writeBase64(w io.Writer, content []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 0 {
+		n := base64LineLength
+		if len(encoded) < n {
+			n = len(encoded)
+		}
+		if _, err := io.WriteString(w, encoded[:n]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+
+	return nil
+}
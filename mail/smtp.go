@@ -0,0 +1,128 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig is the server that the emails are sent through, and the address that they are sent from.
+// The server is authenticated with PLAIN authentication when the username is set.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// sendTimeout bounds sending an email when the context has no deadline.
+const sendTimeout = 30 * time.Second
+
+type smtpMailer struct {
+	config SMTPConfig
+}
+
+// SMTP will create an implementation of Mailer that sends the emails through an SMTP server.
+// STARTTLS is used when the server supports it.
+func SMTP(config SMTPConfig) (model.Mailer, error) {
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, err
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+
+	return &smtpMailer{config: config}, nil
+}
+
+func (mailer *smtpMailer) Send(ctx context.Context, email model.Email) error {
+	from, err := mail.ParseAddress(mailer.config.From)
+	if err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return err
+	}
+
+	body, err := message(mailer.config.From, email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if mailer.config.Username != "" {
+		auth = smtp.PlainAuth("", mailer.config.Username, mailer.config.Password, mailer.config.Host)
+	}
+
+	address := net.JoinHostPort(mailer.config.Host, strconv.Itoa(mailer.config.Port))
+	return mailer.send(ctx, address, auth, from.Address, to.Address, body)
+}
+
+// send is smtp.SendMail with a connection that is closed when the context is done,
+// and that times out after sendTimeout when the context has no deadline.
+func (mailer *smtpMailer) send(ctx context.Context, address string, auth smtp.Auth, from string, to string, body []byte) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sendTimeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, mailer.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: mailer.config.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(body); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
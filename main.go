@@ -1,35 +1,359 @@
 package main
 
 import (
-	"github.com/cemalunal/sample-internship-management-api/api"
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/blob"
+	"github.com/cemalunal/sample-internship-management-api/cli"
 	"github.com/cemalunal/sample-internship-management-api/db"
+	"github.com/cemalunal/sample-internship-management-api/events"
+	"github.com/cemalunal/sample-internship-management-api/mail"
+	"github.com/cemalunal/sample-internship-management-api/migrations"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/notification"
 	_assigneeRepository "github.com/cemalunal/sample-internship-management-api/repository"
 	_candidateRepository "github.com/cemalunal/sample-internship-management-api/repository"
+	"github.com/cemalunal/sample-internship-management-api/scheduler"
 	_assigneeService "github.com/cemalunal/sample-internship-management-api/service"
+	"github.com/cemalunal/sample-internship-management-api/seed"
 	_candidateService "github.com/cemalunal/sample-internship-management-api/service"
-	"github.com/gorilla/mux"
+	"github.com/cemalunal/sample-internship-management-api/stream"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"github.com/cemalunal/sample-internship-management-api/webhook"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	// the time zones of the exports are embedded, since the release image does not have the time zone database
+	_ "time/tzdata"
 )
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	mongodbUri := os.Getenv("MONGODB_URI")
-	if mongodbUri == "" {
-		log.Println("MONGODB_URI is not set, using default connection string mongodb://localhost:27017")
-		mongodbUri = "mongodb://localhost:27017"
+
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var assigneeRepository model.AssigneeRepository
+	var candidateRepository model.CandidateRepository
+	var attachmentRepository model.AttachmentRepository
+	var notificationRepository model.NotificationRepository
+	var lockRepository model.LockRepository
+	var webhookRepository model.WebhookRepository
+	var eventRepository model.EventRepository
+	var changeStreamRepository model.ChangeStreamRepository
+	var reportRepository model.ReportRepository
+	var transactions model.TransactionRunner
+	var blobStore model.BlobStore
+	var migrator *migrations.Migrator
+
+	args := os.Args[1:]
+	serving := len(args) == 0 || args[0] == "serve"
+
+	storageBackend := os.Getenv("STORAGE_BACKEND")
+	switch storageBackend {
+	case "", "mongodb":
+		mongodbUri := os.Getenv("MONGODB_URI")
+		if mongodbUri == "" {
+			log.Println("MONGODB_URI is not set, using default connection string mongodb://localhost:27017")
+			mongodbUri = "mongodb://localhost:27017"
+		}
+
+		client := db.Connect(mongodbUri)
+		database := client.Database("Company")
+		// the other commands run without the indexes, since the migrations and the fixtures may fix the data
+		// that the indexes cannot be created on, such as the duplicate emails
+		if err := db.Bootstrap(context.Background(), database); err != nil {
+			if serving {
+				log.Fatalf("Couldn't bootstrap the MongoDB collections. Error is: %s", err)
+			}
+			log.Printf("Couldn't bootstrap the MongoDB collections, running the %s command anyway. Error is: %s\n", args[0], err)
+		}
+
+		assigneesCollection := database.Collection("Assignees")
+		candidatesCollection := database.Collection("Candidates")
+		meetingsCollection := database.Collection("Meetings")
+
+		assigneeRepository = _assigneeRepository.MongoDBAssigneeRepository(assigneesCollection)
+		candidateRepository = _candidateRepository.MongoDBCandidateRepository(candidatesCollection, meetingsCollection)
+		attachmentRepository = _candidateRepository.MongoDBAttachmentRepository(database.Collection("Attachments"))
+		notificationRepository = _candidateRepository.MongoDBNotificationRepository(database.Collection("Notifications"))
+		lockRepository = _candidateRepository.MongoDBLockRepository(database.Collection("Locks"))
+		webhookRepository = _candidateRepository.MongoDBWebhookRepository(database.Collection("Webhooks"), database.Collection("WebhookDeliveries"))
+		eventRepository = _candidateRepository.MongoDBEventRepository(database.Collection("Events"))
+		changeStreamRepository = _candidateRepository.MongoDBChangeStreamRepository(candidatesCollection, database.Collection("ChangeStreams"))
+		reportRepository = _candidateRepository.MongoDBReportRepository(candidatesCollection, assigneesCollection, meetingsCollection)
+		transactions = _candidateRepository.MongoDBTransactionRunner(client)
+		migrator = migrations.NewMigrator(database)
+
+		if blobStoreName := os.Getenv("BLOB_STORE"); blobStoreName == "" || blobStoreName == "gridfs" {
+			blobStore, err = blob.GridFS(database)
+			if err != nil {
+				log.Fatalf("Couldn't open the GridFS bucket of the attachments. Error is: %s", err)
+			}
+		}
+	case "memory":
+		log.Println("Using the in-memory storage backend, the data is lost when the application stops")
+		assigneeRepository = _assigneeRepository.MemoryAssigneeRepository()
+		candidateRepository = _candidateRepository.MemoryCandidateRepository()
+		attachmentRepository = _candidateRepository.MemoryAttachmentRepository()
+		notificationRepository = _candidateRepository.MemoryNotificationRepository()
+		lockRepository = _candidateRepository.MemoryLockRepository()
+		webhookRepository = _candidateRepository.MemoryWebhookRepository()
+		eventRepository = _candidateRepository.MemoryEventRepository()
+		reportRepository = _candidateRepository.MemoryReportRepository(candidateRepository, assigneeRepository)
+	default:
+		log.Fatalf("Unknown STORAGE_BACKEND %s, expected mongodb or memory", storageBackend)
+	}
+
+	// the attachments are kept on the file system, unless they are kept in GridFS of the MongoDB backend
+	if blobStore == nil {
+		switch blobStoreName := os.Getenv("BLOB_STORE"); blobStoreName {
+		case "", "filesystem":
+			attachmentsDir := os.Getenv("ATTACHMENTS_DIR")
+			if attachmentsDir == "" {
+				attachmentsDir = "attachments"
+			}
+			blobStore, err = blob.FileSystem(attachmentsDir)
+			if err != nil {
+				log.Fatalf("Couldn't create the attachments directory %s. Error is: %s", attachmentsDir, err)
+			}
+		case "gridfs":
+			log.Fatalln("BLOB_STORE gridfs requires the mongodb storage backend")
+		default:
+			log.Fatalf("Unknown BLOB_STORE %s, expected gridfs or filesystem", blobStoreName)
+		}
+	}
+
+	var background []func(ctx context.Context)
+	eventBus := eventPublisher(eventRepository)
+	assigneeServiceOptions := []_assigneeService.AssigneeServiceOption{_assigneeService.WithAssigneeEvents(eventBus)}
+	candidateServiceOptions := []_candidateService.CandidateServiceOption{_candidateService.WithEvents(eventBus)}
+	if eventOutbox, ok := eventBus.(*events.Outbox); ok {
+		background = append(background, eventOutbox.Run)
+		// the events are stored in the outbox in the transactions of the changes, so a change is never saved without its events
+		if transactions != nil {
+			assigneeServiceOptions = append(assigneeServiceOptions, _assigneeService.WithAssigneeTransactions(transactions))
+			candidateServiceOptions = append(candidateServiceOptions, _candidateService.WithTransactions(transactions))
+		}
 	}
+	assigneeService := _assigneeService.AssigneeService(assigneeRepository, assigneeServiceOptions...)
+	attachmentService := _candidateService.AttachmentService(attachmentRepository, candidateRepository, blobStore)
+	candidateServiceOptions = append(candidateServiceOptions, _candidateService.WithAttachments(attachmentService))
+	if maxNoShows := os.Getenv("MAX_NO_SHOWS"); maxNoShows != "" {
+		limit, err := strconv.Atoi(maxNoShows)
+		if err != nil || limit < 0 {
+			log.Fatalf("Invalid MAX_NO_SHOWS %s", maxNoShows)
+		}
+		candidateServiceOptions = append(candidateServiceOptions, _candidateService.WithNoShowPolicy(limit))
+	}
+	outbox := notificationOutbox(notificationRepository, assigneeRepository)
+	if outbox != nil {
+		eventBus.Subscribe("notifications", events.Notifications(outbox), model.GetNotificationEventsAsArray()...)
+		background = append(background, outbox.Run)
+	}
+	webhookService := _candidateService.WebhookService(webhookRepository)
+	reportService := _candidateService.ReportService(reportRepository)
+	dispatcher := webhook.NewDispatcher(webhookRepository)
+	background = append(background, dispatcher.Run)
+	broadcaster := stream.NewBroadcaster()
+	// the webhooks are delivered once from the events of the services, which are stored in the outbox, rather than from
+	// the watched changes, which each replica publishes and the polling watcher merges, see "Watching the Candidates".
+	// The event stream subscribes to the events of the watched changes instead, if the candidates are watched,
+	// so the event stream of each replica has the changes made through the other replicas
+	eventBus.Subscribe("webhooks", dispatcher.Handle, model.GetWebhookEventsAsArray()...)
+	streamEvents := model.EventBus(eventBus)
+	if watcherName := os.Getenv("CANDIDATE_WATCHER"); watcherName != "" {
+		watcherBus := events.NewBus()
+		background = append(background, candidateWatcher(watcherName, changeStreamRepository, candidateRepository, watcherBus).Run)
+		streamEvents = watcherBus
+	}
+	streamEvents.Subscribe("stream", broadcaster.Handle, model.GetStreamEventsAsArray()...)
+	candidateService := _candidateService.CandidateService(candidateRepository, assigneeRepository, candidateServiceOptions...)
+	background = append(background, jobScheduler(lockRepository, candidateRepository, candidateService, outbox).Run)
 
-	client := db.Connect(mongodbUri)
-	database := client.Database("Company")
-	assigneesCollection := database.Collection("Assignees")
-	candidatesCollection := database.Collection("Candidates")
+	if serving {
+		if migrator != nil {
+			if pending, err := migrator.Pending(context.Background()); err != nil {
+				log.Println(err)
+			} else if len(pending) > 0 {
+				log.Printf("%d migrations are not applied, run the migrate command to apply them\n", len(pending))
+			}
+		}
 
-	assigneeRepository := _assigneeRepository.MongoDBAssigneeRepository(assigneesCollection)
-	candidateRepository := _candidateRepository.MongoDBCandidateRepository(candidatesCollection)
-	assigneeService := _assigneeService.AssigneeService(assigneeRepository)
-	candidateService := _candidateService.CandidateService(candidateRepository, assigneeRepository)
+		// the fixture is loaded on startup, mostly to serve sample data with the in-memory backend
+		if seedFile := os.Getenv("SEED_FILE"); seedFile != "" {
+			fixture, err := seed.ReadFile(seedFile)
+			if err == nil {
+				_, err = seed.Load(context.Background(), assigneeRepository, candidateRepository, fixture, seed.Options{})
+			}
+			if err != nil {
+				log.Fatalf("Couldn't seed %s. Error is: %s", seedFile, err)
+			}
+		}
+	}
+
+	// the rest api and the background workers are stopped gracefully on an interrupt, or when the container is stopped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = cli.Run(ctx, cli.Environment{
+		AssigneeService:     assigneeService,
+		CandidateService:    candidateService,
+		AttachmentService:   attachmentService,
+		WebhookService:      webhookService,
+		EventStream:         broadcaster,
+		ReportService:       reportService,
+		AssigneeRepository:  assigneeRepository,
+		CandidateRepository: candidateRepository,
+		Migrator:            migrator,
+		Stdout:              os.Stdout,
+		Background:          background,
+	}, args)
+	stop()
+
+	// the spans are flushed before exiting, since log.Fatal does not run the deferred functions
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Println("Couldn't flush the traces: ", err)
+	}
+	cancelFlush()
+
+	if errors.Is(err, cli.ErrUsage) {
+		log.Fatalf("%s\n\n%s", err, cli.Usage())
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// eventPublisher creates the publisher of the domain events given with the EVENT_PUBLISHER environment variable.
+// The outbox stores the events in the database and handles them in the background, the memory bus handles them right away.
+func eventPublisher(eventRepository model.EventRepository) model.EventBus {
+	switch publisher := os.Getenv("EVENT_PUBLISHER"); publisher {
+	case "", "outbox":
+		return events.NewOutbox(eventRepository)
+	case "memory":
+		return events.NewBus()
+	default:
+		log.Fatalf("Unknown EVENT_PUBLISHER %s, expected outbox or memory", publisher)
+		return nil
+	}
+}
+
+// candidateWatcher creates the watcher of the changes of the candidates given with the CANDIDATE_WATCHER environment variable.
+// The change stream falls back to polling when the database does not support change streams, or with the in-memory backend.
+func candidateWatcher(watcherName string, changeStreamRepository model.ChangeStreamRepository, candidateRepository model.CandidateRepository, publisher model.EventPublisher) *events.Watcher {
+	switch watcherName {
+	case "changestream":
+	case "poll":
+		changeStreamRepository = nil
+	default:
+		log.Fatalf("Unknown CANDIDATE_WATCHER %s, expected changestream or poll", watcherName)
+	}
+
+	watcher := events.NewWatcher(changeStreamRepository, candidateRepository, publisher)
+	if pollInterval := durationEnv("WATCHER_POLL_INTERVAL", 0); pollInterval > 0 {
+		watcher.PollInterval = pollInterval
+	}
+	return watcher
+}
+
+// jobScheduler creates the scheduler of the periodic jobs. The overdue meetings are always flagged, the candidates are
+// reminded of their meetings if they are notified, and the pending candidates are expired if PENDING_EXPIRY is set.
+func jobScheduler(lockRepository model.LockRepository, candidateRepository model.CandidateRepository, candidateService model.CandidateService, outbox *notification.Outbox) *scheduler.Scheduler {
+	jobs := []scheduler.Job{scheduler.OverdueMeetings(candidateRepository)}
+	if reminderBefore := durationEnv("REMINDER_BEFORE", 24*time.Hour); outbox != nil && reminderBefore > 0 {
+		jobs = append(jobs, scheduler.Reminders(candidateRepository, outbox, reminderBefore))
+	}
+	if pendingExpiry := durationEnv("PENDING_EXPIRY", 0); pendingExpiry > 0 {
+		jobs = append(jobs, scheduler.PendingExpiry(candidateService, pendingExpiry))
+	}
+
+	jobScheduler := scheduler.NewScheduler(lockRepository, jobs...)
+	if tickInterval := durationEnv("SCHEDULER_INTERVAL", 0); tickInterval > 0 {
+		jobScheduler.TickInterval = tickInterval
+	}
+	return jobScheduler
+}
+
+// durationEnv parses the duration of the environment variable, such as 24h or 90m, or returns the default duration if it is not set.
+// The duration 0 disables the feature of the environment variable.
+func durationEnv(name string, defaultDuration time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultDuration
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Fatalf("Invalid %s %s, expected a duration such as 24h", name, value)
+	}
+	return duration
+}
+
+// notificationOutbox creates the outbox of the email notifications with the mailer given with the MAILER environment variable.
+// The candidates are not notified if the mailer is not set.
+func notificationOutbox(notificationRepository model.NotificationRepository, assigneeRepository model.AssigneeRepository) *notification.Outbox {
+	var mailer model.Mailer
+	var err error
+	from := os.Getenv("MAIL_FROM")
+	switch mailerName := os.Getenv("MAILER"); mailerName {
+	case "":
+		return nil
+	case "smtp":
+		port := 0
+		if smtpPort := os.Getenv("SMTP_PORT"); smtpPort != "" {
+			port, err = strconv.Atoi(smtpPort)
+			if err != nil {
+				log.Fatalf("Invalid SMTP_PORT %s", smtpPort)
+			}
+		}
+		mailer, err = mail.SMTP(mail.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+	case "directory":
+		mailDir := os.Getenv("MAIL_DIR")
+		if mailDir == "" {
+			mailDir = "mailbox"
+		}
+		if from == "" {
+			from = "Internship Team <internship@localhost>"
+		}
+		mailer, err = mail.Directory(mailDir, from)
+	default:
+		log.Fatalf("Unknown MAILER %s, expected smtp or directory", mailerName)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't create the %s mailer. Error is: %s", os.Getenv("MAILER"), err)
+	}
+
+	language := os.Getenv("NOTIFICATION_LANGUAGE")
+	if language == "" {
+		language = model.English
+	}
+	location := time.UTC
+	if timezone := os.Getenv("NOTIFICATION_TIMEZONE"); timezone != "" {
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			log.Fatalf("Unknown NOTIFICATION_TIMEZONE %s. Error is: %s", timezone, err)
+		}
+	}
+
+	templates, err := notification.LoadTemplates(language, location)
+	if err != nil {
+		log.Fatalf("Couldn't load the notification templates. Error is: %s", err)
+	}
 
-	r := mux.NewRouter()
-	api.Api(r, assigneeService, candidateService)
+	outbox := notification.NewOutbox(notificationRepository, assigneeRepository, templates, mailer)
+	// the invitations of the meetings are organized by the sender of the emails
+	outbox.Organizer = from
+	return outbox
 }
@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// backfillCandidateFields sets the fields that were added to the candidates after they were created.
+// The application date is taken from the creation time of the candidate id, and an arranged meeting
+// record is created for the candidates that have a next meeting.
+func backfillCandidateFields(ctx context.Context, database *mongo.Database, dryRun bool) (int64, error) {
+	candidates := database.Collection("Candidates")
+	meetings := database.Collection("Meetings")
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"version": bson.M{"$exists": false}},
+		bson.M{"meeting_id": bson.M{"$exists": false}},
+		bson.M{"meeting_count": bson.M{"$exists": false}},
+		bson.M{"application_date": bson.M{"$exists": false}},
+		bson.M{"status": bson.M{"$in": bson.A{nil, ""}}},
+	}}
+
+	if dryRun {
+		return candidates.CountDocuments(ctx, filter)
+	}
+
+	cursor, err := candidates.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var affected int64
+	for cursor.Next(ctx) {
+		var candidate bson.M
+		if err := cursor.Decode(&candidate); err != nil {
+			return affected, err
+		}
+
+		set := bson.M{}
+		if _, ok := candidate["version"]; !ok {
+			set["version"] = 0
+		}
+		if _, ok := candidate["meeting_count"]; !ok {
+			set["meeting_count"] = 0
+		}
+		if status, _ := candidate["status"].(string); status == "" {
+			set["status"] = model.Pending
+		}
+		if _, ok := candidate["application_date"]; !ok {
+			set["application_date"] = creationTime(candidate["_id"])
+		}
+		if _, ok := candidate["meeting_id"]; !ok {
+			meetingID, err := recordArrangedMeeting(ctx, meetings, candidate)
+			if err != nil {
+				return affected, err
+			}
+			set["meeting_id"] = meetingID
+		}
+
+		_, err := candidates.UpdateOne(ctx, bson.M{"_id": candidate["_id"]}, bson.M{"$set": set})
+		if err != nil {
+			return affected, err
+		}
+		affected++
+	}
+
+	return affected, cursor.Err()
+}
+
+// recordArrangedMeeting creates the meeting record of the next meeting of the candidate.
+// The id of the record is derived from the candidate id, so running the migration again does not duplicate it.
+func recordArrangedMeeting(ctx context.Context, meetings *mongo.Collection, candidate bson.M) (string, error) {
+	nextMeeting, ok := candidate["next_meeting"].(primitive.DateTime)
+	if !ok {
+		return "", nil
+	}
+
+	candidateID, _ := candidate["_id"].(string)
+	assigneeID, _ := candidate["assignee"].(string)
+	meeting := model.MeetingRecord{
+		ID:          "migrated-" + candidateID,
+		CandidateID: candidateID,
+		AssigneeID:  assigneeID,
+		Time:        dateTimeToTime(nextMeeting),
+		Status:      model.MeetingArranged,
+		ArrangedAt:  time.Now(),
+	}
+
+	_, err := meetings.UpdateOne(ctx,
+		bson.M{"_id": meeting.ID},
+		bson.M{"$setOnInsert": meeting},
+		options.Update().SetUpsert(true),
+	)
+
+	return meeting.ID, err
+}
+
+// creationTime returns the creation time of an object id, or the current time for other ids
+func creationTime(id interface{}) time.Time {
+	if hex, ok := id.(string); ok {
+		if objectID, err := primitive.ObjectIDFromHex(hex); err == nil {
+			return objectID.Timestamp()
+		}
+	}
+	if objectID, ok := id.(primitive.ObjectID); ok {
+		return objectID.Timestamp()
+	}
+
+	return time.Now()
+}
+
+func dateTimeToTime(dateTime primitive.DateTime) time.Time {
+	milliseconds := int64(dateTime)
+	return time.Unix(milliseconds/1000, (milliseconds%1000)*int64(time.Millisecond)).UTC()
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+// backfillDecidedAt sets the decision time of the candidates that were accepted or denied before it was recorded.
+// The decision is taken as the last change of the meetings of the candidate, which is the completion of the last
+// meeting in most cases. The candidates without any meetings are left without a decision time.
+func backfillDecidedAt(ctx context.Context, database *mongo.Database, dryRun bool) (int64, error) {
+	candidates := database.Collection("Candidates")
+	meetings := database.Collection("Meetings")
+
+	filter := bson.M{
+		"status":     bson.M{"$in": bson.A{model.Accepted, model.Denied}},
+		"decided_at": bson.M{"$exists": false},
+	}
+
+	cursor, err := candidates.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var affected int64
+	for cursor.Next(ctx) {
+		var candidate struct {
+			ID string `bson:"_id"`
+		}
+		if err := cursor.Decode(&candidate); err != nil {
+			return affected, err
+		}
+
+		var records []model.MeetingRecord
+		meetingCursor, err := meetings.Find(ctx, bson.M{"candidate_id": candidate.ID})
+		if err == nil {
+			err = meetingCursor.All(ctx, &records)
+		}
+		if err != nil {
+			return affected, err
+		}
+
+		decidedAt := lastMeetingChange(records)
+		if decidedAt == nil {
+			continue
+		}
+		if !dryRun {
+			_, err := candidates.UpdateOne(ctx, bson.M{"_id": candidate.ID}, bson.M{"$set": bson.M{"decided_at": *decidedAt}})
+			if err != nil {
+				return affected, err
+			}
+		}
+		affected++
+	}
+
+	return affected, cursor.Err()
+}
+
+// lastMeetingChange returns the latest time that the meetings were arranged, completed or changed,
+// or nil if there are not any meetings
+func lastMeetingChange(records []model.MeetingRecord) *time.Time {
+	var last *time.Time
+	later := func(t time.Time) {
+		if !t.IsZero() && (last == nil || t.After(*last)) {
+			last = &t
+		}
+	}
+
+	for _, record := range records {
+		later(record.ArrangedAt)
+		if record.CompletedAt != nil {
+			later(*record.CompletedAt)
+		}
+		for _, change := range record.Changes {
+			later(change.At)
+		}
+	}
+
+	return last
+}
@@ -0,0 +1,17 @@
+package migrations
+
+// All returns every migration of the application. New migrations are appended with the next version.
+func All() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "backfill version, application date and meeting records of the existing candidates",
+			Up:          backfillCandidateFields,
+		},
+		{
+			Version:     2,
+			Description: "backfill the decision time of the accepted and the denied candidates from their meetings",
+			Up:          backfillDecidedAt,
+		},
+	}
+}
@@ -0,0 +1,160 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"sort"
+	"time"
+)
+
+// stateCollection keeps the versions of the migrations that are applied
+const stateCollection = "Migrations"
+
+// Migration is a versioned change of the existing documents.
+// Migrations must be idempotent, since a migration that fails halfway is run again.
+type Migration struct {
+	Version     int
+	Description string
+	// Up applies the migration and returns the number of changed documents.
+	// When dryRun is true, it does not change anything and returns the number of documents that would be changed.
+	Up func(ctx context.Context, database *mongo.Database, dryRun bool) (int64, error)
+}
+
+// Result is the outcome of a migration
+type Result struct {
+	Version     int
+	Description string
+	Affected    int64
+	DryRun      bool
+}
+
+// appliedMigration is persisted in the state collection after a migration is applied
+type appliedMigration struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	Affected    int64     `bson:"affected"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// migrationState keeps the versions of the migrations that are applied
+type migrationState interface {
+	applied(ctx context.Context) ([]appliedMigration, error)
+	markApplied(ctx context.Context, migration appliedMigration) error
+}
+
+// mongodbMigrationState keeps the applied migrations in the state collection of the database
+type mongodbMigrationState struct {
+	collection *mongo.Collection
+}
+
+func (state mongodbMigrationState) applied(ctx context.Context) ([]appliedMigration, error) {
+	cursor, err := state.collection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []appliedMigration
+	err = cursor.All(ctx, &applied)
+
+	return applied, err
+}
+
+func (state mongodbMigrationState) markApplied(ctx context.Context, migration appliedMigration) error {
+	_, err := state.collection.InsertOne(ctx, migration)
+	return err
+}
+
+// Migrator applies the migrations in the order of their versions
+type Migrator struct {
+	database   *mongo.Database
+	state      migrationState
+	migrations []Migration
+}
+
+// NewMigrator will create a migrator for all migrations of the given database
+func NewMigrator(database *mongo.Database) *Migrator {
+	return newMigrator(database, mongodbMigrationState{collection: database.Collection(stateCollection)}, All())
+}
+
+func newMigrator(database *mongo.Database, state migrationState, migrations []Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			panic(fmt.Sprintf("migration version %d is declared twice", sorted[i].Version))
+		}
+	}
+
+	return &Migrator{
+		database:   database,
+		state:      state,
+		migrations: sorted,
+	}
+}
+
+// Pending returns the migrations that are not applied yet
+func (migrator *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := migrator.state.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedVersions := make(map[int]bool)
+	for _, migration := range applied {
+		appliedVersions[migration.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range migrator.migrations {
+		if !appliedVersions[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Up applies the pending migrations in order, and stops at the first migration that fails.
+// In dry-run mode, the migrations only report the number of documents that they would change.
+func (migrator *Migrator) Up(ctx context.Context, dryRun bool) ([]Result, error) {
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, migration := range pending {
+		affected, err := migration.Up(ctx, migrator.database, dryRun)
+		if err != nil {
+			return results, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+
+		results = append(results, Result{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Affected:    affected,
+			DryRun:      dryRun,
+		})
+		if dryRun {
+			continue
+		}
+
+		err = migrator.state.markApplied(ctx, appliedMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Affected:    affected,
+			AppliedAt:   time.Now(),
+		})
+		if err != nil {
+			return results, err
+		}
+		log.Printf("Applied migration %d (%s), %d documents changed\n", migration.Version, migration.Description, affected)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,158 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+	"time"
+)
+
+// memoryMigrationState keeps the applied migrations in memory
+type memoryMigrationState struct {
+	migrations []appliedMigration
+}
+
+func (state *memoryMigrationState) applied(ctx context.Context) ([]appliedMigration, error) {
+	return state.migrations, nil
+}
+
+func (state *memoryMigrationState) markApplied(ctx context.Context, migration appliedMigration) error {
+	state.migrations = append(state.migrations, migration)
+	return nil
+}
+
+// fakeMigration records the order that the migrations are run in, and the dry runs
+type fakeMigration struct {
+	runs    []int
+	dryRuns []bool
+}
+
+func (fake *fakeMigration) migration(version int, err error) Migration {
+	return Migration{
+		Version:     version,
+		Description: "fake",
+		Up: func(ctx context.Context, database *mongo.Database, dryRun bool) (int64, error) {
+			fake.runs = append(fake.runs, version)
+			fake.dryRuns = append(fake.dryRuns, dryRun)
+			return int64(version * 10), err
+		},
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	t.Run("ordered", func(t *testing.T) {
+		fake := &fakeMigration{}
+		state := &memoryMigrationState{}
+		migrator := newMigrator(nil, state, []Migration{fake.migration(3, nil), fake.migration(1, nil), fake.migration(2, nil)})
+
+		results, err := migrator.Up(context.TODO(), false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, fake.runs)
+		assert.Equal(t, []Result{
+			{Version: 1, Description: "fake", Affected: 10},
+			{Version: 2, Description: "fake", Affected: 20},
+			{Version: 3, Description: "fake", Affected: 30},
+		}, results)
+		assert.Len(t, state.migrations, 3)
+		assert.Equal(t, int64(30), state.migrations[2].Affected)
+	})
+
+	t.Run("skips-applied", func(t *testing.T) {
+		fake := &fakeMigration{}
+		state := &memoryMigrationState{migrations: []appliedMigration{{Version: 1}, {Version: 3}}}
+		migrator := newMigrator(nil, state, []Migration{fake.migration(1, nil), fake.migration(2, nil), fake.migration(3, nil)})
+
+		pending, err := migrator.Pending(context.TODO())
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+
+		results, err := migrator.Up(context.TODO(), false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2}, fake.runs)
+		assert.Len(t, results, 1)
+		assert.Len(t, state.migrations, 3)
+
+		// nothing is left to apply
+		results, err = migrator.Up(context.TODO(), false)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+		assert.Equal(t, []int{2}, fake.runs)
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		fake := &fakeMigration{}
+		state := &memoryMigrationState{}
+		migrator := newMigrator(nil, state, []Migration{fake.migration(1, nil), fake.migration(2, nil)})
+
+		results, err := migrator.Up(context.TODO(), true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []bool{true, true}, fake.dryRuns)
+		assert.Equal(t, []Result{
+			{Version: 1, Description: "fake", Affected: 10, DryRun: true},
+			{Version: 2, Description: "fake", Affected: 20, DryRun: true},
+		}, results)
+		// the dry run does not record any state, so the migrations are still pending
+		assert.Empty(t, state.migrations)
+		pending, _ := migrator.Pending(context.TODO())
+		assert.Len(t, pending, 2)
+	})
+
+	t.Run("stops-at-first-failure", func(t *testing.T) {
+		fake := &fakeMigration{}
+		state := &memoryMigrationState{}
+		migrator := newMigrator(nil, state, []Migration{fake.migration(1, nil), fake.migration(2, errors.New("write conflict")), fake.migration(3, nil)})
+
+		results, err := migrator.Up(context.TODO(), false)
+
+		assert.EqualError(t, err, "migration 2 (fake) failed: write conflict")
+		assert.Equal(t, []int{1, 2}, fake.runs)
+		assert.Len(t, results, 1)
+		// the failed migration is not recorded, so it is run again
+		assert.Len(t, state.migrations, 1)
+		assert.Equal(t, 1, state.migrations[0].Version)
+	})
+}
+
+func TestNewMigrator_DuplicateVersion(t *testing.T) {
+	fake := &fakeMigration{}
+
+	assert.PanicsWithValue(t, "migration version 2 is declared twice", func() {
+		newMigrator(nil, &memoryMigrationState{}, []Migration{fake.migration(2, nil), fake.migration(1, nil), fake.migration(2, nil)})
+	})
+}
+
+func TestAll(t *testing.T) {
+	// the declared migrations have unique versions
+	assert.NotPanics(t, func() { newMigrator(nil, &memoryMigrationState{}, All()) })
+}
+
+func TestLastMeetingChange(t *testing.T) {
+	arrangedAt := time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC)
+	completedAt := arrangedAt.Add(72 * time.Hour)
+	cancelledAt := arrangedAt.Add(24 * time.Hour)
+
+	t.Run("completed", func(t *testing.T) {
+		last := lastMeetingChange([]model.MeetingRecord{
+			{ArrangedAt: arrangedAt, CompletedAt: &completedAt},
+			{ArrangedAt: arrangedAt.Add(time.Hour), Changes: []model.MeetingChange{{Type: model.MeetingCancelled, At: cancelledAt}}},
+		})
+		assert.Equal(t, completedAt, *last)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		last := lastMeetingChange([]model.MeetingRecord{
+			{ArrangedAt: arrangedAt, Changes: []model.MeetingChange{{Type: model.MeetingCancelled, At: cancelledAt}}},
+		})
+		assert.Equal(t, cancelledAt, *last)
+	})
+
+	t.Run("without-meetings", func(t *testing.T) {
+		assert.Nil(t, lastMeetingChange(nil))
+	})
+}
@@ -9,6 +9,8 @@ import (
 type ApiResponse struct {
 	Code    	int 		`json:"code"`
 	Message		string 		`json:"message"`
+	ErrorCode	string		`json:"error_code,omitempty"`
+	Details		interface{}	`json:"details,omitempty"`
 	Data 		interface{}	`json:"data"`
 }
 
@@ -36,22 +38,15 @@ func GetCreatedResponse(w http.ResponseWriter, message string, data interface{})
 	return response, w
 }
 
-func GetBadRequestResponse(w http.ResponseWriter, message string) (ApiResponse, http.ResponseWriter) {
+func GetErrorResponse(w http.ResponseWriter, err *Error) (ApiResponse, http.ResponseWriter) {
 	var response ApiResponse
-	response.Code = 400
-	response.Message = message
-
-	w.WriteHeader(http.StatusBadRequest)
-
-	return response, w
-}
+	response.Code = err.Status
+	response.Message = err.Message
+	response.ErrorCode = err.Code
+	response.Details = err.Details
 
-func GetInternalServerErrorResponse(w http.ResponseWriter, message string) (ApiResponse, http.ResponseWriter) {
-	var response ApiResponse
-	response.Code = 500
-	response.Message = message
-
-	w.WriteHeader(http.StatusInternalServerError)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(err.Status)
 
 	return response, w
 }
@@ -6,10 +6,19 @@ import (
 
 // Assignee model is used to store and exchange assignee information
 // It is persisted in the DB in Assignees collection
+// CalendarTokenHash is the hash of the secret token of the calendar feed of the assignee, the token itself is not stored.
 type Assignee struct {
 	ID    		string 	`json:"id" bson:"_id,omitempty"`
 	Name     	string 	`json:"name" validate:"required"`
 	Department 	string	`json:"department" validate:"required"`
+	CalendarTokenHash	string	`json:"-" bson:"calendar_token_hash,omitempty"`
+}
+
+// CalendarToken is the secret token of the calendar feed of an assignee, and the url of the feed that is secured with it
+// It is not persisted in the DB
+type CalendarToken struct {
+	Token		string	`json:"token"`
+	URL			string	`json:"url"`
 }
 
 type AssigneeRepository interface {
@@ -19,11 +28,14 @@ type AssigneeRepository interface {
 	FindAssigneeIDByName(ctx context.Context, name string) (string, error)
 	FindAllAssigneesByDepartment(ctx context.Context, department string) ([]Assignee, error)
 	FindOneAssigneeByDepartment(ctx context.Context, department string) (Assignee, error)
+	SetCalendarTokenHash(ctx context.Context, id string, tokenHash string) error
+	DeleteAllAssignees(ctx context.Context) error
 }
 
 type AssigneeService interface {
 	CreateAssignee(ctx context.Context, assignee Assignee) (Assignee, error)
 	FindAllAssignees(ctx context.Context) ([]Assignee, error)
 	FindAllAssigneesByDepartment(ctx context.Context, department string) ([]Assignee, error)
-	FindAssigneeIDByName(ctx context.Context, name string) string
+	FindAssigneeIDByName(ctx context.Context, name string) (string, error)
+	CreateCalendarToken(ctx context.Context, id string) (string, error)
 }
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// simulates enumeration for the kinds of the attachments
+const (
+	AttachmentCV = "CV"
+	AttachmentTranscript = "Transcript"
+	AttachmentCoverLetter = "CoverLetter"
+)
+
+func GetAttachmentKindsAsArray() []string {
+	return []string { AttachmentCV, AttachmentTranscript, AttachmentCoverLetter }
+}
+
+// simulates enumeration for the outcomes of the text extraction of the attachments
+const (
+	TextExtracted = "Extracted"
+	TextExtractionFailed = "Failed"
+	TextExtractionNotSupported = "NotSupported"
+)
+
+// Attachment model is used to store the metadata of the documents of the candidates
+// It is persisted in the DB in Attachments collection, while its content is kept in a BlobStore
+type Attachment struct {
+	ID				string		`json:"id" bson:"_id,omitempty"`
+	CandidateID		string		`json:"candidate_id" bson:"candidate_id"`
+	Kind			string		`json:"kind" bson:"kind"`
+	FileName		string		`json:"file_name" bson:"file_name"`
+	ContentType		string		`json:"content_type" bson:"content_type"`
+	Size			int64		`json:"size" bson:"size"`
+	UploadedAt		time.Time	`json:"uploaded_at" bson:"uploaded_at"`
+	// TextExtraction is the outcome of extracting Text from the content, the reason of a failure is kept in TextExtractionError
+	TextExtraction		string		`json:"text_extraction" bson:"text_extraction"`
+	TextExtractionError	string		`json:"text_extraction_error,omitempty" bson:"text_extraction_error,omitempty"`
+	Text			string		`json:"-" bson:"text,omitempty"`
+}
+
+// AttachmentRepository persists the metadata and the extracted text of the attachments.
+// The text is not read back, it is only searched by FindCandidateIDsByText.
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment Attachment) (Attachment, error)
+	ReadAttachment(ctx context.Context, candidateID string, id string) (Attachment, error)
+	FindCandidatesAttachments(ctx context.Context, candidateID string) ([]Attachment, error)
+	DeleteAttachment(ctx context.Context, candidateID string, id string) error
+	FindCandidateIDsByText(ctx context.Context, word string) ([]string, error)
+}
+
+// BlobStore keeps the contents of the attachments with their ids as keys
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader) (int64, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AttachmentService stores the attachments of the existing candidates, and extracts their text to be searched.
+// DeleteCandidatesAttachments is called when a candidate is deleted.
+// FindCandidateIDsByText finds the candidates that have an attachment which contains the given word.
+type AttachmentService interface {
+	UploadAttachment(ctx context.Context, attachment Attachment, content io.Reader) (Attachment, error)
+	FindCandidatesAttachments(ctx context.Context, candidateID string) ([]Attachment, error)
+	DownloadAttachment(ctx context.Context, candidateID string, id string) (Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, candidateID string, id string) error
+	DeleteCandidatesAttachments(ctx context.Context, candidateID string) error
+	FindCandidateIDsByText(ctx context.Context, word string) ([]string, error)
+}
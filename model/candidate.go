@@ -17,31 +17,124 @@ type Candidate struct {
 	Experience 		bool 		`json:"experience"`
 	ApplicationDate time.Time	`json:"application_date" bson:"application_date"`
 	Status 			string 		`json:"status"`
+	// DecidedAt is the time that the candidate is denied or accepted
+	DecidedAt		*time.Time	`json:"decided_at,omitempty" bson:"decided_at,omitempty"`
 	MeetingCount 	int 		`json:"meeting_count" bson:"meeting_count"`
+	// NoShowCount is the number of the meetings that the candidate has missed, they are not counted in MeetingCount
+	NoShowCount		int			`json:"no_show_count" bson:"no_show_count"`
 	NextMeeting 	*time.Time	`json:"next_meeting" bson:"next_meeting"`
 	Assignee 		string 		`json:"assignee"`
+	MeetingID 		string 		`json:"meeting_id" bson:"meeting_id"`
+	// LastMeetingTransition is the latest change of the meetings of the candidate, keyed by the id of the change,
+	// see MeetingTransition. Only the latest change is kept, so it doesn't grow with the meetings of the candidate.
+	LastMeetingTransition	map[string]MeetingTransition	`json:"-" bson:"last_meeting_transition,omitempty"`
+	Version 		int64 		`json:"version" bson:"version"`
+	// Language is the language that the candidate is notified in, the default language is used when it is empty
+	Language		string		`json:"language,omitempty" bson:"language,omitempty" validate:"omitempty,oneof=en tr"`
 }
 
+// CandidateFilter is used to find the candidates that match all of the given fields. Empty fields match any candidate.
+// The candidates must also match all terms of the Search.
+type CandidateFilter struct {
+	Status			string
+	Department		string
+	University		string
+	AssigneeID		string
+	Search			[]SearchTerm
+}
+
+// LatestMeetingTransition returns the id and the latest change of the meetings of the candidate, or nil if there is not any
+func (candidate Candidate) LatestMeetingTransition() (string, *MeetingTransition) {
+	for id, transition := range candidate.LastMeetingTransition {
+		return id, &transition
+	}
+	return "", nil
+}
+
+// Matches checks the given candidate matches the filter
+func (filter CandidateFilter) Matches(candidate Candidate) bool {
+	return (filter.Status == "" || filter.Status == candidate.Status) &&
+		(filter.Department == "" || filter.Department == candidate.Department) &&
+		(filter.University == "" || filter.University == candidate.University) &&
+		(filter.AssigneeID == "" || filter.AssigneeID == candidate.Assignee) &&
+		matchesAll(filter.Search, candidate)
+}
+
+// ExportedCandidate is used to export a candidate along with the name of its assignee
+type ExportedCandidate struct {
+	Candidate
+	AssigneeName	string
+}
+
+// CandidateRepository persists candidates. UpdateCandidate only succeeds when the stored
+// version of the candidate is equal to the version of the given candidate, and it increments
+// the version on each successful update. Otherwise, ErrCandidateVersionConflict is returned.
+// ArrangeMeeting, RescheduleMeeting, CancelMeeting, RecordNoShow and CompleteMeeting change the meeting of the candidate and its meeting record atomically.
+// RescheduleMeeting, CancelMeeting and RecordNoShow record the change in the history of the meeting record, and return it along with the candidate.
+// They also replace the LastMeetingTransition of the candidate with the transition of the change in the same write,
+// which UpdateCandidate keeps as it is.
+// ReadMeeting reads a meeting record by its id, it returns ErrMeetingDoesNotExist if the record does not exist.
+// FindCandidatesMeetings finds the meeting records of the candidate, ordered by the time they are arranged at.
+// FindAssigneesMeetings finds the meeting records of the assignee that start after the given time, ordered by their time.
+// FindMeetingsToRemind finds the arranged meetings that start in the given time range and have not been reminded yet,
+// and MarkReminderSent marks a meeting as reminded, returning false if it has already been marked.
+// RecordMeetingFeedback records the feedback score of a completed meeting, it returns ErrMeetingDoesNotExist if there is not
+// a completed meeting with the id.
+// FlagOverdueMeetings flags the arranged meetings that have ended before the given time, and FindOverdueMeetings finds them.
+// FindCandidatesToExpire finds the pending candidates without an arranged meeting that have applied before the given time.
+// The flagging returns the number of the flagged meetings.
+// DeleteAllCandidates deletes the candidates along with their meeting records.
+// StreamCandidates calls the given function with each candidate that matches the filter, as they are read from the storage,
+// and stops at the first error that the function returns.
 type CandidateRepository interface {
 	CreateCandidate(ctx context.Context, candidate Candidate) (Candidate, error)
 	UpdateCandidate(ctx context.Context, id string, candidate Candidate) error
 	ReadCandidate(ctx context.Context, id string) (Candidate, error)
 	FindAllCandidates(ctx context.Context) ([]Candidate, error)
+	FindCandidates(ctx context.Context, filter CandidateFilter) ([]Candidate, error)
+	StreamCandidates(ctx context.Context, filter CandidateFilter, fn func(Candidate) error) error
 	FindCandidateByEmail(ctx context.Context, email string) (Candidate, error)
 	FindAssigneesCandidates(ctx context.Context, id string) ([]Candidate, error)
 	DeleteCandidate(ctx context.Context, id string) error
+	ArrangeMeeting(ctx context.Context, id string, version int64, meeting MeetingRecord) (Candidate, error)
+	RescheduleMeeting(ctx context.Context, id string, version int64, change MeetingChange) (Candidate, MeetingRecord, error)
+	CancelMeeting(ctx context.Context, id string, version int64, change MeetingChange) (Candidate, MeetingRecord, error)
+	RecordNoShow(ctx context.Context, id string, version int64, change MeetingChange) (Candidate, MeetingRecord, error)
+	CompleteMeeting(ctx context.Context, id string, completedAt time.Time) (Candidate, error)
+	ReadMeeting(ctx context.Context, id string) (MeetingRecord, error)
+	FindCandidatesMeetings(ctx context.Context, candidateID string) ([]MeetingRecord, error)
+	FindAssigneesMeetings(ctx context.Context, assigneeID string, from time.Time) ([]MeetingRecord, error)
+	FindMeetingsToRemind(ctx context.Context, from time.Time, to time.Time) ([]MeetingRecord, error)
+	MarkReminderSent(ctx context.Context, meetingID string, sentAt time.Time) (bool, error)
+	RecordMeetingFeedback(ctx context.Context, meetingID string, score int) error
+	FlagOverdueMeetings(ctx context.Context, endedBefore time.Time) (int64, error)
+	FindOverdueMeetings(ctx context.Context) ([]MeetingRecord, error)
+	FindCandidatesToExpire(ctx context.Context, appliedBefore time.Time) ([]Candidate, error)
+	DeleteAllCandidates(ctx context.Context) error
 }
 
 type CandidateService interface {
 	CreateCandidate(ctx context.Context, candidate Candidate) (Candidate, error)
-	UpdateCandidate(ctx context.Context, id string, candidate Candidate) error
+	UpdateCandidate(ctx context.Context, id string, candidate Candidate) (Candidate, error)
 	ReadCandidate(ctx context.Context, email string) (Candidate, error)
 	FindAllCandidates(ctx context.Context) ([]Candidate, error)
+	FindCandidates(ctx context.Context, filter CandidateFilter) ([]Candidate, error)
+	ExportCandidates(ctx context.Context, filter CandidateFilter, fn func(ExportedCandidate) error) error
 	FindCandidateByEmail(ctx context.Context, id string) (Candidate, error)
 	FindAssigneesCandidates(ctx context.Context, id string) ([]Candidate, error)
 	DeleteCandidate(ctx context.Context, id string) error
 	DenyCandidate(ctx context.Context, id string) error
 	AcceptCandidate(ctx context.Context, id string) error
 	ArrangeMeeting(ctx context.Context, id string, nextMeetingTime *time.Time) error
+	RescheduleMeeting(ctx context.Context, id string, nextMeetingTime *time.Time) error
+	CancelMeeting(ctx context.Context, id string, reason string) error
+	RecordNoShow(ctx context.Context, id string) error
 	CompleteMeeting(ctx context.Context, id string) error
+	ReadMeeting(ctx context.Context, id string) (MeetingDetails, error)
+	FindCandidatesMeetings(ctx context.Context, id string) ([]MeetingRecord, error)
+	RecordMeetingFeedback(ctx context.Context, meetingID string, score int) error
+	FindAssigneesCalendar(ctx context.Context, assigneeID string, token string) (Assignee, []MeetingDetails, error)
+	FindOverdueMeetings(ctx context.Context) ([]MeetingDetails, error)
+	ImportCandidates(ctx context.Context, candidates []Candidate, dryRun bool) []CandidateImportResult
+	ExpirePendingCandidates(ctx context.Context, appliedBefore time.Time) (int, error)
 }
@@ -0,0 +1,34 @@
+package model
+
+// simulates enumeration for the outcome of an imported row
+const (
+	ImportCreated = "Created"
+	ImportValid = "Valid"
+	ImportFailed = "Failed"
+)
+
+// CandidateImportResult is used to return the outcome of creating one of the imported candidates from the service
+type CandidateImportResult struct {
+	Candidate	Candidate
+	Err			error
+}
+
+// CandidateImportReport model is used to report the outcome of each row of an imported spreadsheet
+// It is not persisted in the DB
+type CandidateImportReport struct {
+	DryRun		bool					`json:"dry_run"`
+	Total		int						`json:"total"`
+	Succeeded	int						`json:"succeeded"`
+	Failed		int						`json:"failed"`
+	Rows		[]CandidateImportRow	`json:"rows"`
+}
+
+// CandidateImportRow is the outcome of a row of an imported spreadsheet.
+// Row is the line number in the spreadsheet, where the header is the first line.
+type CandidateImportRow struct {
+	Row			int		`json:"row"`
+	Email		string	`json:"email"`
+	Status		string	`json:"status"`
+	CandidateID	string	`json:"candidate_id,omitempty"`
+	Error		*Error	`json:"error,omitempty"`
+}
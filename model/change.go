@@ -0,0 +1,70 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// simulates enumeration for the operations of the changes of the candidates
+const (
+	ChangeInsert = "insert"
+	ChangeUpdate = "update"
+	ChangeReplace = "replace"
+	ChangeDelete = "delete"
+)
+
+var (
+	// ErrChangeStreamNotSupported is returned by the watchers of the changes when the database does not support
+	// change streams, such as a standalone MongoDB server
+	ErrChangeStreamNotSupported = errors.New("change streams are only supported by replica sets and sharded clusters")
+	// ErrChangeStreamHistoryLost is returned by the watchers of the changes when the change of the resume token is not kept anymore
+	ErrChangeStreamHistoryLost = errors.New("change of the resume token is not kept anymore")
+)
+
+// CandidateChange is a change of a candidate document in the database. Candidate is the document after the change,
+// it is nil if the candidate is deleted. UpdatedFields are the fields that are set or removed by an update.
+// Status is the status that an update has set, and MeetingTransition is the transition of the meetings that it has recorded,
+// they are taken from the update itself, so they do not reflect the later changes of the candidate like Candidate does.
+// Token identifies the change in the stream of the changes, so it is the same on each replica that watches the stream.
+type CandidateChange struct {
+	Token				[]byte
+	Operation			string
+	CandidateID			string
+	Candidate			*Candidate
+	UpdatedFields		[]string
+	Status				string
+	MeetingTransition	*MeetingTransition
+	OccurredAt			time.Time
+}
+
+// MeetingTransition is a change of the meeting of a candidate, which is recorded on the candidate in the same write
+// as the change, so the watchers of the changes know which change of the meeting each update has made.
+// Each transition is kept under a new id, so an update always reports the whole transition, rather than the fields
+// that differ from the previous one.
+// Event is the event of the change, such as MeetingArrangedEvent, and MeetingID is the id of its meeting record.
+// The completions do not record their meetings, as their events do not carry them.
+type MeetingTransition struct {
+	Event			string		`bson:"event"`
+	MeetingID		string		`bson:"meeting_id,omitempty"`
+}
+
+// ResumeToken model is the position of a watcher in the stream of the changes, the watcher resumes after it when it is restarted
+// It is persisted in the DB in ChangeStreams collection
+type ResumeToken struct {
+	Name			string		`json:"name" bson:"_id"`
+	Token			[]byte		`json:"token" bson:"token"`
+	UpdatedAt		time.Time	`json:"updated_at" bson:"updated_at"`
+}
+
+// ChangeStreamRepository watches the changes of the candidates in the database, and keeps the resume tokens of the watchers.
+// WatchCandidates calls the given function with each change after the given resume token, or with the changes from now on
+// if the token is empty, until the context is done or the function returns an error. It returns ErrChangeStreamNotSupported
+// if the database does not support change streams, and ErrChangeStreamHistoryLost if it cannot resume after the token.
+// A change that cannot be decoded is passed with only its Token, so the watchers move past it without publishing it.
+// ReadResumeToken returns the token that is saved with the name, or an empty token if it is not saved yet.
+type ChangeStreamRepository interface {
+	WatchCandidates(ctx context.Context, resumeToken []byte, fn func(CandidateChange) error) error
+	ReadResumeToken(ctx context.Context, name string) ([]byte, error)
+	SaveResumeToken(ctx context.Context, name string, token []byte) error
+}
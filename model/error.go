@@ -1,14 +1,128 @@
 package model
 
 import (
-	"errors"
+	"net/http"
 )
 
+// Error is the domain error that is returned from the service and repository layers.
+// It carries a stable, machine-readable code and the http status that the api responds with.
+// Errors with the same code are considered equal by errors.Is, so the variables below can be
+// compared against errors that carry additional details or a wrapped cause.
+type Error struct {
+	Code    	string		`json:"code"`
+	Message 	string		`json:"message"`
+	Details 	interface{}	`json:"details,omitempty"`
+	Status  	int			`json:"-"`
+	cause   	error
+}
+
+// FieldError describes a single field of a request body that failed validation
+type FieldError struct {
+	Field   	string	`json:"field"`
+	Rule    	string	`json:"rule"`
+	Message 	string	`json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails returns a copy of the error with the given details
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Wrap returns a copy of the error that wraps the given cause
+func (e *Error) Wrap(cause error) *Error {
+	copied := *e
+	copied.cause = cause
+	return &copied
+}
+
+// NewBadRequestError creates an error for requests that cannot be parsed
+func NewBadRequestError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusBadRequest}
+}
+
+// NewNotFoundError creates an error for resources that do not exist
+func NewNotFoundError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusNotFound}
+}
+
+// NewConflictError creates an error for requests that conflict with the current state of a resource
+func NewConflictError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusConflict}
+}
+
+// NewValidationError creates an error for well-formed requests with invalid content
+func NewValidationError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusUnprocessableEntity}
+}
+
+// NewPreconditionFailedError creates an error for conditional requests whose precondition does not hold
+func NewPreconditionFailedError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusPreconditionFailed}
+}
+
+// NewPreconditionRequiredError creates an error for requests that must be conditional
+func NewPreconditionRequiredError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusPreconditionRequired}
+}
+
+// NewForbiddenError creates an error for requests whose credentials are not valid for the resource
+func NewForbiddenError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusForbidden}
+}
+
+// NewPayloadTooLargeError creates an error for requests whose body is larger than allowed
+func NewPayloadTooLargeError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusRequestEntityTooLarge}
+}
+
+// NewUnsupportedMediaTypeError creates an error for requests whose content type is not supported
+func NewUnsupportedMediaTypeError(code string, message string) *Error {
+	return &Error{Code: code, Message: message, Status: http.StatusUnsupportedMediaType}
+}
+
+// NewInternalError wraps an unexpected error, its message is never exposed to the clients
+func NewInternalError(cause error) *Error {
+	return ErrInternal.Wrap(cause)
+}
+
 var (
-	ErrAssigneeDoesNotExist   = errors.New("assignee does not exist")
-	ErrCandidateDoesNotExist  = errors.New("candidate does not exist")
-	ErrCandidateAlreadyExists = errors.New("candidate already exist")
-	ErrMeetingCountNotEnough  = errors.New("candidates cannot be accepted before the completion of 4 meetings")
-	ErrArrangedMeetingDoesNotExist  = errors.New("current candidate does not have any arranged meetings")
-	ErrDepartmentDoesNotExist  = errors.New("department does not exist")
+	ErrAssigneeDoesNotExist   = NewNotFoundError("assignee_not_found", "assignee does not exist")
+	ErrCandidateDoesNotExist  = NewNotFoundError("candidate_not_found", "candidate does not exist")
+	ErrMeetingDoesNotExist  = NewNotFoundError("meeting_not_found", "meeting does not exist")
+	ErrAttachmentDoesNotExist  = NewNotFoundError("attachment_not_found", "attachment does not exist")
+	ErrWebhookDoesNotExist  = NewNotFoundError("webhook_not_found", "webhook does not exist")
+	ErrAttachmentTooLarge  = NewPayloadTooLargeError("attachment_too_large", "attachment is larger than allowed")
+	ErrAttachmentTypeNotSupported  = NewUnsupportedMediaTypeError("attachment_type_not_supported", "content type of the attachment is not supported")
+	ErrCandidateAlreadyExists = NewConflictError("candidate_already_exists", "candidate already exist")
+	ErrMeetingCountNotEnough  = NewConflictError("meeting_count_not_enough", "candidates cannot be accepted before the completion of 4 meetings")
+	ErrArrangedMeetingDoesNotExist  = NewConflictError("arranged_meeting_not_found", "current candidate does not have any arranged meetings")
+	ErrMeetingAlreadyArranged  = NewConflictError("meeting_already_arranged", "current candidate already has an arranged meeting, reschedule it instead")
+	ErrMeetingAlreadyStarted  = NewConflictError("meeting_already_started", "arranged meeting has already started")
+	ErrMeetingNotStarted  = NewConflictError("meeting_not_started", "arranged meeting has not started yet")
+	ErrMeetingNotCompleted  = NewConflictError("meeting_not_completed", "feedback can only be given to completed meetings")
+	ErrCandidateVersionConflict  = NewConflictError("candidate_version_conflict", "candidate was modified by another request")
+	ErrCandidateModified  = NewPreconditionFailedError("precondition_failed", "candidate was modified since it was read")
+	ErrIfMatchRequired  = NewPreconditionRequiredError("precondition_required", "If-Match header is required to update a candidate")
+	ErrDepartmentDoesNotExist  = NewValidationError("department_not_found", "department does not exist")
+	ErrWebhookEventDoesNotExist  = NewValidationError("webhook_event_not_found", "webhook event does not exist")
+	ErrValidationFailed  = NewValidationError("validation_failed", "request body is not valid")
+	ErrMeetingTimeInPast  = NewValidationError("meeting_time_in_past", "meeting time must be in the future")
+	ErrCalendarTokenInvalid  = NewForbiddenError("calendar_token_invalid", "calendar token is not valid")
+	ErrMalformedRequest  = NewBadRequestError("malformed_request", "request body cannot be parsed")
+	ErrInternal  = &Error{Code: "internal_error", Message: "internal server error", Status: http.StatusInternalServerError}
 )
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// simulates enumeration for the domain events that the candidates are not notified of
+const (
+	CandidateCreatedEvent = "CandidateCreated"
+	CandidateUpdatedEvent = "CandidateUpdated"
+	CandidateDeletedEvent = "CandidateDeleted"
+	CandidateExpiredEvent = "CandidateExpired"
+	MeetingCompletedEvent = "MeetingCompleted"
+	MeetingNoShowEvent = "MeetingNoShow"
+	AssigneeCreatedEvent = "AssigneeCreated"
+)
+
+// StreamResetEvent starts a stream that cannot be resumed from the last event of the client, since the event is not kept anymore.
+// The client should reload the candidates, instead of applying the events to them.
+const StreamResetEvent = "StreamReset"
+
+// GetStreamEventsAsArray returns the events that are streamed to the clients, the changes of the statuses of the candidates
+// and the arrangements and completions of their meetings
+func GetStreamEventsAsArray() []string {
+	return []string { CandidateCreatedEvent, CandidateDeniedEvent, CandidateAcceptedEvent, CandidateExpiredEvent,
+		MeetingArrangedEvent, MeetingCompletedEvent }
+}
+
+// simulates enumeration for the statuses of the events in the outbox
+const (
+	EventPending = "Pending"
+	EventHandled = "Handled"
+	EventFailed = "Failed"
+)
+
+// DomainEvent is a change of a candidate, a meeting or an assignee that is published by the services.
+// Candidate is set for the candidate and the meeting events, Meeting is only set for the meeting events
+// that change a meeting record, and Assignee is only set for the assignee events.
+type DomainEvent struct {
+	ID				string			`json:"id" bson:"id"`
+	Type			string			`json:"type" bson:"type"`
+	OccurredAt		time.Time		`json:"occurred_at" bson:"occurred_at"`
+	Candidate		*Candidate		`json:"candidate,omitempty" bson:"candidate,omitempty"`
+	Meeting			*MeetingRecord	`json:"meeting,omitempty" bson:"meeting,omitempty"`
+	Assignee		*Assignee		`json:"assignee,omitempty" bson:"assignee,omitempty"`
+}
+
+// EventHandler handles a published event. The events are delivered at least once, so the handlers
+// should tolerate the same event more than once, e.g. by its ID.
+type EventHandler func(ctx context.Context, event DomainEvent) error
+
+// EventPublisher publishes the events of the services. The ID and the time of the event are set if they are empty.
+// It must not block on the handling of the event.
+type EventPublisher interface {
+	Publish(ctx context.Context, event DomainEvent) error
+}
+
+// EventBus is an EventPublisher that the subsystems subscribe to. A subscription handles the events of the given types,
+// or all of the events if no type is given. Its name identifies the subscription, and must be unique.
+type EventBus interface {
+	EventPublisher
+	Subscribe(name string, handler EventHandler, types ...string)
+}
+
+// TransactionRunner runs a change of the repositories and the events that it publishes atomically. The repositories and
+// the publishers that are called with the context of fn take part in the transaction, so the change is not saved if its
+// events cannot be stored, and the events are not stored if the change is rolled back. fn may run more than once.
+type TransactionRunner interface {
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// EventFilter selects the streamed events of the candidates in the Department, and of the candidates and the meetings
+// of the assignee with AssigneeID. The empty fields do not filter the events.
+type EventFilter struct {
+	Department		string
+	AssigneeID		string
+}
+
+// EventStream streams the events to the clients as they are published.
+// Subscribe returns the channel of the events that match the filter, starting with the kept events after lastEventID,
+// or with a StreamResetEvent if lastEventID is not kept anymore. The channel is closed when the client falls behind
+// the events, or when the returned function unsubscribes it.
+type EventStream interface {
+	Subscribe(filter EventFilter, lastEventID string) (<-chan DomainEvent, func())
+}
+
+// OutboxEvent model is a published event waiting to be handled by the subscriptions, or the record of a handled event
+// It is persisted in the DB in Events collection
+type OutboxEvent struct {
+	ID				string			`json:"id" bson:"_id,omitempty"`
+	Event			DomainEvent		`json:"event" bson:"event"`
+	Status			string			`json:"status" bson:"status"`
+	Attempts		int				`json:"attempts" bson:"attempts"`
+	NextAttemptAt	time.Time		`json:"next_attempt_at" bson:"next_attempt_at"`
+	// HandledBy are the names of the subscriptions that have handled the event, they are skipped when the event is retried
+	HandledBy		[]string		`json:"handled_by" bson:"handled_by"`
+	LastError		string			`json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt		time.Time		`json:"created_at" bson:"created_at"`
+}
+
+// EventRepository is the outbox of the events.
+// ClaimEvent takes the pending event whose next attempt is due, counts the attempt and postpones its next attempt
+// by the given lease, so it is not taken by another dispatcher while it is being handled.
+// It returns false if there is not any due event.
+type EventRepository interface {
+	CreateEvent(ctx context.Context, event OutboxEvent) (OutboxEvent, error)
+	ClaimEvent(ctx context.Context, now time.Time, lease time.Duration) (OutboxEvent, bool, error)
+	UpdateEvent(ctx context.Context, event OutboxEvent) error
+}
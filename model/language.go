@@ -0,0 +1,11 @@
+package model
+
+// simulates enumeration for the languages that the candidates are notified in
+const (
+	English = "en"
+	Turkish = "tr"
+)
+
+func GetLanguagesAsArray() []string {
+	return []string { English, Turkish }
+}
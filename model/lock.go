@@ -0,0 +1,22 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// Lock model is the lease of a lock that elects a leader among the replicas of the application
+// It is persisted in the DB in Locks collection
+type Lock struct {
+	Name			string		`json:"name" bson:"_id"`
+	Owner			string		`json:"owner" bson:"owner"`
+	ExpiresAt		time.Time	`json:"expires_at" bson:"expires_at"`
+}
+
+// LockRepository keeps the locks. AcquireLock takes the lock for the owner until the given time, if the lock is free,
+// its lease has expired, or it is already held by the owner, in which case its lease is extended.
+// It returns false if another owner holds the lock. ReleaseLock frees the lock, if it is held by the owner.
+type LockRepository interface {
+	AcquireLock(ctx context.Context, name string, owner string, now time.Time, until time.Time) (bool, error)
+	ReleaseLock(ctx context.Context, name string, owner string) error
+}
@@ -4,9 +4,84 @@ import (
 	"time"
 )
 
+// simulates enumeration for the status of the meeting records
+const (
+	MeetingArranged = "Arranged"
+	MeetingCompleted = "Completed"
+	MeetingCancelled = "Cancelled"
+	MeetingNoShow = "NoShow"
+)
+
+// simulates enumeration for the changes of the arranged meetings,
+// the cancellations and the no-shows are recorded as MeetingCancelled and MeetingNoShow
+const (
+	MeetingRescheduled = "Rescheduled"
+)
+
+// MeetingDuration is the expected length of the meetings, the meetings are only arranged with their start time
+const MeetingDuration = time.Hour
+
 // Meeting model is used to exchange meeting metadata while arranging and completing meetings
 // It is not persisted in the DB
 type Meeting struct {
 	CandidateID 	string 		`json:"candidate_id" validate:"required"`
 	NextMeetingTime *time.Time	`json:"next_meeting_time" validate:"required"`
 }
+
+// MeetingReschedule model is used to move an arranged meeting to another time
+// It is not persisted in the DB
+type MeetingReschedule struct {
+	NextMeetingTime *time.Time	`json:"next_meeting_time" validate:"required"`
+}
+
+// MeetingCancellation model is used to cancel an arranged meeting
+// It is not persisted in the DB
+type MeetingCancellation struct {
+	Reason			string		`json:"reason" validate:"required,max=500"`
+}
+
+// MeetingRecord model is used to keep the history of the meetings with the candidates
+// It is persisted in the DB in Meetings collection
+// Sequence is the revision of the calendar event of the meeting, it is incremented each time the meeting is changed.
+// Changes is the history of the reschedules of the meeting, and its cancellation or no-show, in the order they are made.
+// ReminderSentAt is the time that the candidate is reminded of the meeting, it is cleared when the meeting is rescheduled.
+// Overdue is set for the arranged meetings that have ended without being completed.
+// FeedbackScore is the score from 1 to 5 that the assignee has given to the candidate after completing the meeting.
+type MeetingRecord struct {
+	ID				string		`json:"id" bson:"_id,omitempty"`
+	CandidateID		string		`json:"candidate_id" bson:"candidate_id"`
+	AssigneeID		string		`json:"assignee_id" bson:"assignee_id"`
+	Time			time.Time	`json:"time" bson:"time"`
+	Status			string		`json:"status" bson:"status"`
+	ArrangedAt		time.Time	`json:"arranged_at" bson:"arranged_at"`
+	CompletedAt		*time.Time	`json:"completed_at" bson:"completed_at"`
+	Sequence		int			`json:"sequence" bson:"sequence"`
+	Changes			[]MeetingChange	`json:"changes,omitempty" bson:"changes,omitempty"`
+	ReminderSentAt	*time.Time	`json:"reminder_sent_at,omitempty" bson:"reminder_sent_at,omitempty"`
+	Overdue			bool		`json:"overdue" bson:"overdue,omitempty"`
+	FeedbackScore	*int		`json:"feedback_score,omitempty" bson:"feedback_score,omitempty"`
+}
+
+// MeetingChange is a reschedule, the cancellation or the no-show of an arranged meeting
+// Time is the time that the meeting is moved to, and it is only set for the reschedules.
+type MeetingChange struct {
+	Type			string		`json:"type" bson:"type"`
+	At				time.Time	`json:"at" bson:"at"`
+	PreviousTime	time.Time	`json:"previous_time" bson:"previous_time"`
+	Time			*time.Time	`json:"time,omitempty" bson:"time,omitempty"`
+	Reason			string		`json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// MeetingFeedback model is used to score the candidate after a completed meeting
+// It is not persisted in the DB
+type MeetingFeedback struct {
+	Score			int			`json:"score" validate:"required,min=1,max=5"`
+}
+
+// MeetingDetails contains a meeting record along with the candidate and the assignee of the meeting
+// It is not persisted in the DB
+type MeetingDetails struct {
+	Meeting			MeetingRecord	`json:"meeting"`
+	Candidate		Candidate		`json:"candidate"`
+	Assignee		Assignee		`json:"assignee"`
+}
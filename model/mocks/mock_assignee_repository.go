@@ -139,3 +139,29 @@ func (a *AssigneeRepository) FindOneAssigneeByDepartment(ctx context.Context, de
 
 	return r0, r1
 }
+
+func (a *AssigneeRepository) SetCalendarTokenHash(ctx context.Context, id string, tokenHash string) error {
+	ret := a.Called(ctx, id, tokenHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, tokenHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (a *AssigneeRepository) DeleteAllAssignees(ctx context.Context) error {
+	ret := a.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -74,7 +74,7 @@ func (a *AssigneeService) FindAllAssigneesByDepartment(ctx context.Context, depa
 	return r0, r1
 }
 
-func (a *AssigneeService) FindAssigneeIDByName(ctx context.Context, name string) string {
+func (a *AssigneeService) FindAssigneeIDByName(ctx context.Context, name string) (string, error) {
 	ret := a.Called(ctx, name)
 
 	var r0 string
@@ -86,5 +86,34 @@ func (a *AssigneeService) FindAssigneeIDByName(ctx context.Context, name string)
 		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (a *AssigneeService) CreateCalendarToken(ctx context.Context, id string) (string, error) {
+	ret := a.Called(ctx, id)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
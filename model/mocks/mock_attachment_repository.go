@@ -0,0 +1,110 @@
+package mocks
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type AttachmentRepository struct {
+	mock.Mock
+}
+
+func (a *AttachmentRepository) CreateAttachment(ctx context.Context, attachment model.Attachment) (model.Attachment, error) {
+	ret := a.Called(ctx, attachment)
+
+	var r0 model.Attachment
+	if rf, ok := ret.Get(0).(func(context.Context, model.Attachment) model.Attachment); ok {
+		r0 = rf(ctx, attachment)
+	} else {
+		r0 = ret.Get(0).(model.Attachment)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.Attachment) error); ok {
+		r1 = rf(ctx, attachment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (a *AttachmentRepository) ReadAttachment(ctx context.Context, candidateID string, id string) (model.Attachment, error) {
+	ret := a.Called(ctx, candidateID, id)
+
+	var r0 model.Attachment
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) model.Attachment); ok {
+		r0 = rf(ctx, candidateID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Attachment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, candidateID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (a *AttachmentRepository) FindCandidatesAttachments(ctx context.Context, candidateID string) ([]model.Attachment, error) {
+	ret := a.Called(ctx, candidateID)
+
+	var r0 []model.Attachment
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.Attachment); ok {
+		r0 = rf(ctx, candidateID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Attachment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, candidateID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (a *AttachmentRepository) DeleteAttachment(ctx context.Context, candidateID string, id string) error {
+	ret := a.Called(ctx, candidateID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, candidateID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (a *AttachmentRepository) FindCandidateIDsByText(ctx context.Context, word string) ([]string, error) {
+	ret := a.Called(ctx, word)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, word)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, word)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,118 @@
+package mocks
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/mock"
+	"io"
+)
+
+type AttachmentService struct {
+	mock.Mock
+}
+
+func (a *AttachmentService) UploadAttachment(ctx context.Context, attachment model.Attachment, content io.Reader) (model.Attachment, error) {
+	ret := a.Called(ctx, attachment, content)
+
+	var r0 model.Attachment
+	if rf, ok := ret.Get(0).(func(context.Context, model.Attachment, io.Reader) model.Attachment); ok {
+		r0 = rf(ctx, attachment, content)
+	} else {
+		r0 = ret.Get(0).(model.Attachment)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.Attachment, io.Reader) error); ok {
+		r1 = rf(ctx, attachment, content)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (a *AttachmentService) FindCandidatesAttachments(ctx context.Context, candidateID string) ([]model.Attachment, error) {
+	ret := a.Called(ctx, candidateID)
+
+	var r0 []model.Attachment
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.Attachment); ok {
+		r0 = rf(ctx, candidateID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Attachment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, candidateID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (a *AttachmentService) DownloadAttachment(ctx context.Context, candidateID string, id string) (model.Attachment, io.ReadCloser, error) {
+	ret := a.Called(ctx, candidateID, id)
+
+	var r0 model.Attachment
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(model.Attachment)
+	}
+
+	var r1 io.ReadCloser
+	if ret.Get(1) != nil {
+		r1 = ret.Get(1).(io.ReadCloser)
+	}
+
+	return r0, r1, ret.Error(2)
+}
+
+func (a *AttachmentService) DeleteAttachment(ctx context.Context, candidateID string, id string) error {
+	ret := a.Called(ctx, candidateID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, candidateID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (a *AttachmentService) DeleteCandidatesAttachments(ctx context.Context, candidateID string) error {
+	ret := a.Called(ctx, candidateID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, candidateID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (a *AttachmentService) FindCandidateIDsByText(ctx context.Context, word string) ([]string, error) {
+	ret := a.Called(ctx, word)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, word)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, word)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
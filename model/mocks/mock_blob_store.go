@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"io"
+)
+
+type BlobStore struct {
+	mock.Mock
+}
+
+func (b *BlobStore) Put(ctx context.Context, key string, content io.Reader) (int64, error) {
+	ret := b.Called(ctx, key, content)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader) int64); ok {
+		r0 = rf(ctx, key, content)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, io.Reader) error); ok {
+		r1 = rf(ctx, key, content)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (b *BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	ret := b.Called(ctx, key)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(context.Context, string) io.ReadCloser); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (b *BlobStore) Delete(ctx context.Context, key string) error {
+	ret := b.Called(ctx, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
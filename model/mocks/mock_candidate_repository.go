@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/cemalunal/sample-internship-management-api/model"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type CandidateRepository struct {
@@ -143,3 +144,373 @@ func (c *CandidateRepository) DeleteCandidate(ctx context.Context, id string) er
 
 	return r0
 }
+
+func (c *CandidateRepository) ArrangeMeeting(ctx context.Context, id string, version int64, meeting model.MeetingRecord) (model.Candidate, error) {
+	ret := c.Called(ctx, id, version, meeting)
+
+	var r0 model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, model.MeetingRecord) model.Candidate); ok {
+		r0 = rf(ctx, id, version, meeting)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Candidate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, model.MeetingRecord) error); ok {
+		r1 = rf(ctx, id, version, meeting)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) RescheduleMeeting(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	ret := c.Called(ctx, id, version, change)
+
+	var r0 model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, model.MeetingChange) model.Candidate); ok {
+		r0 = rf(ctx, id, version, change)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Candidate)
+		}
+	}
+
+	var r1 model.MeetingRecord
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, model.MeetingChange) model.MeetingRecord); ok {
+		r1 = rf(ctx, id, version, change)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(model.MeetingRecord)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, int64, model.MeetingChange) error); ok {
+		r2 = rf(ctx, id, version, change)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+func (c *CandidateRepository) CancelMeeting(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	ret := c.Called(ctx, id, version, change)
+
+	var r0 model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, model.MeetingChange) model.Candidate); ok {
+		r0 = rf(ctx, id, version, change)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Candidate)
+		}
+	}
+
+	var r1 model.MeetingRecord
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, model.MeetingChange) model.MeetingRecord); ok {
+		r1 = rf(ctx, id, version, change)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(model.MeetingRecord)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, int64, model.MeetingChange) error); ok {
+		r2 = rf(ctx, id, version, change)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+func (c *CandidateRepository) RecordNoShow(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	ret := c.Called(ctx, id, version, change)
+
+	var r0 model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, model.MeetingChange) model.Candidate); ok {
+		r0 = rf(ctx, id, version, change)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Candidate)
+		}
+	}
+
+	var r1 model.MeetingRecord
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, model.MeetingChange) model.MeetingRecord); ok {
+		r1 = rf(ctx, id, version, change)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(model.MeetingRecord)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, int64, model.MeetingChange) error); ok {
+		r2 = rf(ctx, id, version, change)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+func (c *CandidateRepository) CompleteMeeting(ctx context.Context, id string, completedAt time.Time) (model.Candidate, error) {
+	ret := c.Called(ctx, id, completedAt)
+
+	var r0 model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) model.Candidate); ok {
+		r0 = rf(ctx, id, completedAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Candidate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, id, completedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) ReadMeeting(ctx context.Context, id string) (model.MeetingRecord, error) {
+	ret := c.Called(ctx, id)
+
+	var r0 model.MeetingRecord
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.MeetingRecord); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.MeetingRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) FindCandidatesMeetings(ctx context.Context, candidateID string) ([]model.MeetingRecord, error) {
+	ret := c.Called(ctx, candidateID)
+
+	var r0 []model.MeetingRecord
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.MeetingRecord); ok {
+		r0 = rf(ctx, candidateID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MeetingRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, candidateID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) FindAssigneesMeetings(ctx context.Context, assigneeID string, from time.Time) ([]model.MeetingRecord, error) {
+	ret := c.Called(ctx, assigneeID, from)
+
+	var r0 []model.MeetingRecord
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []model.MeetingRecord); ok {
+		r0 = rf(ctx, assigneeID, from)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MeetingRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, assigneeID, from)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) FindMeetingsToRemind(ctx context.Context, from time.Time, to time.Time) ([]model.MeetingRecord, error) {
+	ret := c.Called(ctx, from, to)
+
+	var r0 []model.MeetingRecord
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []model.MeetingRecord); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MeetingRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) MarkReminderSent(ctx context.Context, meetingID string, sentAt time.Time) (bool, error) {
+	ret := c.Called(ctx, meetingID, sentAt)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, meetingID, sentAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, meetingID, sentAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) RecordMeetingFeedback(ctx context.Context, meetingID string, score int) error {
+	ret := c.Called(ctx, meetingID, score)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = rf(ctx, meetingID, score)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (c *CandidateRepository) FlagOverdueMeetings(ctx context.Context, endedBefore time.Time) (int64, error) {
+	ret := c.Called(ctx, endedBefore)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, endedBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, endedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) FindOverdueMeetings(ctx context.Context) ([]model.MeetingRecord, error) {
+	ret := c.Called(ctx)
+
+	var r0 []model.MeetingRecord
+	if rf, ok := ret.Get(0).(func(context.Context) []model.MeetingRecord); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MeetingRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) FindCandidatesToExpire(ctx context.Context, appliedBefore time.Time) ([]model.Candidate, error) {
+	ret := c.Called(ctx, appliedBefore)
+
+	var r0 []model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []model.Candidate); ok {
+		r0 = rf(ctx, appliedBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Candidate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, appliedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) DeleteAllCandidates(ctx context.Context) error {
+	ret := c.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (c *CandidateRepository) FindCandidates(ctx context.Context, filter model.CandidateFilter) ([]model.Candidate, error) {
+	ret := c.Called(ctx, filter)
+
+	var r0 []model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, model.CandidateFilter) []model.Candidate); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Candidate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.CandidateFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateRepository) StreamCandidates(ctx context.Context, filter model.CandidateFilter, fn func(model.Candidate) error) error {
+	ret := c.Called(ctx, filter, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.CandidateFilter, func(model.Candidate) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -31,17 +31,26 @@ func (c *CandidateService) CreateCandidate(ctx context.Context, candidate model.
 	return r0, r1
 }
 
-func (c *CandidateService) UpdateCandidate(ctx context.Context, id string, candidate model.Candidate) error {
+func (c *CandidateService) UpdateCandidate(ctx context.Context, id string, candidate model.Candidate) (model.Candidate, error) {
 	ret := c.Called(ctx, id, candidate)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, model.Candidate) error); ok {
+	var r0 model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.Candidate) model.Candidate); ok {
 		r0 = rf(ctx, id, candidate)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Candidate)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, model.Candidate) error); ok {
+		r1 = rf(ctx, id, candidate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 func (c *CandidateService) ReadCandidate(ctx context.Context, id string) (model.Candidate, error) {
@@ -172,7 +181,7 @@ func (c *CandidateService) AcceptCandidate(ctx context.Context, id string) error
 }
 
 func (c *CandidateService) ArrangeMeeting(ctx context.Context, id string, nextMeetingTime *time.Time) error {
-	ret := c.Called(ctx, id)
+	ret := c.Called(ctx, id, nextMeetingTime)
 
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time) error); ok {
@@ -184,6 +193,45 @@ func (c *CandidateService) ArrangeMeeting(ctx context.Context, id string, nextMe
 	return r0
 }
 
+func (c *CandidateService) RescheduleMeeting(ctx context.Context, id string, nextMeetingTime *time.Time) error {
+	ret := c.Called(ctx, id, nextMeetingTime)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time) error); ok {
+		r0 = rf(ctx, id, nextMeetingTime)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (c *CandidateService) CancelMeeting(ctx context.Context, id string, reason string) error {
+	ret := c.Called(ctx, id, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (c *CandidateService) RecordNoShow(ctx context.Context, id string) error {
+	ret := c.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 func (c *CandidateService) CompleteMeeting(ctx context.Context, id string) error {
 	ret := c.Called(ctx, id)
 
@@ -196,3 +244,183 @@ func (c *CandidateService) CompleteMeeting(ctx context.Context, id string) error
 
 	return r0
 }
+
+func (c *CandidateService) ReadMeeting(ctx context.Context, id string) (model.MeetingDetails, error) {
+	ret := c.Called(ctx, id)
+
+	var r0 model.MeetingDetails
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.MeetingDetails); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.MeetingDetails)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateService) FindCandidatesMeetings(ctx context.Context, id string) ([]model.MeetingRecord, error) {
+	ret := c.Called(ctx, id)
+
+	var r0 []model.MeetingRecord
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.MeetingRecord); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MeetingRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateService) RecordMeetingFeedback(ctx context.Context, meetingID string, score int) error {
+	ret := c.Called(ctx, meetingID, score)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = rf(ctx, meetingID, score)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (c *CandidateService) FindOverdueMeetings(ctx context.Context) ([]model.MeetingDetails, error) {
+	ret := c.Called(ctx)
+
+	var r0 []model.MeetingDetails
+	if rf, ok := ret.Get(0).(func(context.Context) []model.MeetingDetails); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MeetingDetails)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateService) FindAssigneesCalendar(ctx context.Context, assigneeID string, token string) (model.Assignee, []model.MeetingDetails, error) {
+	ret := c.Called(ctx, assigneeID, token)
+
+	var r0 model.Assignee
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) model.Assignee); ok {
+		r0 = rf(ctx, assigneeID, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Assignee)
+		}
+	}
+
+	var r1 []model.MeetingDetails
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) []model.MeetingDetails); ok {
+		r1 = rf(ctx, assigneeID, token)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]model.MeetingDetails)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, assigneeID, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+func (c *CandidateService) ImportCandidates(ctx context.Context, candidates []model.Candidate, dryRun bool) []model.CandidateImportResult {
+	ret := c.Called(ctx, candidates, dryRun)
+
+	var r0 []model.CandidateImportResult
+	if rf, ok := ret.Get(0).(func(context.Context, []model.Candidate, bool) []model.CandidateImportResult); ok {
+		r0 = rf(ctx, candidates, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.CandidateImportResult)
+		}
+	}
+
+	return r0
+}
+
+func (c *CandidateService) FindCandidates(ctx context.Context, filter model.CandidateFilter) ([]model.Candidate, error) {
+	ret := c.Called(ctx, filter)
+
+	var r0 []model.Candidate
+	if rf, ok := ret.Get(0).(func(context.Context, model.CandidateFilter) []model.Candidate); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Candidate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.CandidateFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (c *CandidateService) ExportCandidates(ctx context.Context, filter model.CandidateFilter, fn func(model.ExportedCandidate) error) error {
+	ret := c.Called(ctx, filter, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.CandidateFilter, func(model.ExportedCandidate) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (c *CandidateService) ExpirePendingCandidates(ctx context.Context, appliedBefore time.Time) (int, error) {
+	ret := c.Called(ctx, appliedBefore)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, appliedBefore)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, appliedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type EventPublisher struct {
+	mock.Mock
+}
+
+func (e *EventPublisher) Publish(ctx context.Context, event model.DomainEvent) error {
+	ret := e.Called(ctx, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DomainEvent) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
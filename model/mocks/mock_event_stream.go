@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type EventStream struct {
+	mock.Mock
+}
+
+func (e *EventStream) Subscribe(filter model.EventFilter, lastEventID string) (<-chan model.DomainEvent, func()) {
+	ret := e.Called(filter, lastEventID)
+
+	var r0 <-chan model.DomainEvent
+	if rf, ok := ret.Get(0).(func(model.EventFilter, string) <-chan model.DomainEvent); ok {
+		r0 = rf(filter, lastEventID)
+	} else if ret.Get(0) != nil {
+		r0 = ret.Get(0).(<-chan model.DomainEvent)
+	}
+
+	var r1 func()
+	if rf, ok := ret.Get(1).(func(model.EventFilter, string) func()); ok {
+		r1 = rf(filter, lastEventID)
+	} else if ret.Get(1) != nil {
+		r1 = ret.Get(1).(func())
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type ReportRepository struct {
+	mock.Mock
+}
+
+func (r *ReportRepository) FunnelReport(ctx context.Context) (model.FunnelReport, error) {
+	ret := r.Called(ctx)
+
+	var r0 model.FunnelReport
+	if rf, ok := ret.Get(0).(func(context.Context) model.FunnelReport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.FunnelReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (r *ReportRepository) TimeToHireReport(ctx context.Context) (model.TimeToHireReport, error) {
+	ret := r.Called(ctx)
+
+	var r0 model.TimeToHireReport
+	if rf, ok := ret.Get(0).(func(context.Context) model.TimeToHireReport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.TimeToHireReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (r *ReportRepository) AssigneeWorkloads(ctx context.Context, filter model.WorkloadFilter, now time.Time) ([]model.AssigneeWorkload, error) {
+	ret := r.Called(ctx, filter, now)
+
+	var r0 []model.AssigneeWorkload
+	if rf, ok := ret.Get(0).(func(context.Context, model.WorkloadFilter, time.Time) []model.AssigneeWorkload); ok {
+		r0 = rf(ctx, filter, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AssigneeWorkload)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.WorkloadFilter, time.Time) error); ok {
+		r1 = rf(ctx, filter, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,77 @@
+package mocks
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type ReportService struct {
+	mock.Mock
+}
+
+func (r *ReportService) FunnelReport(ctx context.Context) (model.FunnelReport, error) {
+	ret := r.Called(ctx)
+
+	var r0 model.FunnelReport
+	if rf, ok := ret.Get(0).(func(context.Context) model.FunnelReport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.FunnelReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (r *ReportService) TimeToHireReport(ctx context.Context) (model.TimeToHireReport, error) {
+	ret := r.Called(ctx)
+
+	var r0 model.TimeToHireReport
+	if rf, ok := ret.Get(0).(func(context.Context) model.TimeToHireReport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.TimeToHireReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (r *ReportService) AssigneeWorkloadReport(ctx context.Context, filter model.WorkloadFilter) (model.AssigneeWorkloadReport, error) {
+	ret := r.Called(ctx, filter)
+
+	var r0 model.AssigneeWorkloadReport
+	if rf, ok := ret.Get(0).(func(context.Context, model.WorkloadFilter) model.AssigneeWorkloadReport); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.AssigneeWorkloadReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.WorkloadFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
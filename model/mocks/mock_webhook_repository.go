@@ -0,0 +1,212 @@
+package mocks
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type WebhookRepository struct {
+	mock.Mock
+}
+
+func (w *WebhookRepository) CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	ret := w.Called(ctx, webhook)
+
+	var r0 model.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context, model.Webhook) model.Webhook); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.Webhook) error); ok {
+		r1 = rf(ctx, webhook)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookRepository) ReadWebhook(ctx context.Context, id string) (model.Webhook, error) {
+	ret := w.Called(ctx, id)
+
+	var r0 model.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.Webhook); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookRepository) FindAllWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ret := w.Called(ctx)
+
+	var r0 []model.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Webhook); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookRepository) FindWebhooksByEvent(ctx context.Context, event string) ([]model.Webhook, error) {
+	ret := w.Called(ctx, event)
+
+	var r0 []model.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.Webhook); ok {
+		r0 = rf(ctx, event)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, event)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookRepository) UpdateWebhook(ctx context.Context, webhook model.Webhook) error {
+	ret := w.Called(ctx, webhook)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Webhook) error); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (w *WebhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+	ret := w.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (w *WebhookRepository) CreateDelivery(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	ret := w.Called(ctx, delivery)
+
+	var r0 model.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, model.WebhookDelivery) model.WebhookDelivery); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.WebhookDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.WebhookDelivery) error); ok {
+		r1 = rf(ctx, delivery)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookRepository) ClaimDelivery(ctx context.Context, now time.Time, lease time.Duration) (model.WebhookDelivery, bool, error) {
+	ret := w.Called(ctx, now, lease)
+
+	var r0 model.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Duration) model.WebhookDelivery); ok {
+		r0 = rf(ctx, now, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.WebhookDelivery)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Duration) bool); ok {
+		r1 = rf(ctx, now, lease)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, time.Time, time.Duration) error); ok {
+		r2 = rf(ctx, now, lease)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+func (w *WebhookRepository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	ret := w.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (w *WebhookRepository) FindWebhooksDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	ret := w.Called(ctx, webhookID, limit)
+
+	var r0 []model.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []model.WebhookDelivery); ok {
+		r0 = rf(ctx, webhookID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.WebhookDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, webhookID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
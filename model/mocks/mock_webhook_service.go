@@ -0,0 +1,134 @@
+package mocks
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookService struct {
+	mock.Mock
+}
+
+func (w *WebhookService) CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	ret := w.Called(ctx, webhook)
+
+	var r0 model.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context, model.Webhook) model.Webhook); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.Webhook) error); ok {
+		r1 = rf(ctx, webhook)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookService) ReadWebhook(ctx context.Context, id string) (model.Webhook, error) {
+	ret := w.Called(ctx, id)
+
+	var r0 model.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.Webhook); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookService) FindAllWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ret := w.Called(ctx)
+
+	var r0 []model.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Webhook); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookService) UpdateWebhook(ctx context.Context, id string, webhook model.Webhook) (model.Webhook, error) {
+	ret := w.Called(ctx, id, webhook)
+
+	var r0 model.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.Webhook) model.Webhook); ok {
+		r0 = rf(ctx, id, webhook)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, model.Webhook) error); ok {
+		r1 = rf(ctx, id, webhook)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (w *WebhookService) DeleteWebhook(ctx context.Context, id string) error {
+	ret := w.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (w *WebhookService) FindWebhooksDeliveries(ctx context.Context, id string) ([]model.WebhookDelivery, error) {
+	ret := w.Called(ctx, id)
+
+	var r0 []model.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.WebhookDelivery); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.WebhookDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// simulates enumeration for the events that the candidates are notified of
+const (
+	MeetingArrangedEvent = "MeetingArranged"
+	MeetingRescheduledEvent = "MeetingRescheduled"
+	MeetingCancelledEvent = "MeetingCancelled"
+	MeetingReminderEvent = "MeetingReminder"
+	CandidateDeniedEvent = "CandidateDenied"
+	CandidateAcceptedEvent = "CandidateAccepted"
+)
+
+// GetNotificationEventsAsArray returns the published events that the candidates are notified of,
+// the reminders are not published but scheduled
+func GetNotificationEventsAsArray() []string {
+	return []string { MeetingArrangedEvent, MeetingRescheduledEvent, MeetingCancelledEvent, CandidateDeniedEvent,
+		CandidateAcceptedEvent }
+}
+
+// simulates enumeration for the delivery statuses of the notifications
+const (
+	NotificationPending = "Pending"
+	NotificationSent = "Sent"
+	NotificationFailed = "Failed"
+)
+
+// Email is a message with a plain text body and an alternative HTML body
+type Email struct {
+	MessageID		string
+	To				string
+	Subject			string
+	TextBody		string
+	HTMLBody		string
+	Attachments		[]EmailAttachment
+}
+
+// EmailAttachment is a file that is attached to an email, such as the calendar invitation of a meeting
+type EmailAttachment struct {
+	FileName		string
+	ContentType		string
+	Content			[]byte
+}
+
+// Mailer delivers the emails
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// CandidateEvent is a change of a candidate that the candidate is notified of.
+// Meeting is only set for the meeting events.
+type CandidateEvent struct {
+	Event			string
+	Candidate		Candidate
+	Meeting			*MeetingRecord
+}
+
+// Notifier notifies the candidates of the events. It must not block on the delivery of the notifications.
+type Notifier interface {
+	Notify(ctx context.Context, event CandidateEvent) error
+}
+
+// Notification model is an email waiting in the outbox to be sent, or the record of a sent email
+// It is persisted in the DB in Notifications collection
+type Notification struct {
+	ID				string		`json:"id" bson:"_id,omitempty"`
+	Event			string		`json:"event" bson:"event"`
+	CandidateID		string		`json:"candidate_id" bson:"candidate_id"`
+	Language		string		`json:"language" bson:"language"`
+	Email			Email		`json:"email" bson:"email"`
+	Status			string		`json:"status" bson:"status"`
+	Attempts		int			`json:"attempts" bson:"attempts"`
+	NextAttemptAt	time.Time	`json:"next_attempt_at" bson:"next_attempt_at"`
+	LastError		string		`json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt		time.Time	`json:"created_at" bson:"created_at"`
+	SentAt			*time.Time	`json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+}
+
+// NotificationRepository is the outbox of the notifications.
+// ClaimNotification takes the pending notification whose next attempt is due, counts the attempt and postpones
+// its next attempt by the given lease, so it is not taken by another dispatcher while it is being sent.
+// It returns false if there is not any due notification.
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, notification Notification) (Notification, error)
+	ClaimNotification(ctx context.Context, now time.Time, lease time.Duration) (Notification, bool, error)
+	UpdateNotification(ctx context.Context, notification Notification) error
+}
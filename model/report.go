@@ -0,0 +1,90 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// FunnelCounts are the numbers of the candidates by their status, and by the number of the meetings they have completed.
+// Statuses has all of the statuses, and Meetings has the numbers of the candidates with 0 to 4 completed meetings in order.
+type FunnelCounts struct {
+	Candidates		int				`json:"candidates"`
+	Statuses		map[string]int	`json:"statuses"`
+	Meetings		[]int			`json:"meetings"`
+}
+
+// FunnelGroup is the funnel of the candidates of a department or a university
+type FunnelGroup struct {
+	Name			string			`json:"name"`
+	FunnelCounts
+}
+
+// FunnelReport is the funnel of all of the candidates, along with the funnels of each department and university
+// ordered by their names
+type FunnelReport struct {
+	FunnelCounts
+	Departments		[]FunnelGroup	`json:"departments"`
+	Universities	[]FunnelGroup	`json:"universities"`
+}
+
+// DecisionTimes are the times from the application of the candidates to their acceptance or denial in days.
+// The median and the 90th percentile are the nearest ranks of the times, and they are zero if there are not any candidates.
+type DecisionTimes struct {
+	Candidates		int				`json:"candidates"`
+	MedianDays		float64			`json:"median_days"`
+	P90Days			float64			`json:"p90_days"`
+}
+
+// TimeToHireReport are the decision times of the accepted and the denied candidates, and of both of them as Decided.
+// The candidates that are decided before the decision times are recorded are not counted, they are reported as Undated.
+type TimeToHireReport struct {
+	Decided			DecisionTimes	`json:"decided"`
+	Accepted		DecisionTimes	`json:"accepted"`
+	Denied			DecisionTimes	`json:"denied"`
+	Undated			int				`json:"undated"`
+}
+
+// WorkloadFilter filters the assignees of the workload report by their department, and gives the time range
+// of the completed interviews. From is inclusive and To is exclusive.
+// It is not persisted in the DB
+type WorkloadFilter struct {
+	Department		string
+	From			time.Time
+	To				time.Time
+}
+
+// AssigneeWorkload is the workload of an assignee. UpcomingMeetings are the arranged meetings that have not started yet,
+// CompletedInterviews are the meetings completed in the time range of the report, and OpenCandidates are the pending
+// and in progress candidates of the assignee. AverageFeedbackScore is the average of the feedback scores of the completed
+// interviews, rounded to a tenth, and it is nil if none of them are scored.
+type AssigneeWorkload struct {
+	Assignee				Assignee	`json:"assignee"`
+	UpcomingMeetings		int			`json:"upcoming_meetings"`
+	CompletedInterviews		int			`json:"completed_interviews"`
+	OpenCandidates			int			`json:"open_candidates"`
+	ScoredInterviews		int			`json:"scored_interviews"`
+	AverageFeedbackScore	*float64	`json:"average_feedback_score"`
+}
+
+// AssigneeWorkloadReport is the workload of each assignee, ordered by their upcoming meetings and open candidates
+// from the busiest to the least busy, along with the time range of the completed interviews
+type AssigneeWorkloadReport struct {
+	From			time.Time			`json:"from"`
+	To				time.Time			`json:"to"`
+	Assignees		[]AssigneeWorkload	`json:"assignees"`
+}
+
+// ReportRepository aggregates the candidates into the reports in the database.
+// AssigneeWorkloads aggregates the meetings and the candidates of the assignees that match the filter,
+// the meetings that start after now are upcoming.
+type ReportRepository interface {
+	FunnelReport(ctx context.Context) (FunnelReport, error)
+	TimeToHireReport(ctx context.Context) (TimeToHireReport, error)
+	AssigneeWorkloads(ctx context.Context, filter WorkloadFilter, now time.Time) ([]AssigneeWorkload, error)
+}
+
+type ReportService interface {
+	FunnelReport(ctx context.Context) (FunnelReport, error)
+	TimeToHireReport(ctx context.Context) (TimeToHireReport, error)
+	AssigneeWorkloadReport(ctx context.Context, filter WorkloadFilter) (AssigneeWorkloadReport, error)
+}
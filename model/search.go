@@ -0,0 +1,72 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// wordBoundary matches the start or the end of a word, letters of any language are considered as word characters
+const wordBoundary = `[^\p{L}\p{N}_]`
+
+// SearchTerm is a word that the candidates are searched with.
+// A candidate matches the term if its name, email or university contains the word,
+// or if it is one of AttachedCandidateIDs whose attachments contain the word.
+type SearchTerm struct {
+	Word					string
+	AttachedCandidateIDs	[]string
+}
+
+// NewSearchTerms splits the search into its words, repeated words are searched once
+func NewSearchTerms(search string) []SearchTerm {
+	var terms []SearchTerm
+	for _, word := range strings.Fields(search) {
+		repeated := false
+		for _, term := range terms {
+			repeated = repeated || strings.EqualFold(term.Word, word)
+		}
+		if !repeated {
+			terms = append(terms, SearchTerm{Word: word})
+		}
+	}
+
+	return terms
+}
+
+// WordPattern creates a regular expression that matches the whole word in a text.
+// The pattern does not have flags, so it can be used with MongoDB as well, and it must be matched case-insensitively.
+func WordPattern(word string) string {
+	return `(^|` + wordBoundary + `)` + regexp.QuoteMeta(word) + `($|` + wordBoundary + `)`
+}
+
+// SearchedFields returns the fields of the candidate that the words are searched in
+func SearchedFields(candidate Candidate) []string {
+	return []string{candidate.FirstName, candidate.LastName, candidate.Email, candidate.University}
+}
+
+// Matches checks the candidate matches the term
+func (term SearchTerm) Matches(candidate Candidate) bool {
+	for _, id := range term.AttachedCandidateIDs {
+		if id == candidate.ID {
+			return true
+		}
+	}
+
+	pattern := regexp.MustCompile(`(?i)` + WordPattern(term.Word))
+	for _, field := range SearchedFields(candidate) {
+		if pattern.MatchString(field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAll(terms []SearchTerm, candidate Candidate) bool {
+	for _, term := range terms {
+		if !term.Matches(candidate) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -6,4 +6,10 @@ const (
 	InProgress = "In Progress"
 	Denied = "Denied"
 	Accepted = "Accepted"
+	// Expired is the status of the pending candidates that have not had any meetings for too long
+	Expired = "Expired"
 )
+
+func GetStatusesAsArray() []string {
+	return []string { Pending, InProgress, Denied, Accepted, Expired }
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// GetWebhookEventsAsArray returns the events that the webhooks can be subscribed to
+func GetWebhookEventsAsArray() []string {
+	return []string { CandidateCreatedEvent, CandidateDeniedEvent, CandidateAcceptedEvent, CandidateExpiredEvent,
+		MeetingArrangedEvent, MeetingRescheduledEvent, MeetingCancelledEvent, MeetingCompletedEvent }
+}
+
+// simulates enumeration for the delivery statuses of the webhook deliveries
+const (
+	DeliveryPending = "Pending"
+	DeliveryDelivered = "Delivered"
+	DeliveryFailed = "Failed"
+)
+
+// Webhook model is the subscription of an external system to the events of the candidates
+// It is persisted in the DB in Webhooks collection
+// The deliveries are signed with the Secret, which is only returned when the webhook is created.
+// The URL is posted only if it resolves to a public address, see webhook.newClient.
+type Webhook struct {
+	ID				string		`json:"id" bson:"_id,omitempty"`
+	URL				string		`json:"url" bson:"url" validate:"required,url,startswith=http,max=2048"`
+	Events			[]string	`json:"events" bson:"events" validate:"required,min=1"`
+	// Paused webhooks are not delivered the new events
+	Paused			bool		`json:"paused" bson:"paused"`
+	Secret			string		`json:"secret,omitempty" bson:"secret"`
+	CreatedAt		time.Time	`json:"created_at" bson:"created_at"`
+}
+
+// WebhookDelivery model is an event waiting to be delivered to a webhook, or the record of its delivery
+// It is persisted in the DB in WebhookDeliveries collection
+type WebhookDelivery struct {
+	ID				string		`json:"id" bson:"_id,omitempty"`
+	WebhookID		string		`json:"webhook_id" bson:"webhook_id"`
+	Event			string		`json:"event" bson:"event"`
+	// Payload is the JSON body that is posted to the webhook
+	Payload			string		`json:"payload" bson:"payload"`
+	Status			string		`json:"status" bson:"status"`
+	Attempts		int			`json:"attempts" bson:"attempts"`
+	NextAttemptAt	time.Time	`json:"next_attempt_at" bson:"next_attempt_at"`
+	// ResponseStatus is the http status of the response to the last attempt, it is zero if the request failed
+	ResponseStatus	int			`json:"response_status,omitempty" bson:"response_status,omitempty"`
+	LastError		string		`json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt		time.Time	`json:"created_at" bson:"created_at"`
+	DeliveredAt		*time.Time	`json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+// WebhookRepository persists the webhooks and the log of their deliveries.
+// FindWebhooksByEvent finds the webhooks that are subscribed to the event and are not paused.
+// DeleteWebhook deletes the webhook along with its deliveries.
+// CreateDelivery does not change a delivery that already exists with the same ID, so an event is delivered once.
+// ClaimDelivery takes the pending delivery whose next attempt is due, counts the attempt and postpones its next attempt
+// by the given lease, so it is not taken by another dispatcher while it is being delivered.
+// It returns false if there is not any due delivery.
+// FindWebhooksDeliveries finds the latest deliveries of the webhook up to the given limit, the newest first.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook Webhook) (Webhook, error)
+	ReadWebhook(ctx context.Context, id string) (Webhook, error)
+	FindAllWebhooks(ctx context.Context) ([]Webhook, error)
+	FindWebhooksByEvent(ctx context.Context, event string) ([]Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+	CreateDelivery(ctx context.Context, delivery WebhookDelivery) (WebhookDelivery, error)
+	ClaimDelivery(ctx context.Context, now time.Time, lease time.Duration) (WebhookDelivery, bool, error)
+	UpdateDelivery(ctx context.Context, delivery WebhookDelivery) error
+	FindWebhooksDeliveries(ctx context.Context, webhookID string, limit int) ([]WebhookDelivery, error)
+}
+
+// WebhookService manages the webhooks. The secret of a webhook is generated when it is created,
+// and the other methods do not return it.
+// UpdateWebhook changes the url, the events and the pausing of the webhook.
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, webhook Webhook) (Webhook, error)
+	ReadWebhook(ctx context.Context, id string) (Webhook, error)
+	FindAllWebhooks(ctx context.Context) ([]Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, webhook Webhook) (Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	FindWebhooksDeliveries(ctx context.Context, id string) ([]WebhookDelivery, error)
+}
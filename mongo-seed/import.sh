@@ -1,3 +0,0 @@
-#!/bin/bash
-
-mongorestore --host mongodb:27017 --gzip --archive=/mongo-seed/dump.gz --drop --db Company
@@ -0,0 +1,188 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/repository"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMailer records the emails that it sends, and fails the given number of sends first
+type fakeMailer struct {
+	mutex    sync.Mutex
+	failures int
+	sent     []model.Email
+}
+
+func (mailer *fakeMailer) Send(ctx context.Context, email model.Email) error {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	if mailer.failures > 0 {
+		mailer.failures--
+		return errors.New("connection refused")
+	}
+	mailer.sent = append(mailer.sent, email)
+	return nil
+}
+
+func TestTemplates_Render(t *testing.T) {
+	istanbul, _ := time.LoadLocation("Europe/Istanbul")
+	templates, err := LoadTemplates(model.English, istanbul)
+	assert.NoError(t, err)
+
+	candidate := model.Candidate{FirstName: "Ayşe", Email: "ayse@e.com", Department: model.Design, University: "METU"}
+	meetingTime := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("english", func(t *testing.T) {
+		email, err := templates.Render(model.MeetingArrangedEvent, "", TemplateData{Candidate: candidate, MeetingTime: meetingTime, AssigneeName: "Zafer"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ayse@e.com", email.To)
+		assert.Equal(t, "Your interview for the Design internship", email.Subject)
+		assert.Contains(t, email.TextBody, "Friday, 1 May 2020 15:00 +03")
+		assert.Contains(t, email.TextBody, "You will meet with Zafer.")
+		assert.Contains(t, email.HTMLBody, "<strong>Friday, 1 May 2020 15:00 &#43;03</strong>")
+	})
+
+	t.Run("turkish", func(t *testing.T) {
+		candidate := candidate
+		candidate.Language = model.Turkish
+		email, err := templates.Render(model.CandidateAcceptedEvent, candidate.Language, TemplateData{Candidate: candidate})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Design stajına hoş geldiniz", email.Subject)
+		assert.Contains(t, email.TextBody, "Merhaba Ayşe,")
+	})
+
+	t.Run("rescheduled", func(t *testing.T) {
+		email, err := templates.Render(model.MeetingRescheduledEvent, "", TemplateData{Candidate: candidate,
+			MeetingTime: meetingTime.Add(48 * time.Hour), PreviousMeetingTime: meetingTime})
+
+		assert.NoError(t, err)
+		assert.Contains(t, email.TextBody, "on Friday, 1 May 2020 15:00 +03 is rescheduled to Sunday, 3 May 2020 15:00 +03.")
+	})
+
+	t.Run("reminder", func(t *testing.T) {
+		email, err := templates.Render(model.MeetingReminderEvent, model.Turkish, TemplateData{Candidate: candidate, MeetingTime: meetingTime})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Hatırlatma: Design stajı görüşmeniz", email.Subject)
+		assert.Contains(t, email.TextBody, "01.05.2020 15:00 +03 tarihinde olduğunu hatırlatırız.")
+	})
+
+	t.Run("html-is-escaped", func(t *testing.T) {
+		candidate := candidate
+		candidate.FirstName = "<b>Ayşe</b>"
+		email, err := templates.Render(model.CandidateDeniedEvent, "", TemplateData{Candidate: candidate})
+
+		assert.NoError(t, err)
+		assert.Contains(t, email.TextBody, "Hello <b>Ayşe</b>,")
+		assert.Contains(t, email.HTMLBody, "Hello &lt;b&gt;Ayşe&lt;/b&gt;,")
+	})
+}
+
+func TestOutbox(t *testing.T) {
+	templates, _ := LoadTemplates(model.English, time.UTC)
+	candidate := model.Candidate{ID: "abcd", FirstName: "Ayşe", Email: "ayse@e.com", Department: model.Design}
+
+	newOutbox := func(mailer model.Mailer) (*Outbox, model.NotificationRepository) {
+		notificationRepository := repository.MemoryNotificationRepository()
+		assigneeRepository := repository.MemoryAssigneeRepository()
+		_, _ = assigneeRepository.CreateAssignee(context.TODO(), model.Assignee{ID: "a1", Name: "Zafer", Department: model.Design})
+		outbox := NewOutbox(notificationRepository, assigneeRepository, templates, mailer)
+		return outbox, notificationRepository
+	}
+
+	t.Run("sent", func(t *testing.T) {
+		mailer := &fakeMailer{}
+		outbox, _ := newOutbox(mailer)
+
+		err := outbox.Notify(context.TODO(), model.CandidateEvent{
+			Event:     model.MeetingArrangedEvent,
+			Candidate: candidate,
+			Meeting:   &model.MeetingRecord{ID: "m1", AssigneeID: "a1", Time: time.Now(), Status: model.MeetingArranged},
+		})
+		assert.NoError(t, err)
+		// nothing is sent before the dispatch
+		assert.Empty(t, mailer.sent)
+
+		sent, err := outbox.Dispatch(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Contains(t, mailer.sent[0].TextBody, "You will meet with Zafer.")
+		assert.NotEmpty(t, mailer.sent[0].MessageID)
+		// the calendar invitation of the meeting is attached
+		assert.Len(t, mailer.sent[0].Attachments, 1)
+		assert.Equal(t, "text/calendar; charset=utf-8; method=REQUEST", mailer.sent[0].Attachments[0].ContentType)
+		assert.Contains(t, string(mailer.sent[0].Attachments[0].Content), "UID:m1@internship-management\r\n")
+
+		sent, _ = outbox.Dispatch(context.TODO())
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		mailer := &fakeMailer{}
+		outbox, _ := newOutbox(mailer)
+		meetingTime := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+		change := model.MeetingChange{Type: model.MeetingCancelled, At: time.Now(), PreviousTime: meetingTime, Reason: "The assignee is on leave"}
+
+		err := outbox.Notify(context.TODO(), model.CandidateEvent{
+			Event:     model.MeetingCancelledEvent,
+			Candidate: candidate,
+			Meeting: &model.MeetingRecord{ID: "m1", AssigneeID: "a1", Time: meetingTime, Status: model.MeetingCancelled,
+				Sequence: 1, Changes: []model.MeetingChange{change}},
+		})
+		assert.NoError(t, err)
+
+		_, _ = outbox.Dispatch(context.TODO())
+		assert.Equal(t, "Your interview for the Design internship is cancelled", mailer.sent[0].Subject)
+		assert.Contains(t, mailer.sent[0].TextBody, "Friday, 1 May 2020 12:00 UTC is cancelled.\nReason: The assignee is on leave")
+		// the cancellation replaces the event of the invitation that is sent before
+		assert.Equal(t, "text/calendar; charset=utf-8; method=CANCEL", mailer.sent[0].Attachments[0].ContentType)
+		assert.Contains(t, string(mailer.sent[0].Attachments[0].Content), "UID:m1@internship-management\r\nSEQUENCE:1\r\n")
+	})
+
+	t.Run("retried", func(t *testing.T) {
+		mailer := &fakeMailer{failures: 2}
+		outbox, _ := newOutbox(mailer)
+		now := time.Now()
+		outbox.now = func() time.Time { return now }
+		_ = outbox.Notify(context.TODO(), model.CandidateEvent{Event: model.CandidateDeniedEvent, Candidate: candidate})
+
+		sent, err := outbox.Dispatch(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		// the first retry is delayed by 30 seconds, and the second one by a minute
+		now = now.Add(20 * time.Second)
+		sent, _ = outbox.Dispatch(context.TODO())
+		assert.Equal(t, 0, sent)
+		assert.Len(t, mailer.sent, 0)
+
+		now = now.Add(20 * time.Second)
+		sent, _ = outbox.Dispatch(context.TODO())
+		assert.Equal(t, 0, sent)
+
+		now = now.Add(time.Minute)
+		sent, _ = outbox.Dispatch(context.TODO())
+		assert.Equal(t, 1, sent)
+		assert.Len(t, mailer.sent, 1)
+	})
+
+	t.Run("failed-after-max-attempts", func(t *testing.T) {
+		mailer := &fakeMailer{failures: 100}
+		outbox, notificationRepository := newOutbox(mailer)
+		outbox.MaxAttempts = 1
+		_ = outbox.Notify(context.TODO(), model.CandidateEvent{Event: model.CandidateDeniedEvent, Candidate: candidate})
+
+		_, _ = outbox.Dispatch(context.TODO())
+
+		_, found, _ := notificationRepository.ClaimNotification(context.TODO(), time.Now().Add(time.Hour), 0)
+		assert.False(t, found)
+	})
+}
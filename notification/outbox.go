@@ -0,0 +1,159 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/calendar"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/queue"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"log"
+	"time"
+)
+
+var tracer = otel.Tracer("github.com/cemalunal/sample-internship-management-api/notification")
+
+// Outbox notifies the candidates by email. The emails are rendered and stored in the outbox when the events happen,
+// and they are sent by Run in the background, so a failure of the mailer never fails the change of the candidate.
+// Failed sends are retried with an exponential backoff until MaxAttempts.
+// The emails of the meetings have the calendar invitations of the meetings attached, and Organizer is the
+// email address that the invitations are organized by.
+type Outbox struct {
+	queue.Worker
+	repository         model.NotificationRepository
+	assigneeRepository model.AssigneeRepository
+	templates          *Templates
+	mailer             model.Mailer
+	now                func() time.Time
+
+	Organizer string
+}
+
+// NewOutbox creates an Outbox that sends the notifications through the mailer
+func NewOutbox(repository model.NotificationRepository, assigneeRepository model.AssigneeRepository, templates *Templates, mailer model.Mailer) *Outbox {
+	return &Outbox{
+		Worker:             queue.NewWorker(),
+		repository:         repository,
+		assigneeRepository: assigneeRepository,
+		templates:          templates,
+		mailer:             mailer,
+		now:                time.Now,
+	}
+}
+
+// Notify renders the email of the event and stores it in the outbox
+func (outbox *Outbox) Notify(ctx context.Context, event model.CandidateEvent) error {
+	ctx, span := tracer.Start(ctx, "Outbox.Notify")
+	defer span.End()
+	span.SetAttributes(attribute.String("notification.event", event.Event), attribute.String("candidate.id", event.Candidate.ID))
+
+	now := outbox.now()
+	data := TemplateData{Candidate: event.Candidate}
+	var invite *model.EmailAttachment
+	if event.Meeting != nil {
+		data.MeetingTime = event.Meeting.Time
+		if changes := event.Meeting.Changes; len(changes) > 0 {
+			data.PreviousMeetingTime = changes[len(changes)-1].PreviousTime
+			data.Reason = changes[len(changes)-1].Reason
+		}
+		assignee, err := outbox.assigneeRepository.ReadAssignee(ctx, event.Meeting.AssigneeID)
+		if err != nil && !errors.Is(err, model.ErrAssigneeDoesNotExist) {
+			tracing.RecordError(span, err)
+			return err
+		}
+		data.AssigneeName = assignee.Name
+		invite = outbox.invite(model.MeetingDetails{Meeting: *event.Meeting, Candidate: event.Candidate, Assignee: assignee}, now)
+	}
+
+	email, err := outbox.templates.Render(event.Event, event.Candidate.Language, data)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	if invite != nil {
+		email.Attachments = append(email.Attachments, *invite)
+	}
+
+	id := primitive.NewObjectID().Hex()
+	email.MessageID = id + "@internship-management"
+	_, err = outbox.repository.CreateNotification(ctx, model.Notification{
+		ID:            id,
+		Event:         event.Event,
+		CandidateID:   event.Candidate.ID,
+		Language:      event.Candidate.Language,
+		Email:         email,
+		Status:        model.NotificationPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	// the notification is sent right away, instead of waiting for the next poll
+	outbox.Wake()
+
+	return nil
+}
+
+// invite creates the calendar invitation of the meeting. The invitations of the same meeting have the same UID,
+// so the calendar applications update the event when the meeting is changed.
+func (outbox *Outbox) invite(details model.MeetingDetails, now time.Time) *model.EmailAttachment {
+	method := calendar.Method(details.Meeting)
+	return &model.EmailAttachment{
+		FileName:    "invite.ics",
+		ContentType: calendar.ContentType + "; method=" + method,
+		Content:     calendar.Event(details, method, outbox.Organizer, now),
+	}
+}
+
+// Run sends the due notifications until the context is done. The outbox is polled on each PollInterval,
+// and right after a notification is stored by this process.
+func (outbox *Outbox) Run(ctx context.Context) {
+	outbox.Poll(ctx, "notifications", outbox.Dispatch)
+}
+
+// Dispatch sends the notifications that are due, and returns the number of notifications that are sent
+func (outbox *Outbox) Dispatch(ctx context.Context) (int, error) {
+	return queue.Dispatch(ctx, func(ctx context.Context) (model.Notification, bool, error) {
+		return outbox.repository.ClaimNotification(ctx, outbox.now(), outbox.Lease)
+	}, outbox.send)
+}
+
+// send delivers the claimed notification, and records the outcome of the attempt
+func (outbox *Outbox) send(ctx context.Context, notification model.Notification) bool {
+	ctx, span := tracer.Start(ctx, "Outbox.send")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("notification.id", notification.ID),
+		attribute.String("notification.event", notification.Event),
+		attribute.Int("notification.attempt", notification.Attempts),
+	)
+
+	err := outbox.mailer.Send(ctx, notification.Email)
+	now := outbox.now()
+	if err == nil {
+		notification.Status = model.NotificationSent
+		notification.SentAt = &now
+		notification.LastError = ""
+	} else {
+		log.Printf("Couldn't send notification %s, attempt %d. Error is: %s\n", notification.ID, notification.Attempts, err)
+		tracing.RecordError(span, err)
+		notification.LastError = err.Error()
+		var failed bool
+		notification.NextAttemptAt, failed = outbox.Retry(now, notification.Attempts)
+		if failed {
+			notification.Status = model.NotificationFailed
+		}
+	}
+
+	if err := outbox.repository.UpdateNotification(ctx, notification); err != nil {
+		log.Println("Couldn't record the outcome of notification: ", err)
+	}
+
+	return err == nil
+}
@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// templatesFS contains a directory for each language, with the subject, text and html templates of each event
+// such as en/MeetingArranged.subject.tmpl, en/MeetingArranged.txt.tmpl and en/MeetingArranged.html.tmpl
+//
+//go:embed templates
+var templatesFS embed.FS
+
+// events are the events that the candidates are notified of
+var events = []string{model.MeetingArrangedEvent, model.MeetingRescheduledEvent, model.MeetingCancelledEvent, model.MeetingReminderEvent, model.CandidateDeniedEvent, model.CandidateAcceptedEvent}
+
+// TemplateData is the data that the templates are executed with.
+// MeetingTime is in the time zone of the notifications, and it is only set for the meeting events.
+// PreviousMeetingTime and Reason are only set for the reschedules and the cancellations of the meetings.
+type TemplateData struct {
+	Candidate           model.Candidate
+	MeetingTime         time.Time
+	PreviousMeetingTime time.Time
+	AssigneeName        string
+	Reason              string
+}
+
+// eventTemplates are the templates of an event in a language
+type eventTemplates struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// Templates renders the emails of the events in the language of the candidates
+type Templates struct {
+	defaultLanguage string
+	location        *time.Location
+	templates       map[string]map[string]eventTemplates
+}
+
+// LoadTemplates parses the templates of all languages. The templates of each event must exist in the default language,
+// which is used for the candidates without a language and for the languages that do not have the templates of the event.
+func LoadTemplates(defaultLanguage string, location *time.Location) (*Templates, error) {
+	t := &Templates{
+		defaultLanguage: defaultLanguage,
+		location:        location,
+		templates:       make(map[string]map[string]eventTemplates),
+	}
+
+	for _, language := range model.GetLanguagesAsArray() {
+		for _, event := range events {
+			templates, found, err := parseEventTemplates(language, event)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				continue
+			}
+			if t.templates[language] == nil {
+				t.templates[language] = make(map[string]eventTemplates)
+			}
+			t.templates[language][event] = templates
+		}
+	}
+
+	for _, event := range events {
+		if _, ok := t.templates[defaultLanguage][event]; !ok {
+			return nil, fmt.Errorf("templates of %s are missing in the default language %s", event, defaultLanguage)
+		}
+	}
+
+	return t, nil
+}
+
+// Render creates the email of the event to the candidate
+func (t *Templates) Render(event string, language string, data TemplateData) (model.Email, error) {
+	templates, ok := t.templates[language][event]
+	if !ok {
+		templates, ok = t.templates[t.defaultLanguage][event]
+	}
+	if !ok {
+		return model.Email{}, fmt.Errorf("there is not any template of %s", event)
+	}
+	data.MeetingTime = data.MeetingTime.In(t.location)
+	data.PreviousMeetingTime = data.PreviousMeetingTime.In(t.location)
+
+	var subject, text, html bytes.Buffer
+	if err := templates.subject.Execute(&subject, data); err != nil {
+		return model.Email{}, err
+	}
+	if err := templates.text.Execute(&text, data); err != nil {
+		return model.Email{}, err
+	}
+	if err := templates.html.Execute(&html, data); err != nil {
+		return model.Email{}, err
+	}
+
+	return model.Email{
+		To:       data.Candidate.Email,
+		Subject:  strings.TrimSpace(subject.String()),
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}, nil
+}
+
+// parseEventTemplates parses the templates of the event in the language. The templates of an event
+// must be complete if its subject template exists.
+func parseEventTemplates(language string, event string) (eventTemplates, bool, error) {
+	path := "templates/" + language + "/" + event
+	subject, err := fs.ReadFile(templatesFS, path+".subject.tmpl")
+	if errors.Is(err, fs.ErrNotExist) {
+		return eventTemplates{}, false, nil
+	}
+	if err != nil {
+		return eventTemplates{}, false, err
+	}
+	text, err := fs.ReadFile(templatesFS, path+".txt.tmpl")
+	if err != nil {
+		return eventTemplates{}, false, err
+	}
+	html, err := fs.ReadFile(templatesFS, path+".html.tmpl")
+	if err != nil {
+		return eventTemplates{}, false, err
+	}
+
+	var templates eventTemplates
+	if templates.subject, err = texttemplate.New(path + ".subject").Option("missingkey=error").Parse(string(subject)); err != nil {
+		return eventTemplates{}, false, err
+	}
+	if templates.text, err = texttemplate.New(path + ".txt").Option("missingkey=error").Parse(string(text)); err != nil {
+		return eventTemplates{}, false, err
+	}
+	if templates.html, err = htmltemplate.New(path + ".html").Option("missingkey=error").Parse(string(html)); err != nil {
+		return eventTemplates{}, false, err
+	}
+
+	return templates, true, nil
+}
@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// defaults of the processing of the queues
+const (
+	DefaultPollInterval = 30 * time.Second
+	DefaultLease        = 2 * time.Minute
+	DefaultMaxAttempts  = 8
+	// the retries are delayed by firstRetryDelay, doubled after each failed attempt up to maxRetryDelay
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = time.Hour
+)
+
+// Worker polls a queue that is stored in the database, such as the outbox of the events, the notifications or the
+// webhook deliveries. The items of the queue are claimed with a lease, so an item is processed by one replica at a time,
+// and an item whose lease expires before its outcome is recorded, e.g. because the process has stopped, is claimed again.
+// Failed items are retried with an exponential backoff until MaxAttempts.
+type Worker struct {
+	wake chan struct{}
+
+	PollInterval time.Duration
+	Lease        time.Duration
+	MaxAttempts  int
+}
+
+// NewWorker creates a Worker with the default poll interval, lease and attempts
+func NewWorker() Worker {
+	return Worker{
+		wake:         make(chan struct{}, 1),
+		PollInterval: DefaultPollInterval,
+		Lease:        DefaultLease,
+		MaxAttempts:  DefaultMaxAttempts,
+	}
+}
+
+// Wake makes Poll dispatch the queue right away, instead of waiting for the next poll,
+// e.g. right after an item is stored by this process
+func (worker *Worker) Wake() {
+	select {
+	case worker.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Poll dispatches the queue on each PollInterval and when it is woken, until the context is done.
+// The errors of dispatch are logged with the given name of the items.
+func (worker *Worker) Poll(ctx context.Context, items string, dispatch func(ctx context.Context) (int, error)) {
+	ticker := time.NewTicker(worker.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := dispatch(ctx); err != nil {
+			log.Printf("Couldn't dispatch the %s: %s\n", items, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-worker.wake:
+		}
+	}
+}
+
+// Retry returns the time of the next attempt of an item that has failed the given number of attempts,
+// and whether the item has failed for good since it has reached MaxAttempts
+func (worker *Worker) Retry(now time.Time, attempts int) (time.Time, bool) {
+	return now.Add(RetryDelay(attempts)), attempts >= worker.MaxAttempts
+}
+
+// Dispatch claims the due items one by one and processes them, until no item is due or the context is done.
+// It returns the number of the items that are processed successfully. The claim function returns false
+// if no item is due, and process records the outcome of the attempt, which is retried unless it is successful.
+func Dispatch[T any](ctx context.Context, claim func(ctx context.Context) (T, bool, error), process func(ctx context.Context, item T) bool) (int, error) {
+	processed := 0
+	for ctx.Err() == nil {
+		item, found, err := claim(ctx)
+		if err != nil || !found {
+			return processed, err
+		}
+
+		if process(ctx, item) {
+			processed++
+		}
+	}
+
+	return processed, nil
+}
+
+// RetryDelay is the delay of the next attempt after the given number of failed attempts
+func RetryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+
+	return delay
+}
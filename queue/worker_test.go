@@ -0,0 +1,94 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDispatch(t *testing.T) {
+	claim := func(items []int) func(ctx context.Context) (int, bool, error) {
+		return func(ctx context.Context) (int, bool, error) {
+			if len(items) == 0 {
+				return 0, false, nil
+			}
+			item := items[0]
+			items = items[1:]
+			return item, true, nil
+		}
+	}
+
+	t.Run("until-no-item-is-due", func(t *testing.T) {
+		var processed []int
+		count, err := Dispatch(context.TODO(), claim([]int{1, 2, 3}), func(ctx context.Context, item int) bool {
+			processed = append(processed, item)
+			return item != 2
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, []int{1, 2, 3}, processed)
+	})
+
+	t.Run("claim-fails", func(t *testing.T) {
+		count, err := Dispatch(context.TODO(), func(ctx context.Context) (int, bool, error) {
+			return 0, false, errors.New("mongo: connection refused")
+		}, func(ctx context.Context, item int) bool { return true })
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("context-is-done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		count, err := Dispatch(ctx, claim([]int{1, 2, 3}), func(ctx context.Context, item int) bool {
+			cancel()
+			return true
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestWorker_Poll(t *testing.T) {
+	worker := NewWorker()
+	worker.PollInterval = time.Hour
+	ctx, cancel := context.WithCancel(context.TODO())
+	dispatched := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Poll(ctx, "items", func(ctx context.Context) (int, error) {
+			dispatched <- struct{}{}
+			return 0, nil
+		})
+	}()
+
+	// the queue is dispatched when the worker starts, and right after it is woken
+	<-dispatched
+	worker.Wake()
+	<-dispatched
+	cancel()
+	<-done
+}
+
+func TestWorker_Retry(t *testing.T) {
+	worker := NewWorker()
+	now := time.Now()
+
+	next, failed := worker.Retry(now, 1)
+	assert.Equal(t, now.Add(30*time.Second), next)
+	assert.False(t, failed)
+	_, failed = worker.Retry(now, DefaultMaxAttempts)
+	assert.True(t, failed)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, RetryDelay(1))
+	assert.Equal(t, time.Minute, RetryDelay(2))
+	assert.Equal(t, 4*time.Minute, RetryDelay(4))
+	assert.Equal(t, time.Hour, RetryDelay(20))
+}
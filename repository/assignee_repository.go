@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -21,36 +22,52 @@ func MongoDBAssigneeRepository(collection *mongo.Collection) model.AssigneeRepos
 }
 
 func (repository *mongodbAssigneeRepository) CreateAssignee(ctx context.Context, assignee model.Assignee) (model.Assignee, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAssigneeRepository.CreateAssignee", "insertOne")
+	defer span.End()
+
 	_, err := repository.collection.InsertOne(ctx, assignee)
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return assignee, err
 }
 
 func (repository *mongodbAssigneeRepository) FindAllAssignees(ctx context.Context) ([]model.Assignee, error) {
-	var assignees []model.Assignee
-	cursor, err := repository.collection.Find(ctx, bson.D{})
-	err = cursor.All(ctx, &assignees)
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAssigneeRepository.FindAllAssignees", "find")
+	defer span.End()
+
+	assignees, err := repository.findAssignees(ctx, bson.D{})
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return assignees, err
 }
 
 func (repository *mongodbAssigneeRepository) ReadAssignee(ctx context.Context, id string) (model.Assignee, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAssigneeRepository.ReadAssignee", "findOne")
+	defer span.End()
+
 	var assignee model.Assignee
 	err := repository.collection.FindOne(ctx, bson.D{{"_id", id}}).Decode(&assignee)
+	if err == mongo.ErrNoDocuments {
+		return model.Assignee{}, model.ErrAssigneeDoesNotExist
+	}
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return assignee, err
 }
 
 func (repository *mongodbAssigneeRepository) FindAssigneeIDByName(ctx context.Context, name string) (string, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAssigneeRepository.FindAssigneeIDByName", "findOne")
+	defer span.End()
+
 	var assignee model.Assignee
 	projection := bson.D{{"_id", 1}}
 
@@ -59,39 +76,95 @@ func (repository *mongodbAssigneeRepository) FindAssigneeIDByName(ctx context.Co
 		options.FindOne().SetProjection(projection),
 	).Decode(&assignee)
 
+	if err == mongo.ErrNoDocuments {
+		return "", model.ErrAssigneeDoesNotExist
+	}
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return assignee.ID, err
 }
 
 func (repository *mongodbAssigneeRepository) FindAllAssigneesByDepartment(ctx context.Context, department string) ([]model.Assignee, error) {
-	var assignees []model.Assignee
-	cursor, err := repository.collection.Find(ctx, bson.D{{"department", department}})
-	err = cursor.All(ctx, &assignees)
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAssigneeRepository.FindAllAssigneesByDepartment", "find")
+	defer span.End()
+
+	assignees, err := repository.findAssignees(ctx, bson.D{{"department", department}})
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return assignees, err
 }
 
 func (repository *mongodbAssigneeRepository) FindOneAssigneeByDepartment(ctx context.Context, department string) (model.Assignee, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAssigneeRepository.FindOneAssigneeByDepartment", "aggregate")
+	defer span.End()
+
 	var assignees []model.Assignee
 	matchStage := bson.D{{"$match", bson.D{{"department", department}}}}
 	asd := bson.D{{"$sample", bson.D{{"size", 1}}}}
 
 	cursor, err := repository.collection.Aggregate(ctx, mongo.Pipeline{matchStage, asd})
-	err = cursor.All(ctx, &assignees)
+	if err == nil {
+		err = cursor.All(ctx, &assignees)
+	}
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
+		return model.Assignee{}, err
 	}
 
-	var assignee model.Assignee
-	if len (assignees) > 0 {
-		assignee = assignees[0]
+	if len(assignees) == 0 {
+		return model.Assignee{}, model.ErrAssigneeDoesNotExist
 	}
 
-	return assignee, err
+	return assignees[0], nil
+}
+
+func (repository *mongodbAssigneeRepository) SetCalendarTokenHash(ctx context.Context, id string, tokenHash string) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAssigneeRepository.SetCalendarTokenHash", "updateOne")
+	defer span.End()
+
+	result, err := repository.collection.UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$set", bson.D{{"calendar_token_hash", tokenHash}}}})
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return model.ErrAssigneeDoesNotExist
+	}
+
+	return nil
+}
+
+func (repository *mongodbAssigneeRepository) DeleteAllAssignees(ctx context.Context) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAssigneeRepository.DeleteAllAssignees", "deleteMany")
+	defer span.End()
+
+	_, err := repository.collection.DeleteMany(ctx, bson.D{})
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return err
+}
+
+// findAssignees decodes all assignees that match the given filter
+func (repository *mongodbAssigneeRepository) findAssignees(ctx context.Context, filter interface{}) ([]model.Assignee, error) {
+	cursor, err := repository.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	assignees := []model.Assignee{}
+	err = cursor.All(ctx, &assignees)
+
+	return assignees, err
 }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"strings"
+)
+
+// withoutText is the projection that leaves the extracted text out, since it is only searched
+var withoutText = bson.D{{"text", 0}}
+
+type mongodbAttachmentRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoDBAttachmentRepository will create an implementation of Attachment Repository with MongoDB
+func MongoDBAttachmentRepository(collection *mongo.Collection) model.AttachmentRepository {
+	return &mongodbAttachmentRepository{
+		collection: collection,
+	}
+}
+
+func (repository *mongodbAttachmentRepository) CreateAttachment(ctx context.Context, attachment model.Attachment) (model.Attachment, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAttachmentRepository.CreateAttachment", "insertOne")
+	defer span.End()
+
+	_, err := repository.collection.InsertOne(ctx, attachment)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return attachment, err
+}
+
+func (repository *mongodbAttachmentRepository) ReadAttachment(ctx context.Context, candidateID string, id string) (model.Attachment, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAttachmentRepository.ReadAttachment", "findOne")
+	defer span.End()
+
+	var attachment model.Attachment
+	err := repository.collection.FindOne(ctx, bson.D{{"_id", id}, {"candidate_id", candidateID}}, options.FindOne().SetProjection(withoutText)).Decode(&attachment)
+	if err == mongo.ErrNoDocuments {
+		return model.Attachment{}, model.ErrAttachmentDoesNotExist
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return attachment, err
+}
+
+func (repository *mongodbAttachmentRepository) FindCandidatesAttachments(ctx context.Context, candidateID string) ([]model.Attachment, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAttachmentRepository.FindCandidatesAttachments", "find")
+	defer span.End()
+
+	attachments := []model.Attachment{}
+	cursor, err := repository.collection.Find(ctx, bson.D{{"candidate_id", candidateID}}, options.Find().SetSort(bson.D{{"uploaded_at", 1}}).SetProjection(withoutText))
+	if err == nil {
+		err = cursor.All(ctx, &attachments)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (repository *mongodbAttachmentRepository) DeleteAttachment(ctx context.Context, candidateID string, id string) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAttachmentRepository.DeleteAttachment", "deleteOne")
+	defer span.End()
+
+	result, err := repository.collection.DeleteOne(ctx, bson.D{{"_id", id}, {"candidate_id", candidateID}})
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return model.ErrAttachmentDoesNotExist
+	}
+
+	return nil
+}
+
+// FindCandidateIDsByText finds the candidates through the text index of the attachments, rather than scanning their texts.
+// The word is searched as a phrase, so its punctuation, such as a leading minus, is not read as an operator of the search.
+func (repository *mongodbAttachmentRepository) FindCandidateIDsByText(ctx context.Context, word string) ([]string, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbAttachmentRepository.FindCandidateIDsByText", "aggregate")
+	defer span.End()
+
+	phrase := `"` + strings.ReplaceAll(word, `"`, ``) + `"`
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"$text", bson.D{{"$search", phrase}}}}}},
+		{{"$group", bson.D{{"_id", "$candidate_id"}}}},
+	}
+
+	var results []struct {
+		CandidateID string `bson:"_id"`
+	}
+	cursor, err := repository.collection.Aggregate(ctx, pipeline)
+	if err == nil {
+		err = cursor.All(ctx, &results)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.CandidateID)
+	}
+
+	return ids, nil
+}
@@ -3,94 +3,713 @@ package repository
 import (
 	"context"
 	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
+	"time"
 )
 
 type mongodbCandidateRepository struct {
 	collection *mongo.Collection
+	meetingsCollection *mongo.Collection
+	transactions *transactionRunner
 }
 
 // MongoDBCandidateRepository will create an implementation of Candidate Repository with MongoDB
-func MongoDBCandidateRepository(collection *mongo.Collection) model.CandidateRepository {
+// The meeting records of the candidates are kept in the given meetings collection
+func MongoDBCandidateRepository(collection *mongo.Collection, meetingsCollection *mongo.Collection) model.CandidateRepository {
 	return &mongodbCandidateRepository {
 		collection: collection,
+		meetingsCollection: meetingsCollection,
+		transactions: newTransactionRunner(collection.Database().Client()),
 	}
 }
 
 func (repository *mongodbCandidateRepository) CreateCandidate(ctx context.Context, candidate model.Candidate) (model.Candidate, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.CreateCandidate", "insertOne")
+	defer span.End()
+
+	// the unique email index rejects the candidates that already exist
 	_, err := repository.collection.InsertOne(ctx, candidate)
+	if isDuplicateKeyError(err) {
+		return model.Candidate{}, model.ErrCandidateAlreadyExists
+	}
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return candidate, err
 }
 
 func (repository *mongodbCandidateRepository) UpdateCandidate(ctx context.Context, id string, candidate model.Candidate) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.UpdateCandidate", "updateOne")
+	defer span.End()
+
+	// the candidate is only updated if nobody else has updated it since it was read.
+	// documents that are created before versioning do not have the version field,
+	// and they are considered as the first version.
+	expectedVersion := interface{}(candidate.Version)
+	if candidate.Version == 0 {
+		expectedVersion = bson.M{"$in": bson.A{0, nil}}
+	}
+	candidate.Version += 1
+	// the transition is only replaced by the changes of the meetings
+	candidate.LastMeetingTransition = nil
+
 	collection := repository.collection
-	_, err := collection.UpdateOne(
+	result, err := collection.UpdateOne(
 		ctx,
-		bson.M{"_id": id},
+		bson.M{"_id": id, "version": expectedVersion},
 		bson.D{
 			{"$set", candidate},
 		},
 	)
+	if isDuplicateKeyError(err) {
+		return model.ErrCandidateAlreadyExists
+	}
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
+		return err
 	}
 
-	return err
+	if result.MatchedCount == 0 {
+		return repository.versionConflictOrNotFound(ctx, id)
+	}
+
+	return nil
 }
 
 func (repository *mongodbCandidateRepository) ReadCandidate(ctx context.Context, id string) (model.Candidate, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.ReadCandidate", "findOne")
+	defer span.End()
+
 	var candidate model.Candidate
 	err := repository.collection.FindOne(ctx, bson.D{{"_id", id}}).Decode(&candidate)
+	if err == mongo.ErrNoDocuments {
+		return model.Candidate{}, model.ErrCandidateDoesNotExist
+	}
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return candidate, err
 }
 
 func (repository *mongodbCandidateRepository) FindAllCandidates(ctx context.Context) ([]model.Candidate, error) {
-	var candidates []model.Candidate
-	cursor, err := repository.collection.Find(ctx, bson.D{})
-	err = cursor.All(ctx, &candidates)
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.FindAllCandidates", "find")
+	defer span.End()
+
+	candidates, err := repository.findCandidates(ctx, bson.D{})
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return candidates, err
 }
 
+func (repository *mongodbCandidateRepository) FindCandidates(ctx context.Context, filter model.CandidateFilter) ([]model.Candidate, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.FindCandidates", "find")
+	defer span.End()
+
+	candidates, err := repository.findCandidates(ctx, candidateQuery(filter))
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return candidates, err
+}
+
+func (repository *mongodbCandidateRepository) StreamCandidates(ctx context.Context, filter model.CandidateFilter, fn func(model.Candidate) error) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.StreamCandidates", "find")
+	defer span.End()
+
+	cursor, err := repository.collection.Find(ctx, candidateQuery(filter))
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	// the candidates are decoded one by one, so the whole result is never kept in memory
+	for cursor.Next(ctx) {
+		var candidate model.Candidate
+		if err := cursor.Decode(&candidate); err != nil {
+			log.Println(err)
+			tracing.RecordError(span, err)
+			return err
+		}
+		if err := fn(candidate); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	return nil
+}
+
 func (repository *mongodbCandidateRepository) FindCandidateByEmail(ctx context.Context, email string) (model.Candidate, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.FindCandidateByEmail", "findOne")
+	defer span.End()
+
 	var candidate model.Candidate
 	err := repository.collection.FindOne(ctx, bson.D{{"email", email}}).Decode(&candidate)
+	if err == mongo.ErrNoDocuments {
+		return model.Candidate{}, model.ErrCandidateDoesNotExist
+	}
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return candidate, err
 }
 
 func (repository *mongodbCandidateRepository) FindAssigneesCandidates(ctx context.Context, id string) ([]model.Candidate, error) {
-	var candidates []model.Candidate
-	cursor, err := repository.collection.Find(ctx, bson.D{{"assignee", id}})
-	err = cursor.All(ctx, &candidates)
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.FindAssigneesCandidates", "find")
+	defer span.End()
+
+	candidates, err := repository.findCandidates(ctx, bson.D{{"assignee", id}})
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return candidates, err
 }
 
 func (repository *mongodbCandidateRepository) DeleteCandidate(ctx context.Context, id string) error {
-	_, err := repository.collection.DeleteOne(ctx, bson.D{{"_id", id}})
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.DeleteCandidate", "deleteOne")
+	defer span.End()
+
+	result, err := repository.collection.DeleteOne(ctx, bson.D{{"_id", id}})
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return model.ErrCandidateDoesNotExist
+	}
+
+	return nil
+}
+
+func (repository *mongodbCandidateRepository) ArrangeMeeting(ctx context.Context, id string, version int64, meeting model.MeetingRecord) (model.Candidate, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.ArrangeMeeting", "findAndModify")
+	defer span.End()
+
+	expectedVersion := interface{}(version)
+	if version == 0 {
+		expectedVersion = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	var candidate model.Candidate
+	err := repository.transactions.run(ctx, func(ctx context.Context) error {
+		// set the meeting of the candidate, if nobody else has updated it since it was read
+		err := repository.collection.FindOneAndUpdate(ctx,
+			bson.M{"_id": id, "version": expectedVersion},
+			bson.M{
+				"$set": bson.M{
+					"next_meeting": meeting.Time, "assignee": meeting.AssigneeID, "meeting_id": meeting.ID,
+					"last_meeting_transition": meetingTransition(model.MeetingArrangedEvent, meeting.ID),
+				},
+				"$inc": bson.M{"version": 1},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&candidate)
+		if err == mongo.ErrNoDocuments {
+			return repository.versionConflictOrNotFound(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = repository.meetingsCollection.InsertOne(ctx, meeting)
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*model.Error); !ok {
+			log.Println(err)
+			tracing.RecordError(span, err)
+		}
+		return model.Candidate{}, err
+	}
+
+	return candidate, nil
+}
+
+func (repository *mongodbCandidateRepository) RescheduleMeeting(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	candidate, meeting, err := repository.changeMeeting(ctx, "mongodbCandidateRepository.RescheduleMeeting", id, version, change, model.MeetingRescheduledEvent,
+		bson.M{"$set": bson.M{"next_meeting": change.Time}, "$inc": bson.M{"version": 1}},
+		// the rescheduled meeting is reminded again, and it is not overdue anymore
+		bson.M{"$set": bson.M{"time": change.Time, "reminder_sent_at": nil, "overdue": false}, "$inc": bson.M{"sequence": 1}},
+	)
+	if err != nil {
+		return model.Candidate{}, model.MeetingRecord{}, err
+	}
+
+	// the candidate is read before the update, reflect the changes to the returned candidate
+	candidate.NextMeeting = change.Time
+	candidate.Version += 1
+
+	return candidate, meeting, nil
+}
+
+func (repository *mongodbCandidateRepository) CancelMeeting(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	candidate, meeting, err := repository.changeMeeting(ctx, "mongodbCandidateRepository.CancelMeeting", id, version, change, model.MeetingCancelledEvent,
+		bson.M{"$set": bson.M{"next_meeting": nil, "meeting_id": ""}, "$inc": bson.M{"version": 1}},
+		bson.M{"$set": bson.M{"status": model.MeetingCancelled}, "$inc": bson.M{"sequence": 1}},
+	)
+	if err != nil {
+		return model.Candidate{}, model.MeetingRecord{}, err
+	}
+
+	// the candidate is read before the update, reflect the changes to the returned candidate
+	candidate.NextMeeting = nil
+	candidate.MeetingID = ""
+	candidate.Version += 1
+
+	return candidate, meeting, nil
+}
+
+func (repository *mongodbCandidateRepository) RecordNoShow(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	// the calendar event of the meeting is left as it is, as the meeting is not changed
+	candidate, meeting, err := repository.changeMeeting(ctx, "mongodbCandidateRepository.RecordNoShow", id, version, change, model.MeetingNoShowEvent,
+		bson.M{"$set": bson.M{"next_meeting": nil, "meeting_id": ""}, "$inc": bson.M{"no_show_count": 1, "version": 1}},
+		bson.M{"$set": bson.M{"status": model.MeetingNoShow}},
+	)
+	if err != nil {
+		return model.Candidate{}, model.MeetingRecord{}, err
+	}
+
+	// the candidate is read before the update, reflect the changes to the returned candidate
+	candidate.NextMeeting = nil
+	candidate.MeetingID = ""
+	candidate.NoShowCount += 1
+	candidate.Version += 1
+
+	return candidate, meeting, nil
+}
+
+// changeMeeting applies the given updates to the candidate and its meeting record, if the candidate has an arranged meeting
+// and nobody else has updated it since it was read. The change is appended to the history of the meeting record.
+// The transition of the given event replaces the last transition of the candidate in the same update.
+// The candidate is returned as it is before the update, and the meeting record as it is after the update.
+// The meetings that are arranged before the meeting records were kept do not have a record, and a zero record is returned for them.
+func (repository *mongodbCandidateRepository) changeMeeting(ctx context.Context, spanName string, id string, version int64, change model.MeetingChange, event string, candidateUpdate bson.M, meetingUpdate bson.M) (model.Candidate, model.MeetingRecord, error) {
+	ctx, span := startSpan(ctx, repository.collection, spanName, "findAndModify")
+	defer span.End()
+
+	expectedVersion := interface{}(version)
+	if version == 0 {
+		expectedVersion = bson.M{"$in": bson.A{0, nil}}
+	}
+	meetingUpdate["$push"] = bson.M{"changes": change}
+
+	var candidate model.Candidate
+	var meeting model.MeetingRecord
+	err := repository.transactions.run(ctx, func(ctx context.Context) error {
+		// the candidate is read before the update, as the id of the meeting record may be cleared by the update.
+		// The version does not change between the read and the update, so the transition records the meeting of the update.
+		filter := bson.M{"_id": id, "version": expectedVersion, "next_meeting": bson.M{"$ne": nil}}
+		err := repository.collection.FindOne(ctx, filter).Decode(&candidate)
+		if err == nil {
+			candidateUpdate["$set"].(bson.M)["last_meeting_transition"] = meetingTransition(event, candidate.MeetingID)
+			err = repository.collection.FindOneAndUpdate(ctx, filter, candidateUpdate,
+				options.FindOneAndUpdate().SetReturnDocument(options.Before),
+			).Decode(&candidate)
+		}
+		if err == mongo.ErrNoDocuments {
+			return repository.versionConflictOrNotFound(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+		if candidate.MeetingID == "" {
+			return nil
+		}
+
+		err = repository.meetingsCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": candidate.MeetingID},
+			meetingUpdate,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&meeting)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*model.Error); !ok {
+			log.Println(err)
+			tracing.RecordError(span, err)
+		}
+		return model.Candidate{}, model.MeetingRecord{}, err
+	}
+
+	return candidate, meeting, nil
+}
+
+func (repository *mongodbCandidateRepository) CompleteMeeting(ctx context.Context, id string, completedAt time.Time) (model.Candidate, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.CompleteMeeting", "findAndModify")
+	defer span.End()
+
+	var candidate model.Candidate
+	err := repository.transactions.run(ctx, func(ctx context.Context) error {
+		transition := meetingTransition(model.MeetingCompletedEvent, "")
+		clearMeeting := bson.M{"next_meeting": nil, "meeting_id": "", "last_meeting_transition": transition}
+		// increment the meeting count only if the candidate has an arranged meeting,
+		// and the candidate has not completed all of the meetings yet
+		err := repository.collection.FindOneAndUpdate(ctx,
+			bson.M{"_id": id, "next_meeting": bson.M{"$ne": nil}, "meeting_count": bson.M{"$lt": 4}},
+			bson.M{
+				"$set": bson.M{"next_meeting": nil, "meeting_id": "", "status": model.InProgress, "last_meeting_transition": transition},
+				"$inc": bson.M{"meeting_count": 1, "version": 1},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.Before),
+		).Decode(&candidate)
+		if err == mongo.ErrNoDocuments {
+			// the candidate has completed all of the meetings, only clear the arranged meeting
+			err = repository.collection.FindOneAndUpdate(ctx,
+				bson.M{"_id": id, "next_meeting": bson.M{"$ne": nil}},
+				bson.M{"$set": clearMeeting, "$inc": bson.M{"version": 1}},
+				options.FindOneAndUpdate().SetReturnDocument(options.Before),
+			).Decode(&candidate)
+		}
+		if err == mongo.ErrNoDocuments {
+			return repository.arrangedMeetingOrNotFound(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		if candidate.MeetingID != "" {
+			_, err = repository.meetingsCollection.UpdateOne(ctx,
+				bson.M{"_id": candidate.MeetingID},
+				bson.M{"$set": bson.M{"status": model.MeetingCompleted, "completed_at": completedAt}},
+			)
+		}
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*model.Error); !ok {
+			log.Println(err)
+			tracing.RecordError(span, err)
+		}
+		return model.Candidate{}, err
+	}
+
+	// the candidate is read before the update, reflect the changes to the returned candidate
+	candidate.NextMeeting = nil
+	candidate.MeetingID = ""
+	if candidate.MeetingCount < 4 {
+		candidate.MeetingCount += 1
+		candidate.Status = model.InProgress
+	}
+	candidate.Version += 1
+
+	return candidate, nil
+}
+
+func (repository *mongodbCandidateRepository) ReadMeeting(ctx context.Context, id string) (model.MeetingRecord, error) {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbCandidateRepository.ReadMeeting", "findOne")
+	defer span.End()
+
+	var meeting model.MeetingRecord
+	err := repository.meetingsCollection.FindOne(ctx, bson.D{{"_id", id}}).Decode(&meeting)
+	if err == mongo.ErrNoDocuments {
+		return model.MeetingRecord{}, model.ErrMeetingDoesNotExist
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return meeting, err
+}
+
+func (repository *mongodbCandidateRepository) FindCandidatesMeetings(ctx context.Context, candidateID string) ([]model.MeetingRecord, error) {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbCandidateRepository.FindCandidatesMeetings", "find")
+	defer span.End()
+
+	// the meetings are found with the candidate_id index
+	meetings := []model.MeetingRecord{}
+	cursor, err := repository.meetingsCollection.Find(ctx,
+		bson.D{{"candidate_id", candidateID}},
+		options.Find().SetSort(bson.D{{"arranged_at", 1}}),
+	)
+	if err == nil {
+		err = cursor.All(ctx, &meetings)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return meetings, nil
+}
+
+func (repository *mongodbCandidateRepository) FindAssigneesMeetings(ctx context.Context, assigneeID string, from time.Time) ([]model.MeetingRecord, error) {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbCandidateRepository.FindAssigneesMeetings", "find")
+	defer span.End()
+
+	// the meetings are found with the assignee_id_time index
+	meetings := []model.MeetingRecord{}
+	cursor, err := repository.meetingsCollection.Find(ctx,
+		bson.D{{"assignee_id", assigneeID}, {"time", bson.D{{"$gte", from}}}},
+		options.Find().SetSort(bson.D{{"time", 1}}),
+	)
+	if err == nil {
+		err = cursor.All(ctx, &meetings)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return meetings, nil
+}
+
+func (repository *mongodbCandidateRepository) FindMeetingsToRemind(ctx context.Context, from time.Time, to time.Time) ([]model.MeetingRecord, error) {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbCandidateRepository.FindMeetingsToRemind", "find")
+	defer span.End()
+
+	// the meetings are found with the status_time index
+	meetings := []model.MeetingRecord{}
+	cursor, err := repository.meetingsCollection.Find(ctx,
+		bson.D{{"status", model.MeetingArranged}, {"time", bson.D{{"$gte", from}, {"$lt", to}}}, {"reminder_sent_at", nil}},
+		options.Find().SetSort(bson.D{{"time", 1}}),
+	)
+	if err == nil {
+		err = cursor.All(ctx, &meetings)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return meetings, nil
+}
+
+func (repository *mongodbCandidateRepository) MarkReminderSent(ctx context.Context, meetingID string, sentAt time.Time) (bool, error) {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbCandidateRepository.MarkReminderSent", "update")
+	defer span.End()
+
+	// only one of the concurrent schedulers marks the meeting
+	result, err := repository.meetingsCollection.UpdateOne(ctx,
+		bson.D{{"_id", meetingID}, {"reminder_sent_at", nil}},
+		bson.D{{"$set", bson.D{{"reminder_sent_at", sentAt}}}},
+	)
 	if err != nil {
 		log.Println(err)
+		tracing.RecordError(span, err)
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (repository *mongodbCandidateRepository) RecordMeetingFeedback(ctx context.Context, meetingID string, score int) error {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbCandidateRepository.RecordMeetingFeedback", "update")
+	defer span.End()
+
+	result, err := repository.meetingsCollection.UpdateOne(ctx,
+		bson.D{{"_id", meetingID}, {"status", model.MeetingCompleted}},
+		bson.D{{"$set", bson.D{{"feedback_score", score}}}},
+	)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrMeetingDoesNotExist
+	}
+
+	return nil
+}
+
+func (repository *mongodbCandidateRepository) FlagOverdueMeetings(ctx context.Context, endedBefore time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbCandidateRepository.FlagOverdueMeetings", "update")
+	defer span.End()
+
+	result, err := repository.meetingsCollection.UpdateMany(ctx,
+		bson.D{{"status", model.MeetingArranged}, {"time", bson.D{{"$lt", endedBefore}}}, {"overdue", bson.D{{"$ne", true}}}},
+		bson.D{{"$set", bson.D{{"overdue", true}}}},
+	)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (repository *mongodbCandidateRepository) FindOverdueMeetings(ctx context.Context) ([]model.MeetingRecord, error) {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbCandidateRepository.FindOverdueMeetings", "find")
+	defer span.End()
+
+	meetings := []model.MeetingRecord{}
+	cursor, err := repository.meetingsCollection.Find(ctx,
+		bson.D{{"status", model.MeetingArranged}, {"overdue", true}},
+		options.Find().SetSort(bson.D{{"time", 1}}),
+	)
+	if err == nil {
+		err = cursor.All(ctx, &meetings)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return meetings, nil
+}
+
+func (repository *mongodbCandidateRepository) FindCandidatesToExpire(ctx context.Context, appliedBefore time.Time) ([]model.Candidate, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.FindCandidatesToExpire", "find")
+	defer span.End()
+
+	// the candidates are found with the status index
+	candidates, err := repository.findCandidates(ctx,
+		bson.D{{"status", model.Pending}, {"application_date", bson.D{{"$lt", appliedBefore}}}, {"next_meeting", nil}})
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+func (repository *mongodbCandidateRepository) DeleteAllCandidates(ctx context.Context) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbCandidateRepository.DeleteAllCandidates", "deleteMany")
+	defer span.End()
+
+	err := repository.transactions.run(ctx, func(ctx context.Context) error {
+		if _, err := repository.collection.DeleteMany(ctx, bson.D{}); err != nil {
+			return err
+		}
+
+		_, err := repository.meetingsCollection.DeleteMany(ctx, bson.D{})
+		return err
+	})
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
 	}
 
 	return err
 }
+
+// findCandidates decodes all candidates that match the given filter
+func (repository *mongodbCandidateRepository) findCandidates(ctx context.Context, filter interface{}) ([]model.Candidate, error) {
+	cursor, err := repository.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []model.Candidate{}
+	err = cursor.All(ctx, &candidates)
+
+	return candidates, err
+}
+
+// candidateQuery creates the query of the fields of the filter that are not empty
+func candidateQuery(filter model.CandidateFilter) bson.M {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Department != "" {
+		query["department"] = filter.Department
+	}
+	if filter.University != "" {
+		query["university"] = filter.University
+	}
+	if filter.AssigneeID != "" {
+		query["assignee"] = filter.AssigneeID
+	}
+	if len(filter.Search) > 0 {
+		terms := bson.A{}
+		for _, term := range filter.Search {
+			terms = append(terms, searchTermQuery(term))
+		}
+		query["$and"] = terms
+	}
+
+	return query
+}
+
+// searchTermQuery matches the candidates whose searched fields contain the word of the term,
+// or the candidates whose attachments contain it
+func searchTermQuery(term model.SearchTerm) bson.M {
+	pattern := primitive.Regex{Pattern: model.WordPattern(term.Word), Options: "i"}
+	alternatives := bson.A{
+		bson.M{"first_name": pattern},
+		bson.M{"last_name": pattern},
+		bson.M{"email": pattern},
+		bson.M{"university": pattern},
+	}
+	if len(term.AttachedCandidateIDs) > 0 {
+		alternatives = append(alternatives, bson.M{"_id": bson.M{"$in": term.AttachedCandidateIDs}})
+	}
+
+	return bson.M{"$or": alternatives}
+}
+
+// versionConflictOrNotFound explains why a conditional update did not match any candidate
+func (repository *mongodbCandidateRepository) versionConflictOrNotFound(ctx context.Context, id string) error {
+	count, err := repository.collection.CountDocuments(ctx, bson.D{{"_id", id}})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	if count == 0 {
+		return model.ErrCandidateDoesNotExist
+	}
+
+	return model.ErrCandidateVersionConflict
+}
+
+// arrangedMeetingOrNotFound explains why a meeting of the candidate cannot be completed
+func (repository *mongodbCandidateRepository) arrangedMeetingOrNotFound(ctx context.Context, id string) error {
+	count, err := repository.collection.CountDocuments(ctx, bson.D{{"_id", id}})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	if count == 0 {
+		return model.ErrCandidateDoesNotExist
+	}
+
+	return model.ErrArrangedMeetingDoesNotExist
+}
+
+// meetingTransition creates the last meeting transition of a candidate under a new id. The whole transition is replaced
+// by each change of the meetings, so the candidate keeps only one, and the new id is reported as a new field by the
+// change streams, along with the whole transition.
+func meetingTransition(event string, meetingID string) map[string]model.MeetingTransition {
+	return map[string]model.MeetingTransition{primitive.NewObjectID().Hex(): {Event: event, MeetingID: meetingID}}
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"strings"
+	"time"
+)
+
+// the codes that MongoDB returns when a change stream cannot be opened
+const (
+	// changeStreamNotSupportedErrorCode is returned by the standalone servers
+	changeStreamNotSupportedErrorCode = 40573
+	// changeStreamFatalErrorCode and changeStreamHistoryLostErrorCode are returned when the resume token is not in the oplog anymore
+	changeStreamFatalErrorCode       = 280
+	changeStreamHistoryLostErrorCode = 286
+)
+
+// candidateChangeEvent is the change event of a candidate document in the change stream of the Candidates collection
+type candidateChangeEvent struct {
+	ID            bson.Raw            `bson:"_id"`
+	OperationType string              `bson:"operationType"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	DocumentKey   struct {
+		ID string `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument      *model.Candidate `bson:"fullDocument"`
+	UpdateDescription struct {
+		UpdatedFields bson.Raw `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+type mongodbChangeStreamRepository struct {
+	candidatesCollection    *mongo.Collection
+	changeStreamsCollection *mongo.Collection
+}
+
+// MongoDBChangeStreamRepository will create an implementation of Change Stream Repository with MongoDB,
+// which watches the changes of the Candidates collection, and keeps the resume tokens in the given collection
+func MongoDBChangeStreamRepository(candidatesCollection *mongo.Collection, changeStreamsCollection *mongo.Collection) model.ChangeStreamRepository {
+	return &mongodbChangeStreamRepository{
+		candidatesCollection:    candidatesCollection,
+		changeStreamsCollection: changeStreamsCollection,
+	}
+}
+
+// WatchCandidates opens a change stream of the Candidates collection. The updates are looked up, so the changes carry
+// the current document of the candidate, which can be newer than the change if the candidate is changed again,
+// or nil if the candidate is deleted since the change.
+func (repository *mongodbChangeStreamRepository) WatchCandidates(ctx context.Context, resumeToken []byte, fn func(model.CandidateChange) error) error {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"operationType": bson.M{"$in": bson.A{model.ChangeInsert, model.ChangeUpdate, model.ChangeReplace, model.ChangeDelete}}}}},
+	}
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if len(resumeToken) > 0 {
+		opts.SetResumeAfter(bson.Raw(resumeToken))
+	}
+
+	changeStream, err := repository.candidatesCollection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return changeStreamError(err)
+	}
+	defer changeStream.Close(context.Background())
+
+	for changeStream.Next(ctx) {
+		change, err := candidateChange(changeStream.Current)
+		if err != nil {
+			// the change is passed with only its token, so the watcher moves past it instead of reopening the stream at it
+			log.Printf("Couldn't decode the change %s of the candidates, skipping it. Error is: %s\n", changeStream.Current, err)
+			change = model.CandidateChange{Token: changeStream.ResumeToken()}
+		}
+
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return changeStreamError(changeStream.Err())
+}
+
+// candidateChange decodes the change event of a candidate in the change stream
+func candidateChange(raw bson.Raw) (model.CandidateChange, error) {
+	var event candidateChangeEvent
+	if err := bson.Unmarshal(raw, &event); err != nil {
+		return model.CandidateChange{}, err
+	}
+
+	change := model.CandidateChange{
+		Token:       event.ID,
+		Operation:   event.OperationType,
+		CandidateID: event.DocumentKey.ID,
+		Candidate:   event.FullDocument,
+		OccurredAt:  time.Unix(int64(event.ClusterTime.T), 0),
+	}
+	if err := updatedFields(&change, event.UpdateDescription.UpdatedFields); err != nil {
+		return model.CandidateChange{}, err
+	}
+	change.UpdatedFields = append(change.UpdatedFields, event.UpdateDescription.RemovedFields...)
+
+	return change, nil
+}
+
+func (repository *mongodbChangeStreamRepository) ReadResumeToken(ctx context.Context, name string) ([]byte, error) {
+	ctx, span := startSpan(ctx, repository.changeStreamsCollection, "mongodbChangeStreamRepository.ReadResumeToken", "find")
+	defer span.End()
+
+	var resumeToken model.ResumeToken
+	err := repository.changeStreamsCollection.FindOne(ctx, bson.M{"_id": name}).Decode(&resumeToken)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return resumeToken.Token, nil
+}
+
+func (repository *mongodbChangeStreamRepository) SaveResumeToken(ctx context.Context, name string, token []byte) error {
+	ctx, span := startSpan(ctx, repository.changeStreamsCollection, "mongodbChangeStreamRepository.SaveResumeToken", "update")
+	defer span.End()
+
+	_, err := repository.changeStreamsCollection.UpdateOne(ctx,
+		bson.M{"_id": name},
+		bson.M{"$set": bson.M{"token": token, "updated_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return err
+}
+
+// updatedFields sets the updated fields of the change, along with the status and the meeting transition that the update
+// has set. Each transition is set under a new id, which is either reported as the new field of the last transition,
+// such as last_meeting_transition.<id>, or along with the last transition when it is set as a whole.
+func updatedFields(change *model.CandidateChange, fields bson.Raw) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	elements, err := fields.Elements()
+	if err != nil {
+		return err
+	}
+
+	for _, element := range elements {
+		field := element.Key()
+		change.UpdatedFields = append(change.UpdatedFields, field)
+
+		switch {
+		case field == "status":
+			change.Status, _ = element.Value().StringValueOK()
+		case field == "last_meeting_transition":
+			var candidate model.Candidate
+			if err := element.Value().Unmarshal(&candidate.LastMeetingTransition); err != nil {
+				return err
+			}
+			_, change.MeetingTransition = candidate.LatestMeetingTransition()
+		case strings.HasPrefix(field, "last_meeting_transition.") && strings.Count(field, ".") == 1:
+			var transition model.MeetingTransition
+			if err := element.Value().Unmarshal(&transition); err != nil {
+				return err
+			}
+			change.MeetingTransition = &transition
+		}
+	}
+
+	return nil
+}
+
+// changeStreamError maps the errors of the change streams that the watchers handle to the errors of the model
+func changeStreamError(err error) error {
+	var commandError mongo.CommandError
+	if errors.As(err, &commandError) {
+		switch commandError.Code {
+		case changeStreamNotSupportedErrorCode:
+			return model.ErrChangeStreamNotSupported
+		case changeStreamFatalErrorCode, changeStreamHistoryLostErrorCode:
+			return model.ErrChangeStreamHistoryLost
+		}
+	}
+
+	return err
+}
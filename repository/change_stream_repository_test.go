@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func TestCandidateChange(t *testing.T) {
+	t.Run("update", func(t *testing.T) {
+		raw, _ := bson.Marshal(bson.M{
+			"_id":           bson.M{"_data": "t1"},
+			"operationType": model.ChangeUpdate,
+			"clusterTime":   primitive.Timestamp{T: 1600000000},
+			"documentKey":   bson.M{"_id": "abcd"},
+			"fullDocument":  bson.M{"_id": "abcd", "status": model.Accepted},
+			"updateDescription": bson.M{
+				"updatedFields": bson.M{"status": model.Denied},
+				"removedFields": bson.A{"decided_at"},
+			},
+		})
+
+		change, err := candidateChange(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, model.ChangeUpdate, change.Operation)
+		assert.Equal(t, "abcd", change.CandidateID)
+		assert.Equal(t, model.Accepted, change.Candidate.Status)
+		// the status is taken from the update, rather than the document that is looked up later
+		assert.Equal(t, model.Denied, change.Status)
+		assert.Equal(t, []string{"status", "decided_at"}, change.UpdatedFields)
+		assert.Equal(t, int64(1600000000), change.OccurredAt.Unix())
+	})
+
+	t.Run("cannot-be-decoded", func(t *testing.T) {
+		// a candidate that is edited directly in the database
+		raw, _ := bson.Marshal(bson.M{
+			"_id":           bson.M{"_data": "t1"},
+			"operationType": model.ChangeInsert,
+			"documentKey":   bson.M{"_id": "abcd"},
+			"fullDocument":  bson.M{"_id": "abcd", "meeting_count": "four"},
+		})
+
+		_, err := candidateChange(raw)
+		assert.Error(t, err)
+	})
+}
+
+func TestUpdatedFields(t *testing.T) {
+	arranged := model.MeetingTransition{Event: model.MeetingArrangedEvent, MeetingID: "m1"}
+	cancelled := model.MeetingTransition{Event: model.MeetingCancelledEvent, MeetingID: "m1"}
+	tests := []struct {
+		name       string
+		fields     interface{}
+		want       []string
+		status     string
+		transition *model.MeetingTransition
+	}{
+		{
+			name:   "status",
+			fields: bson.D{{"status", model.Denied}, {"decided_at", primitive.NewDateTimeFromTime(time.Now())}, {"version", 2}},
+			want:   []string{"status", "decided_at", "version"},
+			status: model.Denied,
+		},
+		{
+			name:   "without-transition",
+			fields: bson.D{{"first_name", "Ayşe"}, {"version", 2}},
+			want:   []string{"first_name", "version"},
+		},
+		{
+			// the whole transition is set when the candidate does not have one, or by the servers that do not report the diffs
+			name:       "whole-transition",
+			fields:     bson.D{{"meeting_id", "m1"}, {"last_meeting_transition", bson.M{"t1": arranged}}, {"version", 1}},
+			want:       []string{"meeting_id", "last_meeting_transition", "version"},
+			transition: &arranged,
+		},
+		{
+			// the diffs report the new id of the transition as a new field
+			name:       "new-transition",
+			fields:     bson.D{{"meeting_id", ""}, {"last_meeting_transition.t2", cancelled}, {"version", 2}},
+			want:       []string{"meeting_id", "last_meeting_transition.t2", "version"},
+			transition: &cancelled,
+		},
+		{
+			name:   "nested-field-of-transition",
+			fields: bson.D{{"last_meeting_transition.t2.event", model.MeetingCancelledEvent}},
+			want:   []string{"last_meeting_transition.t2.event"},
+		},
+		{
+			name:   "empty-transition",
+			fields: bson.D{{"last_meeting_transition", bson.M{}}},
+			want:   []string{"last_meeting_transition"},
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var fields bson.Raw
+			if test.fields != nil {
+				fields, _ = bson.Marshal(test.fields)
+			}
+
+			var change model.CandidateChange
+			assert.NoError(t, updatedFields(&change, fields))
+			assert.Equal(t, test.want, change.UpdatedFields)
+			assert.Equal(t, test.status, change.Status)
+			assert.Equal(t, test.transition, change.MeetingTransition)
+		})
+	}
+
+	t.Run("invalid-transition", func(t *testing.T) {
+		fields, _ := bson.Marshal(bson.D{{"last_meeting_transition.t2", "cancelled"}})
+
+		var change model.CandidateChange
+		assert.Error(t, updatedFields(&change, fields))
+	})
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// duplicateKeyErrorCode is the code that MongoDB returns when a write violates a unique index
+const duplicateKeyErrorCode = 11000
+
+// isDuplicateKeyError checks the given error is caused by a unique index violation
+func isDuplicateKeyError(err error) bool {
+	var writeException mongo.WriteException
+	if errors.As(err, &writeException) {
+		for _, writeError := range writeException.WriteErrors {
+			if writeError.Code == duplicateKeyErrorCode {
+				return true
+			}
+		}
+	}
+
+	var commandError mongo.CommandError
+	if errors.As(err, &commandError) {
+		return commandError.Code == duplicateKeyErrorCode
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+type mongodbEventRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoDBEventRepository will create an implementation of Event Repository with MongoDB
+func MongoDBEventRepository(collection *mongo.Collection) model.EventRepository {
+	return &mongodbEventRepository{
+		collection: collection,
+	}
+}
+
+// CreateEvent stores the event. When the context carries a session of a MongoDB transaction,
+// the event is written in the same transaction.
+func (repository *mongodbEventRepository) CreateEvent(ctx context.Context, event model.OutboxEvent) (model.OutboxEvent, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbEventRepository.CreateEvent", "insertOne")
+	defer span.End()
+
+	_, err := repository.collection.InsertOne(ctx, event)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return event, err
+}
+
+// ClaimEvent takes the oldest due event atomically, so concurrent dispatchers never handle the same event
+func (repository *mongodbEventRepository) ClaimEvent(ctx context.Context, now time.Time, lease time.Duration) (model.OutboxEvent, bool, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbEventRepository.ClaimEvent", "findAndModify")
+	defer span.End()
+
+	var event model.OutboxEvent
+	err := repository.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"status": model.EventPending, "next_attempt_at": bson.M{"$lte": now}},
+		bson.D{
+			{"$set", bson.M{"next_attempt_at": now.Add(lease)}},
+			{"$inc", bson.M{"attempts": 1}},
+		},
+		options.FindOneAndUpdate().SetSort(bson.D{{"next_attempt_at", 1}, {"_id", 1}}).SetReturnDocument(options.After),
+	).Decode(&event)
+	if err == mongo.ErrNoDocuments {
+		return model.OutboxEvent{}, false, nil
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return model.OutboxEvent{}, false, err
+	}
+
+	return event, true, nil
+}
+
+func (repository *mongodbEventRepository) UpdateEvent(ctx context.Context, event model.OutboxEvent) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbEventRepository.UpdateEvent", "replaceOne")
+	defer span.End()
+
+	_, err := repository.collection.ReplaceOne(ctx, bson.D{{"_id", event.ID}}, event)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return err
+}
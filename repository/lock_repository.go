@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+type mongodbLockRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoDBLockRepository will create an implementation of Lock Repository with MongoDB
+func MongoDBLockRepository(collection *mongo.Collection) model.LockRepository {
+	return &mongodbLockRepository{
+		collection: collection,
+	}
+}
+
+// AcquireLock upserts the lock document, if it is held by the owner or its lease has expired. If another owner holds the lock,
+// the filter does not match, and the upsert fails with a duplicate key error, as the lock document already exists.
+func (repository *mongodbLockRepository) AcquireLock(ctx context.Context, name string, owner string, now time.Time, until time.Time) (bool, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbLockRepository.AcquireLock", "update")
+	defer span.End()
+
+	_, err := repository.collection.UpdateOne(ctx,
+		bson.M{"_id": name, "$or": bson.A{bson.M{"owner": owner}, bson.M{"expires_at": bson.M{"$lte": now}}}},
+		bson.M{"$set": bson.M{"owner": owner, "expires_at": until}},
+		options.Update().SetUpsert(true),
+	)
+	if isDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repository *mongodbLockRepository) ReleaseLock(ctx context.Context, name string, owner string) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbLockRepository.ReleaseLock", "delete")
+	defer span.End()
+
+	_, err := repository.collection.DeleteOne(ctx, bson.M{"_id": name, "owner": owner})
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return err
+}
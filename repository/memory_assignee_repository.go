@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"math/rand"
+	"sync"
+)
+
+type memoryAssigneeRepository struct {
+	mutex sync.RWMutex
+	assignees map[string]model.Assignee
+	// ids keeps the insertion order, so the assignees are listed in the same order as MongoDB lists them
+	ids []string
+}
+
+// MemoryAssigneeRepository will create an implementation of Assignee Repository that keeps the assignees in memory
+func MemoryAssigneeRepository() model.AssigneeRepository {
+	return &memoryAssigneeRepository{
+		assignees: make(map[string]model.Assignee),
+	}
+}
+
+func (repository *memoryAssigneeRepository) CreateAssignee(ctx context.Context, assignee model.Assignee) (model.Assignee, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.assignees[assignee.ID]; !ok {
+		repository.ids = append(repository.ids, assignee.ID)
+	}
+	repository.assignees[assignee.ID] = assignee
+
+	return assignee, nil
+}
+
+func (repository *memoryAssigneeRepository) ReadAssignee(ctx context.Context, id string) (model.Assignee, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	assignee, ok := repository.assignees[id]
+	if !ok {
+		return model.Assignee{}, model.ErrAssigneeDoesNotExist
+	}
+
+	return assignee, nil
+}
+
+func (repository *memoryAssigneeRepository) FindAllAssignees(ctx context.Context) ([]model.Assignee, error) {
+	return repository.findAssignees(func(a model.Assignee) bool { return true }), nil
+}
+
+func (repository *memoryAssigneeRepository) FindAssigneeIDByName(ctx context.Context, name string) (string, error) {
+	assignees := repository.findAssignees(func(a model.Assignee) bool { return a.Name == name })
+	if len(assignees) == 0 {
+		return "", model.ErrAssigneeDoesNotExist
+	}
+
+	return assignees[0].ID, nil
+}
+
+func (repository *memoryAssigneeRepository) FindAllAssigneesByDepartment(ctx context.Context, department string) ([]model.Assignee, error) {
+	return repository.findAssignees(func(a model.Assignee) bool { return a.Department == department }), nil
+}
+
+func (repository *memoryAssigneeRepository) FindOneAssigneeByDepartment(ctx context.Context, department string) (model.Assignee, error) {
+	assignees := repository.findAssignees(func(a model.Assignee) bool { return a.Department == department })
+	if len(assignees) == 0 {
+		return model.Assignee{}, model.ErrAssigneeDoesNotExist
+	}
+
+	// a random assignee is chosen, like the $sample stage of MongoDB
+	return assignees[rand.Intn(len(assignees))], nil
+}
+
+func (repository *memoryAssigneeRepository) SetCalendarTokenHash(ctx context.Context, id string, tokenHash string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	assignee, ok := repository.assignees[id]
+	if !ok {
+		return model.ErrAssigneeDoesNotExist
+	}
+
+	assignee.CalendarTokenHash = tokenHash
+	repository.assignees[id] = assignee
+
+	return nil
+}
+
+func (repository *memoryAssigneeRepository) DeleteAllAssignees(ctx context.Context) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.assignees = make(map[string]model.Assignee)
+	repository.ids = nil
+
+	return nil
+}
+
+// findAssignees returns the assignees that match the given filter in insertion order
+func (repository *memoryAssigneeRepository) findAssignees(filter func(a model.Assignee) bool) []model.Assignee {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	assignees := []model.Assignee{}
+	for _, id := range repository.ids {
+		if assignee := repository.assignees[id]; filter(assignee) {
+			assignees = append(assignees, assignee)
+		}
+	}
+
+	return assignees
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"regexp"
+	"sync"
+)
+
+type memoryAttachmentRepository struct {
+	mutex sync.RWMutex
+	attachments map[string]model.Attachment
+	// ids keeps the upload order, so the attachments are listed in the same order as MongoDB lists them
+	ids []string
+}
+
+// MemoryAttachmentRepository will create an implementation of Attachment Repository that keeps the attachments in memory
+func MemoryAttachmentRepository() model.AttachmentRepository {
+	return &memoryAttachmentRepository{
+		attachments: make(map[string]model.Attachment),
+	}
+}
+
+func (repository *memoryAttachmentRepository) CreateAttachment(ctx context.Context, attachment model.Attachment) (model.Attachment, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.attachments[attachment.ID]; !ok {
+		repository.ids = append(repository.ids, attachment.ID)
+	}
+	repository.attachments[attachment.ID] = attachment
+
+	return attachment, nil
+}
+
+func (repository *memoryAttachmentRepository) ReadAttachment(ctx context.Context, candidateID string, id string) (model.Attachment, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	attachment, ok := repository.attachments[id]
+	if !ok || attachment.CandidateID != candidateID {
+		return model.Attachment{}, model.ErrAttachmentDoesNotExist
+	}
+
+	return attachment, nil
+}
+
+func (repository *memoryAttachmentRepository) FindCandidatesAttachments(ctx context.Context, candidateID string) ([]model.Attachment, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	attachments := []model.Attachment{}
+	for _, id := range repository.ids {
+		if attachment := repository.attachments[id]; attachment.CandidateID == candidateID {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	return attachments, nil
+}
+
+func (repository *memoryAttachmentRepository) DeleteAttachment(ctx context.Context, candidateID string, id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	attachment, ok := repository.attachments[id]
+	if !ok || attachment.CandidateID != candidateID {
+		return model.ErrAttachmentDoesNotExist
+	}
+
+	delete(repository.attachments, id)
+	for i, existing := range repository.ids {
+		if existing == id {
+			repository.ids = append(repository.ids[:i], repository.ids[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (repository *memoryAttachmentRepository) FindCandidateIDsByText(ctx context.Context, word string) ([]string, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	pattern := regexp.MustCompile(`(?i)` + model.WordPattern(word))
+	ids := []string{}
+	found := make(map[string]bool)
+	for _, id := range repository.ids {
+		attachment := repository.attachments[id]
+		if !found[attachment.CandidateID] && pattern.MatchString(attachment.Text) {
+			found[attachment.CandidateID] = true
+			ids = append(ids, attachment.CandidateID)
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,412 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryCandidateRepository struct {
+	mutex sync.RWMutex
+	candidates map[string]model.Candidate
+	// ids keeps the insertion order, so the candidates are listed in the same order as MongoDB lists them
+	ids []string
+	meetings map[string]model.MeetingRecord
+}
+
+// MemoryCandidateRepository will create an implementation of Candidate Repository that keeps the candidates
+// and their meeting records in memory. It follows the same versioning and meeting rules as the MongoDB implementation.
+func MemoryCandidateRepository() model.CandidateRepository {
+	return &memoryCandidateRepository{
+		candidates: make(map[string]model.Candidate),
+		meetings: make(map[string]model.MeetingRecord),
+	}
+}
+
+func (repository *memoryCandidateRepository) CreateCandidate(ctx context.Context, candidate model.Candidate) (model.Candidate, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.candidates[candidate.ID]; ok || repository.emailExists(candidate.Email, candidate.ID) {
+		return model.Candidate{}, model.ErrCandidateAlreadyExists
+	}
+
+	repository.ids = append(repository.ids, candidate.ID)
+	repository.candidates[candidate.ID] = copyCandidate(candidate)
+
+	return candidate, nil
+}
+
+func (repository *memoryCandidateRepository) UpdateCandidate(ctx context.Context, id string, candidate model.Candidate) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	stored, ok := repository.candidates[id]
+	if !ok {
+		return model.ErrCandidateDoesNotExist
+	}
+	if stored.Version != candidate.Version {
+		return model.ErrCandidateVersionConflict
+	}
+	if repository.emailExists(candidate.Email, id) {
+		return model.ErrCandidateAlreadyExists
+	}
+
+	candidate.ID = id
+	candidate.LastMeetingTransition = stored.LastMeetingTransition
+	candidate.Version += 1
+	repository.candidates[id] = copyCandidate(candidate)
+
+	return nil
+}
+
+func (repository *memoryCandidateRepository) ReadCandidate(ctx context.Context, id string) (model.Candidate, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	candidate, ok := repository.candidates[id]
+	if !ok {
+		return model.Candidate{}, model.ErrCandidateDoesNotExist
+	}
+
+	return copyCandidate(candidate), nil
+}
+
+func (repository *memoryCandidateRepository) FindAllCandidates(ctx context.Context) ([]model.Candidate, error) {
+	return repository.findCandidates(func(c model.Candidate) bool { return true }), nil
+}
+
+func (repository *memoryCandidateRepository) FindCandidates(ctx context.Context, filter model.CandidateFilter) ([]model.Candidate, error) {
+	return repository.findCandidates(filter.Matches), nil
+}
+
+func (repository *memoryCandidateRepository) StreamCandidates(ctx context.Context, filter model.CandidateFilter, fn func(model.Candidate) error) error {
+	// the function is called after the candidates are copied, so it can use the repository
+	for _, candidate := range repository.findCandidates(filter.Matches) {
+		if err := fn(candidate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repository *memoryCandidateRepository) FindCandidateByEmail(ctx context.Context, email string) (model.Candidate, error) {
+	candidates := repository.findCandidates(func(c model.Candidate) bool { return c.Email == email })
+	if len(candidates) == 0 {
+		return model.Candidate{}, model.ErrCandidateDoesNotExist
+	}
+
+	return candidates[0], nil
+}
+
+func (repository *memoryCandidateRepository) FindAssigneesCandidates(ctx context.Context, id string) ([]model.Candidate, error) {
+	return repository.findCandidates(func(c model.Candidate) bool { return c.Assignee == id }), nil
+}
+
+func (repository *memoryCandidateRepository) DeleteCandidate(ctx context.Context, id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.candidates[id]; !ok {
+		return model.ErrCandidateDoesNotExist
+	}
+
+	delete(repository.candidates, id)
+	for i, candidateID := range repository.ids {
+		if candidateID == id {
+			repository.ids = append(repository.ids[:i], repository.ids[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (repository *memoryCandidateRepository) ArrangeMeeting(ctx context.Context, id string, version int64, meeting model.MeetingRecord) (model.Candidate, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	candidate, ok := repository.candidates[id]
+	if !ok {
+		return model.Candidate{}, model.ErrCandidateDoesNotExist
+	}
+	if candidate.Version != version {
+		return model.Candidate{}, model.ErrCandidateVersionConflict
+	}
+
+	nextMeeting := meeting.Time
+	candidate.NextMeeting = &nextMeeting
+	candidate.Assignee = meeting.AssigneeID
+	candidate.MeetingID = meeting.ID
+	candidate.LastMeetingTransition = meetingTransition(model.MeetingArrangedEvent, meeting.ID)
+	candidate.Version += 1
+
+	repository.candidates[id] = candidate
+	repository.meetings[meeting.ID] = meeting
+
+	return copyCandidate(candidate), nil
+}
+
+func (repository *memoryCandidateRepository) RescheduleMeeting(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	return repository.changeMeeting(id, version, change, model.MeetingRescheduledEvent, func(candidate *model.Candidate, meeting *model.MeetingRecord) {
+		nextMeeting := *change.Time
+		candidate.NextMeeting = &nextMeeting
+		meeting.Time = nextMeeting
+		meeting.Sequence += 1
+		meeting.ReminderSentAt = nil
+		meeting.Overdue = false
+	})
+}
+
+func (repository *memoryCandidateRepository) CancelMeeting(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	return repository.changeMeeting(id, version, change, model.MeetingCancelledEvent, func(candidate *model.Candidate, meeting *model.MeetingRecord) {
+		candidate.NextMeeting = nil
+		candidate.MeetingID = ""
+		meeting.Status = model.MeetingCancelled
+		meeting.Sequence += 1
+	})
+}
+
+func (repository *memoryCandidateRepository) RecordNoShow(ctx context.Context, id string, version int64, change model.MeetingChange) (model.Candidate, model.MeetingRecord, error) {
+	return repository.changeMeeting(id, version, change, model.MeetingNoShowEvent, func(candidate *model.Candidate, meeting *model.MeetingRecord) {
+		candidate.NextMeeting = nil
+		candidate.MeetingID = ""
+		candidate.NoShowCount += 1
+		meeting.Status = model.MeetingNoShow
+	})
+}
+
+// changeMeeting changes the arranged meeting of the candidate and its meeting record, and records the change in its history
+// and the transition of the given event on the candidate
+func (repository *memoryCandidateRepository) changeMeeting(id string, version int64, change model.MeetingChange, event string, apply func(*model.Candidate, *model.MeetingRecord)) (model.Candidate, model.MeetingRecord, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	candidate, ok := repository.candidates[id]
+	if !ok {
+		return model.Candidate{}, model.MeetingRecord{}, model.ErrCandidateDoesNotExist
+	}
+	if candidate.Version != version || candidate.NextMeeting == nil {
+		return model.Candidate{}, model.MeetingRecord{}, model.ErrCandidateVersionConflict
+	}
+
+	meeting, recorded := repository.meetings[candidate.MeetingID]
+	candidate.LastMeetingTransition = meetingTransition(event, meeting.ID)
+	apply(&candidate, &meeting)
+	candidate.Version += 1
+	repository.candidates[id] = candidate
+	if !recorded {
+		return copyCandidate(candidate), model.MeetingRecord{}, nil
+	}
+
+	meeting.Changes = append(append([]model.MeetingChange{}, meeting.Changes...), change)
+	repository.meetings[meeting.ID] = meeting
+
+	return copyCandidate(candidate), meeting, nil
+}
+
+func (repository *memoryCandidateRepository) CompleteMeeting(ctx context.Context, id string, completedAt time.Time) (model.Candidate, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	candidate, ok := repository.candidates[id]
+	if !ok {
+		return model.Candidate{}, model.ErrCandidateDoesNotExist
+	}
+	if candidate.NextMeeting == nil {
+		return model.Candidate{}, model.ErrArrangedMeetingDoesNotExist
+	}
+
+	if meeting, ok := repository.meetings[candidate.MeetingID]; ok {
+		meeting.Status = model.MeetingCompleted
+		meeting.CompletedAt = &completedAt
+		repository.meetings[meeting.ID] = meeting
+	}
+
+	// the meeting count is only incremented if the candidate has not completed all of the meetings yet
+	candidate.NextMeeting = nil
+	candidate.MeetingID = ""
+	candidate.LastMeetingTransition = meetingTransition(model.MeetingCompletedEvent, "")
+	if candidate.MeetingCount < 4 {
+		candidate.MeetingCount += 1
+		candidate.Status = model.InProgress
+	}
+	candidate.Version += 1
+	repository.candidates[id] = candidate
+
+	return copyCandidate(candidate), nil
+}
+
+func (repository *memoryCandidateRepository) ReadMeeting(ctx context.Context, id string) (model.MeetingRecord, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	meeting, ok := repository.meetings[id]
+	if !ok {
+		return model.MeetingRecord{}, model.ErrMeetingDoesNotExist
+	}
+
+	return meeting, nil
+}
+
+func (repository *memoryCandidateRepository) FindCandidatesMeetings(ctx context.Context, candidateID string) ([]model.MeetingRecord, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	meetings := []model.MeetingRecord{}
+	for _, meeting := range repository.meetings {
+		if meeting.CandidateID == candidateID {
+			meetings = append(meetings, meeting)
+		}
+	}
+	sort.Slice(meetings, func(i, j int) bool { return meetings[i].ArrangedAt.Before(meetings[j].ArrangedAt) })
+
+	return meetings, nil
+}
+
+func (repository *memoryCandidateRepository) FindAssigneesMeetings(ctx context.Context, assigneeID string, from time.Time) ([]model.MeetingRecord, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	meetings := []model.MeetingRecord{}
+	for _, meeting := range repository.meetings {
+		if meeting.AssigneeID == assigneeID && !meeting.Time.Before(from) {
+			meetings = append(meetings, meeting)
+		}
+	}
+	sort.Slice(meetings, func(i, j int) bool { return meetings[i].Time.Before(meetings[j].Time) })
+
+	return meetings, nil
+}
+
+func (repository *memoryCandidateRepository) FindMeetingsToRemind(ctx context.Context, from time.Time, to time.Time) ([]model.MeetingRecord, error) {
+	return repository.findMeetings(func(meeting model.MeetingRecord) bool {
+		return meeting.Status == model.MeetingArranged && !meeting.Time.Before(from) && meeting.Time.Before(to) && meeting.ReminderSentAt == nil
+	}), nil
+}
+
+func (repository *memoryCandidateRepository) MarkReminderSent(ctx context.Context, meetingID string, sentAt time.Time) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	meeting, ok := repository.meetings[meetingID]
+	if !ok || meeting.ReminderSentAt != nil {
+		return false, nil
+	}
+
+	meeting.ReminderSentAt = &sentAt
+	repository.meetings[meetingID] = meeting
+	return true, nil
+}
+
+func (repository *memoryCandidateRepository) RecordMeetingFeedback(ctx context.Context, meetingID string, score int) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	meeting, ok := repository.meetings[meetingID]
+	if !ok || meeting.Status != model.MeetingCompleted {
+		return model.ErrMeetingDoesNotExist
+	}
+
+	meeting.FeedbackScore = &score
+	repository.meetings[meetingID] = meeting
+	return nil
+}
+
+func (repository *memoryCandidateRepository) FlagOverdueMeetings(ctx context.Context, endedBefore time.Time) (int64, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	var flagged int64
+	for id, meeting := range repository.meetings {
+		if meeting.Status == model.MeetingArranged && meeting.Time.Before(endedBefore) && !meeting.Overdue {
+			meeting.Overdue = true
+			repository.meetings[id] = meeting
+			flagged++
+		}
+	}
+
+	return flagged, nil
+}
+
+func (repository *memoryCandidateRepository) FindOverdueMeetings(ctx context.Context) ([]model.MeetingRecord, error) {
+	return repository.findMeetings(func(meeting model.MeetingRecord) bool {
+		return meeting.Status == model.MeetingArranged && meeting.Overdue
+	}), nil
+}
+
+func (repository *memoryCandidateRepository) FindCandidatesToExpire(ctx context.Context, appliedBefore time.Time) ([]model.Candidate, error) {
+	return repository.findCandidates(func(c model.Candidate) bool {
+		return c.Status == model.Pending && c.ApplicationDate.Before(appliedBefore) && c.NextMeeting == nil
+	}), nil
+}
+
+// findMeetings returns the meeting records that match the given function, ordered by their time
+func (repository *memoryCandidateRepository) findMeetings(matches func(model.MeetingRecord) bool) []model.MeetingRecord {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	meetings := []model.MeetingRecord{}
+	for _, meeting := range repository.meetings {
+		if matches(meeting) {
+			meetings = append(meetings, meeting)
+		}
+	}
+	sort.Slice(meetings, func(i, j int) bool { return meetings[i].Time.Before(meetings[j].Time) })
+
+	return meetings
+}
+
+func (repository *memoryCandidateRepository) DeleteAllCandidates(ctx context.Context) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.candidates = make(map[string]model.Candidate)
+	repository.ids = nil
+	repository.meetings = make(map[string]model.MeetingRecord)
+
+	return nil
+}
+
+// findCandidates returns the candidates that match the given filter in insertion order
+func (repository *memoryCandidateRepository) findCandidates(filter func(c model.Candidate) bool) []model.Candidate {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	candidates := []model.Candidate{}
+	for _, id := range repository.ids {
+		if candidate := repository.candidates[id]; filter(candidate) {
+			candidates = append(candidates, copyCandidate(candidate))
+		}
+	}
+
+	return candidates
+}
+
+// emailExists checks another candidate than the one with given id has the given email, like the unique email index
+func (repository *memoryCandidateRepository) emailExists(email string, id string) bool {
+	for _, candidate := range repository.candidates {
+		if candidate.Email == email && candidate.ID != id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyCandidate copies the next meeting of the candidate, so the stored candidates cannot be changed through the returned ones
+func copyCandidate(candidate model.Candidate) model.Candidate {
+	if candidate.NextMeeting != nil {
+		nextMeeting := *candidate.NextMeeting
+		candidate.NextMeeting = &nextMeeting
+	}
+	if candidate.DecidedAt != nil {
+		decidedAt := *candidate.DecidedAt
+		candidate.DecidedAt = &decidedAt
+	}
+
+	return candidate
+}
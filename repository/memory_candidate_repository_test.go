@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemoryCandidateRepository(t *testing.T) {
+	newRepository := func() model.CandidateRepository {
+		repository := MemoryCandidateRepository()
+		_, _ = repository.CreateCandidate(context.TODO(), model.Candidate{ID: "abcd", Email: "e@e.com", Status: model.Pending})
+		return repository
+	}
+
+	t.Run("email-already-exists", func(t *testing.T) {
+		repository := newRepository()
+		_, err := repository.CreateCandidate(context.TODO(), model.Candidate{ID: "qwe", Email: "e@e.com"})
+		assert.Equal(t, model.ErrCandidateAlreadyExists, err)
+	})
+
+	t.Run("version-conflict", func(t *testing.T) {
+		repository := newRepository()
+		candidate, _ := repository.ReadCandidate(context.TODO(), "abcd")
+
+		assert.NoError(t, repository.UpdateCandidate(context.TODO(), "abcd", candidate))
+		assert.Equal(t, model.ErrCandidateVersionConflict, repository.UpdateCandidate(context.TODO(), "abcd", candidate))
+		assert.Equal(t, model.ErrCandidateDoesNotExist, repository.UpdateCandidate(context.TODO(), "qwe", candidate))
+	})
+
+	t.Run("arrange-and-complete-meeting", func(t *testing.T) {
+		repository := newRepository()
+		meeting := model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: time.Now(), Status: model.MeetingArranged}
+
+		_, err := repository.ArrangeMeeting(context.TODO(), "abcd", 1, meeting)
+		assert.Equal(t, model.ErrCandidateVersionConflict, err)
+
+		candidate, err := repository.ArrangeMeeting(context.TODO(), "abcd", 0, meeting)
+		assert.NoError(t, err)
+		assert.Equal(t, "m1", candidate.MeetingID)
+		assert.Equal(t, int64(1), candidate.Version)
+
+		candidate, err = repository.CompleteMeeting(context.TODO(), "abcd", time.Now())
+		assert.NoError(t, err)
+		assert.Nil(t, candidate.NextMeeting)
+		assert.Equal(t, 1, candidate.MeetingCount)
+		assert.Equal(t, model.InProgress, candidate.Status)
+
+		_, err = repository.CompleteMeeting(context.TODO(), "abcd", time.Now())
+		assert.Equal(t, model.ErrArrangedMeetingDoesNotExist, err)
+
+		record, err := repository.ReadMeeting(context.TODO(), "m1")
+		assert.NoError(t, err)
+		assert.Equal(t, model.MeetingCompleted, record.Status)
+		assert.NotNil(t, record.CompletedAt)
+
+		_, err = repository.ReadMeeting(context.TODO(), "m2")
+		assert.Equal(t, model.ErrMeetingDoesNotExist, err)
+	})
+
+	t.Run("reschedule-and-cancel-meeting", func(t *testing.T) {
+		repository := newRepository()
+		meetingTime := time.Now().Add(time.Hour)
+		_, _ = repository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: meetingTime, Status: model.MeetingArranged})
+
+		nextMeetingTime := meetingTime.Add(24 * time.Hour)
+		rescheduled := model.MeetingChange{Type: model.MeetingRescheduled, At: time.Now(), PreviousTime: meetingTime, Time: &nextMeetingTime}
+		_, _, err := repository.RescheduleMeeting(context.TODO(), "abcd", 0, rescheduled)
+		assert.Equal(t, model.ErrCandidateVersionConflict, err)
+
+		candidate, meeting, err := repository.RescheduleMeeting(context.TODO(), "abcd", 1, rescheduled)
+		assert.NoError(t, err)
+		assert.Equal(t, nextMeetingTime, *candidate.NextMeeting)
+		assert.Equal(t, "a1", candidate.Assignee)
+		assert.Equal(t, nextMeetingTime, meeting.Time)
+		assert.Equal(t, 1, meeting.Sequence)
+
+		cancelled := model.MeetingChange{Type: model.MeetingCancelled, At: time.Now(), PreviousTime: nextMeetingTime, Reason: "The assignee is on leave"}
+		candidate, meeting, err = repository.CancelMeeting(context.TODO(), "abcd", 2, cancelled)
+		assert.NoError(t, err)
+		assert.Nil(t, candidate.NextMeeting)
+		assert.Empty(t, candidate.MeetingID)
+		assert.Equal(t, model.MeetingCancelled, meeting.Status)
+		assert.Equal(t, 2, meeting.Sequence)
+
+		meetings, err := repository.FindCandidatesMeetings(context.TODO(), "abcd")
+		assert.NoError(t, err)
+		assert.Len(t, meetings, 1)
+		assert.Equal(t, []model.MeetingChange{rescheduled, cancelled}, meetings[0].Changes)
+
+		_, _, err = repository.CancelMeeting(context.TODO(), "abcd", 3, cancelled)
+		assert.Equal(t, model.ErrCandidateVersionConflict, err)
+
+		// only the latest transition is kept, and the updates of the candidate keep it
+		cancelledTransitionID, transition := candidate.LatestMeetingTransition()
+		assert.Equal(t, &model.MeetingTransition{Event: model.MeetingCancelledEvent, MeetingID: "m1"}, transition)
+		assert.Len(t, candidate.LastMeetingTransition, 1)
+		candidate.Status = model.Denied
+		assert.NoError(t, repository.UpdateCandidate(context.TODO(), "abcd", candidate))
+		candidate, _ = repository.ReadCandidate(context.TODO(), "abcd")
+		transitionID, _ := candidate.LatestMeetingTransition()
+		assert.Equal(t, cancelledTransitionID, transitionID)
+	})
+
+	t.Run("no-show", func(t *testing.T) {
+		repository := newRepository()
+		meetingTime := time.Now().Add(-time.Hour)
+		_, _ = repository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: meetingTime, Status: model.MeetingArranged})
+
+		candidate, meeting, err := repository.RecordNoShow(context.TODO(), "abcd", 1,
+			model.MeetingChange{Type: model.MeetingNoShow, At: time.Now(), PreviousTime: meetingTime})
+		assert.NoError(t, err)
+		assert.Nil(t, candidate.NextMeeting)
+		assert.Equal(t, 1, candidate.NoShowCount)
+		// the missed meeting is not counted
+		assert.Equal(t, 0, candidate.MeetingCount)
+		assert.Equal(t, model.MeetingNoShow, meeting.Status)
+		assert.Equal(t, 0, meeting.Sequence)
+	})
+
+	t.Run("assignees-meetings", func(t *testing.T) {
+		repository := newRepository()
+		_, _ = repository.CreateCandidate(context.TODO(), model.Candidate{ID: "efgh", Email: "f@f.com", Status: model.Pending})
+		now := time.Now()
+		_, _ = repository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: now.Add(2 * time.Hour), Status: model.MeetingArranged})
+		_, _ = repository.ArrangeMeeting(context.TODO(), "efgh", 0,
+			model.MeetingRecord{ID: "m2", CandidateID: "efgh", AssigneeID: "a1", Time: now.Add(time.Hour), Status: model.MeetingArranged})
+		_, _ = repository.CompleteMeeting(context.TODO(), "efgh", now)
+		_, _ = repository.ArrangeMeeting(context.TODO(), "efgh", 2,
+			model.MeetingRecord{ID: "m3", CandidateID: "efgh", AssigneeID: "a2", Time: now.Add(time.Hour), Status: model.MeetingArranged})
+
+		meetings, err := repository.FindAssigneesMeetings(context.TODO(), "a1", now)
+		assert.NoError(t, err)
+		assert.Len(t, meetings, 2)
+		assert.Equal(t, "m2", meetings[0].ID)
+		assert.Equal(t, "m1", meetings[1].ID)
+
+		meetings, _ = repository.FindAssigneesMeetings(context.TODO(), "a1", now.Add(90*time.Minute))
+		assert.Len(t, meetings, 1)
+	})
+
+	t.Run("reminders", func(t *testing.T) {
+		repository := newRepository()
+		now := time.Now()
+		_, _ = repository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: now.Add(time.Hour), Status: model.MeetingArranged})
+
+		meetings, err := repository.FindMeetingsToRemind(context.TODO(), now, now.Add(24*time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, meetings, 1)
+
+		marked, _ := repository.MarkReminderSent(context.TODO(), "m1", now)
+		assert.True(t, marked)
+		marked, _ = repository.MarkReminderSent(context.TODO(), "m1", now)
+		assert.False(t, marked)
+		meetings, _ = repository.FindMeetingsToRemind(context.TODO(), now, now.Add(24*time.Hour))
+		assert.Len(t, meetings, 0)
+
+		// the rescheduled meeting is reminded again
+		nextTime := now.Add(2 * time.Hour)
+		_, _, _ = repository.RescheduleMeeting(context.TODO(), "abcd", 1,
+			model.MeetingChange{Type: model.MeetingRescheduled, At: now, PreviousTime: now.Add(time.Hour), Time: &nextTime})
+		meetings, _ = repository.FindMeetingsToRemind(context.TODO(), now, now.Add(24*time.Hour))
+		assert.Len(t, meetings, 1)
+	})
+
+	t.Run("overdue-meetings", func(t *testing.T) {
+		repository := newRepository()
+		_, _ = repository.CreateCandidate(context.TODO(), model.Candidate{ID: "efgh", Email: "f@f.com", Status: model.Pending})
+		now := time.Now()
+		_, _ = repository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: now.Add(-3 * time.Hour), Status: model.MeetingArranged})
+		_, _ = repository.ArrangeMeeting(context.TODO(), "efgh", 0,
+			model.MeetingRecord{ID: "m2", CandidateID: "efgh", AssigneeID: "a1", Time: now.Add(-30 * time.Minute), Status: model.MeetingArranged})
+
+		flagged, err := repository.FlagOverdueMeetings(context.TODO(), now.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), flagged)
+		flagged, _ = repository.FlagOverdueMeetings(context.TODO(), now.Add(-time.Hour))
+		assert.Equal(t, int64(0), flagged)
+
+		meetings, err := repository.FindOverdueMeetings(context.TODO())
+		assert.NoError(t, err)
+		assert.Len(t, meetings, 1)
+		assert.Equal(t, "m1", meetings[0].ID)
+
+		_, _ = repository.CompleteMeeting(context.TODO(), "abcd", now)
+		meetings, _ = repository.FindOverdueMeetings(context.TODO())
+		assert.Len(t, meetings, 0)
+	})
+
+	t.Run("find-candidates-to-expire", func(t *testing.T) {
+		repository := MemoryCandidateRepository()
+		now := time.Now()
+		_, _ = repository.CreateCandidate(context.TODO(), model.Candidate{ID: "old", Email: "o@o.com", Status: model.Pending, ApplicationDate: now.AddDate(0, -2, 0)})
+		_, _ = repository.CreateCandidate(context.TODO(), model.Candidate{ID: "new", Email: "n@n.com", Status: model.Pending, ApplicationDate: now})
+		_, _ = repository.CreateCandidate(context.TODO(), model.Candidate{ID: "met", Email: "m@m.com", Status: model.Pending, ApplicationDate: now.AddDate(0, -2, 0)})
+		_, _ = repository.ArrangeMeeting(context.TODO(), "met", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "met", AssigneeID: "a1", Time: now.Add(time.Hour), Status: model.MeetingArranged})
+
+		candidates, err := repository.FindCandidatesToExpire(context.TODO(), now.AddDate(0, -1, 0))
+		assert.NoError(t, err)
+		assert.Len(t, candidates, 1)
+		assert.Equal(t, "old", candidates[0].ID)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		repository := newRepository()
+		_, _ = repository.CreateCandidate(context.TODO(), model.Candidate{ID: "efgh", FirstName: "Ayşe", Email: "ayse@e.com", University: "Go University"})
+
+		find := func(search string, attached ...string) []string {
+			terms := model.NewSearchTerms(search)
+			for i := range terms {
+				terms[i].AttachedCandidateIDs = attached
+			}
+			candidates, _ := repository.FindCandidates(context.TODO(), model.CandidateFilter{Search: terms})
+			ids := []string{}
+			for _, candidate := range candidates {
+				ids = append(ids, candidate.ID)
+			}
+			return ids
+		}
+
+		assert.Equal(t, []string{"efgh"}, find("go"))
+		assert.Equal(t, []string{"efgh"}, find("AYŞE university"))
+		// words are matched as a whole
+		assert.Empty(t, find("Ay"))
+		assert.Empty(t, find("go kubernetes"))
+		assert.Equal(t, []string{"abcd"}, find("kubernetes", "abcd"))
+	})
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"sync"
+	"time"
+)
+
+type memoryEventRepository struct {
+	mutex  sync.Mutex
+	events map[string]model.OutboxEvent
+}
+
+// MemoryEventRepository will create an implementation of Event Repository that keeps the events in memory
+func MemoryEventRepository() model.EventRepository {
+	return &memoryEventRepository{
+		events: make(map[string]model.OutboxEvent),
+	}
+}
+
+func (repository *memoryEventRepository) CreateEvent(ctx context.Context, event model.OutboxEvent) (model.OutboxEvent, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.events[event.ID] = copyOutboxEvent(event)
+
+	return event, nil
+}
+
+func (repository *memoryEventRepository) ClaimEvent(ctx context.Context, now time.Time, lease time.Duration) (model.OutboxEvent, bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	var claimed model.OutboxEvent
+	found := false
+	for _, event := range repository.events {
+		if event.Status != model.EventPending || event.NextAttemptAt.After(now) {
+			continue
+		}
+		// the events that are due at the same time are handled in the order of their ids, as they are in MongoDB
+		if !found || event.NextAttemptAt.Before(claimed.NextAttemptAt) ||
+			(event.NextAttemptAt.Equal(claimed.NextAttemptAt) && event.ID < claimed.ID) {
+			claimed = event
+			found = true
+		}
+	}
+	if !found {
+		return model.OutboxEvent{}, false, nil
+	}
+
+	claimed.NextAttemptAt = now.Add(lease)
+	claimed.Attempts += 1
+	repository.events[claimed.ID] = claimed
+
+	return copyOutboxEvent(claimed), true, nil
+}
+
+func (repository *memoryEventRepository) UpdateEvent(ctx context.Context, event model.OutboxEvent) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.events[event.ID] = copyOutboxEvent(event)
+
+	return nil
+}
+
+// copyOutboxEvent copies the subscriptions that have handled the event, so the stored event is not changed through the returned one
+func copyOutboxEvent(event model.OutboxEvent) model.OutboxEvent {
+	event.HandledBy = append([]string{}, event.HandledBy...)
+	return event
+}
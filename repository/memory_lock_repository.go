@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"sync"
+	"time"
+)
+
+type memoryLockRepository struct {
+	mutex sync.Mutex
+	locks map[string]model.Lock
+}
+
+// MemoryLockRepository will create an implementation of Lock Repository that keeps the locks in memory.
+// The locks are only shared by the schedulers of the same process.
+func MemoryLockRepository() model.LockRepository {
+	return &memoryLockRepository{
+		locks: make(map[string]model.Lock),
+	}
+}
+
+func (repository *memoryLockRepository) AcquireLock(ctx context.Context, name string, owner string, now time.Time, until time.Time) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if lock, ok := repository.locks[name]; ok && lock.Owner != owner && lock.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	repository.locks[name] = model.Lock{Name: name, Owner: owner, ExpiresAt: until}
+	return true, nil
+}
+
+func (repository *memoryLockRepository) ReleaseLock(ctx context.Context, name string, owner string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if lock, ok := repository.locks[name]; ok && lock.Owner == owner {
+		delete(repository.locks, name)
+	}
+
+	return nil
+}
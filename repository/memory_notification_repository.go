@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"sync"
+	"time"
+)
+
+type memoryNotificationRepository struct {
+	mutex         sync.Mutex
+	notifications map[string]model.Notification
+}
+
+// MemoryNotificationRepository will create an implementation of Notification Repository that keeps the notifications in memory
+func MemoryNotificationRepository() model.NotificationRepository {
+	return &memoryNotificationRepository{
+		notifications: make(map[string]model.Notification),
+	}
+}
+
+func (repository *memoryNotificationRepository) CreateNotification(ctx context.Context, notification model.Notification) (model.Notification, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.notifications[notification.ID] = notification
+
+	return notification, nil
+}
+
+func (repository *memoryNotificationRepository) ClaimNotification(ctx context.Context, now time.Time, lease time.Duration) (model.Notification, bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	var claimed model.Notification
+	found := false
+	for _, notification := range repository.notifications {
+		if notification.Status != model.NotificationPending || notification.NextAttemptAt.After(now) {
+			continue
+		}
+		if !found || notification.NextAttemptAt.Before(claimed.NextAttemptAt) {
+			claimed = notification
+			found = true
+		}
+	}
+	if !found {
+		return model.Notification{}, false, nil
+	}
+
+	claimed.NextAttemptAt = now.Add(lease)
+	claimed.Attempts += 1
+	repository.notifications[claimed.ID] = claimed
+
+	return claimed, true, nil
+}
+
+func (repository *memoryNotificationRepository) UpdateNotification(ctx context.Context, notification model.Notification) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.notifications[notification.ID] = notification
+
+	return nil
+}
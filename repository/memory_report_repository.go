@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"sort"
+	"time"
+)
+
+type memoryReportRepository struct {
+	candidateRepository model.CandidateRepository
+	assigneeRepository  model.AssigneeRepository
+}
+
+// MemoryReportRepository will create an implementation of Report Repository that aggregates the candidates, the meetings
+// and the assignees of the given repositories in memory, like the aggregations of MongoDB
+func MemoryReportRepository(candidateRepository model.CandidateRepository, assigneeRepository model.AssigneeRepository) model.ReportRepository {
+	return &memoryReportRepository{
+		candidateRepository: candidateRepository,
+		assigneeRepository:  assigneeRepository,
+	}
+}
+
+func (repository *memoryReportRepository) FunnelReport(ctx context.Context) (model.FunnelReport, error) {
+	var all, departments, universities []funnelRow
+	err := repository.candidateRepository.StreamCandidates(ctx, model.CandidateFilter{}, func(candidate model.Candidate) error {
+		all = append(all, newFunnelRow("", candidate))
+		departments = append(departments, newFunnelRow(candidate.Department, candidate))
+		universities = append(universities, newFunnelRow(candidate.University, candidate))
+		return nil
+	})
+	if err != nil {
+		return model.FunnelReport{}, err
+	}
+
+	report := model.FunnelReport{
+		FunnelCounts: newFunnelCounts(),
+		Departments:  funnelGroups(departments),
+		Universities: funnelGroups(universities),
+	}
+	for _, group := range funnelGroups(all) {
+		report.FunnelCounts = group.FunnelCounts
+	}
+
+	return report, nil
+}
+
+func (repository *memoryReportRepository) TimeToHireReport(ctx context.Context) (model.TimeToHireReport, error) {
+	times := make(map[string][]time.Duration)
+	undated := 0
+	err := repository.candidateRepository.StreamCandidates(ctx, model.CandidateFilter{}, func(candidate model.Candidate) error {
+		if candidate.Status != model.Accepted && candidate.Status != model.Denied {
+			return nil
+		}
+		if candidate.DecidedAt == nil {
+			undated++
+			return nil
+		}
+
+		// the times are truncated to milliseconds, like the dates of MongoDB
+		decisionTime := candidate.DecidedAt.Sub(candidate.ApplicationDate).Truncate(time.Millisecond)
+		times[""] = append(times[""], decisionTime)
+		times[candidate.Status] = append(times[candidate.Status], decisionTime)
+		return nil
+	})
+	if err != nil {
+		return model.TimeToHireReport{}, err
+	}
+
+	for _, statusTimes := range times {
+		sort.Slice(statusTimes, func(i, j int) bool { return statusTimes[i] < statusTimes[j] })
+	}
+	return model.TimeToHireReport{
+		Decided:  decisionTimes(times[""]),
+		Accepted: decisionTimes(times[model.Accepted]),
+		Denied:   decisionTimes(times[model.Denied]),
+		Undated:  undated,
+	}, nil
+}
+
+func (repository *memoryReportRepository) AssigneeWorkloads(ctx context.Context, filter model.WorkloadFilter, now time.Time) ([]model.AssigneeWorkload, error) {
+	var assignees []model.Assignee
+	var err error
+	if filter.Department != "" {
+		assignees, err = repository.assigneeRepository.FindAllAssigneesByDepartment(ctx, filter.Department)
+	} else {
+		assignees, err = repository.assigneeRepository.FindAllAssignees(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []workloadRow
+	for _, assignee := range assignees {
+		meetings, err := repository.candidateRepository.FindAssigneesMeetings(ctx, assignee.ID, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		candidates, err := repository.candidateRepository.FindAssigneesCandidates(ctx, assignee.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		row := workloadRow{AssigneeID: assignee.ID}
+		for _, meeting := range meetings {
+			switch {
+			case meeting.Status == model.MeetingArranged && meeting.Time.After(now):
+				row.Upcoming++
+			case meeting.Status == model.MeetingCompleted && meeting.CompletedAt != nil &&
+				!meeting.CompletedAt.Before(filter.From) && meeting.CompletedAt.Before(filter.To):
+				row.Completed++
+				if meeting.FeedbackScore != nil {
+					row.Scored++
+					row.Score += *meeting.FeedbackScore
+				}
+			}
+		}
+		for _, candidate := range candidates {
+			if candidate.Status == model.Pending || candidate.Status == model.InProgress {
+				row.Open++
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return assigneeWorkloads(assignees, rows), nil
+}
+
+// newFunnelRow counts the candidate in the funnel group with the name
+func newFunnelRow(name string, candidate model.Candidate) funnelRow {
+	row := funnelRow{Candidates: 1}
+	row.ID.Name = name
+	row.ID.Status = candidate.Status
+	row.ID.Meetings = candidate.MeetingCount
+	return row
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemoryReportRepository(t *testing.T) {
+	applied := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	decided := func(days int) *time.Time {
+		decidedAt := applied.AddDate(0, 0, days)
+		return &decidedAt
+	}
+
+	candidateRepository := MemoryCandidateRepository()
+	for _, candidate := range []model.Candidate{
+		{ID: "c1", Email: "c1@e.com", Department: model.Design, University: "Ankara", Status: model.Pending, Assignee: "a1"},
+		{ID: "c2", Email: "c2@e.com", Department: model.Design, University: "Hacettepe", Status: model.InProgress, MeetingCount: 2, Assignee: "a1"},
+		{ID: "c3", Email: "c3@e.com", Department: model.Development, University: "Ankara", Status: model.Denied, MeetingCount: 1, DecidedAt: decided(10), Assignee: "a2"},
+		{ID: "c4", Email: "c4@e.com", Department: model.Development, University: "Ankara", Status: model.Denied, MeetingCount: 2, DecidedAt: decided(20)},
+		{ID: "c5", Email: "c5@e.com", Department: model.Development, University: "Hacettepe", Status: model.Accepted, MeetingCount: 4, DecidedAt: decided(30)},
+		// the candidates that are decided before the decision times are recorded are not counted in the time to hire
+		{ID: "c6", Email: "c6@e.com", Department: model.Development, University: "Hacettepe", Status: model.Accepted, MeetingCount: 4},
+	} {
+		candidate.ApplicationDate = applied
+		_, _ = candidateRepository.CreateCandidate(context.TODO(), candidate)
+	}
+	assigneeRepository := MemoryAssigneeRepository()
+	_, _ = assigneeRepository.CreateAssignee(context.TODO(), model.Assignee{ID: "a1", Name: "Zafer", Department: model.Design})
+	_, _ = assigneeRepository.CreateAssignee(context.TODO(), model.Assignee{ID: "a2", Name: "Ayşe", Department: model.Development})
+	reportRepository := MemoryReportRepository(candidateRepository, assigneeRepository)
+
+	t.Run("funnel", func(t *testing.T) {
+		report, err := reportRepository.FunnelReport(context.TODO())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6, report.Candidates)
+		assert.Equal(t, map[string]int{model.Pending: 1, model.InProgress: 1, model.Denied: 2, model.Accepted: 2, model.Expired: 0}, report.Statuses)
+		assert.Equal(t, []int{1, 1, 2, 0, 2}, report.Meetings)
+
+		assert.Len(t, report.Departments, 2)
+		assert.Equal(t, model.Design, report.Departments[0].Name)
+		assert.Equal(t, 2, report.Departments[0].Candidates)
+		assert.Equal(t, []int{1, 0, 1, 0, 0}, report.Departments[0].Meetings)
+		assert.Equal(t, model.Development, report.Departments[1].Name)
+		assert.Equal(t, 2, report.Departments[1].Statuses[model.Accepted])
+
+		assert.Len(t, report.Universities, 2)
+		assert.Equal(t, "Ankara", report.Universities[0].Name)
+		assert.Equal(t, 3, report.Universities[0].Candidates)
+	})
+
+	t.Run("time-to-hire", func(t *testing.T) {
+		report, err := reportRepository.TimeToHireReport(context.TODO())
+
+		assert.NoError(t, err)
+		// the median and the 90th percentile are the nearest ranks of the times
+		assert.Equal(t, model.DecisionTimes{Candidates: 3, MedianDays: 20, P90Days: 30}, report.Decided)
+		assert.Equal(t, model.DecisionTimes{Candidates: 1, MedianDays: 30, P90Days: 30}, report.Accepted)
+		assert.Equal(t, model.DecisionTimes{Candidates: 2, MedianDays: 10, P90Days: 20}, report.Denied)
+		assert.Equal(t, 1, report.Undated)
+	})
+
+	t.Run("assignee-workloads", func(t *testing.T) {
+		now := time.Now()
+		interview := func(id string, meetingID string, at time.Time) {
+			candidate, _ := candidateRepository.ReadCandidate(context.TODO(), id)
+			_, _ = candidateRepository.ArrangeMeeting(context.TODO(), id, candidate.Version,
+				model.MeetingRecord{ID: meetingID, CandidateID: id, AssigneeID: candidate.Assignee, Time: at, Status: model.MeetingArranged})
+		}
+		interview("c1", "m1", now.Add(-time.Hour))
+		_, _ = candidateRepository.CompleteMeeting(context.TODO(), "c1", now.Add(-30*time.Minute))
+		_ = candidateRepository.RecordMeetingFeedback(context.TODO(), "m1", 4)
+		interview("c1", "m2", now.Add(24*time.Hour))
+		interview("c2", "m3", now.Add(48*time.Hour))
+		// the interview is completed before the time range of the report
+		interview("c3", "m4", now.AddDate(0, 0, -41))
+		_, _ = candidateRepository.CompleteMeeting(context.TODO(), "c3", now.AddDate(0, 0, -40))
+		_ = candidateRepository.RecordMeetingFeedback(context.TODO(), "m4", 2)
+
+		workloads, err := reportRepository.AssigneeWorkloads(context.TODO(), model.WorkloadFilter{From: now.AddDate(0, 0, -30), To: now}, now)
+
+		assert.NoError(t, err)
+		assert.Len(t, workloads, 2)
+		average := 4.0
+		assert.Equal(t, model.AssigneeWorkload{
+			Assignee:             model.Assignee{ID: "a1", Name: "Zafer", Department: model.Design},
+			UpcomingMeetings:     2,
+			CompletedInterviews:  1,
+			OpenCandidates:       2,
+			ScoredInterviews:     1,
+			AverageFeedbackScore: &average,
+		}, workloads[0])
+		assert.Equal(t, "a2", workloads[1].Assignee.ID)
+		assert.Equal(t, 0, workloads[1].CompletedInterviews)
+		assert.Nil(t, workloads[1].AverageFeedbackScore)
+
+		workloads, err = reportRepository.AssigneeWorkloads(context.TODO(), model.WorkloadFilter{Department: model.Development, From: now.AddDate(0, 0, -30), To: now}, now)
+
+		assert.NoError(t, err)
+		assert.Len(t, workloads, 1)
+		assert.Equal(t, "a2", workloads[0].Assignee.ID)
+	})
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryWebhookRepository struct {
+	mutex      sync.RWMutex
+	webhooks   map[string]model.Webhook
+	deliveries map[string]model.WebhookDelivery
+}
+
+// MemoryWebhookRepository will create an implementation of Webhook Repository that keeps the webhooks and their deliveries in memory
+func MemoryWebhookRepository() model.WebhookRepository {
+	return &memoryWebhookRepository{
+		webhooks:   make(map[string]model.Webhook),
+		deliveries: make(map[string]model.WebhookDelivery),
+	}
+}
+
+func (repository *memoryWebhookRepository) CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.webhooks[webhook.ID] = copyWebhook(webhook)
+
+	return webhook, nil
+}
+
+func (repository *memoryWebhookRepository) ReadWebhook(ctx context.Context, id string) (model.Webhook, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	webhook, ok := repository.webhooks[id]
+	if !ok {
+		return model.Webhook{}, model.ErrWebhookDoesNotExist
+	}
+
+	return copyWebhook(webhook), nil
+}
+
+func (repository *memoryWebhookRepository) FindAllWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	return repository.findWebhooks(func(webhook model.Webhook) bool { return true }), nil
+}
+
+func (repository *memoryWebhookRepository) FindWebhooksByEvent(ctx context.Context, event string) ([]model.Webhook, error) {
+	return repository.findWebhooks(func(webhook model.Webhook) bool {
+		return !webhook.Paused && subscribes(webhook, event)
+	}), nil
+}
+
+// findWebhooks returns the webhooks that match the given function, ordered by their creation time
+func (repository *memoryWebhookRepository) findWebhooks(matches func(model.Webhook) bool) []model.Webhook {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	webhooks := []model.Webhook{}
+	for _, webhook := range repository.webhooks {
+		if matches(webhook) {
+			webhooks = append(webhooks, copyWebhook(webhook))
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt) })
+
+	return webhooks
+}
+
+func (repository *memoryWebhookRepository) UpdateWebhook(ctx context.Context, webhook model.Webhook) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.webhooks[webhook.ID]; !ok {
+		return model.ErrWebhookDoesNotExist
+	}
+	repository.webhooks[webhook.ID] = copyWebhook(webhook)
+
+	return nil
+}
+
+func (repository *memoryWebhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.webhooks[id]; !ok {
+		return model.ErrWebhookDoesNotExist
+	}
+	delete(repository.webhooks, id)
+	for deliveryID, delivery := range repository.deliveries {
+		if delivery.WebhookID == id {
+			delete(repository.deliveries, deliveryID)
+		}
+	}
+
+	return nil
+}
+
+func (repository *memoryWebhookRepository) CreateDelivery(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.deliveries[delivery.ID]; !ok {
+		repository.deliveries[delivery.ID] = delivery
+	}
+
+	return delivery, nil
+}
+
+func (repository *memoryWebhookRepository) ClaimDelivery(ctx context.Context, now time.Time, lease time.Duration) (model.WebhookDelivery, bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	var claimed model.WebhookDelivery
+	found := false
+	for _, delivery := range repository.deliveries {
+		if delivery.Status != model.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		if !found || delivery.NextAttemptAt.Before(claimed.NextAttemptAt) {
+			claimed = delivery
+			found = true
+		}
+	}
+	if !found {
+		return model.WebhookDelivery{}, false, nil
+	}
+
+	claimed.NextAttemptAt = now.Add(lease)
+	claimed.Attempts += 1
+	repository.deliveries[claimed.ID] = claimed
+
+	return claimed, true, nil
+}
+
+func (repository *memoryWebhookRepository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	// the delivery of a deleted webhook is not recreated
+	if _, ok := repository.deliveries[delivery.ID]; ok {
+		repository.deliveries[delivery.ID] = delivery
+	}
+
+	return nil
+}
+
+func (repository *memoryWebhookRepository) FindWebhooksDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	deliveries := []model.WebhookDelivery{}
+	for _, delivery := range repository.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// copyWebhook copies the events of the webhook, so the stored webhook is not changed through the returned one
+func copyWebhook(webhook model.Webhook) model.Webhook {
+	webhook.Events = append([]string{}, webhook.Events...)
+	return webhook
+}
+
+// subscribes checks the webhook is subscribed to the event
+func subscribes(webhook model.Webhook, event string) bool {
+	for _, subscribed := range webhook.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
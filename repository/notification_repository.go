@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+type mongodbNotificationRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoDBNotificationRepository will create an implementation of Notification Repository with MongoDB
+func MongoDBNotificationRepository(collection *mongo.Collection) model.NotificationRepository {
+	return &mongodbNotificationRepository{
+		collection: collection,
+	}
+}
+
+func (repository *mongodbNotificationRepository) CreateNotification(ctx context.Context, notification model.Notification) (model.Notification, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbNotificationRepository.CreateNotification", "insertOne")
+	defer span.End()
+
+	_, err := repository.collection.InsertOne(ctx, notification)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return notification, err
+}
+
+// ClaimNotification takes the oldest due notification atomically, so concurrent dispatchers never send the same notification
+func (repository *mongodbNotificationRepository) ClaimNotification(ctx context.Context, now time.Time, lease time.Duration) (model.Notification, bool, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbNotificationRepository.ClaimNotification", "findAndModify")
+	defer span.End()
+
+	var notification model.Notification
+	err := repository.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"status": model.NotificationPending, "next_attempt_at": bson.M{"$lte": now}},
+		bson.D{
+			{"$set", bson.M{"next_attempt_at": now.Add(lease)}},
+			{"$inc", bson.M{"attempts": 1}},
+		},
+		options.FindOneAndUpdate().SetSort(bson.D{{"next_attempt_at", 1}}).SetReturnDocument(options.After),
+	).Decode(&notification)
+	if err == mongo.ErrNoDocuments {
+		return model.Notification{}, false, nil
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return model.Notification{}, false, err
+	}
+
+	return notification, true, nil
+}
+
+func (repository *mongodbNotificationRepository) UpdateNotification(ctx context.Context, notification model.Notification) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbNotificationRepository.UpdateNotification", "replaceOne")
+	defer span.End()
+
+	_, err := repository.collection.ReplaceOne(ctx, bson.D{{"_id", notification.ID}}, notification)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return err
+}
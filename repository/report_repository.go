@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// funnelStages is the number of the meeting stages of the funnels, the candidates complete up to 4 meetings
+const funnelStages = 5
+
+// funnelRow is the number of the candidates of a department or a university with the status and the completed meetings
+type funnelRow struct {
+	ID struct {
+		Name     string `bson:"name"`
+		Status   string `bson:"status"`
+		Meetings int    `bson:"meetings"`
+	} `bson:"_id"`
+	Candidates int `bson:"candidates"`
+}
+
+// decisionRow is the number of the decided candidates with the status and their decision times in milliseconds,
+// or of all of them if the status is empty
+type decisionRow struct {
+	Status     string `bson:"_id"`
+	Candidates int    `bson:"candidates"`
+	Median     int64  `bson:"median"`
+	P90        int64  `bson:"p90"`
+}
+
+// workloadRow are the meetings or the open candidates of an assignee, the scores of the interviews are summed up
+type workloadRow struct {
+	AssigneeID string `bson:"_id"`
+	Upcoming   int    `bson:"upcoming"`
+	Completed  int    `bson:"completed"`
+	Scored     int    `bson:"scored"`
+	Score      int    `bson:"score"`
+	Open       int    `bson:"open"`
+}
+
+type mongodbReportRepository struct {
+	candidatesCollection *mongo.Collection
+	assigneesCollection  *mongo.Collection
+	meetingsCollection   *mongo.Collection
+}
+
+// MongoDBReportRepository will create an implementation of Report Repository with MongoDB aggregations
+// The workloads of the assignees are aggregated from the given assignees and meetings collections
+func MongoDBReportRepository(candidatesCollection *mongo.Collection, assigneesCollection *mongo.Collection, meetingsCollection *mongo.Collection) model.ReportRepository {
+	return &mongodbReportRepository{
+		candidatesCollection: candidatesCollection,
+		assigneesCollection:  assigneesCollection,
+		meetingsCollection:   meetingsCollection,
+	}
+}
+
+// FunnelReport counts the candidates by their status and their completed meetings, once for all of the candidates,
+// and once for each department and university, in the facets of a single aggregation
+func (repository *mongodbReportRepository) FunnelReport(ctx context.Context) (model.FunnelReport, error) {
+	ctx, span := startSpan(ctx, repository.candidatesCollection, "mongodbReportRepository.FunnelReport", "aggregate")
+	defer span.End()
+
+	countBy := func(name interface{}) bson.A {
+		return bson.A{bson.D{{"$group", bson.D{
+			{"_id", bson.D{{"name", name}, {"status", "$status"}, {"meetings", "$meeting_count"}}},
+			{"candidates", bson.D{{"$sum", 1}}},
+		}}}}
+	}
+	pipeline := mongo.Pipeline{
+		{{"$facet", bson.D{
+			{"all", countBy("")},
+			{"departments", countBy("$department")},
+			{"universities", countBy("$university")},
+		}}},
+	}
+
+	var facets []struct {
+		All          []funnelRow `bson:"all"`
+		Departments  []funnelRow `bson:"departments"`
+		Universities []funnelRow `bson:"universities"`
+	}
+	cursor, err := repository.candidatesCollection.Aggregate(ctx, pipeline)
+	if err == nil {
+		err = cursor.All(ctx, &facets)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return model.FunnelReport{}, err
+	}
+
+	report := model.FunnelReport{FunnelCounts: newFunnelCounts()}
+	if len(facets) > 0 {
+		for _, group := range funnelGroups(facets[0].All) {
+			report.FunnelCounts = group.FunnelCounts
+		}
+		report.Departments = funnelGroups(facets[0].Departments)
+		report.Universities = funnelGroups(facets[0].Universities)
+	}
+
+	return report, nil
+}
+
+// TimeToHireReport counts the decided candidates of each status, and then picks the median and the 90th percentile
+// of their decision times by their ranks, for each status and for all of the decided candidates. Each rank is found
+// by sorting the decision times and skipping to it, so the times are never collected into a single document.
+// The decided candidates without decision times are counted separately.
+func (repository *mongodbReportRepository) TimeToHireReport(ctx context.Context) (model.TimeToHireReport, error) {
+	ctx, span := startSpan(ctx, repository.candidatesCollection, "mongodbReportRepository.TimeToHireReport", "aggregate")
+	defer span.End()
+
+	pipeline := mongo.Pipeline{
+		{{"$match", decidedCandidates("")}},
+		{{"$group", bson.D{{"_id", "$status"}, {"candidates", bson.D{{"$sum", 1}}}}}},
+	}
+
+	var rows []decisionRow
+	cursor, err := repository.candidatesCollection.Aggregate(ctx, pipeline)
+	if err == nil {
+		err = cursor.All(ctx, &rows)
+	}
+	decided := decisionRow{}
+	for _, row := range rows {
+		decided.Candidates += row.Candidates
+	}
+	rows = append(rows, decided)
+	var undated int64
+	if err == nil {
+		undated, err = repository.candidatesCollection.CountDocuments(ctx, bson.D{
+			{"status", bson.D{{"$in", bson.A{model.Accepted, model.Denied}}}},
+			{"decided_at", bson.D{{"$not", bson.D{{"$type", "date"}}}}},
+		})
+	}
+	for i := 0; err == nil && i < len(rows); i++ {
+		if rows[i].Candidates == 0 {
+			continue
+		}
+		rows[i].Median, err = repository.decisionTime(ctx, rows[i].Status, rank(rows[i].Candidates, 50))
+		if err == nil {
+			rows[i].P90, err = repository.decisionTime(ctx, rows[i].Status, rank(rows[i].Candidates, 90))
+		}
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return model.TimeToHireReport{}, err
+	}
+
+	report := model.TimeToHireReport{Undated: int(undated)}
+	for _, row := range rows {
+		times := model.DecisionTimes{
+			Candidates: row.Candidates,
+			MedianDays: days(time.Duration(row.Median) * time.Millisecond),
+			P90Days:    days(time.Duration(row.P90) * time.Millisecond),
+		}
+		switch row.Status {
+		case "":
+			report.Decided = times
+		case model.Accepted:
+			report.Accepted = times
+		case model.Denied:
+			report.Denied = times
+		}
+	}
+
+	return report, nil
+}
+
+// decisionTime finds the decision time in milliseconds at the index of the sorted decision times of the decided candidates
+// with the status, or of all of them if the status is empty. The sort is limited to the times up to the index.
+func (repository *mongodbReportRepository) decisionTime(ctx context.Context, status string, index int) (int64, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", decidedCandidates(status)}},
+		{{"$project", bson.D{{"time", bson.D{{"$subtract", bson.A{"$decided_at", "$application_date"}}}}}}},
+		{{"$sort", bson.D{{"time", 1}}}},
+		{{"$skip", index}},
+		{{"$limit", 1}},
+	}
+
+	var times []struct {
+		Time int64 `bson:"time"`
+	}
+	cursor, err := repository.candidatesCollection.Aggregate(ctx, pipeline)
+	if err == nil {
+		err = cursor.All(ctx, &times)
+	}
+	// the candidates that are deleted after they are counted are not found
+	if err != nil || len(times) == 0 {
+		return 0, err
+	}
+	return times[0].Time, nil
+}
+
+// decidedCandidates filters the decided candidates with the status, or all of them if the status is empty,
+// whose decision times are recorded
+func decidedCandidates(status string) bson.D {
+	statuses := bson.D{{"$in", bson.A{model.Accepted, model.Denied}}}
+	if status != "" {
+		statuses = bson.D{{"$eq", status}}
+	}
+	return bson.D{
+		{"status", statuses},
+		{"decided_at", bson.D{{"$type", "date"}}},
+	}
+}
+
+// AssigneeWorkloads finds the assignees of the department, and aggregates their meetings with the assignee_id_time index
+// and their open candidates. The upcoming meetings and the interviews completed in the time range are counted in the
+// same aggregation of the meetings.
+func (repository *mongodbReportRepository) AssigneeWorkloads(ctx context.Context, filter model.WorkloadFilter, now time.Time) ([]model.AssigneeWorkload, error) {
+	ctx, span := startSpan(ctx, repository.meetingsCollection, "mongodbReportRepository.AssigneeWorkloads", "aggregate")
+	defer span.End()
+
+	assigneeFilter := bson.D{}
+	if filter.Department != "" {
+		assigneeFilter = bson.D{{"department", filter.Department}}
+	}
+	assignees := []model.Assignee{}
+	cursor, err := repository.assigneesCollection.Find(ctx, assigneeFilter)
+	if err == nil {
+		err = cursor.All(ctx, &assignees)
+	}
+
+	ids := make(bson.A, 0, len(assignees))
+	for _, assignee := range assignees {
+		ids = append(ids, assignee.ID)
+	}
+	countIf := func(condition bson.D) bson.D {
+		return bson.D{{"$sum", bson.D{{"$cond", bson.A{condition, 1, 0}}}}}
+	}
+	meetingsPipeline := mongo.Pipeline{
+		{{"$match", bson.D{
+			{"assignee_id", bson.D{{"$in", ids}}},
+			{"$or", bson.A{
+				bson.D{{"status", model.MeetingArranged}, {"time", bson.D{{"$gt", now}}}},
+				bson.D{{"status", model.MeetingCompleted}, {"completed_at", bson.D{{"$gte", filter.From}, {"$lt", filter.To}}}},
+			}},
+		}}},
+		{{"$group", bson.D{
+			{"_id", "$assignee_id"},
+			{"upcoming", countIf(bson.D{{"$eq", bson.A{"$status", model.MeetingArranged}}})},
+			{"completed", countIf(bson.D{{"$eq", bson.A{"$status", model.MeetingCompleted}}})},
+			// the missing scores are less than null
+			{"scored", countIf(bson.D{{"$gt", bson.A{"$feedback_score", nil}}})},
+			{"score", bson.D{{"$sum", "$feedback_score"}}},
+		}}},
+	}
+	candidatesPipeline := mongo.Pipeline{
+		{{"$match", bson.D{
+			{"assignee", bson.D{{"$in", ids}}},
+			{"status", bson.D{{"$in", bson.A{model.Pending, model.InProgress}}}},
+		}}},
+		{{"$group", bson.D{{"_id", "$assignee"}, {"open", bson.D{{"$sum", 1}}}}}},
+	}
+
+	var meetingRows, candidateRows []workloadRow
+	if err == nil {
+		cursor, err = repository.meetingsCollection.Aggregate(ctx, meetingsPipeline)
+	}
+	if err == nil {
+		err = cursor.All(ctx, &meetingRows)
+	}
+	if err == nil {
+		cursor, err = repository.candidatesCollection.Aggregate(ctx, candidatesPipeline)
+	}
+	if err == nil {
+		err = cursor.All(ctx, &candidateRows)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return assigneeWorkloads(assignees, append(meetingRows, candidateRows...)), nil
+}
+
+// newFunnelCounts creates the funnel counts without any candidates
+func newFunnelCounts() model.FunnelCounts {
+	counts := model.FunnelCounts{
+		Statuses: make(map[string]int),
+		Meetings: make([]int, funnelStages),
+	}
+	for _, status := range model.GetStatusesAsArray() {
+		counts.Statuses[status] = 0
+	}
+	return counts
+}
+
+// funnelGroups adds up the rows of each name into a funnel group, and orders the groups by their names
+func funnelGroups(rows []funnelRow) []model.FunnelGroup {
+	groups := make(map[string]*model.FunnelGroup)
+	for _, row := range rows {
+		group, ok := groups[row.ID.Name]
+		if !ok {
+			group = &model.FunnelGroup{Name: row.ID.Name, FunnelCounts: newFunnelCounts()}
+			groups[row.ID.Name] = group
+		}
+
+		group.Candidates += row.Candidates
+		group.Statuses[row.ID.Status] += row.Candidates
+		for len(group.Meetings) <= row.ID.Meetings {
+			group.Meetings = append(group.Meetings, 0)
+		}
+		group.Meetings[row.ID.Meetings] += row.Candidates
+	}
+
+	result := make([]model.FunnelGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
+
+// decisionTimes picks the median and the 90th percentile of the sorted times by their ranks, like the aggregations
+func decisionTimes(times []time.Duration) model.DecisionTimes {
+	if len(times) == 0 {
+		return model.DecisionTimes{}
+	}
+
+	return model.DecisionTimes{
+		Candidates: len(times),
+		MedianDays: days(times[rank(len(times), 50)]),
+		P90Days:    days(times[rank(len(times), 90)]),
+	}
+}
+
+// rank finds the index of the percent of n sorted values. The rank of the percent is ceil(n * percent / 100),
+// and its index is one less.
+func rank(n int, percent int) int {
+	return (n*percent+99)/100 - 1
+}
+
+// assigneeWorkloads adds up the rows of each assignee into its workload, and orders the workloads by their upcoming
+// meetings and open candidates, from the busiest assignee to the least busy one. The rows of the other assignees are skipped.
+func assigneeWorkloads(assignees []model.Assignee, rows []workloadRow) []model.AssigneeWorkload {
+	workloads := make([]model.AssigneeWorkload, len(assignees))
+	indexes := make(map[string]int, len(assignees))
+	for i, assignee := range assignees {
+		workloads[i].Assignee = assignee
+		indexes[assignee.ID] = i
+	}
+
+	scores := make([]int, len(assignees))
+	for _, row := range rows {
+		i, ok := indexes[row.AssigneeID]
+		if !ok {
+			continue
+		}
+		workloads[i].UpcomingMeetings += row.Upcoming
+		workloads[i].CompletedInterviews += row.Completed
+		workloads[i].OpenCandidates += row.Open
+		workloads[i].ScoredInterviews += row.Scored
+		scores[i] += row.Score
+	}
+	for i := range workloads {
+		if workloads[i].ScoredInterviews > 0 {
+			average := math.Round(float64(scores[i])/float64(workloads[i].ScoredInterviews)*10) / 10
+			workloads[i].AverageFeedbackScore = &average
+		}
+	}
+
+	sort.SliceStable(workloads, func(i, j int) bool {
+		a, b := workloads[i], workloads[j]
+		if a.UpcomingMeetings != b.UpcomingMeetings {
+			return a.UpcomingMeetings > b.UpcomingMeetings
+		}
+		if a.OpenCandidates != b.OpenCandidates {
+			return a.OpenCandidates > b.OpenCandidates
+		}
+		return a.Assignee.Name < b.Assignee.Name
+	})
+
+	return workloads
+}
+
+// days converts the duration to days, rounded to a tenth of a day
+func days(duration time.Duration) float64 {
+	return math.Round(duration.Hours()/24*10) / 10
+}
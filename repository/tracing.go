@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/cemalunal/sample-internship-management-api/repository")
+
+// startSpan starts a client span for a MongoDB operation on the given collection
+func startSpan(ctx context.Context, collection *mongo.Collection, name string, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.name", collection.Database().Name()),
+			attribute.String("db.collection", collection.Name()),
+			attribute.String("db.operation", operation),
+		),
+	)
+}
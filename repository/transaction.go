@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"sync"
+	"time"
+)
+
+// topologyTimeout is the time that the topology of the server is checked in
+const topologyTimeout = 10 * time.Second
+
+// transactionKey is the key of the context of the functions that run in a transaction
+type transactionKey struct{}
+
+// transactionRunner runs functions in MongoDB transactions. Transactions are only supported
+// by replica sets and sharded clusters, so the functions run without a transaction
+// when the server is a standalone instance.
+type transactionRunner struct {
+	client    *mongo.Client
+	mutex     sync.Mutex
+	checked   bool
+	supported bool
+}
+
+func newTransactionRunner(client *mongo.Client) *transactionRunner {
+	return &transactionRunner{
+		client: client,
+	}
+}
+
+// MongoDBTransactionRunner creates a TransactionRunner of the collections of the client, so the services
+// can store the events in the same transaction as the changes of the repositories
+func MongoDBTransactionRunner(client *mongo.Client) model.TransactionRunner {
+	return newTransactionRunner(client)
+}
+
+// RunInTransaction runs fn in a transaction, see run
+func (runner *transactionRunner) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return runner.run(ctx, fn)
+}
+
+// run executes the given function in a transaction if the server supports them. The function is not run
+// if the topology of the server cannot be checked, so it never runs without a transaction on a replica set.
+// A function that is run in the transaction of another one, such as a change of the candidate that is run by the service
+// along with its events, takes part in that transaction.
+func (runner *transactionRunner) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(transactionKey{}) != nil {
+		return fn(ctx)
+	}
+
+	supported, err := runner.transactionsSupported()
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fn(ctx)
+	}
+
+	return runner.client.UseSession(ctx, func(sessionContext mongo.SessionContext) error {
+		_, err := sessionContext.WithTransaction(sessionContext, func(sessionContext mongo.SessionContext) (interface{}, error) {
+			return nil, fn(context.WithValue(sessionContext, transactionKey{}, true))
+		})
+		return err
+	})
+}
+
+// transactionsSupported checks the topology of the server until it is checked successfully. The check does not use
+// the context of the request, so a cancelled request does not fail the check of the others.
+func (runner *transactionRunner) transactionsSupported() (bool, error) {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+
+	if runner.checked {
+		return runner.supported, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), topologyTimeout)
+	defer cancel()
+
+	var result bson.M
+	err := runner.client.Database("admin").RunCommand(ctx, bson.D{{"isMaster", 1}}).Decode(&result)
+	if err != nil {
+		log.Println("Couldn't check whether MongoDB supports transactions: ", err)
+		return false, err
+	}
+
+	_, isReplicaSet := result["setName"]
+	isMongos := result["msg"] == "isdbgrid"
+	runner.checked = true
+	runner.supported = isReplicaSet || isMongos
+	if !runner.supported {
+		log.Println("MongoDB is a standalone server, meeting changes will not run in transactions")
+	}
+
+	return runner.supported, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+type mongodbWebhookRepository struct {
+	collection           *mongo.Collection
+	deliveriesCollection *mongo.Collection
+}
+
+// MongoDBWebhookRepository will create an implementation of Webhook Repository with MongoDB
+// The deliveries of the webhooks are kept in their own collection
+func MongoDBWebhookRepository(collection *mongo.Collection, deliveriesCollection *mongo.Collection) model.WebhookRepository {
+	return &mongodbWebhookRepository{
+		collection:           collection,
+		deliveriesCollection: deliveriesCollection,
+	}
+}
+
+func (repository *mongodbWebhookRepository) CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbWebhookRepository.CreateWebhook", "insertOne")
+	defer span.End()
+
+	_, err := repository.collection.InsertOne(ctx, webhook)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return webhook, err
+}
+
+func (repository *mongodbWebhookRepository) ReadWebhook(ctx context.Context, id string) (model.Webhook, error) {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbWebhookRepository.ReadWebhook", "findOne")
+	defer span.End()
+
+	var webhook model.Webhook
+	err := repository.collection.FindOne(ctx, bson.D{{"_id", id}}).Decode(&webhook)
+	if err == mongo.ErrNoDocuments {
+		return model.Webhook{}, model.ErrWebhookDoesNotExist
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return webhook, err
+}
+
+func (repository *mongodbWebhookRepository) FindAllWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	return repository.findWebhooks(ctx, "mongodbWebhookRepository.FindAllWebhooks", bson.D{})
+}
+
+func (repository *mongodbWebhookRepository) FindWebhooksByEvent(ctx context.Context, event string) ([]model.Webhook, error) {
+	return repository.findWebhooks(ctx, "mongodbWebhookRepository.FindWebhooksByEvent", bson.D{{"events", event}, {"paused", false}})
+}
+
+func (repository *mongodbWebhookRepository) findWebhooks(ctx context.Context, spanName string, filter bson.D) ([]model.Webhook, error) {
+	ctx, span := startSpan(ctx, repository.collection, spanName, "find")
+	defer span.End()
+
+	webhooks := []model.Webhook{}
+	cursor, err := repository.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{"created_at", 1}}))
+	if err == nil {
+		err = cursor.All(ctx, &webhooks)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (repository *mongodbWebhookRepository) UpdateWebhook(ctx context.Context, webhook model.Webhook) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbWebhookRepository.UpdateWebhook", "replaceOne")
+	defer span.End()
+
+	result, err := repository.collection.ReplaceOne(ctx, bson.D{{"_id", webhook.ID}}, webhook)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrWebhookDoesNotExist
+	}
+
+	return nil
+}
+
+func (repository *mongodbWebhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, repository.collection, "mongodbWebhookRepository.DeleteWebhook", "deleteOne")
+	defer span.End()
+
+	result, err := repository.collection.DeleteOne(ctx, bson.D{{"_id", id}})
+	if err == nil && result.DeletedCount == 0 {
+		return model.ErrWebhookDoesNotExist
+	}
+	if err == nil {
+		_, err = repository.deliveriesCollection.DeleteMany(ctx, bson.D{{"webhook_id", id}})
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return err
+}
+
+func (repository *mongodbWebhookRepository) CreateDelivery(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, repository.deliveriesCollection, "mongodbWebhookRepository.CreateDelivery", "insertOne")
+	defer span.End()
+
+	_, err := repository.deliveriesCollection.InsertOne(ctx, delivery)
+	if isDuplicateKeyError(err) {
+		// the delivery of the event is already created, e.g. by another replica
+		return delivery, nil
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return delivery, err
+}
+
+// ClaimDelivery takes the oldest due delivery atomically, so concurrent dispatchers never deliver the same event twice
+func (repository *mongodbWebhookRepository) ClaimDelivery(ctx context.Context, now time.Time, lease time.Duration) (model.WebhookDelivery, bool, error) {
+	ctx, span := startSpan(ctx, repository.deliveriesCollection, "mongodbWebhookRepository.ClaimDelivery", "findAndModify")
+	defer span.End()
+
+	var delivery model.WebhookDelivery
+	err := repository.deliveriesCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"status": model.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}},
+		bson.D{
+			{"$set", bson.M{"next_attempt_at": now.Add(lease)}},
+			{"$inc", bson.M{"attempts": 1}},
+		},
+		options.FindOneAndUpdate().SetSort(bson.D{{"next_attempt_at", 1}}).SetReturnDocument(options.After),
+	).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return model.WebhookDelivery{}, false, nil
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return model.WebhookDelivery{}, false, err
+	}
+
+	return delivery, true, nil
+}
+
+func (repository *mongodbWebhookRepository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	ctx, span := startSpan(ctx, repository.deliveriesCollection, "mongodbWebhookRepository.UpdateDelivery", "replaceOne")
+	defer span.End()
+
+	_, err := repository.deliveriesCollection.ReplaceOne(ctx, bson.D{{"_id", delivery.ID}}, delivery)
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+	}
+
+	return err
+}
+
+func (repository *mongodbWebhookRepository) FindWebhooksDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, repository.deliveriesCollection, "mongodbWebhookRepository.FindWebhooksDeliveries", "find")
+	defer span.End()
+
+	deliveries := []model.WebhookDelivery{}
+	cursor, err := repository.deliveriesCollection.Find(ctx,
+		bson.D{{"webhook_id", webhookID}},
+		options.Find().SetSort(bson.D{{"created_at", -1}}).SetLimit(int64(limit)),
+	)
+	if err == nil {
+		err = cursor.All(ctx, &deliveries)
+	}
+	if err != nil {
+		log.Println(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
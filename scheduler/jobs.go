@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"log"
+	"time"
+)
+
+// Reminders creates the job that reminds the candidates of their meetings that start within the given duration.
+// A meeting is marked as reminded before the candidate is notified, so the candidate is reminded at most once,
+// even if another scheduler takes over while the job is running. The candidates who are not met anymore, e.g. because
+// they are denied, and the meetings that are not the arranged meeting of their candidate anymore are not reminded.
+func Reminders(repository model.CandidateRepository, notifier model.Notifier, before time.Duration) Job {
+	return Job{
+		Name:     "reminders",
+		Interval: 5 * time.Minute,
+		Run: func(ctx context.Context, now time.Time) error {
+			meetings, err := repository.FindMeetingsToRemind(ctx, now, now.Add(before))
+			if err != nil {
+				return err
+			}
+
+			for _, meeting := range meetings {
+				candidate, err := repository.ReadCandidate(ctx, meeting.CandidateID)
+				if errors.Is(err, model.ErrCandidateDoesNotExist) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if (candidate.Status != model.Pending && candidate.Status != model.InProgress) || candidate.MeetingID != meeting.ID {
+					continue
+				}
+
+				marked, err := repository.MarkReminderSent(ctx, meeting.ID, now)
+				if err != nil {
+					return err
+				}
+				if !marked {
+					continue
+				}
+
+				meeting := meeting
+				event := model.CandidateEvent{Event: model.MeetingReminderEvent, Candidate: candidate, Meeting: &meeting}
+				if err := notifier.Notify(ctx, event); err != nil {
+					log.Printf("Couldn't remind candidate %s of meeting %s. Error is: %s\n", candidate.ID, meeting.ID, err)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// OverdueMeetings creates the job that flags the arranged meetings that have ended without being completed
+func OverdueMeetings(repository model.CandidateRepository) Job {
+	return Job{
+		Name:     "overdue-meetings",
+		Interval: 15 * time.Minute,
+		Run: func(ctx context.Context, now time.Time) error {
+			flagged, err := repository.FlagOverdueMeetings(ctx, now.Add(-model.MeetingDuration))
+			if err == nil && flagged > 0 {
+				log.Printf("Flagged %d overdue meetings\n", flagged)
+			}
+			return err
+		},
+	}
+}
+
+// PendingExpiry creates the job that expires the pending candidates that have applied more than the given age ago,
+// and have not had any meetings arranged since. The candidates are expired through the service, so the expirations
+// are published like the other changes of their statuses.
+func PendingExpiry(candidateService model.CandidateService, age time.Duration) Job {
+	return Job{
+		Name:     "pending-expiry",
+		Interval: time.Hour,
+		Run: func(ctx context.Context, now time.Time) error {
+			expired, err := candidateService.ExpirePendingCandidates(ctx, now.Add(-age))
+			if err == nil && expired > 0 {
+				log.Printf("Expired %d pending candidates\n", expired)
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"log"
+	"os"
+	"time"
+)
+
+var tracer = otel.Tracer("github.com/cemalunal/sample-internship-management-api/scheduler")
+
+// lockName is the name of the lock that elects the leader of the schedulers
+const lockName = "scheduler"
+
+// defaults of the scheduler
+const (
+	defaultTickInterval = time.Minute
+	defaultLease        = 3 * time.Minute
+)
+
+// Job is a periodic job of the scheduler. Run is called with the time of the tick, at most once in each Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, now time.Time) error
+}
+
+// Scheduler runs the jobs periodically in the background. When the application has several replicas, only the replica
+// that holds the lock of the scheduler runs the jobs. The leader extends the lease of the lock on each TickInterval,
+// and another replica takes the lock over when the lease is not extended for Lease, e.g. when the leader stops.
+type Scheduler struct {
+	locks   model.LockRepository
+	owner   string
+	jobs    []Job
+	lastRun map[string]time.Time
+	now     func() time.Time
+
+	TickInterval time.Duration
+	Lease        time.Duration
+}
+
+// NewScheduler creates a Scheduler of the jobs that is elected as the leader with the locks
+func NewScheduler(locks model.LockRepository, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		locks:        locks,
+		owner:        owner(),
+		jobs:         jobs,
+		lastRun:      make(map[string]time.Time),
+		now:          time.Now,
+		TickInterval: defaultTickInterval,
+		Lease:        defaultLease,
+	}
+}
+
+// Run runs the due jobs on each TickInterval until the context is done, and then releases the lock
+func (scheduler *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := scheduler.Tick(ctx); err != nil {
+			log.Println("Couldn't run the scheduled jobs: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			// the lock is released with a new context, since the context of the scheduler is done
+			if err := scheduler.locks.ReleaseLock(context.Background(), lockName, scheduler.owner); err != nil {
+				log.Println("Couldn't release the lock of the scheduler: ", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick runs the jobs whose interval has passed since their last run, if the scheduler is the leader.
+// It returns the names of the jobs that are run. The errors of the jobs are only logged, and they are run again
+// after their next interval.
+func (scheduler *Scheduler) Tick(ctx context.Context) ([]string, error) {
+	now := scheduler.now()
+	leader, err := scheduler.locks.AcquireLock(ctx, lockName, scheduler.owner, now, now.Add(scheduler.Lease))
+	if err != nil {
+		return nil, err
+	}
+	if !leader {
+		// the jobs are run right away if the scheduler becomes the leader again
+		scheduler.lastRun = make(map[string]time.Time)
+		return nil, nil
+	}
+
+	run := []string{}
+	for _, job := range scheduler.jobs {
+		if lastRun, ok := scheduler.lastRun[job.Name]; ok && now.Sub(lastRun) < job.Interval {
+			continue
+		}
+
+		scheduler.lastRun[job.Name] = now
+		scheduler.run(ctx, job, now)
+		run = append(run, job.Name)
+	}
+
+	return run, nil
+}
+
+// run runs the job and logs its error
+func (scheduler *Scheduler) run(ctx context.Context, job Job, now time.Time) {
+	ctx, span := tracer.Start(ctx, "Scheduler.run")
+	defer span.End()
+	span.SetAttributes(attribute.String("job.name", job.Name))
+
+	if err := job.Run(ctx, now); err != nil {
+		log.Printf("Couldn't run the %s job. Error is: %s\n", job.Name, err)
+		tracing.RecordError(span, err)
+	}
+}
+
+// owner identifies the scheduler of this process among the replicas
+func owner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return hostname + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/model/mocks"
+	"github.com/cemalunal/sample-internship-management-api/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestScheduler_Tick(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	newScheduler := func(locks model.LockRepository, runs *int) *Scheduler {
+		scheduler := NewScheduler(locks, Job{Name: "job", Interval: 10 * time.Minute, Run: func(ctx context.Context, now time.Time) error {
+			*runs++
+			return errors.New("job failed")
+		}})
+		scheduler.now = func() time.Time { return now }
+		return scheduler
+	}
+
+	t.Run("interval", func(t *testing.T) {
+		runs := 0
+		scheduler := newScheduler(repository.MemoryLockRepository(), &runs)
+
+		run, err := scheduler.Tick(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"job"}, run)
+
+		// the failed job is not run again before its interval
+		now = now.Add(5 * time.Minute)
+		run, _ = scheduler.Tick(context.TODO())
+		assert.Empty(t, run)
+
+		now = now.Add(5 * time.Minute)
+		run, _ = scheduler.Tick(context.TODO())
+		assert.Equal(t, []string{"job"}, run)
+		assert.Equal(t, 2, runs)
+	})
+
+	t.Run("leader-election", func(t *testing.T) {
+		locks := repository.MemoryLockRepository()
+		leaderRuns, followerRuns := 0, 0
+		leader := newScheduler(locks, &leaderRuns)
+		follower := newScheduler(locks, &followerRuns)
+
+		_, _ = leader.Tick(context.TODO())
+		run, err := follower.Tick(context.TODO())
+		assert.NoError(t, err)
+		assert.Empty(t, run)
+
+		// the follower takes over after the lease of the leader expires
+		now = now.Add(leader.Lease)
+		run, _ = follower.Tick(context.TODO())
+		assert.Equal(t, []string{"job"}, run)
+		run, _ = leader.Tick(context.TODO())
+		assert.Empty(t, run)
+		assert.Equal(t, 1, leaderRuns)
+		assert.Equal(t, 1, followerRuns)
+	})
+
+	t.Run("released-lock", func(t *testing.T) {
+		locks := repository.MemoryLockRepository()
+		leaderRuns, followerRuns := 0, 0
+		leader := newScheduler(locks, &leaderRuns)
+		follower := newScheduler(locks, &followerRuns)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		leader.Run(ctx)
+
+		run, _ := follower.Tick(context.TODO())
+		assert.Equal(t, []string{"job"}, run)
+	})
+}
+
+func TestJobs(t *testing.T) {
+	now := time.Now()
+	newRepository := func() model.CandidateRepository {
+		candidateRepository := repository.MemoryCandidateRepository()
+		_, _ = candidateRepository.CreateCandidate(context.TODO(), model.Candidate{ID: "abcd", Email: "e@e.com", Status: model.Pending, ApplicationDate: now.AddDate(0, -2, 0)})
+		return candidateRepository
+	}
+
+	t.Run("reminders", func(t *testing.T) {
+		candidateRepository := newRepository()
+		_, _ = candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: now.Add(time.Hour), Status: model.MeetingArranged})
+		notifier := &mocks.Notifier{}
+		notifier.On("Notify", mock.Anything, mock.MatchedBy(func(event model.CandidateEvent) bool {
+			return event.Event == model.MeetingReminderEvent && event.Candidate.ID == "abcd" && event.Meeting.ID == "m1"
+		})).Return(nil).Once()
+
+		job := Reminders(candidateRepository, notifier, 24*time.Hour)
+		assert.NoError(t, job.Run(context.TODO(), now))
+		// the candidate is reminded only once
+		assert.NoError(t, job.Run(context.TODO(), now))
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("reminders-of-deleted-candidates", func(t *testing.T) {
+		candidateRepository := newRepository()
+		_, _ = candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: now.Add(time.Hour), Status: model.MeetingArranged})
+		_ = candidateRepository.DeleteCandidate(context.TODO(), "abcd")
+		notifier := &mocks.Notifier{}
+
+		assert.NoError(t, Reminders(candidateRepository, notifier, 24*time.Hour).Run(context.TODO(), now))
+		notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reminders-of-closed-candidates", func(t *testing.T) {
+		candidateRepository := newRepository()
+		_, _ = candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: now.Add(time.Hour), Status: model.MeetingArranged})
+		candidate, _ := candidateRepository.ReadCandidate(context.TODO(), "abcd")
+		candidate.Status = model.Denied
+		_ = candidateRepository.UpdateCandidate(context.TODO(), "abcd", candidate)
+		notifier := &mocks.Notifier{}
+
+		assert.NoError(t, Reminders(candidateRepository, notifier, 24*time.Hour).Run(context.TODO(), now))
+		notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reminders-of-stale-meetings", func(t *testing.T) {
+		candidateRepository := newRepository()
+		_, _ = candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: now.Add(time.Hour), Status: model.MeetingArranged})
+		// the candidate has moved on to another meeting, while the first one is still recorded as arranged
+		candidate, _ := candidateRepository.ReadCandidate(context.TODO(), "abcd")
+		candidate.MeetingID = "m2"
+		_ = candidateRepository.UpdateCandidate(context.TODO(), "abcd", candidate)
+		notifier := &mocks.Notifier{}
+
+		assert.NoError(t, Reminders(candidateRepository, notifier, 24*time.Hour).Run(context.TODO(), now))
+		notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+	})
+
+	t.Run("overdue-meetings", func(t *testing.T) {
+		candidateRepository := newRepository()
+		_, _ = candidateRepository.ArrangeMeeting(context.TODO(), "abcd", 0,
+			model.MeetingRecord{ID: "m1", CandidateID: "abcd", AssigneeID: "a1", Time: now.Add(-90 * time.Minute), Status: model.MeetingArranged})
+
+		assert.NoError(t, OverdueMeetings(candidateRepository).Run(context.TODO(), now))
+		meetings, _ := candidateRepository.FindOverdueMeetings(context.TODO())
+		assert.Len(t, meetings, 1)
+	})
+
+	t.Run("pending-expiry", func(t *testing.T) {
+		candidateService := &mocks.CandidateService{}
+		candidateService.On("ExpirePendingCandidates", mock.Anything, now.Add(-30*24*time.Hour)).Return(1, nil).Once()
+
+		assert.NoError(t, PendingExpiry(candidateService, 30*24*time.Hour).Run(context.TODO(), now))
+		candidateService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,53 @@
+package seed
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+)
+
+// Dump reads all assignees and candidates into a fixture, which can be loaded again with Load
+func Dump(ctx context.Context, assigneeRepository model.AssigneeRepository, candidateRepository model.CandidateRepository) (Fixture, error) {
+	assignees, err := assigneeRepository.FindAllAssignees(ctx)
+	if err != nil {
+		return Fixture{}, err
+	}
+	candidates, err := candidateRepository.FindAllCandidates(ctx)
+	if err != nil {
+		return Fixture{}, err
+	}
+
+	fixture := Fixture{
+		Departments: model.GetDepartmentsAsArray(),
+		Assignees:   []AssigneeFixture{},
+		Candidates:  []CandidateFixture{},
+	}
+
+	names := make(map[string]string)
+	for _, a := range assignees {
+		names[a.ID] = a.Name
+		fixture.Assignees = append(fixture.Assignees, AssigneeFixture{ID: a.ID, Name: a.Name, Department: a.Department})
+	}
+
+	for _, c := range candidates {
+		applicationDate := c.ApplicationDate
+		fixture.Candidates = append(fixture.Candidates, CandidateFixture{
+			ID:              c.ID,
+			FirstName:       c.FirstName,
+			LastName:        c.LastName,
+			Email:           c.Email,
+			Department:      c.Department,
+			University:      c.University,
+			Experience:      c.Experience,
+			ApplicationDate: &applicationDate,
+			Status:          c.Status,
+			DecidedAt:       c.DecidedAt,
+			MeetingCount:    c.MeetingCount,
+			NoShowCount:     c.NoShowCount,
+			NextMeeting:     c.NextMeeting,
+			// the assignees that do not exist anymore are left out
+			Assignee: names[c.Assignee],
+		})
+	}
+
+	return fixture, nil
+}
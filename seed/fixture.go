@@ -0,0 +1,94 @@
+package seed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Fixture is the human-readable format of the sample data.
+// The assignees of the candidates are referred by their names.
+type Fixture struct {
+	Departments []string           `json:"departments" yaml:"departments"`
+	Assignees   []AssigneeFixture  `json:"assignees" yaml:"assignees"`
+	Candidates  []CandidateFixture `json:"candidates" yaml:"candidates"`
+}
+
+// AssigneeFixture is an assignee in a fixture. A new id is generated when the id is empty.
+type AssigneeFixture struct {
+	ID         string `json:"id,omitempty" yaml:"id,omitempty"`
+	Name       string `json:"name" yaml:"name"`
+	Department string `json:"department" yaml:"department"`
+}
+
+// CandidateFixture is a candidate in a fixture. A new id is generated when the id is empty,
+// the status is Pending when it is empty, and the application date is the creation time of the id when it is empty.
+type CandidateFixture struct {
+	ID              string     `json:"id,omitempty" yaml:"id,omitempty"`
+	FirstName       string     `json:"first_name" yaml:"first_name"`
+	LastName        string     `json:"last_name" yaml:"last_name"`
+	Email           string     `json:"email" yaml:"email"`
+	Department      string     `json:"department" yaml:"department"`
+	University      string     `json:"university" yaml:"university"`
+	Experience      bool       `json:"experience" yaml:"experience"`
+	ApplicationDate *time.Time `json:"application_date,omitempty" yaml:"application_date,omitempty"`
+	Status          string     `json:"status,omitempty" yaml:"status,omitempty"`
+	DecidedAt       *time.Time `json:"decided_at,omitempty" yaml:"decided_at,omitempty"`
+	MeetingCount    int        `json:"meeting_count" yaml:"meeting_count"`
+	NoShowCount     int        `json:"no_show_count,omitempty" yaml:"no_show_count,omitempty"`
+	NextMeeting     *time.Time `json:"next_meeting,omitempty" yaml:"next_meeting,omitempty"`
+	Assignee        string     `json:"assignee,omitempty" yaml:"assignee,omitempty"`
+}
+
+// ReadFile reads a fixture from a YAML (.yaml, .yml) or JSON (.json) file
+func ReadFile(path string) (Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Fixture{}, err
+	}
+
+	fixture, err := Parse(data, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return Fixture{}, fmt.Errorf("couldn't read %s: %w", path, err)
+	}
+
+	return fixture, nil
+}
+
+// Parse parses a fixture in the given format, yaml or json
+func Parse(data []byte, format string) (Fixture, error) {
+	var fixture Fixture
+	var err error
+
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&fixture)
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&fixture)
+	default:
+		err = fmt.Errorf("unknown fixture format %q, expected yaml or json", format)
+	}
+
+	return fixture, err
+}
+
+// Encode writes the fixture in the given format, yaml or json
+func Encode(fixture Fixture, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		return yaml.Marshal(fixture)
+	case "json":
+		return json.MarshalIndent(fixture, "", "  ")
+	}
+
+	return nil, fmt.Errorf("unknown fixture format %q, expected yaml or json", format)
+}
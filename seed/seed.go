@@ -0,0 +1,218 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"time"
+)
+
+// Options changes how a fixture is loaded
+type Options struct {
+	// Reset deletes all assignees, candidates and meeting records before loading the fixture
+	Reset bool
+}
+
+// Result is the summary of a loaded fixture
+type Result struct {
+	AssigneesCreated  int
+	CandidatesCreated int
+	// Skipped is the number of the assignees (by name) and candidates (by email) that already exist
+	Skipped int
+}
+
+// Load loads the fixture through the given repositories, so it works with any storage backend.
+// The candidates are stored in their state in the fixture, and a meeting record is arranged
+// for their next meetings. Existing records are skipped, so a fixture can be loaded again.
+func Load(ctx context.Context, assigneeRepository model.AssigneeRepository, candidateRepository model.CandidateRepository,
+	fixture Fixture, options Options) (Result, error) {
+
+	var result Result
+	if err := Validate(fixture); err != nil {
+		return result, err
+	}
+
+	if options.Reset {
+		if err := candidateRepository.DeleteAllCandidates(ctx); err != nil {
+			return result, err
+		}
+		if err := assigneeRepository.DeleteAllAssignees(ctx); err != nil {
+			return result, err
+		}
+	}
+
+	assigneeIDs := make(map[string]string)
+	for _, a := range fixture.Assignees {
+		id, err := assigneeRepository.FindAssigneeIDByName(ctx, a.Name)
+		if err == nil {
+			assigneeIDs[a.Name] = id
+			result.Skipped++
+			continue
+		}
+		if !errors.Is(err, model.ErrAssigneeDoesNotExist) {
+			return result, err
+		}
+
+		assignee, err := assigneeRepository.CreateAssignee(ctx, model.Assignee{
+			ID:         idOrNew(a.ID),
+			Name:       a.Name,
+			Department: a.Department,
+		})
+		if err != nil {
+			return result, err
+		}
+		assigneeIDs[a.Name] = assignee.ID
+		result.AssigneesCreated++
+	}
+
+	for _, c := range fixture.Candidates {
+		assigneeID, err := resolveAssignee(ctx, assigneeRepository, assigneeIDs, c.Assignee)
+		if err != nil {
+			return result, fmt.Errorf("candidate %s: %w", c.Email, err)
+		}
+
+		candidate := newCandidate(c, assigneeID)
+		candidate, err = candidateRepository.CreateCandidate(ctx, candidate)
+		if errors.Is(err, model.ErrCandidateAlreadyExists) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if c.NextMeeting != nil {
+			_, err = candidateRepository.ArrangeMeeting(ctx, candidate.ID, candidate.Version, model.MeetingRecord{
+				ID:          primitive.NewObjectID().Hex(),
+				CandidateID: candidate.ID,
+				AssigneeID:  assigneeID,
+				Time:        *c.NextMeeting,
+				Status:      model.MeetingArranged,
+				ArrangedAt:  time.Now(),
+			})
+			if err != nil {
+				return result, err
+			}
+		}
+		result.CandidatesCreated++
+	}
+
+	log.Printf("Seeded %d assignees and %d candidates, skipped %d existing records\n",
+		result.AssigneesCreated, result.CandidatesCreated, result.Skipped)
+
+	return result, nil
+}
+
+// Validate checks the departments and statuses of the fixture, and the assignee names that the candidates refer to
+func Validate(fixture Fixture) error {
+	departments := make(map[string]bool)
+	for _, department := range fixture.Departments {
+		if !contains(model.GetDepartmentsAsArray(), department) {
+			return fmt.Errorf("department %s: %w", department, model.ErrDepartmentDoesNotExist)
+		}
+		departments[department] = true
+	}
+	// the departments of the fixture are optional, all departments of the system are allowed without them
+	checkDepartment := func(department string) error {
+		if !contains(model.GetDepartmentsAsArray(), department) || (len(departments) > 0 && !departments[department]) {
+			return fmt.Errorf("department %s: %w", department, model.ErrDepartmentDoesNotExist)
+		}
+		return nil
+	}
+
+	names := make(map[string]bool)
+	for _, a := range fixture.Assignees {
+		if a.Name == "" {
+			return fmt.Errorf("assignee name is required")
+		}
+		if names[a.Name] {
+			return fmt.Errorf("assignee %s is declared twice", a.Name)
+		}
+		if err := checkDepartment(a.Department); err != nil {
+			return fmt.Errorf("assignee %s: %w", a.Name, err)
+		}
+		names[a.Name] = true
+	}
+
+	emails := make(map[string]bool)
+	for _, c := range fixture.Candidates {
+		if c.Email == "" {
+			return fmt.Errorf("candidate email is required")
+		}
+		if emails[c.Email] {
+			return fmt.Errorf("candidate %s: %w", c.Email, model.ErrCandidateAlreadyExists)
+		}
+		if err := checkDepartment(c.Department); err != nil {
+			return fmt.Errorf("candidate %s: %w", c.Email, err)
+		}
+		if c.Status != "" && !contains(model.GetStatusesAsArray(), c.Status) {
+			return fmt.Errorf("candidate %s has unknown status %s", c.Email, c.Status)
+		}
+		emails[c.Email] = true
+	}
+
+	return nil
+}
+
+// resolveAssignee finds the id of the assignee with given name, in the fixture or in the repository
+func resolveAssignee(ctx context.Context, assigneeRepository model.AssigneeRepository, assigneeIDs map[string]string, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	if id, ok := assigneeIDs[name]; ok {
+		return id, nil
+	}
+
+	return assigneeRepository.FindAssigneeIDByName(ctx, name)
+}
+
+func newCandidate(c CandidateFixture, assigneeID string) model.Candidate {
+	candidate := model.Candidate{
+		ID:           idOrNew(c.ID),
+		FirstName:    c.FirstName,
+		LastName:     c.LastName,
+		Email:        c.Email,
+		Department:   c.Department,
+		University:   c.University,
+		Experience:   c.Experience,
+		Status:       c.Status,
+		DecidedAt:    c.DecidedAt,
+		MeetingCount: c.MeetingCount,
+		NoShowCount:  c.NoShowCount,
+		Assignee:     assigneeID,
+	}
+
+	if candidate.Status == "" {
+		candidate.Status = model.Pending
+	}
+	if c.ApplicationDate != nil {
+		candidate.ApplicationDate = *c.ApplicationDate
+	} else if objectID, err := primitive.ObjectIDFromHex(candidate.ID); err == nil {
+		candidate.ApplicationDate = objectID.Timestamp()
+	} else {
+		candidate.ApplicationDate = time.Now()
+	}
+
+	return candidate
+}
+
+func idOrNew(id string) string {
+	if id == "" {
+		return primitive.NewObjectID().Hex()
+	}
+
+	return id
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
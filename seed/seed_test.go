@@ -0,0 +1,125 @@
+package seed
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const sampleFixture = "../fixtures/sample.yaml"
+
+func TestSeed_LoadForTest(t *testing.T) {
+	assigneeRepository, candidateRepository := LoadForTest(t, sampleFixture)
+
+	assignees, _ := assigneeRepository.FindAllAssignees(context.TODO())
+	candidates, _ := candidateRepository.FindAllCandidates(context.TODO())
+	assert.Len(t, assignees, 8)
+	assert.Len(t, candidates, 22)
+
+	zaferID, err := assigneeRepository.FindAssigneeIDByName(context.TODO(), "Zafer")
+	assert.NoError(t, err)
+	assert.Equal(t, "5c191acea7948900011168d4", zaferID)
+
+	candidate, err := candidateRepository.ReadCandidate(context.TODO(), "5b75881051d9590001def62a")
+	assert.NoError(t, err)
+	assert.Equal(t, model.InProgress, candidate.Status)
+	assert.Equal(t, 3, candidate.MeetingCount)
+	assert.Equal(t, zaferID, candidate.Assignee)
+	assert.NotNil(t, candidate.NextMeeting)
+	assert.NotEmpty(t, candidate.MeetingID)
+	assert.False(t, candidate.ApplicationDate.IsZero())
+}
+
+func TestSeed_Load(t *testing.T) {
+	fixture := Fixture{
+		Assignees: []AssigneeFixture{{Name: "Sercan", Department: model.Development}},
+		Candidates: []CandidateFixture{
+			{Email: "a@a.com", Department: model.Development, Assignee: "Sercan"},
+			{Email: "b@b.com", Department: model.Development},
+		},
+	}
+
+	t.Run("existing-records-are-skipped", func(t *testing.T) {
+		assigneeRepository := repository.MemoryAssigneeRepository()
+		candidateRepository := repository.MemoryCandidateRepository()
+
+		_, err := Load(context.TODO(), assigneeRepository, candidateRepository, fixture, Options{})
+		assert.NoError(t, err)
+		result, err := Load(context.TODO(), assigneeRepository, candidateRepository, fixture, Options{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, Result{Skipped: 3}, result)
+		candidate, _ := candidateRepository.FindCandidateByEmail(context.TODO(), "b@b.com")
+		assert.Equal(t, model.Pending, candidate.Status)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		assigneeRepository, candidateRepository := LoadForTest(t, sampleFixture)
+
+		result, err := Load(context.TODO(), assigneeRepository, candidateRepository, fixture, Options{Reset: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, Result{AssigneesCreated: 1, CandidatesCreated: 2}, result)
+		candidates, _ := candidateRepository.FindAllCandidates(context.TODO())
+		assert.Len(t, candidates, 2)
+	})
+
+	t.Run("assignee-does-not-exist", func(t *testing.T) {
+		invalid := Fixture{Candidates: []CandidateFixture{{Email: "a@a.com", Department: model.Design, Assignee: "Nobody"}}}
+
+		_, err := Load(context.TODO(), repository.MemoryAssigneeRepository(), repository.MemoryCandidateRepository(), invalid, Options{})
+
+		assert.ErrorIs(t, err, model.ErrAssigneeDoesNotExist)
+	})
+}
+
+func TestSeed_Validate(t *testing.T) {
+	t.Run("unknown-department", func(t *testing.T) {
+		err := Validate(Fixture{Assignees: []AssigneeFixture{{Name: "Sercan", Department: "test"}}})
+		assert.ErrorIs(t, err, model.ErrDepartmentDoesNotExist)
+	})
+
+	t.Run("department-is-not-in-fixture", func(t *testing.T) {
+		err := Validate(Fixture{
+			Departments: []string{model.Design},
+			Candidates:  []CandidateFixture{{Email: "a@a.com", Department: model.Development}},
+		})
+		assert.ErrorIs(t, err, model.ErrDepartmentDoesNotExist)
+	})
+
+	t.Run("duplicate-email", func(t *testing.T) {
+		err := Validate(Fixture{Candidates: []CandidateFixture{
+			{Email: "a@a.com", Department: model.Design},
+			{Email: "a@a.com", Department: model.Design},
+		}})
+		assert.ErrorIs(t, err, model.ErrCandidateAlreadyExists)
+	})
+}
+
+func TestSeed_Parse(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		fixture, err := Parse([]byte(`{"assignees": [{"name": "Sercan", "department": "Development"}]}`), "json")
+		assert.NoError(t, err)
+		assert.Equal(t, "Sercan", fixture.Assignees[0].Name)
+	})
+
+	t.Run("unknown-field", func(t *testing.T) {
+		_, err := Parse([]byte("assignees:\n  - nam: Sercan\n"), "yaml")
+		assert.Error(t, err)
+	})
+
+	t.Run("dump-can-be-loaded-again", func(t *testing.T) {
+		assigneeRepository, candidateRepository := LoadForTest(t, sampleFixture)
+		fixture, err := Dump(context.TODO(), assigneeRepository, candidateRepository)
+		assert.NoError(t, err)
+
+		encoded, err := Encode(fixture, "yaml")
+		assert.NoError(t, err)
+		parsed, err := Parse(encoded, "yaml")
+		assert.NoError(t, err)
+		assert.Len(t, parsed.Candidates, 22)
+		assert.NoError(t, Validate(parsed))
+	})
+}
@@ -0,0 +1,26 @@
+package seed
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/repository"
+	"testing"
+)
+
+// LoadForTest loads the fixture file into new in-memory repositories, and fails the test if it cannot be loaded
+func LoadForTest(tb testing.TB, path string) (model.AssigneeRepository, model.CandidateRepository) {
+	tb.Helper()
+
+	fixture, err := ReadFile(path)
+	if err != nil {
+		tb.Fatalf("couldn't read the fixture: %s", err)
+	}
+
+	assigneeRepository := repository.MemoryAssigneeRepository()
+	candidateRepository := repository.MemoryCandidateRepository()
+	if _, err := Load(context.Background(), assigneeRepository, candidateRepository, fixture, Options{}); err != nil {
+		tb.Fatalf("couldn't load the fixture: %s", err)
+	}
+
+	return assigneeRepository, candidateRepository
+}
@@ -2,37 +2,153 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"github.com/cemalunal/sample-internship-management-api/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"log"
 )
 
+// calendarTokenLength is the number of random bytes of the calendar tokens
+const calendarTokenLength = 32
+
 type assigneeService struct {
 	assigneeRepository model.AssigneeRepository
+	events model.EventPublisher
+	transactions model.TransactionRunner
+}
+
+// AssigneeServiceOption configures the optional dependencies of the AssigneeService
+type AssigneeServiceOption func(service *assigneeService)
+
+// WithAssigneeEvents publishes the events of the assignees
+func WithAssigneeEvents(publisher model.EventPublisher) AssigneeServiceOption {
+	return func(service *assigneeService) {
+		service.events = publisher
+	}
+}
+
+// WithAssigneeTransactions publishes the events of the assignees in the transactions of their changes,
+// see WithTransactions
+func WithAssigneeTransactions(transactions model.TransactionRunner) AssigneeServiceOption {
+	return func(service *assigneeService) {
+		service.transactions = transactions
+	}
 }
 
 // AssigneeService will create an implementation of AssigneeService interface
-func AssigneeService(assigneeRepository model.AssigneeRepository) model.AssigneeService {
-	return &assigneeService{
+func AssigneeService(assigneeRepository model.AssigneeRepository, options ...AssigneeServiceOption) model.AssigneeService {
+	service := &assigneeService{
 		assigneeRepository: assigneeRepository,
 	}
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
 }
 
 func (service *assigneeService) CreateAssignee(ctx context.Context, assignee model.Assignee) (model.Assignee, error) {
+	ctx, span := tracer.Start(ctx, "assigneeService.CreateAssignee")
+	defer span.End()
+
 	assignee.ID = primitive.NewObjectID().Hex()
 
-	return service.assigneeRepository.CreateAssignee(ctx, assignee)
+	if service.transactions == nil {
+		created, err := service.assigneeRepository.CreateAssignee(ctx, assignee)
+		if err != nil {
+			return model.Assignee{}, err
+		}
+
+		// the assignee is already saved, so a failure of the event is only logged
+		if err := service.publish(ctx, created); err != nil {
+			log.Printf("Couldn't publish %s of assignee %s. Error is: %s\n", model.AssigneeCreatedEvent, created.ID, err)
+		}
+		return created, nil
+	}
+
+	var created model.Assignee
+	err := service.transactions.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = service.assigneeRepository.CreateAssignee(ctx, assignee)
+		if err != nil {
+			return err
+		}
+
+		return service.publish(ctx, created)
+	})
+	if err != nil {
+		return model.Assignee{}, err
+	}
+
+	return created, nil
+}
+
+// publish publishes the creation of the assignee if the events are enabled
+func (service *assigneeService) publish(ctx context.Context, created model.Assignee) error {
+	if service.events == nil {
+		return nil
+	}
+
+	return service.events.Publish(ctx, model.DomainEvent{Type: model.AssigneeCreatedEvent, Assignee: &created})
 }
 
 func (service *assigneeService) FindAllAssignees(ctx context.Context) ([]model.Assignee, error) {
+	ctx, span := tracer.Start(ctx, "assigneeService.FindAllAssignees")
+	defer span.End()
+
 	return service.assigneeRepository.FindAllAssignees(ctx)
 }
 
 func (service *assigneeService) FindAllAssigneesByDepartment(ctx context.Context, department string) ([]model.Assignee, error) {
+	ctx, span := tracer.Start(ctx, "assigneeService.FindAllAssigneesByDepartment")
+	defer span.End()
+
 	return service.assigneeRepository.FindAllAssigneesByDepartment(ctx, department)
 }
 
-func (service *assigneeService) FindAssigneeIDByName(ctx context.Context, name string) string {
-	id, _ := service.assigneeRepository.FindAssigneeIDByName(ctx, name)
+func (service *assigneeService) FindAssigneeIDByName(ctx context.Context, name string) (string, error) {
+	ctx, span := tracer.Start(ctx, "assigneeService.FindAssigneeIDByName")
+	defer span.End()
+
+	return service.assigneeRepository.FindAssigneeIDByName(ctx, name)
+}
+
+// CreateCalendarToken creates a new secret token for the calendar feed of the assignee. Only the hash of the token is stored,
+// so the token cannot be read again, and the previous token of the assignee stops working.
+func (service *assigneeService) CreateCalendarToken(ctx context.Context, id string) (string, error) {
+	ctx, span := tracer.Start(ctx, "assigneeService.CreateCalendarToken")
+	defer span.End()
+	span.SetAttributes(attribute.String("assignee.id", id))
+
+	random := make([]byte, calendarTokenLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(random)
+
+	if err := service.assigneeRepository.SetCalendarTokenHash(ctx, id, hashCalendarToken(token)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// hashCalendarToken returns the hash of the calendar token that is stored instead of the token
+func hashCalendarToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// calendarTokenMatches reports whether the token is the calendar token of the assignee, in constant time
+func calendarTokenMatches(assignee model.Assignee, token string) bool {
+	if token == "" || assignee.CalendarTokenHash == "" {
+		return false
+	}
 
-	return id
+	return subtle.ConstantTimeCompare([]byte(hashCalendarToken(token)), []byte(assignee.CalendarTokenHash)) == 1
 }
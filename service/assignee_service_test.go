@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/cemalunal/sample-internship-management-api/model"
 	"github.com/cemalunal/sample-internship-management-api/model/mocks"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +29,21 @@ func TestAssigneeService_CreateAssignee(t *testing.T) {
 		assert.NotNil(t, savedAssignee.ID)
 		mockAssigneeRepository.AssertExpectations(t)
 	})
+
+	t.Run("publishes-event", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockAssigneeRepository.On("CreateAssignee", mock.Anything, mock.AnythingOfType("model.Assignee")).
+			Return(func(ctx context.Context, assignee model.Assignee) model.Assignee { return assignee }, nil).Once()
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.AssigneeCreatedEvent && event.Assignee.Name == "FN" && event.Assignee.ID != ""
+		})).Return(nil).Once()
+
+		aService := AssigneeService(mockAssigneeRepository, WithAssigneeEvents(mockEvents))
+		_, err := aService.CreateAssignee(context.TODO(), mockAssignee)
+
+		assert.NoError(t, err)
+		mockEvents.AssertExpectations(t)
+	})
 }
 
 func TestAssigneeService_FindAllAssignees(t *testing.T) {
@@ -98,10 +114,63 @@ func TestAssigneeService_FindAssigneeIDByName(t *testing.T) {
 		mockAssigneeRepository.On("FindAssigneeIDByName", mock.Anything, mock.AnythingOfType("string")).Return(mockAssignee.ID, nil).Once()
 
 		aService := AssigneeService(mockAssigneeRepository)
-		foundId := aService.FindAssigneeIDByName(context.TODO(), mockAssignee.Name)
+		foundId, err := aService.FindAssigneeIDByName(context.TODO(), mockAssignee.Name)
 
-		assert.NotNil(t, foundId)
+		assert.NoError(t, err)
 		assert.Equal(t, mockAssignee.ID, foundId)
 		mockAssigneeRepository.AssertExpectations(t)
 	})
+
+	t.Run("assignee-does-not-exist", func(t *testing.T) {
+		mockAssigneeRepository.On("FindAssigneeIDByName", mock.Anything, mock.AnythingOfType("string")).
+			Return("", model.ErrAssigneeDoesNotExist).Once()
+
+		aService := AssigneeService(mockAssigneeRepository)
+		_, err := aService.FindAssigneeIDByName(context.TODO(), mockAssignee.Name)
+
+		assert.Equal(t, model.ErrAssigneeDoesNotExist, err)
+		mockAssigneeRepository.AssertExpectations(t)
+	})
+
+	t.Run("backend-failure", func(t *testing.T) {
+		mockAssigneeRepository.On("FindAssigneeIDByName", mock.Anything, mock.AnythingOfType("string")).
+			Return("", errors.New("server selection timeout")).Once()
+
+		aService := AssigneeService(mockAssigneeRepository)
+		_, err := aService.FindAssigneeIDByName(context.TODO(), mockAssignee.Name)
+
+		assert.EqualError(t, err, "server selection timeout")
+		mockAssigneeRepository.AssertExpectations(t)
+	})
+}
+
+func TestAssigneeService_CreateCalendarToken(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		var storedHash string
+		mockAssigneeRepository.On("SetCalendarTokenHash", mock.Anything, "abcd", mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { storedHash = args.String(2) }).Return(nil).Once()
+
+		aService := AssigneeService(mockAssigneeRepository)
+		token, err := aService.CreateCalendarToken(context.TODO(), "abcd")
+
+		assert.NoError(t, err)
+		assert.Len(t, token, 43)
+		// only the hash of the token is stored
+		assert.NotEqual(t, token, storedHash)
+		assert.True(t, calendarTokenMatches(model.Assignee{CalendarTokenHash: storedHash}, token))
+		assert.False(t, calendarTokenMatches(model.Assignee{CalendarTokenHash: storedHash}, token[1:]))
+		mockAssigneeRepository.AssertExpectations(t)
+	})
+
+	t.Run("assignee-does-not-exist", func(t *testing.T) {
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockAssigneeRepository.On("SetCalendarTokenHash", mock.Anything, "abcd", mock.AnythingOfType("string")).
+			Return(model.ErrAssigneeDoesNotExist).Once()
+
+		aService := AssigneeService(mockAssigneeRepository)
+		_, err := aService.CreateCalendarToken(context.TODO(), "abcd")
+
+		assert.Equal(t, model.ErrAssigneeDoesNotExist, err)
+	})
 }
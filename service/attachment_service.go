@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/extract"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"io"
+	"log"
+	"time"
+)
+
+type attachmentService struct {
+	attachmentRepository model.AttachmentRepository
+	candidateRepository model.CandidateRepository
+	blobStore model.BlobStore
+}
+
+// AttachmentService will create an implementation of AttachmentService interface
+// The contents of the attachments are kept in the given blob store with the attachment ids as keys
+func AttachmentService(attachmentRepository model.AttachmentRepository, candidateRepository model.CandidateRepository, blobStore model.BlobStore) model.AttachmentService {
+	return &attachmentService{
+		attachmentRepository: attachmentRepository,
+		candidateRepository: candidateRepository,
+		blobStore: blobStore,
+	}
+}
+
+// UploadAttachment stores the content of the attachment first and its metadata afterwards,
+// so an attachment is never listed without its content.
+// The text of the content is extracted to be searched, the upload does not fail if the text cannot be extracted.
+func (service *attachmentService) UploadAttachment(ctx context.Context, attachment model.Attachment, content io.Reader) (model.Attachment, error) {
+	ctx, span := tracer.Start(ctx, "attachmentService.UploadAttachment")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", attachment.CandidateID))
+
+	// Check candidate exists with given id, return error if does not exist.
+	_, err := service.candidateRepository.ReadCandidate(ctx, attachment.CandidateID)
+	if err != nil {
+		log.Println(err)
+		return model.Attachment{}, err
+	}
+
+	attachment.ID = primitive.NewObjectID().Hex()
+	attachment.UploadedAt = time.Now()
+	span.SetAttributes(attribute.String("attachment.id", attachment.ID))
+
+	// the content is kept in memory while it is stored, since the extraction needs the whole document.
+	// the size of the uploads are limited by the api.
+	var buffer bytes.Buffer
+	attachment.Size, err = service.blobStore.Put(ctx, attachment.ID, io.TeeReader(content, &buffer))
+	if err != nil {
+		log.Println(err)
+		return model.Attachment{}, err
+	}
+	service.extractText(ctx, &attachment, buffer.Bytes())
+
+	createdAttachment, err := service.attachmentRepository.CreateAttachment(ctx, attachment)
+	if err != nil {
+		if deleteErr := service.blobStore.Delete(ctx, attachment.ID); deleteErr != nil {
+			log.Println(deleteErr)
+		}
+		return model.Attachment{}, err
+	}
+
+	return createdAttachment, nil
+}
+
+// extractText records the text of the content on the attachment, or the reason it could not be extracted
+func (service *attachmentService) extractText(ctx context.Context, attachment *model.Attachment, content []byte) {
+	_, span := tracer.Start(ctx, "attachmentService.extractText")
+	defer span.End()
+	span.SetAttributes(attribute.String("attachment.content_type", attachment.ContentType))
+
+	text, err := extract.Text(attachment.ContentType, content)
+	switch {
+	case err == nil:
+		attachment.TextExtraction = model.TextExtracted
+		attachment.Text = text
+	case errors.Is(err, extract.ErrNotSupported):
+		attachment.TextExtraction = model.TextExtractionNotSupported
+	default:
+		log.Printf("Couldn't extract the text of attachment %s. Error is: %s\n", attachment.ID, err)
+		tracing.RecordError(span, err)
+		attachment.TextExtraction = model.TextExtractionFailed
+		attachment.TextExtractionError = err.Error()
+	}
+}
+
+func (service *attachmentService) FindCandidatesAttachments(ctx context.Context, candidateID string) ([]model.Attachment, error) {
+	ctx, span := tracer.Start(ctx, "attachmentService.FindCandidatesAttachments")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", candidateID))
+
+	// Check candidate exists with given id, return error if does not exist.
+	_, err := service.candidateRepository.ReadCandidate(ctx, candidateID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return service.attachmentRepository.FindCandidatesAttachments(ctx, candidateID)
+}
+
+// DownloadAttachment returns the metadata of the attachment with its content, the content must be closed by the caller
+func (service *attachmentService) DownloadAttachment(ctx context.Context, candidateID string, id string) (model.Attachment, io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "attachmentService.DownloadAttachment")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", candidateID), attribute.String("attachment.id", id))
+
+	attachment, err := service.attachmentRepository.ReadAttachment(ctx, candidateID, id)
+	if err != nil {
+		log.Println(err)
+		return model.Attachment{}, nil, err
+	}
+
+	content, err := service.blobStore.Get(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return model.Attachment{}, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// DeleteAttachment deletes the metadata of the attachment first, so a failure leaves an unlisted blob rather than a broken attachment
+func (service *attachmentService) DeleteAttachment(ctx context.Context, candidateID string, id string) error {
+	ctx, span := tracer.Start(ctx, "attachmentService.DeleteAttachment")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", candidateID), attribute.String("attachment.id", id))
+
+	// Repository returns an error if the attachment does not exist with given ids.
+	if err := service.attachmentRepository.DeleteAttachment(ctx, candidateID, id); err != nil {
+		return err
+	}
+
+	return service.blobStore.Delete(ctx, id)
+}
+
+// DeleteCandidatesAttachments deletes all attachments of the candidate, it does not check the candidate exists
+// since it is called after the candidate is deleted
+func (service *attachmentService) DeleteCandidatesAttachments(ctx context.Context, candidateID string) error {
+	ctx, span := tracer.Start(ctx, "attachmentService.DeleteCandidatesAttachments")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", candidateID))
+
+	attachments, err := service.attachmentRepository.FindCandidatesAttachments(ctx, candidateID)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		if err := service.DeleteAttachment(ctx, candidateID, attachment.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (service *attachmentService) FindCandidateIDsByText(ctx context.Context, word string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "attachmentService.FindCandidateIDsByText")
+	defer span.End()
+
+	return service.attachmentRepository.FindCandidateIDsByText(ctx, word)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/model/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentService_UploadAttachment(t *testing.T) {
+	mockAttachment := model.Attachment{
+		CandidateID: "123asd123",
+		Kind: model.AttachmentCV,
+		FileName: "cv.pdf",
+		ContentType: "application/pdf",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockAttachmentRepository := new(mocks.AttachmentRepository)
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockBlobStore := new(mocks.BlobStore)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "123asd123").Return(model.Candidate{ID: "123asd123"}, nil).Once()
+		mockBlobStore.On("Put", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(int64(7), nil).Once()
+		mockAttachmentRepository.On("CreateAttachment", mock.Anything, mock.AnythingOfType("model.Attachment")).
+			Return(func(ctx context.Context, attachment model.Attachment) model.Attachment { return attachment }, nil).Once()
+
+		aService := AttachmentService(mockAttachmentRepository, mockCandidateRepository, mockBlobStore)
+		attachment, err := aService.UploadAttachment(context.TODO(), mockAttachment, strings.NewReader("content"))
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, attachment.ID)
+		assert.Equal(t, int64(7), attachment.Size)
+		assert.False(t, attachment.UploadedAt.IsZero())
+		// the blob is stored with the id of the attachment
+		mockBlobStore.AssertCalled(t, "Put", mock.Anything, attachment.ID, mock.Anything)
+		mockAttachmentRepository.AssertExpectations(t)
+	})
+
+	t.Run("candidate-does-not-exist", func(t *testing.T) {
+		mockAttachmentRepository := new(mocks.AttachmentRepository)
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockBlobStore := new(mocks.BlobStore)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "123asd123").Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
+
+		aService := AttachmentService(mockAttachmentRepository, mockCandidateRepository, mockBlobStore)
+		_, err := aService.UploadAttachment(context.TODO(), mockAttachment, strings.NewReader("content"))
+
+		assert.Equal(t, model.ErrCandidateDoesNotExist, err)
+		mockBlobStore.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("metadata-failure-deletes-blob", func(t *testing.T) {
+		mockAttachmentRepository := new(mocks.AttachmentRepository)
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockBlobStore := new(mocks.BlobStore)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "123asd123").Return(model.Candidate{ID: "123asd123"}, nil).Once()
+		mockBlobStore.On("Put", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(int64(7), nil).Once()
+		mockBlobStore.On("Delete", mock.Anything, mock.AnythingOfType("string")).Return(nil).Once()
+		mockAttachmentRepository.On("CreateAttachment", mock.Anything, mock.AnythingOfType("model.Attachment")).
+			Return(model.Attachment{}, errors.New("server selection timeout")).Once()
+
+		aService := AttachmentService(mockAttachmentRepository, mockCandidateRepository, mockBlobStore)
+		_, err := aService.UploadAttachment(context.TODO(), mockAttachment, strings.NewReader("content"))
+
+		assert.EqualError(t, err, "server selection timeout")
+		mockBlobStore.AssertExpectations(t)
+	})
+}
+
+func TestAttachmentService_UploadAttachment_TextExtraction(t *testing.T) {
+	upload := func(contentType string, content string) model.Attachment {
+		mockAttachmentRepository := new(mocks.AttachmentRepository)
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockBlobStore := new(mocks.BlobStore)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "123asd123").Return(model.Candidate{ID: "123asd123"}, nil).Once()
+		mockBlobStore.On("Put", mock.Anything, mock.AnythingOfType("string"), mock.Anything).
+			Return(func(ctx context.Context, key string, content io.Reader) int64 {
+				data, _ := io.ReadAll(content)
+				return int64(len(data))
+			}, nil).Once()
+		mockAttachmentRepository.On("CreateAttachment", mock.Anything, mock.AnythingOfType("model.Attachment")).
+			Return(func(ctx context.Context, attachment model.Attachment) model.Attachment { return attachment }, nil).Once()
+
+		aService := AttachmentService(mockAttachmentRepository, mockCandidateRepository, mockBlobStore)
+		attachment, err := aService.UploadAttachment(context.TODO(),
+			model.Attachment{CandidateID: "123asd123", Kind: model.AttachmentCV, ContentType: contentType}, strings.NewReader(content))
+		assert.NoError(t, err)
+		return attachment
+	}
+
+	t.Run("extracted", func(t *testing.T) {
+		attachment := upload("text/plain", "Go\nKubernetes")
+
+		assert.Equal(t, model.TextExtracted, attachment.TextExtraction)
+		assert.Equal(t, "Go Kubernetes", attachment.Text)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		// the upload is not rejected
+		attachment := upload("application/pdf", "%PDF-1.4 broken")
+
+		assert.Equal(t, model.TextExtractionFailed, attachment.TextExtraction)
+		assert.NotEmpty(t, attachment.TextExtractionError)
+		assert.Empty(t, attachment.Text)
+	})
+
+	t.Run("not-supported", func(t *testing.T) {
+		attachment := upload("application/msword", "content")
+
+		assert.Equal(t, model.TextExtractionNotSupported, attachment.TextExtraction)
+		assert.Empty(t, attachment.TextExtractionError)
+	})
+}
+
+func TestAttachmentService_DownloadAttachment(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockAttachmentRepository := new(mocks.AttachmentRepository)
+		mockBlobStore := new(mocks.BlobStore)
+		mockAttachmentRepository.On("ReadAttachment", mock.Anything, "123asd123", "456").
+			Return(model.Attachment{ID: "456", CandidateID: "123asd123", FileName: "cv.pdf"}, nil).Once()
+		mockBlobStore.On("Get", mock.Anything, "456").Return(io.NopCloser(strings.NewReader("content")), nil).Once()
+
+		aService := AttachmentService(mockAttachmentRepository, new(mocks.CandidateRepository), mockBlobStore)
+		attachment, content, err := aService.DownloadAttachment(context.TODO(), "123asd123", "456")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "cv.pdf", attachment.FileName)
+		data, _ := io.ReadAll(content)
+		assert.Equal(t, "content", string(data))
+	})
+
+	t.Run("attachment-does-not-exist", func(t *testing.T) {
+		mockAttachmentRepository := new(mocks.AttachmentRepository)
+		mockBlobStore := new(mocks.BlobStore)
+		mockAttachmentRepository.On("ReadAttachment", mock.Anything, "123asd123", "456").
+			Return(model.Attachment{}, model.ErrAttachmentDoesNotExist).Once()
+
+		aService := AttachmentService(mockAttachmentRepository, new(mocks.CandidateRepository), mockBlobStore)
+		_, _, err := aService.DownloadAttachment(context.TODO(), "123asd123", "456")
+
+		assert.Equal(t, model.ErrAttachmentDoesNotExist, err)
+		mockBlobStore.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAttachmentService_DeleteCandidatesAttachments(t *testing.T) {
+	mockAttachmentRepository := new(mocks.AttachmentRepository)
+	mockBlobStore := new(mocks.BlobStore)
+	mockAttachmentRepository.On("FindCandidatesAttachments", mock.Anything, "123asd123").
+		Return([]model.Attachment{{ID: "1", CandidateID: "123asd123"}, {ID: "2", CandidateID: "123asd123"}}, nil).Once()
+	mockAttachmentRepository.On("DeleteAttachment", mock.Anything, "123asd123", "1").Return(nil).Once()
+	mockAttachmentRepository.On("DeleteAttachment", mock.Anything, "123asd123", "2").Return(nil).Once()
+	mockBlobStore.On("Delete", mock.Anything, "1").Return(nil).Once()
+	mockBlobStore.On("Delete", mock.Anything, "2").Return(nil).Once()
+
+	aService := AttachmentService(mockAttachmentRepository, new(mocks.CandidateRepository), mockBlobStore)
+	err := aService.DeleteCandidatesAttachments(context.TODO(), "123asd123")
+
+	assert.NoError(t, err)
+	mockAttachmentRepository.AssertExpectations(t)
+	mockBlobStore.AssertExpectations(t)
+}
@@ -2,135 +2,822 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/cemalunal/sample-internship-management-api/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
 	"log"
+	"sort"
+	"strings"
 	"time"
 )
 
+// maxUpdateAttempts is the number of times a state transition of a candidate is tried
+// when it conflicts with the concurrent updates of the same candidate
+const maxUpdateAttempts = 5
+
+// errCandidateNotExpired is returned when a candidate is not pending anymore by the time it is expired
+var errCandidateNotExpired = errors.New("candidate is not pending without an arranged meeting")
+
 type candidateService struct {
 	candidateRepository model.CandidateRepository
 	assigneeRepository model.AssigneeRepository
+	attachmentService model.AttachmentService
+	events model.EventPublisher
+	transactions model.TransactionRunner
+	// maxNoShows is the number of the missed meetings that the candidates are denied after, zero disables the policy
+	maxNoShows int
+}
+
+// CandidateServiceOption configures the optional dependencies of the CandidateService
+type CandidateServiceOption func(service *candidateService)
+
+// WithAttachments deletes the attachments of the candidates when they are deleted
+func WithAttachments(attachmentService model.AttachmentService) CandidateServiceOption {
+	return func(service *candidateService) {
+		service.attachmentService = attachmentService
+	}
+}
+
+// WithEvents publishes the events of the candidates and their meetings, such as the notifications
+// and the webhooks subscribe to
+func WithEvents(publisher model.EventPublisher) CandidateServiceOption {
+	return func(service *candidateService) {
+		service.events = publisher
+	}
+}
+
+// WithTransactions publishes the events of the candidates in the transactions of their changes, so a change is not saved
+// unless its events are stored, e.g. in the outbox. Without it, the events are published after the changes are saved,
+// and a failure of the publisher is only logged.
+func WithTransactions(transactions model.TransactionRunner) CandidateServiceOption {
+	return func(service *candidateService) {
+		service.transactions = transactions
+	}
+}
+
+// WithNoShowPolicy denies the candidates automatically, once they have missed the given number of meetings
+func WithNoShowPolicy(maxNoShows int) CandidateServiceOption {
+	return func(service *candidateService) {
+		service.maxNoShows = maxNoShows
+	}
 }
 
 // CandidateService will create an implementation of CandidateService interface
-func CandidateService(candidateRepository model.CandidateRepository, assigneeRepository model.AssigneeRepository) model.CandidateService {
-	return &candidateService{
+func CandidateService(candidateRepository model.CandidateRepository, assigneeRepository model.AssigneeRepository, options ...CandidateServiceOption) model.CandidateService {
+	service := &candidateService{
 		candidateRepository: candidateRepository,
 		assigneeRepository: assigneeRepository,
 	}
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
 }
 
 func (service *candidateService) CreateCandidate(ctx context.Context, candidate model.Candidate) (model.Candidate, error) {
-	// Check candidate exists with given email, return error if exists.
-	c, _ := service.FindCandidateByEmail(ctx, candidate.Email)
-	if c != (model.Candidate{}) {
-		log.Println(model.ErrCandidateAlreadyExists)
-		return model.Candidate{}, model.ErrCandidateAlreadyExists
-	}
+	ctx, span := tracer.Start(ctx, "candidateService.CreateCandidate")
+	defer span.End()
 
 	candidate.ID = primitive.NewObjectID().Hex()
 	candidate.Status = model.Pending
 	candidate.MeetingCount = 0
+	candidate.NoShowCount = 0
 	candidate.NextMeeting = nil
+	candidate.DecidedAt = nil
 	candidate.ApplicationDate = time.Now()
 
-	return service.candidateRepository.CreateCandidate(ctx, candidate)
+	var created model.Candidate
+	err := service.inTransaction(ctx, func(ctx context.Context) error {
+		// Repository returns an error if a candidate exists with given email
+		var err error
+		created, err = service.candidateRepository.CreateCandidate(ctx, candidate)
+		if err != nil {
+			return err
+		}
+
+		return service.publish(ctx, model.CandidateCreatedEvent, created, nil)
+	})
+	if err != nil {
+		return model.Candidate{}, err
+	}
+
+	return created, nil
 }
 
-func (service *candidateService) UpdateCandidate(ctx context.Context, id string, candidate model.Candidate) error {
-	return service.candidateRepository.UpdateCandidate(ctx, id, candidate)
+// UpdateCandidate updates the editable fields of the candidate, if the version of the given candidate is
+// still the stored version. The status and the meeting information can only be changed by their own operations.
+func (service *candidateService) UpdateCandidate(ctx context.Context, id string, candidate model.Candidate) (model.Candidate, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.UpdateCandidate")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
+	c, err := service.candidateRepository.ReadCandidate(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return model.Candidate{}, err
+	}
+
+	if c.Version != candidate.Version {
+		log.Println(model.ErrCandidateModified)
+		return model.Candidate{}, model.ErrCandidateModified
+	}
+
+	c.FirstName = candidate.FirstName
+	c.LastName = candidate.LastName
+	c.Email = candidate.Email
+	c.Department = candidate.Department
+	c.University = candidate.University
+	c.Experience = candidate.Experience
+	c.Language = candidate.Language
+
+	err = service.inTransaction(ctx, func(ctx context.Context) error {
+		// Repository returns an error if another candidate exists with the new email
+		if err := service.candidateRepository.UpdateCandidate(ctx, id, c); err != nil {
+			return err
+		}
+
+		updated := c
+		updated.Version += 1
+		return service.publish(ctx, model.CandidateUpdatedEvent, updated, nil)
+	})
+	if errors.Is(err, model.ErrCandidateVersionConflict) {
+		log.Println(model.ErrCandidateModified)
+		return model.Candidate{}, model.ErrCandidateModified
+	}
+	if err != nil {
+		return model.Candidate{}, err
+	}
+
+	c.Version += 1
+	return c, nil
 }
 
 func (service *candidateService) ReadCandidate(ctx context.Context, id string) (model.Candidate, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.ReadCandidate")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
 	return service.candidateRepository.ReadCandidate(ctx, id)
 }
 
 func (service *candidateService) FindAllCandidates(ctx context.Context) ([]model.Candidate, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.FindAllCandidates")
+	defer span.End()
+
 	return service.candidateRepository.FindAllCandidates(ctx)
 }
 
+func (service *candidateService) FindCandidates(ctx context.Context, filter model.CandidateFilter) ([]model.Candidate, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.FindCandidates")
+	defer span.End()
+
+	filter, err := service.searchAttachments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.candidateRepository.FindCandidates(ctx, filter)
+}
+
+// ExportCandidates streams the candidates that match the filter, along with the names of their assignees.
+// Each assignee is read once, and the assignees that do not exist anymore have an empty name.
+func (service *candidateService) ExportCandidates(ctx context.Context, filter model.CandidateFilter, fn func(model.ExportedCandidate) error) error {
+	ctx, span := tracer.Start(ctx, "candidateService.ExportCandidates")
+	defer span.End()
+
+	filter, err := service.searchAttachments(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	assigneeNames := make(map[string]string)
+	return service.candidateRepository.StreamCandidates(ctx, filter, func(candidate model.Candidate) error {
+		name, ok := assigneeNames[candidate.Assignee]
+		if !ok && candidate.Assignee != "" {
+			assignee, err := service.assigneeRepository.ReadAssignee(ctx, candidate.Assignee)
+			if err != nil && !errors.Is(err, model.ErrAssigneeDoesNotExist) {
+				return err
+			}
+			name = assignee.Name
+			assigneeNames[candidate.Assignee] = name
+		}
+
+		return fn(model.ExportedCandidate{Candidate: candidate, AssigneeName: name})
+	})
+}
+
+// searchAttachments finds the candidates whose attachments contain the words of the search,
+// so the candidates match the search with their attachments as well as their own fields
+func (service *candidateService) searchAttachments(ctx context.Context, filter model.CandidateFilter) (model.CandidateFilter, error) {
+	if service.attachmentService == nil || len(filter.Search) == 0 {
+		return filter, nil
+	}
+
+	terms := make([]model.SearchTerm, len(filter.Search))
+	for i, term := range filter.Search {
+		ids, err := service.attachmentService.FindCandidateIDsByText(ctx, term.Word)
+		if err != nil {
+			log.Println(err)
+			return model.CandidateFilter{}, err
+		}
+		term.AttachedCandidateIDs = ids
+		terms[i] = term
+	}
+	filter.Search = terms
+
+	return filter, nil
+}
+
 func (service *candidateService) FindCandidateByEmail(ctx context.Context, email string) (model.Candidate, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.FindCandidateByEmail")
+	defer span.End()
+
 	return service.candidateRepository.FindCandidateByEmail(ctx, email)
 }
 
 func (service *candidateService) FindAssigneesCandidates(ctx context.Context, id string) ([]model.Candidate, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.FindAssigneesCandidates")
+	defer span.End()
+	span.SetAttributes(attribute.String("assignee.id", id))
+
 	// Check assignee exists with given id, return error if does not exist.
-	a, _ := service.assigneeRepository.ReadAssignee(ctx, id)
-	if a == (model.Assignee{}) {
-		log.Println(model.ErrAssigneeDoesNotExist)
-		return nil, model.ErrAssigneeDoesNotExist
+	_, err := service.assigneeRepository.ReadAssignee(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
 	}
 
 	return service.candidateRepository.FindAssigneesCandidates(ctx, id)
 }
 
 func (service *candidateService) DeleteCandidate(ctx context.Context, id string) error {
-	// Check candidate exists with given id, return error if does not exist.
-	c, _ := service.candidateRepository.ReadCandidate(ctx, id)
-	if c == (model.Candidate{}) {
-		log.Println(model.ErrCandidateDoesNotExist)
-		return model.ErrCandidateDoesNotExist
+	ctx, span := tracer.Start(ctx, "candidateService.DeleteCandidate")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
+	err := service.inTransaction(ctx, func(ctx context.Context) error {
+		// Repository returns an error if the candidate does not exist with given id.
+		if err := service.candidateRepository.DeleteCandidate(ctx, id); err != nil {
+			return err
+		}
+
+		return service.publish(ctx, model.CandidateDeletedEvent, model.Candidate{ID: id}, nil)
+	})
+	if err != nil {
+		return err
 	}
 
-	return service.candidateRepository.DeleteCandidate(ctx, id)
+	// the candidate is already deleted, so the attachments that are left behind are only logged
+	if service.attachmentService != nil {
+		if err := service.attachmentService.DeleteCandidatesAttachments(ctx, id); err != nil {
+			log.Println(err)
+		}
+	}
+
+	return nil
 }
 
 func (service *candidateService) DenyCandidate(ctx context.Context, id string) error {
-	// Check candidate exists with given id, return error if does not exist.
-	c, _ := service.candidateRepository.ReadCandidate(ctx, id)
-	if c == (model.Candidate{}) {
-		log.Println(model.ErrCandidateDoesNotExist)
-		return model.ErrCandidateDoesNotExist
+	ctx, span := tracer.Start(ctx, "candidateService.DenyCandidate")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
+	return service.updateCandidate(ctx, id, model.CandidateDeniedEvent, func(c *model.Candidate) error {
+		now := time.Now()
+		c.Status = model.Denied
+		c.DecidedAt = &now
+		return nil
+	})
+}
+
+func (service *candidateService) AcceptCandidate(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "candidateService.AcceptCandidate")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
+	return service.updateCandidate(ctx, id, model.CandidateAcceptedEvent, func(c *model.Candidate) error {
+		// Candidates cannot be accepted before the completion of 4 meetings
+		if c.MeetingCount < 4 {
+			return model.ErrMeetingCountNotEnough
+		}
+
+		now := time.Now()
+		c.Status = model.Accepted
+		c.DecidedAt = &now
+		return nil
+	})
+}
+
+func (service *candidateService) ArrangeMeeting(ctx context.Context, id string, nextMeetingTime *time.Time) error {
+	ctx, span := tracer.Start(ctx, "candidateService.ArrangeMeeting")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
+	return service.retryOnVersionConflict(id, func() error {
+		// Check candidate exists with given id, return error if does not exist.
+		c, err := service.candidateRepository.ReadCandidate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// an arranged meeting is rescheduled instead, so it keeps its assignee and its calendar event
+		if c.NextMeeting != nil {
+			return model.ErrMeetingAlreadyArranged
+		}
+
+		assigneeID, err := service.chooseAssignee(ctx, c)
+		if err != nil {
+			return err
+		}
+
+		meeting := model.MeetingRecord{
+			ID: primitive.NewObjectID().Hex(),
+			CandidateID: id,
+			AssigneeID: assigneeID,
+			Time: *nextMeetingTime,
+			Status: model.MeetingArranged,
+			ArrangedAt: time.Now(),
+		}
+
+		return service.inTransaction(ctx, func(ctx context.Context) error {
+			// the meeting is only arranged if the candidate has not changed since it was read
+			arranged, err := service.candidateRepository.ArrangeMeeting(ctx, id, c.Version, meeting)
+			if err != nil {
+				return err
+			}
+
+			return service.publish(ctx, model.MeetingArrangedEvent, arranged, &meeting)
+		})
+	})
+}
+
+func (service *candidateService) CompleteMeeting(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "candidateService.CompleteMeeting")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
+	err := service.inTransaction(ctx, func(ctx context.Context) error {
+		// Repository returns an error if the candidate does not have any arranged meetings,
+		// otherwise it clears the next meeting and updates the meeting count by one.
+		completed, err := service.candidateRepository.CompleteMeeting(ctx, id, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return service.publish(ctx, model.MeetingCompletedEvent, completed, nil)
+	})
+	if err != nil {
+		log.Println(err)
 	}
 
-	c.Status = model.Denied
+	return err
+}
+
+// RescheduleMeeting moves the arranged meeting of the candidate to the given time, which must be in the future.
+// The meeting keeps its assignee, and the change is recorded in the history of the meeting.
+func (service *candidateService) RescheduleMeeting(ctx context.Context, id string, nextMeetingTime *time.Time) error {
+	ctx, span := tracer.Start(ctx, "candidateService.RescheduleMeeting")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
 
-	return service.UpdateCandidate(ctx, id, c)
+	now := time.Now()
+	if !nextMeetingTime.After(now) {
+		return model.ErrMeetingTimeInPast
+	}
+
+	return service.retryOnVersionConflict(id, func() error {
+		c, err := service.candidateRepository.ReadCandidate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.NextMeeting == nil {
+			return model.ErrArrangedMeetingDoesNotExist
+		}
+
+		change := model.MeetingChange{Type: model.MeetingRescheduled, At: now, PreviousTime: *c.NextMeeting, Time: nextMeetingTime}
+		return service.inTransaction(ctx, func(ctx context.Context) error {
+			rescheduled, meeting, err := service.candidateRepository.RescheduleMeeting(ctx, id, c.Version, change)
+			if err != nil {
+				return err
+			}
+			if meeting.ID == "" {
+				meeting = unrecordedMeeting(c, change)
+				meeting.Time = *nextMeetingTime
+			}
+
+			return service.publish(ctx, model.MeetingRescheduledEvent, rescheduled, &meeting)
+		})
+	})
 }
 
-func (service *candidateService) AcceptCandidate(ctx context.Context, id string) error {
-	// Check candidate exists with given id, return error if does not exist.
-	c, _ := service.candidateRepository.ReadCandidate(ctx, id)
-	if c == (model.Candidate{}) {
-		log.Println(model.ErrCandidateDoesNotExist)
-		return model.ErrCandidateDoesNotExist
+// CancelMeeting cancels the arranged meeting of the candidate for the given reason. The meetings that have already started
+// cannot be cancelled, they are completed instead. The cancellation is recorded in the history of the meeting.
+func (service *candidateService) CancelMeeting(ctx context.Context, id string, reason string) error {
+	ctx, span := tracer.Start(ctx, "candidateService.CancelMeeting")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
+	now := time.Now()
+	return service.retryOnVersionConflict(id, func() error {
+		c, err := service.candidateRepository.ReadCandidate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.NextMeeting == nil {
+			return model.ErrArrangedMeetingDoesNotExist
+		}
+		if !c.NextMeeting.After(now) {
+			return model.ErrMeetingAlreadyStarted
+		}
+
+		change := model.MeetingChange{Type: model.MeetingCancelled, At: now, PreviousTime: *c.NextMeeting, Reason: reason}
+		return service.inTransaction(ctx, func(ctx context.Context) error {
+			cancelled, meeting, err := service.candidateRepository.CancelMeeting(ctx, id, c.Version, change)
+			if err != nil {
+				return err
+			}
+			if meeting.ID == "" {
+				meeting = unrecordedMeeting(c, change)
+				meeting.Status = model.MeetingCancelled
+			}
+
+			return service.publish(ctx, model.MeetingCancelledEvent, cancelled, &meeting)
+		})
+	})
+}
+
+// RecordNoShow records that the candidate has missed the arranged meeting, which must have started. The meeting is closed
+// without counting it towards the meetings of the candidate. The candidates who have missed as many meetings as the no-show
+// policy allows are denied, just like they are denied by DenyCandidate.
+func (service *candidateService) RecordNoShow(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "candidateService.RecordNoShow")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
+	now := time.Now()
+	var missed model.Candidate
+	err := service.retryOnVersionConflict(id, func() error {
+		c, err := service.candidateRepository.ReadCandidate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.NextMeeting == nil {
+			return model.ErrArrangedMeetingDoesNotExist
+		}
+		if c.NextMeeting.After(now) {
+			return model.ErrMeetingNotStarted
+		}
+
+		change := model.MeetingChange{Type: model.MeetingNoShow, At: now, PreviousTime: *c.NextMeeting}
+		return service.inTransaction(ctx, func(ctx context.Context) error {
+			var meeting model.MeetingRecord
+			var err error
+			missed, meeting, err = service.candidateRepository.RecordNoShow(ctx, id, c.Version, change)
+			if err != nil {
+				return err
+			}
+			if meeting.ID == "" {
+				meeting = unrecordedMeeting(c, change)
+				meeting.Status = model.MeetingNoShow
+			}
+
+			return service.publish(ctx, model.MeetingNoShowEvent, missed, &meeting)
+		})
+	})
+	if err != nil {
+		return err
 	}
+	span.SetAttributes(attribute.Int("candidate.no_show_count", missed.NoShowCount))
 
-	// Candidates cannot be accepted before the completion of 4 meetings
-	if c.MeetingCount < 4 {
-		log.Println(model.ErrMeetingCountNotEnough)
-		return model.ErrMeetingCountNotEnough
+	if service.maxNoShows <= 0 || missed.NoShowCount < service.maxNoShows ||
+		(missed.Status != model.Pending && missed.Status != model.InProgress) {
+		return nil
 	}
 
-	c.Status = model.Accepted
+	log.Printf("Candidate with id %s has missed %d meetings, denying the candidate\n", id, missed.NoShowCount)
+	// the no-show is already saved, so a failure of the denial is only logged
+	if err := service.DenyCandidate(ctx, id); err != nil {
+		log.Printf("Couldn't deny candidate %s after %d no-shows. Error is: %s\n", id, missed.NoShowCount, err)
+	}
 
-	return service.UpdateCandidate(ctx, id, c)
+	return nil
 }
 
-func (service *candidateService) ArrangeMeeting(ctx context.Context, id string, nextMeetingTime *time.Time) error {
+// ExpirePendingCandidates expires the pending candidates without an arranged meeting that have applied before the given time,
+// and returns the number of the expired candidates. Each candidate is expired with a versioned update, so a candidate that
+// has a meeting arranged in the meantime is not expired, and the expiration is published like the other status changes.
+func (service *candidateService) ExpirePendingCandidates(ctx context.Context, appliedBefore time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.ExpirePendingCandidates")
+	defer span.End()
+
+	candidates, err := service.candidateRepository.FindCandidatesToExpire(ctx, appliedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, candidate := range candidates {
+		err := service.updateCandidate(ctx, candidate.ID, model.CandidateExpiredEvent, func(c *model.Candidate) error {
+			if c.Status != model.Pending || c.NextMeeting != nil || !c.ApplicationDate.Before(appliedBefore) {
+				return errCandidateNotExpired
+			}
+
+			c.Status = model.Expired
+			return nil
+		})
+		if errors.Is(err, errCandidateNotExpired) || errors.Is(err, model.ErrCandidateDoesNotExist) {
+			continue
+		}
+		if err != nil {
+			span.SetAttributes(attribute.Int("candidate.expired", expired))
+			return expired, err
+		}
+
+		expired++
+	}
+	span.SetAttributes(attribute.Int("candidate.expired", expired))
+
+	return expired, nil
+}
+
+// unrecordedMeeting creates the meeting record of a meeting that was arranged before the meeting records were kept,
+// so the candidate can be notified of its change. Its calendar event has the id of the candidate.
+func unrecordedMeeting(c model.Candidate, change model.MeetingChange) model.MeetingRecord {
+	return model.MeetingRecord{
+		ID: c.ID,
+		CandidateID: c.ID,
+		AssigneeID: c.Assignee,
+		Time: *c.NextMeeting,
+		Status: model.MeetingArranged,
+		Sequence: 1,
+		Changes: []model.MeetingChange{change},
+	}
+}
+
+// ReadMeeting reads a meeting record along with its candidate and assignee.
+// The meetings of the deleted candidates do not exist, but the assignee of a meeting may have been deleted,
+// in which case only the id of the assignee is known.
+func (service *candidateService) ReadMeeting(ctx context.Context, id string) (model.MeetingDetails, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.ReadMeeting")
+	defer span.End()
+	span.SetAttributes(attribute.String("meeting.id", id))
+
+	meeting, err := service.candidateRepository.ReadMeeting(ctx, id)
+	if err != nil {
+		return model.MeetingDetails{}, err
+	}
+
+	candidate, err := service.candidateRepository.ReadCandidate(ctx, meeting.CandidateID)
+	if errors.Is(err, model.ErrCandidateDoesNotExist) {
+		return model.MeetingDetails{}, model.ErrMeetingDoesNotExist
+	}
+	if err != nil {
+		return model.MeetingDetails{}, err
+	}
+
+	assignee, err := service.assigneeRepository.ReadAssignee(ctx, meeting.AssigneeID)
+	if errors.Is(err, model.ErrAssigneeDoesNotExist) {
+		assignee = model.Assignee{ID: meeting.AssigneeID}
+	} else if err != nil {
+		return model.MeetingDetails{}, err
+	}
+
+	return model.MeetingDetails{Meeting: meeting, Candidate: candidate, Assignee: assignee}, nil
+}
+
+// FindCandidatesMeetings finds the history of the meetings of the candidate
+func (service *candidateService) FindCandidatesMeetings(ctx context.Context, id string) ([]model.MeetingRecord, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.FindCandidatesMeetings")
+	defer span.End()
+	span.SetAttributes(attribute.String("candidate.id", id))
+
 	// Check candidate exists with given id, return error if does not exist.
-	c, _ := service.candidateRepository.ReadCandidate(ctx, id)
-	if c == (model.Candidate{}) {
-		log.Println(model.ErrCandidateDoesNotExist)
-		return model.ErrCandidateDoesNotExist
+	if _, err := service.candidateRepository.ReadCandidate(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return service.candidateRepository.FindCandidatesMeetings(ctx, id)
+}
+
+// RecordMeetingFeedback records the feedback score of the meeting with the given id, which must be completed.
+// The score can be changed by recording it again.
+func (service *candidateService) RecordMeetingFeedback(ctx context.Context, meetingID string, score int) error {
+	ctx, span := tracer.Start(ctx, "candidateService.RecordMeetingFeedback")
+	defer span.End()
+	span.SetAttributes(attribute.String("meeting.id", meetingID))
+
+	meeting, err := service.candidateRepository.ReadMeeting(ctx, meetingID)
+	if err != nil {
+		return err
+	}
+	if meeting.Status != model.MeetingCompleted {
+		return model.ErrMeetingNotCompleted
+	}
+
+	// Repository returns an error if the meeting is deleted in the meantime.
+	err = service.candidateRepository.RecordMeetingFeedback(ctx, meetingID, score)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// FindOverdueMeetings finds the meetings whose time has passed without being completed, after the scheduler flags them.
+// The meetings of the deleted candidates are skipped.
+func (service *candidateService) FindOverdueMeetings(ctx context.Context) ([]model.MeetingDetails, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.FindOverdueMeetings")
+	defer span.End()
+
+	meetings, err := service.candidateRepository.FindOverdueMeetings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := []model.MeetingDetails{}
+	for _, meeting := range meetings {
+		candidate, err := service.candidateRepository.ReadCandidate(ctx, meeting.CandidateID)
+		if errors.Is(err, model.ErrCandidateDoesNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		assignee, err := service.assigneeRepository.ReadAssignee(ctx, meeting.AssigneeID)
+		if errors.Is(err, model.ErrAssigneeDoesNotExist) {
+			assignee = model.Assignee{ID: meeting.AssigneeID}
+		} else if err != nil {
+			return nil, err
+		}
+
+		details = append(details, model.MeetingDetails{Meeting: meeting, Candidate: candidate, Assignee: assignee})
+	}
+
+	return details, nil
+}
+
+// FindAssigneesCalendar finds the upcoming meetings of the assignee, if the token is the calendar token of the assignee.
+// The meetings that have started but have not ended yet are still upcoming.
+// The candidates of the meetings are the candidates of the assignee, except the ones that are assigned to another assignee
+// for their later meetings. The meetings that are arranged before the meeting records were kept only exist on the candidates.
+func (service *candidateService) FindAssigneesCalendar(ctx context.Context, assigneeID string, token string) (model.Assignee, []model.MeetingDetails, error) {
+	ctx, span := tracer.Start(ctx, "candidateService.FindAssigneesCalendar")
+	defer span.End()
+	span.SetAttributes(attribute.String("assignee.id", assigneeID))
+
+	// the feed does not reveal whether the assignee exists
+	assignee, err := service.assigneeRepository.ReadAssignee(ctx, assigneeID)
+	if errors.Is(err, model.ErrAssigneeDoesNotExist) {
+		return model.Assignee{}, nil, model.ErrCalendarTokenInvalid
+	}
+	if err != nil {
+		return model.Assignee{}, nil, err
+	}
+	if !calendarTokenMatches(assignee, token) {
+		return model.Assignee{}, nil, model.ErrCalendarTokenInvalid
+	}
+
+	from := time.Now().Add(-model.MeetingDuration)
+	meetings, err := service.candidateRepository.FindAssigneesMeetings(ctx, assigneeID, from)
+	if err != nil {
+		return model.Assignee{}, nil, err
+	}
+	candidates, err := service.candidateRepository.FindAssigneesCandidates(ctx, assigneeID)
+	if err != nil {
+		return model.Assignee{}, nil, err
+	}
+
+	assigned := make(map[string]model.Candidate, len(candidates))
+	for _, candidate := range candidates {
+		assigned[candidate.ID] = candidate
+	}
+
+	details := []model.MeetingDetails{}
+	recorded := make(map[string]bool, len(meetings))
+	for _, meeting := range meetings {
+		recorded[meeting.ID] = true
+		candidate, ok := assigned[meeting.CandidateID]
+		if !ok {
+			candidate, err = service.candidateRepository.ReadCandidate(ctx, meeting.CandidateID)
+			if errors.Is(err, model.ErrCandidateDoesNotExist) {
+				continue
+			}
+			if err != nil {
+				return model.Assignee{}, nil, err
+			}
+		}
+		details = append(details, model.MeetingDetails{Meeting: meeting, Candidate: candidate, Assignee: assignee})
+	}
+
+	for _, candidate := range candidates {
+		if candidate.NextMeeting == nil || candidate.NextMeeting.Before(from) || recorded[candidate.MeetingID] {
+			continue
+		}
+
+		meetingID := candidate.MeetingID
+		if meetingID == "" {
+			meetingID = candidate.ID
+		}
+		meeting := model.MeetingRecord{
+			ID: meetingID,
+			CandidateID: candidate.ID,
+			AssigneeID: assigneeID,
+			Time: *candidate.NextMeeting,
+			Status: model.MeetingArranged,
+		}
+		details = append(details, model.MeetingDetails{Meeting: meeting, Candidate: candidate, Assignee: assignee})
 	}
 
-	// Get assignees by department
-	// Each time a random assignee is chosen by department
-	a, _ := service.assigneeRepository.FindOneAssigneeByDepartment(ctx, c.Department)
-	if a == (model.Assignee{}) {
-		log.Println(model.ErrAssigneeDoesNotExist)
-		return model.ErrAssigneeDoesNotExist
+	sort.SliceStable(details, func(i, j int) bool { return details[i].Meeting.Time.Before(details[j].Meeting.Time) })
+	return assignee, details, nil
+}
+
+// publish publishes the event of the candidate if the events are enabled. It is called in the transaction of the change,
+// so a failure rolls back the change. Without the transactions, the change is already saved, so a failure is only logged.
+func (service *candidateService) publish(ctx context.Context, eventType string, candidate model.Candidate, meeting *model.MeetingRecord) error {
+	if service.events == nil {
+		return nil
+	}
+
+	event := model.DomainEvent{Type: eventType, Candidate: &candidate, Meeting: meeting}
+	err := service.events.Publish(ctx, event)
+	if err != nil {
+		log.Printf("Couldn't publish %s of candidate %s. Error is: %s\n", eventType, candidate.ID, err)
 	}
+	if service.transactions == nil {
+		return nil
+	}
+
+	return err
+}
 
-	c.NextMeeting = nextMeetingTime
+// inTransaction runs the change of the candidate and the publishing of its events in a transaction, if the service runs
+// with the transactions. Otherwise, the change is run without one, and its events are published after it is saved.
+func (service *candidateService) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if service.transactions == nil {
+		return fn(ctx)
+	}
+
+	return service.transactions.RunInTransaction(ctx, fn)
+}
+
+// ImportCandidates creates the given candidates one by one, and returns the outcome of each of them in the same order.
+// The candidates whose email exists, or is repeated in the given candidates, are not created.
+// In dry-run mode, nothing is created, and only the duplicate emails are reported.
+func (service *candidateService) ImportCandidates(ctx context.Context, candidates []model.Candidate, dryRun bool) []model.CandidateImportResult {
+	ctx, span := tracer.Start(ctx, "candidateService.ImportCandidates")
+	defer span.End()
+	span.SetAttributes(attribute.Int("import.candidates", len(candidates)), attribute.Bool("import.dry_run", dryRun))
+
+	results := make([]model.CandidateImportResult, len(candidates))
+	emails := make(map[string]bool)
+	for i, candidate := range candidates {
+		if emails[candidate.Email] {
+			results[i] = model.CandidateImportResult{Candidate: candidate, Err: model.ErrCandidateAlreadyExists}
+			continue
+		}
+		emails[candidate.Email] = true
+
+		if dryRun {
+			_, err := service.candidateRepository.FindCandidateByEmail(ctx, candidate.Email)
+			if err == nil {
+				err = model.ErrCandidateAlreadyExists
+			} else if errors.Is(err, model.ErrCandidateDoesNotExist) {
+				err = nil
+			}
+			results[i] = model.CandidateImportResult{Candidate: candidate, Err: err}
+			continue
+		}
 
+		created, err := service.CreateCandidate(ctx, candidate)
+		if err != nil {
+			log.Println(err)
+			created = candidate
+		}
+		results[i] = model.CandidateImportResult{Candidate: created, Err: err}
+	}
+
+	return results
+}
+
+// chooseAssignee chooses the assignee of the next meeting of the given candidate
+func (service *candidateService) chooseAssignee(ctx context.Context, c model.Candidate) (string, error) {
 	// if this will not be the last meeting of the candidate
 	// choose an assignee from the related department
 	if c.MeetingCount >= 0 && c.MeetingCount < 3 {
+		// Get assignees by department
+		// Each time a random assignee is chosen by department
+		a, err := service.assigneeRepository.FindOneAssigneeByDepartment(ctx, c.Department)
+		if err != nil {
+			return "", err
+		}
 		// Assign an assignee to the candidate according to the meeting count
-		c.Assignee = a.ID
+		return a.ID, nil
 
 	} else if c.MeetingCount == 3 {
 		// if current completed meeting number for the assignee is 3
@@ -138,39 +825,91 @@ func (service *candidateService) ArrangeMeeting(ctx context.Context, id string,
 		// arrange next meeting with the CEO
 
 		// Get the CEO
-		ceo, _ := service.assigneeRepository.FindOneAssigneeByDepartment(ctx, model.CEO)
-		if ceo == (model.Assignee{}) {
-			log.Println(model.ErrAssigneeDoesNotExist)
-			return model.ErrAssigneeDoesNotExist
+		ceo, err := service.assigneeRepository.FindOneAssigneeByDepartment(ctx, model.CEO)
+		if err != nil {
+			return "", err
 		}
 		// Assign the CEO to the candidate according in the last meeting
-		c.Assignee = ceo.ID
+		return ceo.ID, nil
 	}
 
-	return service.UpdateCandidate(ctx, id, c)
+	// the candidate has completed all of the meetings, keep the last assignee
+	return c.Assignee, nil
 }
 
-func (service *candidateService) CompleteMeeting(ctx context.Context, id string) error {
-	// Check candidate exists with given id, return error if does not exist.
-	c, _ := service.candidateRepository.ReadCandidate(ctx, id)
-	if c == (model.Candidate{}) {
-		log.Println(model.ErrCandidateDoesNotExist)
-		return model.ErrCandidateDoesNotExist
-	}
+// updateCandidate reads the candidate with given id, applies the given change and writes it back along with its event.
+// If another request updates the candidate in the meantime, the change is applied again to the
+// latest version of the candidate, so concurrent transitions never overwrite each other.
+// The arranged meeting of a candidate who is denied or expired is cancelled first, like it is cancelled by CancelMeeting.
+func (service *candidateService) updateCandidate(ctx context.Context, id string, eventType string, change func(c *model.Candidate) error) error {
+	return service.retryOnVersionConflict(id, func() error {
+		// Check candidate exists with given id, return error if does not exist.
+		c, err := service.candidateRepository.ReadCandidate(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	// if the next meeting is null, it means current candidate does not have
-	// any arranged meetings. then return an error accordingly.
-	if c.NextMeeting == nil {
-		log.Println(model.ErrArrangedMeetingDoesNotExist)
-		return model.ErrArrangedMeetingDoesNotExist
-	}
+		if err := change(&c); err != nil {
+			return err
+		}
+
+		return service.inTransaction(ctx, func(ctx context.Context) error {
+			if c.NextMeeting != nil && (c.Status == model.Denied || c.Status == model.Expired) {
+				if err := service.cancelArrangedMeeting(ctx, &c); err != nil {
+					return err
+				}
+			}
 
-	// set next meeting to nil and update meeting count by one.
-	c.NextMeeting = nil
-	if c.MeetingCount < 4 {
-		c.MeetingCount += 1
-		c.Status = model.InProgress
+			if err := service.candidateRepository.UpdateCandidate(ctx, id, c); err != nil {
+				return err
+			}
+
+			return service.publish(ctx, eventType, c, nil)
+		})
+	})
+}
+
+// cancelArrangedMeeting cancels the arranged meeting of the candidate, who is not met anymore because of its new status,
+// and publishes the cancellation, so the candidate gets the cancellation of the invitation.
+// The meeting is cancelled even if it has started, so it is not flagged as overdue. The candidate is updated
+// to the cancelled version, so the change of its status is written after the cancellation.
+func (service *candidateService) cancelArrangedMeeting(ctx context.Context, c *model.Candidate) error {
+	change := model.MeetingChange{
+		Type: model.MeetingCancelled,
+		At: time.Now(),
+		PreviousTime: *c.NextMeeting,
+		Reason: fmt.Sprintf("The candidate is %s", strings.ToLower(c.Status)),
+	}
+	cancelled, meeting, err := service.candidateRepository.CancelMeeting(ctx, c.ID, c.Version, change)
+	if err != nil {
+		return err
+	}
+	if meeting.ID == "" {
+		meeting = unrecordedMeeting(*c, change)
+		meeting.Status = model.MeetingCancelled
+	}
+	if err := service.publish(ctx, model.MeetingCancelledEvent, cancelled, &meeting); err != nil {
+		return err
 	}
 
-	return service.UpdateCandidate(ctx, id, c)
+	c.NextMeeting = cancelled.NextMeeting
+	c.MeetingID = cancelled.MeetingID
+	c.Version = cancelled.Version
+	return nil
+}
+
+// retryOnVersionConflict runs the given read-modify-write function of the candidate with given id again,
+// as long as it fails because the candidate was updated concurrently
+func (service *candidateService) retryOnVersionConflict(id string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err != nil {
+			log.Println(err)
+		}
+		if !errors.Is(err, model.ErrCandidateVersionConflict) || attempt == maxUpdateAttempts {
+			return err
+		}
+
+		log.Printf("Candidate with id %s was updated concurrently, retrying (attempt %d)\n", id, attempt)
+	}
 }
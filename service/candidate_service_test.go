@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/cemalunal/sample-internship-management-api/model"
 	"github.com/cemalunal/sample-internship-management-api/model/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 func TestCandidateService_CreateCandidate(t *testing.T) {
@@ -23,9 +25,6 @@ func TestCandidateService_CreateCandidate(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockCandidateRepository.On("FindCandidateByEmail", mock.Anything,
-			mock.AnythingOfType("string")).Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
-
 		mockCandidateRepository.On("CreateCandidate", mock.Anything,
 			mock.AnythingOfType("model.Candidate")).Return(model.Candidate{}, nil).Once()
 
@@ -39,9 +38,8 @@ func TestCandidateService_CreateCandidate(t *testing.T) {
 	})
 
 	t.Run("candidate-already-exists", func(t *testing.T) {
-		existingCandidate := mockCandidate
-		mockCandidateRepository.On("FindCandidateByEmail", mock.Anything,
-			mock.AnythingOfType("string")).Return(existingCandidate, nil).Once()
+		mockCandidateRepository.On("CreateCandidate", mock.Anything,
+			mock.AnythingOfType("model.Candidate")).Return(model.Candidate{}, model.ErrCandidateAlreadyExists).Once()
 
 		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
 		_, err := cService.CreateCandidate(context.TODO(), mockCandidate)
@@ -49,6 +47,96 @@ func TestCandidateService_CreateCandidate(t *testing.T) {
 		assert.Equal(t, err, model.ErrCandidateAlreadyExists)
 		mockCandidateRepository.AssertExpectations(t)
 	})
+
+	t.Run("backend-failure", func(t *testing.T) {
+		mockCandidateRepository.On("CreateCandidate", mock.Anything,
+			mock.AnythingOfType("model.Candidate")).Return(model.Candidate{}, errors.New("server selection timeout")).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		_, err := cService.CreateCandidate(context.TODO(), mockCandidate)
+
+		assert.EqualError(t, err, "server selection timeout")
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("publishes-event", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("CreateCandidate", mock.Anything, mock.AnythingOfType("model.Candidate")).
+			Return(func(ctx context.Context, candidate model.Candidate) model.Candidate { return candidate }, nil).Once()
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.CandidateCreatedEvent && event.Candidate.Email == "e@e.com" && event.Candidate.ID != ""
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents))
+		_, err := cService.CreateCandidate(context.TODO(), mockCandidate)
+
+		assert.NoError(t, err)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("publishes-event-in-transaction", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("CreateCandidate", inTransaction(), mock.AnythingOfType("model.Candidate")).
+			Return(func(ctx context.Context, candidate model.Candidate) model.Candidate { return candidate }, nil).Once()
+		mockEvents.On("Publish", inTransaction(), mock.Anything).Return(nil).Once()
+		transactions := &fakeTransactions{}
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents), WithTransactions(transactions))
+		_, err := cService.CreateCandidate(context.TODO(), mockCandidate)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, transactions.runs)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("event-is-not-stored", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("CreateCandidate", inTransaction(), mock.AnythingOfType("model.Candidate")).
+			Return(func(ctx context.Context, candidate model.Candidate) model.Candidate { return candidate }, nil).Once()
+		mockEvents.On("Publish", inTransaction(), mock.Anything).Return(errors.New("server selection timeout")).Once()
+
+		// the transaction is rolled back, so the candidate is not created without its event
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents), WithTransactions(&fakeTransactions{}))
+		_, err := cService.CreateCandidate(context.TODO(), mockCandidate)
+
+		assert.EqualError(t, err, "server selection timeout")
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("event-failure-without-transactions", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("CreateCandidate", mock.Anything, mock.AnythingOfType("model.Candidate")).
+			Return(func(ctx context.Context, candidate model.Candidate) model.Candidate { return candidate }, nil).Once()
+		mockEvents.On("Publish", mock.Anything, mock.Anything).Return(errors.New("server selection timeout")).Once()
+
+		// the candidate is already saved, so the failure is only logged
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents))
+		_, err := cService.CreateCandidate(context.TODO(), mockCandidate)
+
+		assert.NoError(t, err)
+		mockEvents.AssertExpectations(t)
+	})
+}
+
+// transactionKey marks the context of the functions that run in a fakeTransactions
+type transactionKey struct{}
+
+// fakeTransactions runs the functions right away with a marked context, and counts the transactions
+type fakeTransactions struct {
+	runs int
+}
+
+func (transactions *fakeTransactions) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	transactions.runs++
+	return fn(context.WithValue(ctx, transactionKey{}, true))
+}
+
+// inTransaction matches the context of a function that runs in a fakeTransactions
+func inTransaction() interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value(transactionKey{}) != nil
+	})
 }
 
 func TestCandidateService_UpdateCandidate(t *testing.T) {
@@ -65,15 +153,49 @@ func TestCandidateService_UpdateCandidate(t *testing.T) {
 		Assignee: "123123123123",
 	}
 
+	mockCandidate.Version = 3
+	mockStoredCandidate := mockCandidate
+	mockStoredCandidate.FirstName = "Old FN"
+	mockStoredCandidate.Status = model.InProgress
+
 	t.Run("success", func(t *testing.T) {
-		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mock.AnythingOfType("string"), mockCandidate).Once().Return(nil)
+		mockUpdatedCandidate := mockCandidate
+		mockUpdatedCandidate.Status = model.InProgress
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockStoredCandidate, nil).Once()
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, mockUpdatedCandidate).Once().Return(nil)
 
 		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
-		err := cService.UpdateCandidate(context.TODO(), mockCandidate.ID, mockCandidate)
+		updatedCandidate, err := cService.UpdateCandidate(context.TODO(), mockCandidate.ID, mockCandidate)
+
 		assert.NoError(t, err)
+		assert.Equal(t, int64(4), updatedCandidate.Version)
+		assert.Equal(t, model.InProgress, updatedCandidate.Status)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("stale-version", func(t *testing.T) {
+		staleCandidate := mockCandidate
+		staleCandidate.Version = 2
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockStoredCandidate, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		_, err := cService.UpdateCandidate(context.TODO(), mockCandidate.ID, staleCandidate)
+
+		assert.Equal(t, model.ErrCandidateModified, err)
 		mockCandidateRepository.AssertExpectations(t)
 	})
 
+	t.Run("modified-concurrently", func(t *testing.T) {
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockStoredCandidate, nil).Once()
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, mock.AnythingOfType("model.Candidate")).
+			Once().Return(model.ErrCandidateVersionConflict)
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		_, err := cService.UpdateCandidate(context.TODO(), mockCandidate.ID, mockCandidate)
+
+		assert.Equal(t, model.ErrCandidateModified, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
 }
 
 func TestCandidateService_ReadCandidate(t *testing.T) {
@@ -100,6 +222,17 @@ func TestCandidateService_ReadCandidate(t *testing.T) {
 		assert.NoError(t, err)
 		mockCandidateRepository.AssertExpectations(t)
 	})
+
+	t.Run("candidate-does-not-exist", func(t *testing.T) {
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).
+			Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		_, err := cService.ReadCandidate(context.TODO(), mockCandidate.ID)
+
+		assert.Equal(t, model.ErrCandidateDoesNotExist, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
 }
 
 func TestCandidateService_FindAllCandidates(t *testing.T) {
@@ -148,6 +281,39 @@ func TestCandidateService_FindAllCandidates(t *testing.T) {
 	})
 }
 
+func TestCandidateService_FindCandidates(t *testing.T) {
+	t.Run("searches-attachments", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAttachmentService := new(mocks.AttachmentService)
+		mockAttachmentService.On("FindCandidateIDsByText", mock.Anything, "Go").Return([]string{"abcd"}, nil).Once()
+		mockAttachmentService.On("FindCandidateIDsByText", mock.Anything, "Kubernetes").Return([]string{}, nil).Once()
+		mockCandidateRepository.On("FindCandidates", mock.Anything, model.CandidateFilter{
+			Status: model.Pending,
+			Search: []model.SearchTerm{{Word: "Go", AttachedCandidateIDs: []string{"abcd"}}, {Word: "Kubernetes", AttachedCandidateIDs: []string{}}},
+		}).Return([]model.Candidate{{ID: "abcd"}}, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository), WithAttachments(mockAttachmentService))
+		candidates, err := cService.FindCandidates(context.TODO(), model.CandidateFilter{Status: model.Pending, Search: model.NewSearchTerms("Go Kubernetes")})
+
+		assert.NoError(t, err)
+		assert.Len(t, candidates, 1)
+		mockCandidateRepository.AssertExpectations(t)
+		mockAttachmentService.AssertExpectations(t)
+	})
+
+	t.Run("attachment-search-failure", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAttachmentService := new(mocks.AttachmentService)
+		mockAttachmentService.On("FindCandidateIDsByText", mock.Anything, "Go").Return(nil, errors.New("server selection timeout")).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository), WithAttachments(mockAttachmentService))
+		_, err := cService.FindCandidates(context.TODO(), model.CandidateFilter{Search: model.NewSearchTerms("Go")})
+
+		assert.EqualError(t, err, "server selection timeout")
+		mockCandidateRepository.AssertNotCalled(t, "FindCandidates", mock.Anything, mock.Anything)
+	})
+}
+
 func TestCandidateService_FindCandidateByEmail(t *testing.T) {
 	mockCandidateRepository := new(mocks.CandidateRepository)
 	mockAssigneeRepository := new(mocks.AssigneeRepository)
@@ -255,7 +421,6 @@ func TestCandidateService_DeleteCandidate(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(mockCandidate, nil).Once()
 		mockCandidateRepository.On("DeleteCandidate", mock.Anything, mock.AnythingOfType("string")).Return(nil).Once()
 
 		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
@@ -267,7 +432,7 @@ func TestCandidateService_DeleteCandidate(t *testing.T) {
 	})
 
 	t.Run("candidate-does-not-exist", func(t *testing.T) {
-		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(model.Candidate{}, nil).Once()
+		mockCandidateRepository.On("DeleteCandidate", mock.Anything, mock.AnythingOfType("string")).Return(model.ErrCandidateDoesNotExist).Once()
 
 		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
 
@@ -276,6 +441,42 @@ func TestCandidateService_DeleteCandidate(t *testing.T) {
 		assert.Equal(t, err, model.ErrCandidateDoesNotExist)
 		mockCandidateRepository.AssertExpectations(t)
 	})
+
+	t.Run("deletes-attachments", func(t *testing.T) {
+		mockAttachmentService := new(mocks.AttachmentService)
+		mockCandidateRepository.On("DeleteCandidate", mock.Anything, "123asd123").Return(nil).Once()
+		mockAttachmentService.On("DeleteCandidatesAttachments", mock.Anything, "123asd123").Return(errors.New("disk failure")).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithAttachments(mockAttachmentService))
+
+		// the candidate is deleted even if its attachments could not be deleted
+		err := cService.DeleteCandidate(context.TODO(), "123asd123")
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockAttachmentService.AssertExpectations(t)
+	})
+
+	t.Run("keeps-attachments-of-missing-candidate", func(t *testing.T) {
+		mockAttachmentService := new(mocks.AttachmentService)
+		mockCandidateRepository.On("DeleteCandidate", mock.Anything, "123asd123").Return(model.ErrCandidateDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithAttachments(mockAttachmentService))
+
+		err := cService.DeleteCandidate(context.TODO(), "123asd123")
+
+		assert.Equal(t, err, model.ErrCandidateDoesNotExist)
+		mockAttachmentService.AssertNotCalled(t, "DeleteCandidatesAttachments", mock.Anything, mock.Anything)
+	})
+}
+
+// decidedCandidate matches the expected candidate along with the time that the service has decided it
+func decidedCandidate(expected model.Candidate) interface{} {
+	return mock.MatchedBy(func(candidate model.Candidate) bool {
+		decidedAt := candidate.DecidedAt
+		candidate.DecidedAt = nil
+		return decidedAt != nil && assert.ObjectsAreEqual(expected, candidate)
+	})
 }
 
 func TestCandidateService_DenyCandidate(t *testing.T) {
@@ -297,7 +498,7 @@ func TestCandidateService_DenyCandidate(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(mockCandidate, nil).Once()
-		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, mockDeniedCandidate).Once().Return(nil)
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, decidedCandidate(mockDeniedCandidate)).Once().Return(nil)
 
 		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
 		err := cService.DenyCandidate(context.TODO(), mockCandidate.ID)
@@ -306,8 +507,56 @@ func TestCandidateService_DenyCandidate(t *testing.T) {
 		mockCandidateRepository.AssertExpectations(t)
 	})
 
+	t.Run("publishes-event-in-transaction", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockCandidateRepository.On("UpdateCandidate", inTransaction(), mockCandidate.ID, decidedCandidate(mockDeniedCandidate)).Once().Return(nil)
+		mockEvents.On("Publish", inTransaction(), mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.CandidateDeniedEvent && event.Candidate.Status == model.Denied
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents), WithTransactions(&fakeTransactions{}))
+		err := cService.DenyCandidate(context.TODO(), mockCandidate.ID)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("cancels-arranged-meeting", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		meetingTime := time.Now().Add(time.Hour)
+		arranged := mockCandidate
+		arranged.NextMeeting = &meetingTime
+		arranged.MeetingID = "m1"
+		arranged.Version = 3
+		cancelled := mockCandidate
+		cancelled.Version = 4
+		deniedAfterCancel := mockDeniedCandidate
+		deniedAfterCancel.Version = 4
+		meeting := model.MeetingRecord{ID: "m1", CandidateID: mockCandidate.ID, Time: meetingTime, Status: model.MeetingCancelled}
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(arranged, nil).Once()
+		mockCandidateRepository.On("CancelMeeting", inTransaction(), mockCandidate.ID, int64(3), mock.MatchedBy(func(change model.MeetingChange) bool {
+			return change.Type == model.MeetingCancelled && change.PreviousTime.Equal(meetingTime) && change.Reason == "The candidate is denied"
+		})).Return(cancelled, meeting, nil).Once()
+		mockCandidateRepository.On("UpdateCandidate", inTransaction(), mockCandidate.ID, decidedCandidate(deniedAfterCancel)).Once().Return(nil)
+		mockEvents.On("Publish", inTransaction(), mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.MeetingCancelledEvent && event.Meeting.ID == "m1"
+		})).Return(nil).Once()
+		mockEvents.On("Publish", inTransaction(), mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.CandidateDeniedEvent && event.Candidate.NextMeeting == nil
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents), WithTransactions(&fakeTransactions{}))
+		err := cService.DenyCandidate(context.TODO(), mockCandidate.ID)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
 	t.Run("candidate-does-not-exist", func(t *testing.T) {
-		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(model.Candidate{}, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
 		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
 
 		err := cService.DenyCandidate(context.TODO(), mockCandidate.ID)
@@ -315,6 +564,60 @@ func TestCandidateService_DenyCandidate(t *testing.T) {
 		assert.Equal(t, err, model.ErrCandidateDoesNotExist)
 		mockCandidateRepository.AssertExpectations(t)
 	})
+
+	t.Run("retry-on-version-conflict", func(t *testing.T) {
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(mockCandidate, nil).Twice()
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, decidedCandidate(mockDeniedCandidate)).
+			Once().Return(model.ErrCandidateVersionConflict)
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, decidedCandidate(mockDeniedCandidate)).Once().Return(nil)
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.DenyCandidate(context.TODO(), mockCandidate.ID)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("backend-failure", func(t *testing.T) {
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).
+			Return(model.Candidate{}, errors.New("server selection timeout")).Once()
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+
+		err := cService.DenyCandidate(context.TODO(), mockCandidate.ID)
+
+		assert.EqualError(t, err, "server selection timeout")
+		assert.False(t, errors.Is(err, model.ErrCandidateDoesNotExist))
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("publishes-event", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(mockCandidate, nil).Once()
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, decidedCandidate(mockDeniedCandidate)).Once().Return(nil)
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.CandidateDeniedEvent && event.Candidate.DecidedAt != nil && event.Candidate.Status == model.Denied
+		})).
+			Return(errors.New("server selection timeout")).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents))
+		err := cService.DenyCandidate(context.TODO(), mockCandidate.ID)
+
+		// the candidate is denied even if the event cannot be stored
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("does-not-publish-on-failure", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents))
+		err := cService.DenyCandidate(context.TODO(), mockCandidate.ID)
+
+		assert.Equal(t, err, model.ErrCandidateDoesNotExist)
+		mockEvents.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
 }
 
 func TestCandidateService_AcceptCandidate(t *testing.T) {
@@ -339,7 +642,7 @@ func TestCandidateService_AcceptCandidate(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(mockCandidate, nil).Once()
-		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, mockAcceptedCandidate).Once().Return(nil)
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, decidedCandidate(mockAcceptedCandidate)).Once().Return(nil)
 
 		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
 		err := cService.AcceptCandidate(context.TODO(), mockCandidate.ID)
@@ -359,7 +662,7 @@ func TestCandidateService_AcceptCandidate(t *testing.T) {
 	})
 
 	t.Run("candidate-does-not-exist", func(t *testing.T) {
-		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(model.Candidate{}, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mock.AnythingOfType("string")).Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
 
 		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
 		err := cService.AcceptCandidate(context.TODO(), mockCandidate.ID)
@@ -368,3 +671,696 @@ func TestCandidateService_AcceptCandidate(t *testing.T) {
 		mockCandidateRepository.AssertExpectations(t)
 	})
 }
+
+func TestCandidateService_ArrangeMeeting(t *testing.T) {
+	mockCandidateRepository := new(mocks.CandidateRepository)
+	mockAssigneeRepository := new(mocks.AssigneeRepository)
+	mockCandidate := model.Candidate{
+		ID: "123asd123",
+		Email: "e@e.com",
+		Department: model.Development,
+		University: "HU",
+		Status: model.InProgress,
+		MeetingCount: 1,
+		Version: 7,
+	}
+	mockAssignee := model.Assignee{
+		ID: "asd123dsa",
+		Name: "A1",
+		Department: model.Development,
+	}
+	mockCEO := model.Assignee{
+		ID: "ceo123",
+		Name: "CEO",
+		Department: model.CEO,
+	}
+	nextMeetingTime := time.Date(2020, 5, 3, 13, 40, 0, 0, time.UTC)
+	meetingWith := func(assigneeID string) interface{} {
+		return mock.MatchedBy(func(meeting model.MeetingRecord) bool {
+			return meeting.ID != "" && meeting.CandidateID == mockCandidate.ID && meeting.AssigneeID == assigneeID &&
+				meeting.Time.Equal(nextMeetingTime) && meeting.Status == model.MeetingArranged
+		})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockAssigneeRepository.On("FindOneAssigneeByDepartment", mock.Anything, model.Development).Return(mockAssignee, nil).Once()
+		mockCandidateRepository.On("ArrangeMeeting", mock.Anything, mockCandidate.ID, mockCandidate.Version, meetingWith(mockAssignee.ID)).
+			Return(mockCandidate, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.ArrangeMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockAssigneeRepository.AssertExpectations(t)
+	})
+
+	t.Run("publishes-event", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockAssigneeRepository.On("FindOneAssigneeByDepartment", mock.Anything, model.Development).Return(mockAssignee, nil).Once()
+		mockCandidateRepository.On("ArrangeMeeting", mock.Anything, mockCandidate.ID, mockCandidate.Version, meetingWith(mockAssignee.ID)).
+			Return(mockCandidate, nil).Once()
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.MeetingArrangedEvent && event.Candidate.ID == mockCandidate.ID &&
+				event.Meeting != nil && event.Meeting.AssigneeID == mockAssignee.ID && event.Meeting.Time.Equal(nextMeetingTime)
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents))
+		err := cService.ArrangeMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("last-meeting-with-ceo", func(t *testing.T) {
+		lastMeetingCandidate := mockCandidate
+		lastMeetingCandidate.MeetingCount = 3
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(lastMeetingCandidate, nil).Once()
+		mockAssigneeRepository.On("FindOneAssigneeByDepartment", mock.Anything, model.CEO).Return(mockCEO, nil).Once()
+		mockCandidateRepository.On("ArrangeMeeting", mock.Anything, mockCandidate.ID, mockCandidate.Version, meetingWith(mockCEO.ID)).
+			Return(lastMeetingCandidate, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.ArrangeMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockAssigneeRepository.AssertExpectations(t)
+	})
+
+	t.Run("retry-on-version-conflict", func(t *testing.T) {
+		updatedCandidate := mockCandidate
+		updatedCandidate.Version = 8
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(updatedCandidate, nil).Once()
+		mockAssigneeRepository.On("FindOneAssigneeByDepartment", mock.Anything, model.Development).Return(mockAssignee, nil).Twice()
+		mockCandidateRepository.On("ArrangeMeeting", mock.Anything, mockCandidate.ID, mockCandidate.Version, meetingWith(mockAssignee.ID)).
+			Return(model.Candidate{}, model.ErrCandidateVersionConflict).Once()
+		mockCandidateRepository.On("ArrangeMeeting", mock.Anything, mockCandidate.ID, updatedCandidate.Version, meetingWith(mockAssignee.ID)).
+			Return(updatedCandidate, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.ArrangeMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockAssigneeRepository.AssertExpectations(t)
+	})
+
+	t.Run("assignee-does-not-exist", func(t *testing.T) {
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockAssigneeRepository.On("FindOneAssigneeByDepartment", mock.Anything, model.Development).
+			Return(model.Assignee{}, model.ErrAssigneeDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.ArrangeMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.Equal(t, model.ErrAssigneeDoesNotExist, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockAssigneeRepository.AssertExpectations(t)
+	})
+
+	t.Run("meeting-already-arranged", func(t *testing.T) {
+		arrangedCandidate := mockCandidate
+		arrangedCandidate.NextMeeting = &nextMeetingTime
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(arrangedCandidate, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.ArrangeMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.Equal(t, model.ErrMeetingAlreadyArranged, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+}
+
+func TestCandidateService_RescheduleMeeting(t *testing.T) {
+	meetingTime := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	nextMeetingTime := meetingTime.Add(48 * time.Hour)
+	mockCandidate := model.Candidate{ID: "123asd123", Email: "e@e.com", Assignee: "asd123dsa", NextMeeting: &meetingTime, MeetingID: "m1", Version: 7}
+	rescheduledMeeting := model.MeetingRecord{ID: "m1", CandidateID: "123asd123", AssigneeID: "asd123dsa", Time: nextMeetingTime,
+		Status: model.MeetingArranged, Sequence: 1}
+	rescheduled := mock.MatchedBy(func(change model.MeetingChange) bool {
+		return change.Type == model.MeetingRescheduled && change.PreviousTime.Equal(meetingTime) && change.Time.Equal(nextMeetingTime)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockCandidateRepository.On("RescheduleMeeting", mock.Anything, mockCandidate.ID, mockCandidate.Version, rescheduled).
+			Return(mockCandidate, rescheduledMeeting, nil).Once()
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.MeetingRescheduledEvent && event.Meeting != nil && event.Meeting.ID == "m1"
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository), WithEvents(mockEvents))
+		err := cService.RescheduleMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("meeting-time-in-past", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		pastTime := time.Now().Add(-time.Minute)
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository))
+		err := cService.RescheduleMeeting(context.TODO(), mockCandidate.ID, &pastTime)
+
+		assert.Equal(t, model.ErrMeetingTimeInPast, err)
+		mockCandidateRepository.AssertNotCalled(t, "RescheduleMeeting", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("arranged-meeting-does-not-exist", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).
+			Return(model.Candidate{ID: mockCandidate.ID, Version: 8}, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository))
+		err := cService.RescheduleMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.Equal(t, model.ErrArrangedMeetingDoesNotExist, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("meeting-is-not-recorded", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockEvents := new(mocks.EventPublisher)
+		unrecordedCandidate := mockCandidate
+		unrecordedCandidate.MeetingID = ""
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(unrecordedCandidate, nil).Once()
+		mockCandidateRepository.On("RescheduleMeeting", mock.Anything, mockCandidate.ID, mockCandidate.Version, rescheduled).
+			Return(unrecordedCandidate, model.MeetingRecord{}, nil).Once()
+		// the calendar event of the meeting has the id of the candidate
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Meeting.ID == mockCandidate.ID && event.Meeting.AssigneeID == "asd123dsa" && event.Meeting.Time.Equal(nextMeetingTime)
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository), WithEvents(mockEvents))
+		err := cService.RescheduleMeeting(context.TODO(), mockCandidate.ID, &nextMeetingTime)
+
+		assert.NoError(t, err)
+		mockEvents.AssertExpectations(t)
+	})
+}
+
+func TestCandidateService_CancelMeeting(t *testing.T) {
+	meetingTime := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	mockCandidate := model.Candidate{ID: "123asd123", Email: "e@e.com", Assignee: "asd123dsa", NextMeeting: &meetingTime, MeetingID: "m1", Version: 7}
+	cancelledMeeting := model.MeetingRecord{ID: "m1", CandidateID: "123asd123", AssigneeID: "asd123dsa", Time: meetingTime,
+		Status: model.MeetingCancelled, Sequence: 1}
+	cancelled := mock.MatchedBy(func(change model.MeetingChange) bool {
+		return change.Type == model.MeetingCancelled && change.PreviousTime.Equal(meetingTime) && change.Reason == "The assignee is on leave"
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockCandidateRepository.On("CancelMeeting", mock.Anything, mockCandidate.ID, mockCandidate.Version, cancelled).
+			Return(model.Candidate{ID: mockCandidate.ID, Version: 8}, cancelledMeeting, nil).Once()
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.MeetingCancelledEvent && event.Meeting.Status == model.MeetingCancelled
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository), WithEvents(mockEvents))
+		err := cService.CancelMeeting(context.TODO(), mockCandidate.ID, "The assignee is on leave")
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("arranged-meeting-does-not-exist", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).
+			Return(model.Candidate{ID: mockCandidate.ID, Version: 8}, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository))
+		err := cService.CancelMeeting(context.TODO(), mockCandidate.ID, "The assignee is on leave")
+
+		assert.Equal(t, model.ErrArrangedMeetingDoesNotExist, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("meeting-already-started", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		startedAt := time.Now().Add(-10 * time.Minute)
+		startedCandidate := mockCandidate
+		startedCandidate.NextMeeting = &startedAt
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(startedCandidate, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository))
+		err := cService.CancelMeeting(context.TODO(), mockCandidate.ID, "The assignee is on leave")
+
+		assert.Equal(t, model.ErrMeetingAlreadyStarted, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+}
+
+func TestCandidateService_RecordNoShow(t *testing.T) {
+	meetingTime := time.Now().Add(-30 * time.Minute).Truncate(time.Second)
+	mockCandidate := model.Candidate{ID: "123asd123", Email: "e@e.com", Status: model.InProgress, MeetingCount: 1,
+		NoShowCount: 1, NextMeeting: &meetingTime, MeetingID: "m1", Version: 7}
+	missedCandidate := mockCandidate
+	missedCandidate.NextMeeting = nil
+	missedCandidate.MeetingID = ""
+	missedCandidate.NoShowCount = 2
+	missedCandidate.Version = 8
+	noShow := mock.MatchedBy(func(change model.MeetingChange) bool {
+		return change.Type == model.MeetingNoShow && change.PreviousTime.Equal(meetingTime)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockCandidateRepository.On("RecordNoShow", mock.Anything, mockCandidate.ID, mockCandidate.Version, noShow).
+			Return(missedCandidate, model.MeetingRecord{ID: "m1", Status: model.MeetingNoShow}, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository))
+		err := cService.RecordNoShow(context.TODO(), mockCandidate.ID)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("candidate-is-denied-by-policy", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockCandidateRepository.On("RecordNoShow", mock.Anything, mockCandidate.ID, mockCandidate.Version, noShow).
+			Return(missedCandidate, model.MeetingRecord{ID: "m1", Status: model.MeetingNoShow}, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(missedCandidate, nil).Once()
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, mockCandidate.ID, mock.MatchedBy(func(c model.Candidate) bool {
+			return c.Status == model.Denied && c.Version == missedCandidate.Version
+		})).Return(nil).Once()
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.MeetingNoShowEvent && event.Meeting.ID == "m1"
+		})).Return(nil).Once()
+		// the denial is published like the denial of any other denied candidate
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.CandidateDeniedEvent
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository),
+			WithEvents(mockEvents), WithNoShowPolicy(2))
+		err := cService.RecordNoShow(context.TODO(), mockCandidate.ID)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("policy-is-not-reached", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(mockCandidate, nil).Once()
+		mockCandidateRepository.On("RecordNoShow", mock.Anything, mockCandidate.ID, mockCandidate.Version, noShow).
+			Return(missedCandidate, model.MeetingRecord{ID: "m1", Status: model.MeetingNoShow}, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository), WithNoShowPolicy(3))
+		err := cService.RecordNoShow(context.TODO(), mockCandidate.ID)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+		mockCandidateRepository.AssertNotCalled(t, "UpdateCandidate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("meeting-not-started", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		upcomingTime := time.Now().Add(time.Hour)
+		upcomingCandidate := mockCandidate
+		upcomingCandidate.NextMeeting = &upcomingTime
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(upcomingCandidate, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository))
+		err := cService.RecordNoShow(context.TODO(), mockCandidate.ID)
+
+		assert.Equal(t, model.ErrMeetingNotStarted, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("arranged-meeting-does-not-exist", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, mockCandidate.ID).Return(missedCandidate, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository))
+		err := cService.RecordNoShow(context.TODO(), mockCandidate.ID)
+
+		assert.Equal(t, model.ErrArrangedMeetingDoesNotExist, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+}
+
+func TestCandidateService_CompleteMeeting(t *testing.T) {
+	mockCandidateRepository := new(mocks.CandidateRepository)
+	mockAssigneeRepository := new(mocks.AssigneeRepository)
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository.On("CompleteMeeting", mock.Anything, "123asd123", mock.AnythingOfType("time.Time")).
+			Return(model.Candidate{ID: "123asd123", MeetingCount: 2}, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.CompleteMeeting(context.TODO(), "123asd123")
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("arranged-meeting-does-not-exist", func(t *testing.T) {
+		mockCandidateRepository.On("CompleteMeeting", mock.Anything, "123asd123", mock.AnythingOfType("time.Time")).
+			Return(model.Candidate{}, model.ErrArrangedMeetingDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.CompleteMeeting(context.TODO(), "123asd123")
+
+		assert.Equal(t, model.ErrArrangedMeetingDoesNotExist, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("publishes-event", func(t *testing.T) {
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("CompleteMeeting", mock.Anything, "123asd123", mock.AnythingOfType("time.Time")).
+			Return(model.Candidate{ID: "123asd123", MeetingCount: 2}, nil).Once()
+		mockEvents.On("Publish", mock.Anything, model.DomainEvent{Type: model.MeetingCompletedEvent,
+			Candidate: &model.Candidate{ID: "123asd123", MeetingCount: 2}}).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository, WithEvents(mockEvents))
+		err := cService.CompleteMeeting(context.TODO(), "123asd123")
+
+		assert.NoError(t, err)
+		mockEvents.AssertExpectations(t)
+	})
+}
+
+func TestCandidateService_ReadMeeting(t *testing.T) {
+	meeting := model.MeetingRecord{ID: "m1", CandidateID: "123asd123", AssigneeID: "asd123dsa", Status: model.MeetingArranged}
+	candidate := model.Candidate{ID: "123asd123", Email: "e@e.com", MeetingID: "m1"}
+	assignee := model.Assignee{ID: "asd123dsa", Name: "A1", Department: model.Development}
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockCandidateRepository.On("ReadMeeting", mock.Anything, "m1").Return(meeting, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "123asd123").Return(candidate, nil).Once()
+		mockAssigneeRepository.On("ReadAssignee", mock.Anything, "asd123dsa").Return(assignee, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		details, err := cService.ReadMeeting(context.TODO(), "m1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.MeetingDetails{Meeting: meeting, Candidate: candidate, Assignee: assignee}, details)
+	})
+
+	t.Run("assignee-is-deleted", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockCandidateRepository.On("ReadMeeting", mock.Anything, "m1").Return(meeting, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "123asd123").Return(candidate, nil).Once()
+		mockAssigneeRepository.On("ReadAssignee", mock.Anything, "asd123dsa").Return(model.Assignee{}, model.ErrAssigneeDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		details, err := cService.ReadMeeting(context.TODO(), "m1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.Assignee{ID: "asd123dsa"}, details.Assignee)
+	})
+
+	t.Run("candidate-is-deleted", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockCandidateRepository.On("ReadMeeting", mock.Anything, "m1").Return(meeting, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "123asd123").Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		_, err := cService.ReadMeeting(context.TODO(), "m1")
+
+		assert.Equal(t, model.ErrMeetingDoesNotExist, err)
+	})
+}
+
+func TestCandidateService_FindOverdueMeetings(t *testing.T) {
+	meetings := []model.MeetingRecord{
+		{ID: "m1", CandidateID: "123asd123", AssigneeID: "asd123dsa", Status: model.MeetingArranged, Overdue: true},
+		{ID: "m2", CandidateID: "deleted", AssigneeID: "asd123dsa", Status: model.MeetingArranged, Overdue: true},
+	}
+	candidate := model.Candidate{ID: "123asd123", Email: "e@e.com", MeetingID: "m1"}
+
+	mockCandidateRepository := new(mocks.CandidateRepository)
+	mockAssigneeRepository := new(mocks.AssigneeRepository)
+	mockCandidateRepository.On("FindOverdueMeetings", mock.Anything).Return(meetings, nil).Once()
+	mockCandidateRepository.On("ReadCandidate", mock.Anything, "123asd123").Return(candidate, nil).Once()
+	mockCandidateRepository.On("ReadCandidate", mock.Anything, "deleted").Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
+	mockAssigneeRepository.On("ReadAssignee", mock.Anything, "asd123dsa").Return(model.Assignee{}, model.ErrAssigneeDoesNotExist).Once()
+
+	cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+	details, err := cService.FindOverdueMeetings(context.TODO())
+
+	// the meetings of the deleted candidates are skipped
+	assert.NoError(t, err)
+	assert.Equal(t, []model.MeetingDetails{{Meeting: meetings[0], Candidate: candidate, Assignee: model.Assignee{ID: "asd123dsa"}}}, details)
+	mockAssigneeRepository.AssertExpectations(t)
+}
+
+func TestCandidateService_RecordMeetingFeedback(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockCandidateRepository.On("ReadMeeting", mock.Anything, "m1").Return(model.MeetingRecord{ID: "m1", Status: model.MeetingCompleted}, nil).Once()
+		mockCandidateRepository.On("RecordMeetingFeedback", mock.Anything, "m1", 4).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.RecordMeetingFeedback(context.TODO(), "m1", 4)
+
+		assert.NoError(t, err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("meeting-not-completed", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockCandidateRepository.On("ReadMeeting", mock.Anything, "m1").Return(model.MeetingRecord{ID: "m1", Status: model.MeetingArranged}, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.RecordMeetingFeedback(context.TODO(), "m1", 4)
+
+		assert.Equal(t, model.ErrMeetingNotCompleted, err)
+		mockCandidateRepository.AssertNotCalled(t, "RecordMeetingFeedback", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("meeting-does-not-exist", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockCandidateRepository.On("ReadMeeting", mock.Anything, "m1").Return(model.MeetingRecord{}, model.ErrMeetingDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		err := cService.RecordMeetingFeedback(context.TODO(), "m1", 4)
+
+		assert.Equal(t, model.ErrMeetingDoesNotExist, err)
+	})
+}
+
+func TestCandidateService_FindAssigneesCalendar(t *testing.T) {
+	assignee := model.Assignee{ID: "a1", Name: "Zafer", Department: model.Design, CalendarTokenHash: hashCalendarToken("secret")}
+	now := time.Now()
+	later, evenLater := now.Add(time.Hour), now.Add(2*time.Hour)
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockAssigneeRepository.On("ReadAssignee", mock.Anything, "a1").Return(assignee, nil).Once()
+		mockCandidateRepository.On("FindAssigneesMeetings", mock.Anything, "a1", mock.AnythingOfType("time.Time")).Return([]model.MeetingRecord{
+			{ID: "m1", CandidateID: "c1", AssigneeID: "a1", Time: evenLater, Status: model.MeetingArranged},
+			// the candidate is assigned to another assignee for its next meeting
+			{ID: "m2", CandidateID: "c2", AssigneeID: "a1", Time: later, Status: model.MeetingArranged},
+			// the candidate is deleted
+			{ID: "m3", CandidateID: "c3", AssigneeID: "a1", Time: later, Status: model.MeetingArranged},
+		}, nil).Once()
+		mockCandidateRepository.On("FindAssigneesCandidates", mock.Anything, "a1").Return([]model.Candidate{
+			{ID: "c1", Assignee: "a1", NextMeeting: &evenLater, MeetingID: "m1"},
+			// the meeting is arranged before the meeting records were kept
+			{ID: "c4", Assignee: "a1", NextMeeting: &now},
+			{ID: "c5", Assignee: "a1"},
+		}, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "c2").Return(model.Candidate{ID: "c2", Assignee: "a2"}, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, "c3").Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		foundAssignee, meetings, err := cService.FindAssigneesCalendar(context.TODO(), "a1", "secret")
+
+		assert.NoError(t, err)
+		assert.Equal(t, assignee, foundAssignee)
+		var ids []string
+		for _, meeting := range meetings {
+			ids = append(ids, meeting.Meeting.ID+"/"+meeting.Candidate.ID)
+			assert.Equal(t, assignee, meeting.Assignee)
+		}
+		assert.Equal(t, []string{"c4/c4", "m2/c2", "m1/c1"}, ids)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("token-is-not-valid", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockAssigneeRepository.On("ReadAssignee", mock.Anything, "a1").Return(assignee, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		_, _, err := cService.FindAssigneesCalendar(context.TODO(), "a1", "guess")
+
+		assert.Equal(t, model.ErrCalendarTokenInvalid, err)
+		mockCandidateRepository.AssertNotCalled(t, "FindAssigneesMeetings", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("token-is-not-created", func(t *testing.T) {
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockAssigneeRepository.On("ReadAssignee", mock.Anything, "a1").Return(model.Assignee{ID: "a1"}, nil).Once()
+
+		cService := CandidateService(new(mocks.CandidateRepository), mockAssigneeRepository)
+		_, _, err := cService.FindAssigneesCalendar(context.TODO(), "a1", "")
+
+		assert.Equal(t, model.ErrCalendarTokenInvalid, err)
+	})
+
+	t.Run("assignee-does-not-exist", func(t *testing.T) {
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockAssigneeRepository.On("ReadAssignee", mock.Anything, "a1").Return(model.Assignee{}, model.ErrAssigneeDoesNotExist).Once()
+
+		cService := CandidateService(new(mocks.CandidateRepository), mockAssigneeRepository)
+		_, _, err := cService.FindAssigneesCalendar(context.TODO(), "a1", "secret")
+
+		// the existence of the assignee is not revealed
+		assert.Equal(t, model.ErrCalendarTokenInvalid, err)
+	})
+}
+
+func TestCandidateService_ImportCandidates(t *testing.T) {
+	candidates := []model.Candidate{
+		{Email: "a@a.com", Department: model.Design, University: "METU"},
+		{Email: "b@b.com", Department: model.Design, University: "METU"},
+		{Email: "a@a.com", Department: model.Design, University: "METU"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockCandidateRepository.On("CreateCandidate", mock.Anything, mock.MatchedBy(func(c model.Candidate) bool { return c.Email == "a@a.com" })).
+			Return(func(ctx context.Context, c model.Candidate) model.Candidate { return c }, nil).Once()
+		mockCandidateRepository.On("CreateCandidate", mock.Anything, mock.MatchedBy(func(c model.Candidate) bool { return c.Email == "b@b.com" })).
+			Return(model.Candidate{}, model.ErrCandidateAlreadyExists).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		results := cService.ImportCandidates(context.TODO(), candidates, false)
+
+		assert.NoError(t, results[0].Err)
+		assert.NotEmpty(t, results[0].Candidate.ID)
+		assert.Equal(t, model.Pending, results[0].Candidate.Status)
+		assert.Equal(t, model.ErrCandidateAlreadyExists, results[1].Err)
+		assert.Equal(t, model.ErrCandidateAlreadyExists, results[2].Err)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockAssigneeRepository := new(mocks.AssigneeRepository)
+		mockCandidateRepository.On("FindCandidateByEmail", mock.Anything, "a@a.com").
+			Return(model.Candidate{}, model.ErrCandidateDoesNotExist).Once()
+		mockCandidateRepository.On("FindCandidateByEmail", mock.Anything, "b@b.com").
+			Return(model.Candidate{ID: "abcd"}, nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+		results := cService.ImportCandidates(context.TODO(), candidates, true)
+
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, model.ErrCandidateAlreadyExists, results[1].Err)
+		assert.Equal(t, model.ErrCandidateAlreadyExists, results[2].Err)
+		mockCandidateRepository.AssertNotCalled(t, "CreateCandidate", mock.Anything, mock.Anything)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+}
+
+func TestCandidateService_ExportCandidates(t *testing.T) {
+	mockCandidateRepository := new(mocks.CandidateRepository)
+	mockAssigneeRepository := new(mocks.AssigneeRepository)
+	filter := model.CandidateFilter{Status: model.InProgress}
+	candidates := []model.Candidate{
+		{ID: "c1", Assignee: "a1"},
+		{ID: "c2", Assignee: "a1"},
+		{ID: "c3", Assignee: "deleted"},
+		{ID: "c4"},
+	}
+
+	mockCandidateRepository.On("StreamCandidates", mock.Anything, filter, mock.Anything).
+		Return(func(ctx context.Context, filter model.CandidateFilter, fn func(model.Candidate) error) error {
+			for _, candidate := range candidates {
+				if err := fn(candidate); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Once()
+	mockAssigneeRepository.On("ReadAssignee", mock.Anything, "a1").Return(model.Assignee{ID: "a1", Name: "Sercan"}, nil).Once()
+	mockAssigneeRepository.On("ReadAssignee", mock.Anything, "deleted").Return(model.Assignee{}, model.ErrAssigneeDoesNotExist).Once()
+
+	cService := CandidateService(mockCandidateRepository, mockAssigneeRepository)
+	var names []string
+	err := cService.ExportCandidates(context.TODO(), filter, func(candidate model.ExportedCandidate) error {
+		names = append(names, candidate.AssigneeName)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Sercan", "Sercan", "", ""}, names)
+	mockCandidateRepository.AssertExpectations(t)
+	mockAssigneeRepository.AssertExpectations(t)
+}
+
+func TestCandidateService_ExpirePendingCandidates(t *testing.T) {
+	appliedBefore := time.Now().AddDate(0, -1, 0)
+	pendingCandidate := model.Candidate{ID: "123asd123", Email: "e@e.com", Status: model.Pending,
+		ApplicationDate: appliedBefore.AddDate(0, -1, 0), Version: 3}
+	arrangedCandidate := pendingCandidate
+	arrangedCandidate.ID = "456asd456"
+	nextMeeting := time.Now().Add(time.Hour)
+	arrangedCandidate.NextMeeting = &nextMeeting
+
+	t.Run("success", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockEvents := new(mocks.EventPublisher)
+		mockCandidateRepository.On("FindCandidatesToExpire", mock.Anything, appliedBefore).
+			Return([]model.Candidate{pendingCandidate, arrangedCandidate}, nil).Once()
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, pendingCandidate.ID).Return(pendingCandidate, nil).Once()
+		mockCandidateRepository.On("UpdateCandidate", mock.Anything, pendingCandidate.ID, mock.MatchedBy(func(c model.Candidate) bool {
+			return c.Status == model.Expired && c.Version == pendingCandidate.Version
+		})).Return(nil).Once()
+		// a meeting was arranged for the candidate after it was found, so it is not expired
+		mockCandidateRepository.On("ReadCandidate", mock.Anything, arrangedCandidate.ID).Return(arrangedCandidate, nil).Once()
+		mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(event model.DomainEvent) bool {
+			return event.Type == model.CandidateExpiredEvent && event.Candidate.ID == pendingCandidate.ID &&
+				event.Candidate.Status == model.Expired
+		})).Return(nil).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository), WithEvents(mockEvents))
+		expired, err := cService.ExpirePendingCandidates(context.TODO(), appliedBefore)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		mockCandidateRepository.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockCandidateRepository := new(mocks.CandidateRepository)
+		mockCandidateRepository.On("FindCandidatesToExpire", mock.Anything, appliedBefore).
+			Return(nil, errors.New("mongo: connection refused")).Once()
+
+		cService := CandidateService(mockCandidateRepository, new(mocks.AssigneeRepository))
+		expired, err := cService.ExpirePendingCandidates(context.TODO(), appliedBefore)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, expired)
+		mockCandidateRepository.AssertExpectations(t)
+	})
+}
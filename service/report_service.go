@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"go.opentelemetry.io/otel/attribute"
+	"time"
+)
+
+// defaultWorkloadRange is the time range of the completed interviews in the workload report if it is not given
+const defaultWorkloadRange = 30 * 24 * time.Hour
+
+type reportService struct {
+	reportRepository model.ReportRepository
+}
+
+// ReportService will create an implementation of ReportService interface
+func ReportService(reportRepository model.ReportRepository) model.ReportService {
+	return &reportService{
+		reportRepository: reportRepository,
+	}
+}
+
+// FunnelReport counts the candidates by their status and their completed meetings, broken down by department and university
+func (service *reportService) FunnelReport(ctx context.Context) (model.FunnelReport, error) {
+	ctx, span := tracer.Start(ctx, "reportService.FunnelReport")
+	defer span.End()
+
+	return service.reportRepository.FunnelReport(ctx)
+}
+
+// TimeToHireReport finds the median and the 90th percentile of the times from the application of the candidates to their decision
+func (service *reportService) TimeToHireReport(ctx context.Context) (model.TimeToHireReport, error) {
+	ctx, span := tracer.Start(ctx, "reportService.TimeToHireReport")
+	defer span.End()
+
+	return service.reportRepository.TimeToHireReport(ctx)
+}
+
+// AssigneeWorkloadReport finds the upcoming meetings, the completed interviews and the open candidates of the assignees.
+// The interviews completed in the last 30 days are counted, unless the time range is given.
+func (service *reportService) AssigneeWorkloadReport(ctx context.Context, filter model.WorkloadFilter) (model.AssigneeWorkloadReport, error) {
+	ctx, span := tracer.Start(ctx, "reportService.AssigneeWorkloadReport")
+	defer span.End()
+	span.SetAttributes(attribute.String("assignee.department", filter.Department))
+
+	now := time.Now()
+	if filter.To.IsZero() {
+		filter.To = now
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultWorkloadRange)
+	}
+	if !filter.From.Before(filter.To) {
+		return model.AssigneeWorkloadReport{}, model.ErrValidationFailed.WithDetails([]model.FieldError{
+			{Field: "from", Rule: "ltfield", Message: "from must be before to"},
+		})
+	}
+
+	workloads, err := service.reportRepository.AssigneeWorkloads(ctx, filter, now)
+	if err != nil {
+		return model.AssigneeWorkloadReport{}, err
+	}
+
+	return model.AssigneeWorkloadReport{
+		From: filter.From,
+		To: filter.To,
+		Assignees: workloads,
+	}, nil
+}
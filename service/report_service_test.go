@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/model/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestReportService_AssigneeWorkloadReport(t *testing.T) {
+	workloads := []model.AssigneeWorkload{{Assignee: model.Assignee{ID: "a1", Name: "Zafer", Department: model.Design}, UpcomingMeetings: 2}}
+
+	t.Run("last-30-days", func(t *testing.T) {
+		mockReportRepository := new(mocks.ReportRepository)
+		mockReportRepository.On("AssigneeWorkloads", mock.Anything, mock.MatchedBy(func(filter model.WorkloadFilter) bool {
+			return filter.Department == model.Design && filter.To.Sub(filter.From) == 30*24*time.Hour && time.Since(filter.To) < time.Minute
+		}), mock.AnythingOfType("time.Time")).Return(workloads, nil).Once()
+
+		rService := ReportService(mockReportRepository)
+		report, err := rService.AssigneeWorkloadReport(context.TODO(), model.WorkloadFilter{Department: model.Design})
+
+		assert.NoError(t, err)
+		assert.Equal(t, workloads, report.Assignees)
+		assert.Equal(t, 30*24*time.Hour, report.To.Sub(report.From))
+		mockReportRepository.AssertExpectations(t)
+	})
+
+	t.Run("time-range", func(t *testing.T) {
+		filter := model.WorkloadFilter{From: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)}
+		mockReportRepository := new(mocks.ReportRepository)
+		mockReportRepository.On("AssigneeWorkloads", mock.Anything, filter, mock.AnythingOfType("time.Time")).Return(workloads, nil).Once()
+
+		rService := ReportService(mockReportRepository)
+		report, err := rService.AssigneeWorkloadReport(context.TODO(), filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, filter.From, report.From)
+		assert.Equal(t, filter.To, report.To)
+		mockReportRepository.AssertExpectations(t)
+	})
+
+	t.Run("from-after-to", func(t *testing.T) {
+		mockReportRepository := new(mocks.ReportRepository)
+
+		rService := ReportService(mockReportRepository)
+		_, err := rService.AssigneeWorkloadReport(context.TODO(), model.WorkloadFilter{From: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)})
+
+		assert.ErrorIs(t, err, model.ErrValidationFailed)
+		mockReportRepository.AssertNotCalled(t, "AssigneeWorkloads", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
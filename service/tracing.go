@@ -0,0 +1,7 @@
+package service
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/cemalunal/sample-internship-management-api/service")
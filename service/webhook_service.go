@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"log"
+	"time"
+)
+
+// webhookSecretLength is the number of random bytes of the webhook secrets
+const webhookSecretLength = 32
+
+// maxDeliveriesListed is the number of the latest deliveries that are listed for a webhook
+const maxDeliveriesListed = 100
+
+type webhookService struct {
+	webhookRepository model.WebhookRepository
+}
+
+// WebhookService will create an implementation of WebhookService interface
+func WebhookService(webhookRepository model.WebhookRepository) model.WebhookService {
+	return &webhookService{
+		webhookRepository: webhookRepository,
+	}
+}
+
+// CreateWebhook creates the webhook with a new secret, and returns it along with the secret
+func (service *webhookService) CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.CreateWebhook")
+	defer span.End()
+
+	random := make([]byte, webhookSecretLength)
+	if _, err := rand.Read(random); err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhook.ID = primitive.NewObjectID().Hex()
+	webhook.Secret = base64.RawURLEncoding.EncodeToString(random)
+	webhook.CreatedAt = time.Now()
+	span.SetAttributes(attribute.String("webhook.id", webhook.ID))
+
+	return service.webhookRepository.CreateWebhook(ctx, webhook)
+}
+
+func (service *webhookService) ReadWebhook(ctx context.Context, id string) (model.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.ReadWebhook")
+	defer span.End()
+	span.SetAttributes(attribute.String("webhook.id", id))
+
+	webhook, err := service.webhookRepository.ReadWebhook(ctx, id)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (service *webhookService) FindAllWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.FindAllWebhooks")
+	defer span.End()
+
+	webhooks, err := service.webhookRepository.FindAllWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook updates the url, the events and the pausing of the webhook, it keeps the secret of the webhook
+func (service *webhookService) UpdateWebhook(ctx context.Context, id string, webhook model.Webhook) (model.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.UpdateWebhook")
+	defer span.End()
+	span.SetAttributes(attribute.String("webhook.id", id))
+
+	w, err := service.webhookRepository.ReadWebhook(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return model.Webhook{}, err
+	}
+
+	w.URL = webhook.URL
+	w.Events = webhook.Events
+	w.Paused = webhook.Paused
+
+	if err := service.webhookRepository.UpdateWebhook(ctx, w); err != nil {
+		return model.Webhook{}, err
+	}
+
+	w.Secret = ""
+	return w, nil
+}
+
+// DeleteWebhook deletes the webhook, and the deliveries to the webhook that are not delivered yet are dropped
+func (service *webhookService) DeleteWebhook(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "webhookService.DeleteWebhook")
+	defer span.End()
+	span.SetAttributes(attribute.String("webhook.id", id))
+
+	return service.webhookRepository.DeleteWebhook(ctx, id)
+}
+
+// FindWebhooksDeliveries finds the latest deliveries of the webhook, the newest first
+func (service *webhookService) FindWebhooksDeliveries(ctx context.Context, id string) ([]model.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.FindWebhooksDeliveries")
+	defer span.End()
+	span.SetAttributes(attribute.String("webhook.id", id))
+
+	// Check webhook exists with given id, return error if does not exist.
+	if _, err := service.webhookRepository.ReadWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return service.webhookRepository.FindWebhooksDeliveries(ctx, id, maxDeliveriesListed)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/model/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	mockWebhookRepository := new(mocks.WebhookRepository)
+	mockWebhookRepository.On("CreateWebhook", mock.Anything, mock.AnythingOfType("model.Webhook")).
+		Return(func(ctx context.Context, webhook model.Webhook) model.Webhook { return webhook }, nil).Once()
+
+	wService := WebhookService(mockWebhookRepository)
+	webhook, err := wService.CreateWebhook(context.TODO(), model.Webhook{URL: "https://ats.example.com/hooks", Events: []string{model.CandidateCreatedEvent}, Secret: "chosen"})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, webhook.ID)
+	assert.False(t, webhook.CreatedAt.IsZero())
+	// the secret is always generated
+	assert.Len(t, webhook.Secret, 43)
+	assert.NotEqual(t, "chosen", webhook.Secret)
+}
+
+func TestWebhookService_ReadWebhook(t *testing.T) {
+	mockWebhookRepository := new(mocks.WebhookRepository)
+	mockWebhookRepository.On("ReadWebhook", mock.Anything, "w1").Return(model.Webhook{ID: "w1", Secret: "s3cr3t"}, nil).Once()
+	mockWebhookRepository.On("FindAllWebhooks", mock.Anything).Return([]model.Webhook{{ID: "w1", Secret: "s3cr3t"}}, nil).Once()
+
+	wService := WebhookService(mockWebhookRepository)
+	webhook, err := wService.ReadWebhook(context.TODO(), "w1")
+	assert.NoError(t, err)
+	assert.Empty(t, webhook.Secret)
+
+	webhooks, err := wService.FindAllWebhooks(context.TODO())
+	assert.NoError(t, err)
+	assert.Empty(t, webhooks[0].Secret)
+}
+
+func TestWebhookService_UpdateWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		stored := model.Webhook{ID: "w1", URL: "https://old.example.com", Events: []string{model.CandidateCreatedEvent}, Secret: "s3cr3t"}
+		mockWebhookRepository := new(mocks.WebhookRepository)
+		mockWebhookRepository.On("ReadWebhook", mock.Anything, "w1").Return(stored, nil).Once()
+		mockWebhookRepository.On("UpdateWebhook", mock.Anything, model.Webhook{ID: "w1", URL: "https://new.example.com",
+			Events: []string{model.MeetingArrangedEvent}, Paused: true, Secret: "s3cr3t"}).Return(nil).Once()
+
+		wService := WebhookService(mockWebhookRepository)
+		webhook, err := wService.UpdateWebhook(context.TODO(), "w1", model.Webhook{ID: "other", URL: "https://new.example.com",
+			Events: []string{model.MeetingArrangedEvent}, Paused: true, Secret: "changed"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "w1", webhook.ID)
+		assert.Empty(t, webhook.Secret)
+		mockWebhookRepository.AssertExpectations(t)
+	})
+
+	t.Run("webhook-does-not-exist", func(t *testing.T) {
+		mockWebhookRepository := new(mocks.WebhookRepository)
+		mockWebhookRepository.On("ReadWebhook", mock.Anything, "w1").Return(model.Webhook{}, model.ErrWebhookDoesNotExist).Once()
+
+		wService := WebhookService(mockWebhookRepository)
+		_, err := wService.UpdateWebhook(context.TODO(), "w1", model.Webhook{})
+
+		assert.Equal(t, model.ErrWebhookDoesNotExist, err)
+	})
+}
+
+func TestWebhookService_FindWebhooksDeliveries(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockWebhookRepository := new(mocks.WebhookRepository)
+		mockWebhookRepository.On("ReadWebhook", mock.Anything, "w1").Return(model.Webhook{ID: "w1"}, nil).Once()
+		mockWebhookRepository.On("FindWebhooksDeliveries", mock.Anything, "w1", maxDeliveriesListed).
+			Return([]model.WebhookDelivery{{ID: "d1", WebhookID: "w1"}}, nil).Once()
+
+		wService := WebhookService(mockWebhookRepository)
+		deliveries, err := wService.FindWebhooksDeliveries(context.TODO(), "w1")
+
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+	})
+
+	t.Run("webhook-does-not-exist", func(t *testing.T) {
+		mockWebhookRepository := new(mocks.WebhookRepository)
+		mockWebhookRepository.On("ReadWebhook", mock.Anything, "w1").Return(model.Webhook{}, model.ErrWebhookDoesNotExist).Once()
+
+		wService := WebhookService(mockWebhookRepository)
+		_, err := wService.FindWebhooksDeliveries(context.TODO(), "w1")
+
+		assert.Equal(t, model.ErrWebhookDoesNotExist, err)
+	})
+}
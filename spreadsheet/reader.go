@@ -0,0 +1,124 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"strings"
+)
+
+// supported spreadsheet formats
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+// maxUnzipSize is the number of bytes that an XLSX file may decompress to, so a small file that decompresses
+// to gigabytes is rejected before it is read
+const maxUnzipSize = 64 << 20
+
+// utf8BOM is written by spreadsheet applications at the beginning of the CSV files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ReadRows reads the rows of a CSV file, or the first sheet of an XLSX file, up to the given number of rows.
+// The rows after the limit are not read, all rows are read if the limit is zero.
+// The empty lines are returned as empty rows, so the first row is always the first line.
+func ReadRows(r io.Reader, format string, limit int) ([][]string, error) {
+	switch strings.ToLower(format) {
+	case CSV:
+		return readCSV(r, limit)
+	case XLSX:
+		return readXLSX(r, limit)
+	}
+
+	return nil, fmt.Errorf("unknown spreadsheet format %q, expected csv or xlsx", format)
+}
+
+// readCSV reads the rows of a CSV file. Files that are separated with semicolons,
+// like the ones that are exported with some locales, are detected from their header.
+func readCSV(r io.Reader, limit int) ([][]string, error) {
+	reader := bufio.NewReader(r)
+	if bom, _ := reader.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+		_, _ = reader.Discard(len(utf8BOM))
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	if header, _ := reader.Peek(4096); isSemicolonSeparated(header) {
+		csvReader.Comma = ';'
+	}
+
+	// the blank lines are skipped by the csv reader, they are kept as empty rows,
+	// so the index of each row is the line of the file it starts at
+	var rows [][]string
+	for limit <= 0 || len(rows) < limit {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		for len(rows) < line-1 {
+			rows = append(rows, []string{})
+		}
+		rows = append(rows, record)
+	}
+
+	// the empty rows before the last row may go over the limit
+	return rows[:limit], nil
+}
+
+func isSemicolonSeparated(data []byte) bool {
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+
+	return bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(","))
+}
+
+// readXLSX reads the rows of the first sheet one by one, rather than reading the whole sheet at once.
+// Like the spreadsheet applications, the empty rows at the end of the sheet are not returned.
+func readXLSX(r io.Reader, limit int) ([][]string, error) {
+	file, err := excelize.OpenReader(r, excelize.Options{UnzipSizeLimit: maxUnzipSize})
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("the spreadsheet does not have any sheets")
+	}
+
+	sheet, err := file.Rows(sheets[0])
+	if err != nil {
+		return nil, err
+	}
+	defer sheet.Close()
+
+	var rows [][]string
+	filled := 0
+	for (limit <= 0 || len(rows) < limit) && sheet.Next() {
+		row, err := sheet.Columns()
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+		if len(row) > 0 {
+			filled = len(rows)
+		}
+	}
+	if err := sheet.Error(); err != nil {
+		return nil, err
+	}
+
+	return rows[:filled], nil
+}
@@ -0,0 +1,94 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"strings"
+)
+
+// sheet is the name of the sheet that the rows are written to in the XLSX files
+const sheet = "Sheet1"
+
+// Writer writes rows to a CSV or XLSX file
+type Writer interface {
+	Write(row []string) error
+	// Close completes the file. CSV rows are written as they come, while XLSX files
+	// can only be written to the underlying writer when they are closed.
+	Close() error
+}
+
+// NewWriter creates a writer of the given format, csv or xlsx
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch strings.ToLower(format) {
+	case CSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case XLSX:
+		file := excelize.NewFile()
+		stream, err := file.NewStreamWriter(sheet)
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		return &xlsxWriter{w: w, file: file, stream: stream}, nil
+	}
+
+	return nil, fmt.Errorf("unknown spreadsheet format %q, expected csv or xlsx", format)
+}
+
+// ContentType returns the media type of the given format
+func ContentType(format string) string {
+	if strings.ToLower(format) == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(row []string) error {
+	return w.writer.Write(row)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// xlsxWriter keeps the rows in the temporary files of the stream writer until it is closed
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	rows   int
+}
+
+func (w *xlsxWriter) Write(row []string) error {
+	w.rows++
+	cell, err := excelize.CoordinatesToCellName(1, w.rows)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+
+	_, err := w.file.WriteTo(w.w)
+	return err
+}
@@ -0,0 +1,126 @@
+package stream
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"sync"
+	"time"
+)
+
+// defaults of the broadcaster
+const (
+	// defaultHistory is the number of the latest events that are kept to resume the streams
+	defaultHistory = 1000
+	// subscriberBuffer is the number of events that a subscriber can fall behind before it is closed
+	subscriberBuffer = 64
+)
+
+// subscriber is a client of the stream
+type subscriber struct {
+	filter model.EventFilter
+	events chan model.DomainEvent
+}
+
+// Broadcaster streams the events that it handles to its subscribers, and keeps the latest events,
+// so the clients that reconnect are sent the events that they have missed.
+// The events are kept in memory, so a client cannot resume its stream after the application restarts.
+type Broadcaster struct {
+	mutex       sync.Mutex
+	history     []model.DomainEvent
+	subscribers map[*subscriber]bool
+
+	// History is the number of the latest events that are kept
+	History int
+}
+
+// NewBroadcaster creates a Broadcaster without any subscribers
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[*subscriber]bool),
+		History:     defaultHistory,
+	}
+}
+
+// Handle keeps the event and sends it to the subscribers whose filters match it. It is subscribed to the events
+// that are streamed, see model.GetStreamEventsAsArray. A subscriber that has fallen behind is closed instead of
+// blocking the others, so its client reconnects and is sent the events that it has missed.
+func (broadcaster *Broadcaster) Handle(ctx context.Context, event model.DomainEvent) error {
+	broadcaster.mutex.Lock()
+	defer broadcaster.mutex.Unlock()
+
+	broadcaster.history = append(broadcaster.history, event)
+	if len(broadcaster.history) > broadcaster.History {
+		broadcaster.history = append([]model.DomainEvent{}, broadcaster.history[len(broadcaster.history)-broadcaster.History:]...)
+	}
+
+	for s := range broadcaster.subscribers {
+		if !matches(s.filter, event) {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			delete(broadcaster.subscribers, s)
+			close(s.events)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe subscribes to the events that match the filter, starting with the kept events after lastEventID
+func (broadcaster *Broadcaster) Subscribe(filter model.EventFilter, lastEventID string) (<-chan model.DomainEvent, func()) {
+	broadcaster.mutex.Lock()
+	defer broadcaster.mutex.Unlock()
+
+	var missed []model.DomainEvent
+	if lastEventID != "" {
+		missed = []model.DomainEvent{{Type: model.StreamResetEvent, OccurredAt: time.Now()}}
+		for i, event := range broadcaster.history {
+			if event.ID == lastEventID {
+				missed = nil
+				for _, event := range broadcaster.history[i+1:] {
+					if matches(filter, event) {
+						missed = append(missed, event)
+					}
+				}
+				break
+			}
+		}
+	}
+
+	s := &subscriber{filter: filter, events: make(chan model.DomainEvent, len(missed)+subscriberBuffer)}
+	for _, event := range missed {
+		s.events <- event
+	}
+	broadcaster.subscribers[s] = true
+
+	unsubscribe := func() {
+		broadcaster.mutex.Lock()
+		defer broadcaster.mutex.Unlock()
+
+		if broadcaster.subscribers[s] {
+			delete(broadcaster.subscribers, s)
+			close(s.events)
+		}
+	}
+	return s.events, unsubscribe
+}
+
+// matches checks the event is of a candidate in the department of the filter, and of the assignee of the filter,
+// who is either the assignee of the candidate or of the meeting of the event
+func matches(filter model.EventFilter, event model.DomainEvent) bool {
+	if event.Candidate == nil {
+		return filter.Department == "" && filter.AssigneeID == ""
+	}
+	if filter.Department != "" && event.Candidate.Department != filter.Department {
+		return false
+	}
+	if filter.AssigneeID != "" && event.Candidate.Assignee != filter.AssigneeID &&
+		(event.Meeting == nil || event.Meeting.AssigneeID != filter.AssigneeID) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"context"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBroadcaster(t *testing.T) {
+	design := model.Candidate{ID: "abcd", Department: model.Design, Assignee: "a1"}
+	development := model.Candidate{ID: "efgh", Department: model.Development}
+	created := model.DomainEvent{ID: "e1", Type: model.CandidateCreatedEvent, Candidate: &development}
+	arranged := model.DomainEvent{ID: "e2", Type: model.MeetingArrangedEvent, Candidate: &development, Meeting: &model.MeetingRecord{AssigneeID: "a2"}}
+	denied := model.DomainEvent{ID: "e3", Type: model.CandidateDeniedEvent, Candidate: &design}
+
+	t.Run("filters", func(t *testing.T) {
+		broadcaster := NewBroadcaster()
+		all, unsubscribeAll := broadcaster.Subscribe(model.EventFilter{}, "")
+		defer unsubscribeAll()
+		designs, unsubscribeDesigns := broadcaster.Subscribe(model.EventFilter{Department: model.Design}, "")
+		defer unsubscribeDesigns()
+		assignees, unsubscribeAssignees := broadcaster.Subscribe(model.EventFilter{AssigneeID: "a2"}, "")
+		defer unsubscribeAssignees()
+
+		for _, event := range []model.DomainEvent{created, arranged, denied} {
+			assert.NoError(t, broadcaster.Handle(context.TODO(), event))
+		}
+
+		assert.Equal(t, []string{"e1", "e2", "e3"}, receive(all))
+		assert.Equal(t, []string{"e3"}, receive(designs))
+		// the assignee of the meeting matches the filter
+		assert.Equal(t, []string{"e2"}, receive(assignees))
+	})
+
+	t.Run("resumes-after-last-event", func(t *testing.T) {
+		broadcaster := NewBroadcaster()
+		for _, event := range []model.DomainEvent{created, arranged, denied} {
+			_ = broadcaster.Handle(context.TODO(), event)
+		}
+
+		events, unsubscribe := broadcaster.Subscribe(model.EventFilter{Department: model.Development}, "e1")
+		defer unsubscribe()
+
+		assert.Equal(t, []string{"e2"}, receive(events))
+	})
+
+	t.Run("resets-unknown-last-event", func(t *testing.T) {
+		broadcaster := NewBroadcaster()
+		broadcaster.History = 2
+		for _, event := range []model.DomainEvent{created, arranged, denied} {
+			_ = broadcaster.Handle(context.TODO(), event)
+		}
+
+		// the first event is not kept anymore
+		events, unsubscribe := broadcaster.Subscribe(model.EventFilter{}, "e1")
+		defer unsubscribe()
+
+		event := <-events
+		assert.Equal(t, model.StreamResetEvent, event.Type)
+		assert.Empty(t, receive(events))
+	})
+
+	t.Run("closes-slow-subscriber", func(t *testing.T) {
+		broadcaster := NewBroadcaster()
+		events, unsubscribe := broadcaster.Subscribe(model.EventFilter{}, "")
+		defer unsubscribe()
+
+		for i := 0; i <= subscriberBuffer; i++ {
+			_ = broadcaster.Handle(context.TODO(), created)
+		}
+
+		received := 0
+		for range events {
+			received++
+		}
+		assert.Equal(t, subscriberBuffer, received)
+	})
+}
+
+// receive returns the ids of the events that are waiting in the channel
+func receive(events <-chan model.DomainEvent) []string {
+	ids := []string{}
+	for {
+		select {
+		case event := <-events:
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
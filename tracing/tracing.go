@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"log"
+	"os"
+)
+
+const defaultServiceName = "sample-internship-management-api"
+
+// Init configures the global tracer provider and the W3C trace-context propagator.
+// The exporter is chosen with the OTEL_TRACES_EXPORTER environment variable:
+//   - stdout: spans are pretty printed to the standard output
+//   - otlp:   spans are sent to an OTLP/HTTP collector, configured with the
+//             standard OTEL_EXPORTER_OTLP_* variables (localhost:4318 by default)
+//   - none:   no tracer provider is installed, so the spans are neither recorded nor exported (default)
+// The returned function flushes and stops the tracer provider.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporterName := os.Getenv("OTEL_TRACES_EXPORTER")
+	if exporterName == "" {
+		log.Println("OTEL_TRACES_EXPORTER is not set, traces will not be exported")
+		exporterName = "none"
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName {
+	case "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER: %s", exporterName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	log.Printf("Exporting traces of %s with the %s exporter\n", serviceName, exporterName)
+
+	return provider.Shutdown, nil
+}
+
+// RecordError marks the given span as failed with the given error
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errAddressNotAllowed is returned when the url of a webhook resolves to an address that is not public
+var errAddressNotAllowed = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, which some clouds use for their metadata services
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// newClient creates the client of the deliveries, which connects only to the addresses that are allowed.
+// The addresses are checked when they are dialed, after the host of the url is resolved, so a webhook cannot
+// reach the internal network by a host name that resolves to it. Redirects are not followed, the redirect
+// response is the response of the delivery.
+func newClient(timeout time.Duration, allowed func(ip net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return errAddressNotAllowed
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		// the transport doesn't use the proxy of the environment, which would be dialed instead of the webhook
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddress reports whether the ip is a public address, which excludes the loopback, private,
+// link-local (such as the metadata service at 169.254.169.254), multicast and unspecified addresses
+func publicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/queue"
+	"github.com/cemalunal/sample-internship-management-api/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var tracer = otel.Tracer("github.com/cemalunal/sample-internship-management-api/webhook")
+
+// headers of the deliveries
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// defaultTimeout is the timeout of the deliveries
+const defaultTimeout = 10 * time.Second
+
+// Payload is the JSON body of the deliveries. ID is the id of the event, so it is the same in the deliveries of the event
+// to different webhooks and in the retries of a delivery. Meeting is set for the arranged, rescheduled and cancelled meetings.
+type Payload struct {
+	ID         string               `json:"id"`
+	Event      string               `json:"event"`
+	OccurredAt time.Time            `json:"occurred_at"`
+	Candidate  model.Candidate      `json:"candidate"`
+	Meeting    *model.MeetingRecord `json:"meeting,omitempty"`
+}
+
+// Dispatcher delivers the events of the candidates to the webhooks that are subscribed to them. The deliveries are
+// stored when the events happen, and they are posted by Run in the background, so a failing webhook never fails
+// the change of the candidate. Failed deliveries are retried with an exponential backoff until MaxAttempts.
+// The deliveries are posted only to the public addresses, see newClient.
+type Dispatcher struct {
+	queue.Worker
+	repository model.WebhookRepository
+	now        func() time.Time
+
+	Client *http.Client
+}
+
+// NewDispatcher creates a Dispatcher of the webhooks in the repository
+func NewDispatcher(repository model.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		Worker:     queue.NewWorker(),
+		repository: repository,
+		now:        time.Now,
+		Client:     newClient(defaultTimeout, publicAddress),
+	}
+}
+
+// Handle stores a delivery of the event for each webhook that is subscribed to it. It is subscribed to the events
+// that the webhooks can be subscribed to, see model.GetWebhookEventsAsArray. The ids of the deliveries are derived
+// from the id of the event, so an event that is handled again, or by each replica, is delivered once.
+func (dispatcher *Dispatcher) Handle(ctx context.Context, event model.DomainEvent) error {
+	if event.Candidate == nil {
+		return nil
+	}
+
+	ctx, span := tracer.Start(ctx, "Dispatcher.Handle")
+	defer span.End()
+	span.SetAttributes(attribute.String("webhook.event", event.Type), attribute.String("candidate.id", event.Candidate.ID))
+
+	webhooks, err := dispatcher.repository.FindWebhooksByEvent(ctx, event.Type)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	now := dispatcher.now()
+	payload, err := json.Marshal(Payload{
+		ID:         event.ID,
+		Event:      event.Type,
+		OccurredAt: event.OccurredAt,
+		Candidate:  *event.Candidate,
+		Meeting:    event.Meeting,
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		_, err := dispatcher.repository.CreateDelivery(ctx, model.WebhookDelivery{
+			ID:            deliveryID(event.ID, webhook.ID),
+			WebhookID:     webhook.ID,
+			Event:         event.Type,
+			Payload:       string(payload),
+			Status:        model.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			tracing.RecordError(span, err)
+			return err
+		}
+	}
+
+	// the events are delivered right away, instead of waiting for the next poll
+	dispatcher.Wake()
+
+	return nil
+}
+
+// Run delivers the due deliveries until the context is done. The deliveries are polled on each PollInterval,
+// and right after a delivery is stored by this process.
+func (dispatcher *Dispatcher) Run(ctx context.Context) {
+	dispatcher.Poll(ctx, "webhook deliveries", dispatcher.Dispatch)
+}
+
+// Dispatch posts the deliveries that are due, and returns the number of deliveries that are delivered
+func (dispatcher *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	return queue.Dispatch(ctx, func(ctx context.Context) (model.WebhookDelivery, bool, error) {
+		return dispatcher.repository.ClaimDelivery(ctx, dispatcher.now(), dispatcher.Lease)
+	}, dispatcher.deliver)
+}
+
+// deliver posts the claimed delivery to its webhook, and records the outcome of the attempt
+func (dispatcher *Dispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery) bool {
+	ctx, span := tracer.Start(ctx, "Dispatcher.deliver")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("webhook.id", delivery.WebhookID),
+		attribute.String("webhook.delivery.id", delivery.ID),
+		attribute.String("webhook.event", delivery.Event),
+		attribute.Int("webhook.delivery.attempt", delivery.Attempts),
+	)
+
+	webhook, err := dispatcher.repository.ReadWebhook(ctx, delivery.WebhookID)
+	if errors.Is(err, model.ErrWebhookDoesNotExist) {
+		// the webhook is deleted after the event, it is not retried
+		delivery.Attempts = dispatcher.MaxAttempts
+	}
+	if err == nil {
+		delivery.ResponseStatus, err = dispatcher.post(ctx, webhook, delivery)
+	}
+
+	now := dispatcher.now()
+	if err == nil {
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		log.Printf("Couldn't deliver %s to webhook %s, attempt %d. Error is: %s\n", delivery.ID, delivery.WebhookID, delivery.Attempts, err)
+		tracing.RecordError(span, err)
+		delivery.LastError = err.Error()
+		var failed bool
+		delivery.NextAttemptAt, failed = dispatcher.Retry(now, delivery.Attempts)
+		if failed {
+			delivery.Status = model.DeliveryFailed
+		}
+	}
+
+	if err := dispatcher.repository.UpdateDelivery(ctx, delivery); err != nil {
+		log.Println("Couldn't record the outcome of webhook delivery: ", err)
+	}
+
+	return err == nil
+}
+
+// post sends the payload of the delivery to the webhook, signed with the secret of the webhook.
+// It returns the status of the response, and an error unless the response is successful.
+func (dispatcher *Dispatcher) post(ctx context.Context, webhook model.Webhook, delivery model.WebhookDelivery) (int, error) {
+	timestamp := dispatcher.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "internship-management-webhooks")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	res, err := dispatcher.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// the connection is reused only if the body is read to the end. The body is not recorded,
+	// so the responses of the webhooks are not exposed through their deliveries.
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook responded with %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Sign returns the signature of the payload that is sent at the given unix timestamp, which is
+// sha256= followed by the hex encoded HMAC-SHA256 of the timestamp, a dot and the payload, keyed with the secret.
+// The receivers compute the same signature to verify that the delivery is sent by this application,
+// and reject the old timestamps so the deliveries cannot be replayed.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliveryID derives the id of the delivery of the event to the webhook, it is as long as the ids of the events
+func deliveryID(eventID string, webhookID string) string {
+	hash := sha256.Sum256([]byte(eventID + "/" + webhookID))
+	return hex.EncodeToString(hash[:12])
+}
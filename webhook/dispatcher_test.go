@@ -0,0 +1,197 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cemalunal/sample-internship-management-api/model"
+	"github.com/cemalunal/sample-internship-management-api/repository"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver records the deliveries that it receives, and fails the given number of deliveries first
+type receiver struct {
+	mutex    sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (receiver *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	if receiver.failures > 0 {
+		receiver.failures--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	receiver.requests = append(receiver.requests, req)
+	receiver.bodies = append(receiver.bodies, body)
+}
+
+func TestDispatcher(t *testing.T) {
+	candidate := model.Candidate{ID: "abcd", FirstName: "Ayşe", Email: "ayse@e.com", Department: model.Design}
+
+	newDispatcher := func(receiver *receiver, events ...string) (*Dispatcher, model.WebhookRepository, func()) {
+		server := httptest.NewServer(receiver)
+		webhookRepository := repository.MemoryWebhookRepository()
+		_, _ = webhookRepository.CreateWebhook(context.TODO(), model.Webhook{ID: "w1", URL: server.URL, Events: events, Secret: "s3cr3t"})
+		dispatcher := NewDispatcher(webhookRepository)
+		// the test server listens on the loopback address
+		dispatcher.Client = newClient(time.Second, func(ip net.IP) bool { return true })
+		return dispatcher, webhookRepository, server.Close
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		receiver := &receiver{}
+		dispatcher, webhookRepository, closeServer := newDispatcher(receiver, model.CandidateCreatedEvent)
+		defer closeServer()
+
+		assert.NoError(t, dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e1", Type: model.CandidateCreatedEvent, Candidate: &candidate}))
+		// the webhook is not subscribed to the event
+		assert.NoError(t, dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e2", Type: model.CandidateDeniedEvent, Candidate: &candidate}))
+
+		delivered, err := dispatcher.Dispatch(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+
+		req, body := receiver.requests[0], receiver.bodies[0]
+		assert.Equal(t, model.CandidateCreatedEvent, req.Header.Get(EventHeader))
+		timestamp, _ := strconv.ParseInt(req.Header.Get(TimestampHeader), 10, 64)
+		assert.Equal(t, Sign("s3cr3t", timestamp, body), req.Header.Get(SignatureHeader))
+		var payload Payload
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, model.CandidateCreatedEvent, payload.Event)
+		assert.Equal(t, "e1", payload.ID)
+		assert.Equal(t, "abcd", payload.Candidate.ID)
+
+		deliveries, _ := webhookRepository.FindWebhooksDeliveries(context.TODO(), "w1", 10)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, model.DeliveryDelivered, deliveries[0].Status)
+		assert.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+		assert.Equal(t, req.Header.Get(DeliveryHeader), deliveries[0].ID)
+	})
+
+	t.Run("retried", func(t *testing.T) {
+		receiver := &receiver{failures: 1}
+		dispatcher, webhookRepository, closeServer := newDispatcher(receiver, model.MeetingCompletedEvent)
+		defer closeServer()
+		now := time.Now()
+		dispatcher.now = func() time.Time { return now }
+		_ = dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e1", Type: model.MeetingCompletedEvent, Candidate: &candidate})
+
+		delivered, _ := dispatcher.Dispatch(context.TODO())
+		assert.Equal(t, 0, delivered)
+		deliveries, _ := webhookRepository.FindWebhooksDeliveries(context.TODO(), "w1", 10)
+		assert.Equal(t, model.DeliveryPending, deliveries[0].Status)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].ResponseStatus)
+		// the body of the response is not recorded
+		assert.Equal(t, "webhook responded with 503 Service Unavailable", deliveries[0].LastError)
+
+		// the first retry is delayed by 30 seconds
+		now = now.Add(30 * time.Second)
+		delivered, _ = dispatcher.Dispatch(context.TODO())
+		assert.Equal(t, 1, delivered)
+		assert.Len(t, receiver.requests, 1)
+	})
+
+	t.Run("failed-after-max-attempts", func(t *testing.T) {
+		receiver := &receiver{failures: 100}
+		dispatcher, webhookRepository, closeServer := newDispatcher(receiver, model.CandidateCreatedEvent)
+		defer closeServer()
+		dispatcher.MaxAttempts = 1
+		_ = dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e1", Type: model.CandidateCreatedEvent, Candidate: &candidate})
+
+		_, _ = dispatcher.Dispatch(context.TODO())
+		deliveries, _ := webhookRepository.FindWebhooksDeliveries(context.TODO(), "w1", 10)
+		assert.Equal(t, model.DeliveryFailed, deliveries[0].Status)
+	})
+
+	t.Run("private-address", func(t *testing.T) {
+		receiver := &receiver{}
+		_, webhookRepository, closeServer := newDispatcher(receiver, model.CandidateCreatedEvent)
+		defer closeServer()
+		dispatcher := NewDispatcher(webhookRepository)
+		_ = dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e1", Type: model.CandidateCreatedEvent, Candidate: &candidate})
+
+		delivered, _ := dispatcher.Dispatch(context.TODO())
+		assert.Equal(t, 0, delivered)
+		assert.Len(t, receiver.requests, 0)
+		deliveries, _ := webhookRepository.FindWebhooksDeliveries(context.TODO(), "w1", 10)
+		assert.Contains(t, deliveries[0].LastError, errAddressNotAllowed.Error())
+	})
+
+	t.Run("redirect-is-not-followed", func(t *testing.T) {
+		receiver := &receiver{}
+		redirect := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data/", http.StatusFound))
+		defer redirect.Close()
+		dispatcher, webhookRepository, closeServer := newDispatcher(receiver, model.CandidateCreatedEvent)
+		defer closeServer()
+		_ = webhookRepository.UpdateWebhook(context.TODO(), model.Webhook{ID: "w1", URL: redirect.URL, Events: []string{model.CandidateCreatedEvent}, Secret: "s3cr3t"})
+		_ = dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e1", Type: model.CandidateCreatedEvent, Candidate: &candidate})
+
+		delivered, _ := dispatcher.Dispatch(context.TODO())
+		assert.Equal(t, 0, delivered)
+		deliveries, _ := webhookRepository.FindWebhooksDeliveries(context.TODO(), "w1", 10)
+		assert.Equal(t, http.StatusFound, deliveries[0].ResponseStatus)
+	})
+
+	t.Run("handled-again", func(t *testing.T) {
+		receiver := &receiver{}
+		dispatcher, webhookRepository, closeServer := newDispatcher(receiver, model.CandidateCreatedEvent)
+		defer closeServer()
+
+		// e.g. the event is retried by the outbox, or handled by each replica
+		_ = dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e1", Type: model.CandidateCreatedEvent, Candidate: &candidate})
+		_ = dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e1", Type: model.CandidateCreatedEvent, Candidate: &candidate})
+
+		deliveries, _ := webhookRepository.FindWebhooksDeliveries(context.TODO(), "w1", 10)
+		assert.Len(t, deliveries, 1)
+	})
+
+	t.Run("paused", func(t *testing.T) {
+		receiver := &receiver{}
+		dispatcher, webhookRepository, closeServer := newDispatcher(receiver, model.CandidateCreatedEvent)
+		defer closeServer()
+		webhook, _ := webhookRepository.ReadWebhook(context.TODO(), "w1")
+		webhook.Paused = true
+		_ = webhookRepository.UpdateWebhook(context.TODO(), webhook)
+
+		_ = dispatcher.Handle(context.TODO(), model.DomainEvent{ID: "e1", Type: model.CandidateCreatedEvent, Candidate: &candidate})
+		deliveries, _ := webhookRepository.FindWebhooksDeliveries(context.TODO(), "w1", 10)
+		assert.Len(t, deliveries, 0)
+	})
+}
+
+func TestPublicAddress(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:2800::1":    true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.0.0.1":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.100.100.200": false,
+		"0.0.0.0":         false,
+		"::ffff:10.0.0.1": false,
+		"fd00::1":         false,
+	} {
+		assert.Equal(t, public, publicAddress(net.ParseIP(address)), address)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// the signature is the HMAC-SHA256 of "1588334400.{}" keyed with "secret", as computed by openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=5d0a6b8030dff9e7ea85b8b00fcbf1a0099fff30c8d3a9f82df4772b6ea2cfe2", Sign("secret", 1588334400, []byte("{}")))
+}